@@ -139,6 +139,9 @@ func (*Message) XXX_OneofWrappers() []interface{} {
 
 // SnapshotsRequest is sent to request a snapshot.
 type SnapshotsRequest struct {
+	// The snapshot formats supported by the requesting node, which must only be
+	// offered snapshots in these formats. If empty, all formats are supported.
+	Formats []uint32 `protobuf:"varint,1,rep,packed,name=formats,proto3" json:"formats,omitempty"`
 }
 
 func (m *SnapshotsRequest) Reset()         { *m = SnapshotsRequest{} }
@@ -174,6 +177,13 @@ func (m *SnapshotsRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_SnapshotsRequest proto.InternalMessageInfo
 
+func (m *SnapshotsRequest) GetFormats() []uint32 {
+	if m != nil {
+		return m.Formats
+	}
+	return nil
+}
+
 // SnapshotsResponse contains the snapshot metadata.
 type SnapshotsResponse struct {
 	Height   uint64 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
@@ -400,32 +410,33 @@ func init() {
 func init() { proto.RegisterFile("cometbft/statesync/v1/types.proto", fileDescriptor_95fd383b29885bb3) }
 
 var fileDescriptor_95fd383b29885bb3 = []byte{
-	// 399 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x53, 0xcd, 0x6a, 0xdb, 0x40,
-	0x18, 0x94, 0xfc, 0xcf, 0x57, 0xab, 0xd8, 0x4b, 0x5b, 0x44, 0x0f, 0xa2, 0x55, 0x0b, 0xf5, 0x49,
-	0xc2, 0x2d, 0xf4, 0x01, 0xdc, 0x8b, 0x29, 0x18, 0xca, 0xb6, 0x14, 0x92, 0x4b, 0x58, 0xcb, 0x6b,
-	0x49, 0x04, 0xfd, 0xc4, 0xdf, 0xca, 0xc4, 0x0f, 0x90, 0x53, 0x2e, 0x79, 0xac, 0x1c, 0x7d, 0xcc,
-	0x29, 0x04, 0xfb, 0x45, 0x82, 0x56, 0x3f, 0x51, 0x1c, 0x27, 0x21, 0x90, 0xdb, 0xce, 0x30, 0x1e,
-	0xcf, 0x8c, 0xf8, 0xe0, 0xb3, 0x13, 0x05, 0x5c, 0x4c, 0xe7, 0xc2, 0x46, 0xc1, 0x04, 0xc7, 0x55,
-	0xe8, 0xd8, 0xcb, 0xa1, 0x2d, 0x56, 0x31, 0x47, 0x2b, 0x5e, 0x44, 0x22, 0x22, 0xef, 0x0b, 0x89,
-	0x55, 0x4a, 0xac, 0xe5, 0xd0, 0xbc, 0xae, 0x41, 0x7b, 0xc2, 0x11, 0x99, 0xcb, 0xc9, 0x7f, 0xe8,
-	0x63, 0xc8, 0x62, 0xf4, 0x22, 0x81, 0x47, 0x0b, 0x7e, 0x92, 0x70, 0x14, 0xba, 0xfa, 0x49, 0x1d,
-	0xbc, 0xf9, 0xfe, 0xcd, 0xda, 0xfb, 0x73, 0xeb, 0x6f, 0xa1, 0xa7, 0x99, 0x7c, 0xac, 0xd0, 0x1e,
-	0xee, 0x70, 0xe4, 0x00, 0x48, 0xd5, 0x17, 0xe3, 0x28, 0x44, 0xae, 0xd7, 0xa4, 0xf1, 0xe0, 0x79,
-	0xe3, 0x4c, 0x3f, 0x56, 0x68, 0x1f, 0x77, 0x49, 0xf2, 0x1b, 0x34, 0xc7, 0x4b, 0xc2, 0xe3, 0x32,
-	0x6e, 0x5d, 0xba, 0x7e, 0x79, 0xc4, 0xf5, 0x57, 0xaa, 0xbd, 0x8b, 0xda, 0x75, 0x2a, 0x98, 0x4c,
-	0xe0, 0x6d, 0xe1, 0x95, 0x47, 0x6c, 0x48, 0xb3, 0xaf, 0x4f, 0x9b, 0x95, 0xf1, 0x34, 0xa7, 0x4a,
-	0x8c, 0x9a, 0x50, 0xc7, 0x24, 0x30, 0x09, 0xf4, 0x76, 0x47, 0x32, 0xcf, 0x55, 0xe8, 0x3f, 0x28,
-	0x48, 0x3e, 0x40, 0xcb, 0xe3, 0xbe, 0xeb, 0x65, 0x9b, 0x37, 0x68, 0x8e, 0x52, 0x7e, 0x1e, 0x2d,
-	0x02, 0x26, 0xe4, 0x64, 0x1a, 0xcd, 0x51, 0xca, 0xcb, 0x7f, 0x44, 0x59, 0x5a, 0xa3, 0x39, 0x22,
-	0x04, 0x1a, 0x1e, 0x43, 0x4f, 0xa6, 0xef, 0x52, 0xf9, 0x26, 0x1f, 0xa1, 0x13, 0x70, 0xc1, 0x66,
-	0x4c, 0x30, 0xbd, 0x29, 0xf9, 0x12, 0x9b, 0xff, 0xa0, 0x5b, 0xdd, 0xe5, 0xc5, 0x39, 0xde, 0x41,
-	0xd3, 0x0f, 0x67, 0xfc, 0x34, 0x8f, 0x91, 0x01, 0xf3, 0x4c, 0x05, 0xed, 0xde, 0x42, 0xaf, 0xe3,
-	0x9b, 0xb2, 0xb2, 0x67, 0x5e, 0x2f, 0x03, 0x44, 0x87, 0x76, 0xe0, 0x23, 0xfa, 0xa1, 0x2b, 0xeb,
-	0x75, 0x68, 0x01, 0x47, 0x7f, 0x2e, 0x37, 0x86, 0xba, 0xde, 0x18, 0xea, 0xcd, 0xc6, 0x50, 0x2f,
-	0xb6, 0x86, 0xb2, 0xde, 0x1a, 0xca, 0xd5, 0xd6, 0x50, 0x0e, 0x7f, 0xba, 0xbe, 0xf0, 0x92, 0x69,
-	0xfa, 0x75, 0xed, 0xf2, 0x7e, 0xca, 0x07, 0x8b, 0x7d, 0x7b, 0xef, 0x55, 0x4d, 0x5b, 0xf2, 0xa0,
-	0x7e, 0xdc, 0x06, 0x00, 0x00, 0xff, 0xff, 0x99, 0x49, 0xe4, 0x5a, 0x75, 0x03, 0x00, 0x00,
+	// 408 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x53, 0x4f, 0x8b, 0xd3, 0x40,
+	0x1c, 0xcd, 0xf4, 0x3f, 0x3f, 0x1b, 0x69, 0x07, 0x95, 0xe0, 0x21, 0xd4, 0x28, 0xd8, 0x83, 0x24,
+	0x54, 0xc1, 0x0f, 0x50, 0x2f, 0x45, 0x28, 0xc8, 0x28, 0x82, 0x5e, 0x64, 0x9a, 0x4e, 0x93, 0x20,
+	0xf9, 0x63, 0x7f, 0x93, 0x62, 0x3f, 0x80, 0x27, 0x2f, 0x7e, 0x2c, 0x8f, 0x3d, 0x7a, 0x92, 0xa5,
+	0xfd, 0x22, 0x4b, 0x26, 0x7f, 0x36, 0xdb, 0xed, 0xee, 0xb2, 0xb0, 0xb7, 0x79, 0x8f, 0x37, 0x8f,
+	0xf7, 0xde, 0x30, 0xf0, 0xcc, 0x8d, 0x43, 0x21, 0x17, 0x2b, 0xe9, 0xa0, 0xe4, 0x52, 0xe0, 0x36,
+	0x72, 0x9d, 0xcd, 0xc4, 0x91, 0xdb, 0x44, 0xa0, 0x9d, 0xac, 0x63, 0x19, 0xd3, 0xc7, 0xa5, 0xc4,
+	0xae, 0x24, 0xf6, 0x66, 0x62, 0xfd, 0x6f, 0x40, 0x77, 0x2e, 0x10, 0xb9, 0x27, 0xe8, 0x67, 0x18,
+	0x62, 0xc4, 0x13, 0xf4, 0x63, 0x89, 0xdf, 0xd6, 0xe2, 0x47, 0x2a, 0x50, 0x1a, 0x64, 0x44, 0xc6,
+	0x0f, 0x5e, 0xbf, 0xb4, 0x4f, 0x5e, 0xb7, 0x3f, 0x96, 0x7a, 0x96, 0xcb, 0x67, 0x1a, 0x1b, 0xe0,
+	0x11, 0x47, 0xbf, 0x00, 0xad, 0xfb, 0x62, 0x12, 0x47, 0x28, 0x8c, 0x86, 0x32, 0x1e, 0xdf, 0x6e,
+	0x9c, 0xeb, 0x67, 0x1a, 0x1b, 0xe2, 0x31, 0x49, 0xdf, 0x83, 0xee, 0xfa, 0x69, 0xf4, 0xbd, 0x8a,
+	0xdb, 0x54, 0xae, 0xcf, 0xaf, 0x71, 0x7d, 0x97, 0x69, 0x2f, 0xa2, 0xf6, 0xdd, 0x1a, 0xa6, 0x73,
+	0x78, 0x58, 0x7a, 0x15, 0x11, 0x5b, 0xca, 0xec, 0xc5, 0xcd, 0x66, 0x55, 0x3c, 0xdd, 0xad, 0x13,
+	0xd3, 0x36, 0x34, 0x31, 0x0d, 0xad, 0x57, 0x30, 0x38, 0x1e, 0x89, 0x1a, 0xd0, 0x5d, 0xc5, 0xeb,
+	0x90, 0x4b, 0x34, 0xc8, 0xa8, 0x39, 0xd6, 0x59, 0x09, 0xad, 0xdf, 0x04, 0x86, 0x57, 0xaa, 0xd3,
+	0x27, 0xd0, 0xf1, 0x45, 0xe0, 0xf9, 0xf9, 0x6b, 0xb4, 0x58, 0x81, 0x32, 0x3e, 0xbf, 0xa8, 0xc6,
+	0xd4, 0x59, 0x81, 0x32, 0x5e, 0x65, 0x41, 0x35, 0x87, 0xce, 0x0a, 0x44, 0x29, 0xb4, 0x7c, 0x8e,
+	0xbe, 0xea, 0xd5, 0x67, 0xea, 0x4c, 0x9f, 0x42, 0x2f, 0x14, 0x92, 0x2f, 0xb9, 0xe4, 0x46, 0x5b,
+	0xf1, 0x15, 0xb6, 0x3e, 0x41, 0xbf, 0xbe, 0xd8, 0x9d, 0x73, 0x3c, 0x82, 0x76, 0x10, 0x2d, 0xc5,
+	0xcf, 0x22, 0x46, 0x0e, 0xac, 0x5f, 0x04, 0xf4, 0x4b, 0xdb, 0xdd, 0x8f, 0x6f, 0xc6, 0xaa, 0x9e,
+	0x45, 0xbd, 0x1c, 0x64, 0x5b, 0x87, 0x01, 0x62, 0x10, 0x79, 0xaa, 0x5e, 0x8f, 0x95, 0x70, 0xfa,
+	0xe1, 0xef, 0xde, 0x24, 0xbb, 0xbd, 0x49, 0xce, 0xf6, 0x26, 0xf9, 0x73, 0x30, 0xb5, 0xdd, 0xc1,
+	0xd4, 0xfe, 0x1d, 0x4c, 0xed, 0xeb, 0x5b, 0x2f, 0x90, 0x7e, 0xba, 0xc8, 0xde, 0xdd, 0xa9, 0x7e,
+	0x56, 0x75, 0xe0, 0x49, 0xe0, 0x9c, 0xfc, 0x6f, 0x8b, 0x8e, 0xfa, 0x6a, 0x6f, 0xce, 0x07, 0x00,
+	0x19, 0x9c, 0x68, 0x0f, 0x8f, 0x03, 0x00, 0x00,
 }
 
 func (m *Message) Marshal() (dAtA []byte, err error) {
@@ -564,6 +575,24 @@ func (m *SnapshotsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Formats) > 0 {
+		dAtA6 := make([]byte, len(m.Formats)*10)
+		var j5 int
+		for _, num := range m.Formats {
+			for num >= 1<<7 {
+				dAtA6[j5] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j5++
+			}
+			dAtA6[j5] = uint8(num)
+			j5++
+		}
+		i -= j5
+		copy(dAtA[i:], dAtA6[:j5])
+		i = encodeVarintTypes(dAtA, i, uint64(j5))
+		i--
+		dAtA[i] = 0xa
+	}
 	return len(dAtA) - i, nil
 }
 
@@ -789,6 +818,13 @@ func (m *SnapshotsRequest) Size() (n int) {
 	}
 	var l int
 	_ = l
+	if len(m.Formats) > 0 {
+		l = 0
+		for _, e := range m.Formats {
+			l += sovTypes(uint64(e))
+		}
+		n += 1 + sovTypes(uint64(l)) + l
+	}
 	return n
 }
 
@@ -1086,6 +1122,82 @@ func (m *SnapshotsRequest) Unmarshal(dAtA []byte) error {
 			return fmt.Errorf("proto: SnapshotsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType == 0 {
+				var v uint32
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowTypes
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= uint32(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Formats = append(m.Formats, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowTypes
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthTypes
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthTypes
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.Formats) == 0 {
+					m.Formats = make([]uint32, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint32
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowTypes
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= uint32(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Formats = append(m.Formats, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Formats", wireType)
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -60,11 +60,12 @@ var (
 	home               string
 	maxOpenConnections int
 
-	sequential     bool
-	trustingPeriod time.Duration
-	trustedHeight  int64
-	trustedHash    []byte
-	trustLevelStr  string
+	sequential      bool
+	trustingPeriod  time.Duration
+	trustedHeight   int64
+	trustedHash     []byte
+	trustLevelStr   string
+	parallelFetches int
 
 	verbose bool
 
@@ -97,6 +98,10 @@ func init() {
 	LightCmd.Flags().BoolVar(&sequential, "sequential", false,
 		"sequential verification. Verify all headers sequentially as opposed to using skipping verification",
 	)
+	LightCmd.Flags().IntVar(&parallelFetches, "parallel-fetches", 0,
+		"maximum number of light blocks fetched in parallel from the primary and witnesses during skipping verification. "+
+			"0 or 1 fetches them one at a time from the primary",
+	)
 }
 
 func runProxy(_ *cobra.Command, args []string) error {
@@ -168,7 +173,10 @@ func runProxy(_ *cobra.Command, args []string) error {
 	if sequential {
 		options = append(options, light.SequentialVerification())
 	} else {
-		options = append(options, light.SkippingVerification(trustLevel))
+		options = append(options,
+			light.SkippingVerification(trustLevel),
+			light.ParallelFetching(parallelFetches),
+		)
 	}
 
 	var c *light.Client
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	cmtos "github.com/cometbft/cometbft/internal/os"
+	"github.com/cometbft/cometbft/privval"
+)
+
+// ExportValidatorStateCmd exports the last sign state of this node's
+// validator, to migrate the validator to another node.
+var ExportValidatorStateCmd = &cobra.Command{
+	Use:   "export-validator-state [file]",
+	Short: "Export this node's validator state to a file, to migrate the validator to another node",
+	Long: `Export the last signed height, round and step of this node's validator to a file,
+to be imported by the node the validator is migrated to with import-validator-state.
+The node must be stopped first, and must not be restarted with this validator.`,
+	Args: cobra.ExactArgs(1),
+	RunE: exportValidatorState,
+}
+
+// ImportValidatorStateCmd imports the last sign state of a validator exported
+// by another node.
+var ImportValidatorStateCmd = &cobra.Command{
+	Use:   "import-validator-state [file]",
+	Short: "Import a validator state exported by another node",
+	Long: `Import the last signed height, round and step of this node's validator from a file
+written by export-validator-state on the node the validator is migrated from. The
+validator doesn't sign anything until the migration is confirmed with
+confirm-validator-migration.`,
+	Args: cobra.ExactArgs(1),
+	RunE: importValidatorState,
+}
+
+// ConfirmValidatorMigrationCmd allows this node's validator to sign again after
+// importing its state.
+var ConfirmValidatorMigrationCmd = &cobra.Command{
+	Use:   "confirm-validator-migration",
+	Short: "Confirm that the node a validator state was exported from is down",
+	Long: `Allow this node's validator to sign again after importing its state with
+import-validator-state. Only confirm once you made sure that the node the state was
+exported from is down, otherwise the validator may double sign.`,
+	Args: cobra.NoArgs,
+	RunE: confirmValidatorMigration,
+}
+
+func loadFilePVForMigration() (*privval.FilePV, error) {
+	keyFilePath := config.PrivValidatorKeyFile()
+	if !cmtos.FileExists(keyFilePath) {
+		return nil, fmt.Errorf("private validator file %s does not exist", keyFilePath)
+	}
+	return privval.LoadFilePV(keyFilePath, config.PrivValidatorStateFile()), nil
+}
+
+func exportValidatorState(_ *cobra.Command, args []string) error {
+	pv, err := loadFilePVForMigration()
+	if err != nil {
+		return err
+	}
+	if err := pv.ExportState(args[0]); err != nil {
+		return fmt.Errorf("failed to export validator state: %w", err)
+	}
+	logger.Info("Exported validator state", "file", args[0], "height", pv.LastSignState.Height,
+		"round", pv.LastSignState.Round, "step", pv.LastSignState.Step)
+	return nil
+}
+
+func importValidatorState(_ *cobra.Command, args []string) error {
+	pv, err := loadFilePVForMigration()
+	if err != nil {
+		return err
+	}
+	if err := pv.ImportState(args[0]); err != nil {
+		return fmt.Errorf("failed to import validator state: %w", err)
+	}
+	logger.Info("Imported validator state; confirm the migration once the node it was exported from is down",
+		"file", args[0], "height", pv.LastSignState.Height, "round", pv.LastSignState.Round,
+		"step", pv.LastSignState.Step, "exportTime", pv.LastSignState.MigrationExportTime)
+	return nil
+}
+
+func confirmValidatorMigration(*cobra.Command, []string) error {
+	pv, err := loadFilePVForMigration()
+	if err != nil {
+		return err
+	}
+	if pv.LastSignState.MigrationExportTime == nil {
+		return errors.New("no validator migration to confirm")
+	}
+	pv.ConfirmMigration()
+	logger.Info("Confirmed validator migration")
+	return nil
+}
@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	cfg "github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/state/indexer"
+	blockidxnull "github.com/cometbft/cometbft/state/indexer/block/null"
+	"github.com/cometbft/cometbft/state/txindex"
+	"github.com/cometbft/cometbft/state/txindex/null"
+)
+
+var (
+	pruneRetainHeight int64
+	pruneToHeight     int64
+	pruneDryRun       bool
+)
+
+func init() {
+	PruneCmd.Flags().Int64Var(&pruneRetainHeight, "retain-height", 0,
+		"set the application block retain height before pruning (default: keep the stored one)")
+	PruneCmd.Flags().Int64Var(&pruneToHeight, "to-height", 0,
+		"prune the blocks below this height once, without changing the stored retain heights")
+	PruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false,
+		"print how many heights would be pruned, and an estimate of the disk space freed, without pruning")
+	PruneCmd.MarkFlagsMutuallyExclusive("retain-height", "to-height")
+}
+
+// PruneCmd prunes the stores of a stopped node once, up to their retain
+// heights.
+var PruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "prune the blocks, states, ABCI results and indexers of a stopped node",
+	Long: `
+prune is an offline tool that prunes the stores of the node once, up to their
+retain heights, as the pruner of a running node would, and prints what was
+pruned. Run it while the node is stopped to reclaim disk space without starting
+the node. It refuses to run if the databases are locked by a running node.
+
+The application block retain height stored by the node is used, unless a higher
+one is set with --retain-height. The ABCI results and the indexers are only
+pruned if the data companion is enabled.
+
+With --to-height, only the blocks below the given height and their states are
+pruned, whatever the application block retain height, which is left unchanged.
+If the data companion is enabled, the blocks it still needs are kept.
+
+With --dry-run, nothing is pruned: the number of heights whose blocks would be
+pruned, and an estimate of the disk space they take, are printed instead.
+	`,
+	Example: `
+	cometbft prune
+	cometbft prune --retain-height 1000
+	cometbft prune --to-height 1000
+	cometbft prune --to-height 1000 --dry-run
+	`,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if pruneDryRun {
+			return estimatePruning(config, pruneRetainHeight, pruneToHeight)
+		}
+		var (
+			info *state.PrunedInfo
+			err  error
+		)
+		if pruneToHeight != 0 {
+			info, err = pruneOnce(config, pruneToHeight)
+		} else {
+			info, err = pruneNow(config, pruneRetainHeight)
+		}
+		if info != nil {
+			printPrunedInfo(info)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to prune: %w", err)
+		}
+		return nil
+	},
+}
+
+// errNodeRunning is returned if the databases are locked, most likely by a
+// running node.
+var errNodeRunning = errors.New("the databases are locked, make sure that the node is not running")
+
+// pruneNow prunes the stores of the node once with a pruner configured as the
+// node's, after setting the application block retain height to retainHeight,
+// unless it is 0.
+func pruneNow(config *cfg.Config, retainHeight int64) (*state.PrunedInfo, error) {
+	var info *state.PrunedInfo
+	err := withPruner(config, func(pruner *state.Pruner) error {
+		if retainHeight != 0 {
+			if err := pruner.SetApplicationBlockRetainHeight(retainHeight); err != nil {
+				return fmt.Errorf("failed to set retain height %d: %w", retainHeight, err)
+			}
+		}
+		var err error
+		info, err = pruner.PruneOnce(context.Background())
+		return err
+	})
+	return info, err
+}
+
+// pruneOnce prunes the blocks of the node below height, and their states, once
+// with a pruner configured as the node's.
+func pruneOnce(config *cfg.Config, height int64) (*state.PrunedInfo, error) {
+	var info *state.PrunedInfo
+	err := withPruner(config, func(pruner *state.Pruner) error {
+		var err error
+		info, err = pruner.PruneToHeight(height)
+		return err
+	})
+	return info, err
+}
+
+// estimatePruning prints what pruning the blocks of the node would prune, up to
+// toHeight if it is not 0, or else up to the application block retain height,
+// or retainHeight if it is higher, without pruning anything.
+func estimatePruning(config *cfg.Config, retainHeight, toHeight int64) error {
+	return withPruner(config, func(pruner *state.Pruner) error {
+		height := toHeight
+		if height == 0 {
+			appRetainHeight, err := pruner.GetApplicationRetainHeight()
+			if err != nil && !errors.Is(err, state.ErrKeyNotFound) {
+				return fmt.Errorf("failed to get the application block retain height: %w", err)
+			}
+			height = max(appRetainHeight, retainHeight)
+		}
+		if height == 0 {
+			fmt.Println("Nothing to prune")
+			return nil
+		}
+		estimate, err := pruner.EstimatePruning(height)
+		if err != nil {
+			return fmt.Errorf("failed to estimate pruning: %w", err)
+		}
+		printPruningEstimate(estimate)
+		return nil
+	})
+}
+
+// withPruner opens the stores of the node, and calls fn with a pruner
+// configured as the node's, before closing them. It returns errNodeRunning if
+// the stores are locked by a running node.
+func withPruner(config *cfg.Config, fn func(*state.Pruner) error) error {
+	blockStore, stateStore, err := loadStateAndBlockStore(config)
+	if err != nil {
+		return checkDBLocked(err)
+	}
+	defer func() {
+		_ = blockStore.Close()
+		_ = stateStore.Close()
+	}()
+
+	blockIndexer, txIndexer, err := loadPrunerIndexers(config, stateStore)
+	if err != nil {
+		return checkDBLocked(err)
+	}
+
+	pruneCfg := config.Storage.Pruning
+	opts := []state.PrunerOption{
+		state.WithPrunerStatePruningRetries(pruneCfg.StatePruningRetries, pruneCfg.StatePruningRetryBackoff),
+		state.WithPrunerTransientErrorRetries(pruneCfg.TransientErrorRetries, pruneCfg.TransientErrorRetryBackoff),
+		state.WithPrunerLease(pruneCfg.LeaseTTL),
+		state.WithPrunerEvidenceMaxAgeBlocks(pruneCfg.EvidenceMaxAgeBlocks),
+		state.WithPrunerVerifyBeforePrune(pruneCfg.VerifyBeforePrune),
+		state.WithPrunerEvidenceWindowCheck(pruneCfg.CheckEvidenceWindow),
+		state.WithPrunerPrefetch(pruneCfg.PrefetchBlocks),
+		state.WithPrunerABCIResponsesDiscarded(config.Storage.DiscardABCIResponses),
+	}
+	if pruneCfg.DataCompanion.Enabled {
+		opts = append(opts,
+			state.WithPrunerCompanionEnabled(),
+			state.WithPrunerCompanionSafetyMargin(pruneCfg.DataCompanion.SafetyMargin),
+		)
+	}
+	pruner, err := state.NewPrunerWithValidation(stateStore, blockStore, blockIndexer, txIndexer, logger, opts...)
+	if err != nil {
+		return err
+	}
+	return fn(pruner)
+}
+
+// loadPrunerIndexers returns the indexers configured for the node, which are
+// only pruned if the data companion is enabled.
+func loadPrunerIndexers(config *cfg.Config, stateStore state.Store) (indexer.BlockIndexer, txindex.TxIndexer, error) {
+	if !config.Storage.Pruning.DataCompanion.Enabled || strings.ToLower(config.TxIndex.Indexer) == "null" {
+		return &blockidxnull.BlockerIndexer{}, &null.TxIndex{}, nil
+	}
+	st, err := stateStore.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	return loadEventSinks(config, st.ChainID)
+}
+
+// checkDBLocked returns errNodeRunning if err was returned because a database
+// is locked by another process.
+func checkDBLocked(err error) error {
+	if errors.Is(err, syscall.EAGAIN) {
+		return fmt.Errorf("%w: %w", errNodeRunning, err)
+	}
+	return err
+}
+
+func printPrunedInfo(info *state.PrunedInfo) {
+	if info.Blocks == nil && info.ABCIResponses == nil && info.Indexer == nil && info.OrphanedBlockParts == 0 {
+		fmt.Println("Nothing to prune")
+		return
+	}
+	if b := info.Blocks; b != nil {
+		fmt.Printf("Pruned blocks from height %d to %d, %d heights remaining\n", b.FromHeight, b.ToHeight, b.RemainingHeights)
+	}
+	if s := info.States; s != nil {
+		fmt.Printf("Pruned states from height %d to %d, including %d validator sets and %d consensus params\n",
+			s.FromHeight, s.ToHeight, s.ValidatorSets, s.ConsensusParams)
+	}
+	if info.OrphanedBlockParts > 0 {
+		fmt.Printf("Pruned %d orphaned block parts below the base\n", info.OrphanedBlockParts)
+	}
+	if info.EvidencePinnedHeights > 0 {
+		fmt.Printf("Kept the headers and commits of %d heights below the base, needed to verify evidence\n",
+			info.EvidencePinnedHeights)
+	}
+	if r := info.ABCIResponses; r != nil {
+		fmt.Printf("Pruned ABCI responses up to height %d, %d heights remaining\n", r.ToHeight, r.RemainingHeights)
+	}
+	if i := info.Indexer; i != nil {
+		fmt.Printf("Pruned the indexers below height %d, %d heights from the tx indexer and %d from the block indexer\n",
+			i.RetainHeight, i.TxIndexerHeights, i.BlockIndexerHeights)
+	}
+}
+
+func printPruningEstimate(estimate *state.PruningEstimate) {
+	if estimate.Heights == 0 {
+		fmt.Println("Nothing to prune")
+		return
+	}
+	if estimate.Bytes < 0 {
+		fmt.Printf("Would prune the blocks below height %d, %d heights\n", estimate.RetainHeight, estimate.Heights)
+		return
+	}
+	fmt.Printf("Would prune the blocks below height %d, %d heights, freeing about %d bytes\n",
+		estimate.RetainHeight, estimate.Heights, estimate.Bytes)
+}
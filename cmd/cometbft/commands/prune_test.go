@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dbm "github.com/cometbft/cometbft-db"
+	cfg "github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/state"
+)
+
+func TestPruneNowRefusesLockedDB(t *testing.T) {
+	config := cfg.TestConfig()
+	dir := t.TempDir()
+	config.SetRoot(dir)
+	cfg.EnsureRoot(dir)
+	config.DBBackend = string(dbm.GoLevelDBBackend)
+
+	stateDB, err := dbm.NewDB("state", dbm.GoLevelDBBackend, config.DBDir())
+	require.NoError(t, err)
+	require.NoError(t, stateDB.Close())
+	// Hold the lock on the block store, as a running node would.
+	blockStoreDB, err := dbm.NewDB("blockstore", dbm.GoLevelDBBackend, config.DBDir())
+	require.NoError(t, err)
+
+	_, err = pruneNow(config, 0)
+	require.ErrorIs(t, err, errNodeRunning)
+	_, err = pruneOnce(config, 1)
+	require.ErrorIs(t, err, errNodeRunning)
+
+	require.NoError(t, blockStoreDB.Close())
+	info, err := pruneNow(config, 0)
+	require.NoError(t, err)
+	require.Nil(t, info.Blocks)
+	require.Nil(t, info.ABCIResponses)
+
+	// There are no blocks to prune.
+	_, err = pruneOnce(config, 1)
+	require.ErrorIs(t, err, state.ErrNoBlocksToPrune)
+}
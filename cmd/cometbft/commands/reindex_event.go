@@ -110,7 +110,7 @@ func loadEventSinks(cfg *cmtcfg.Config, chainID string) (indexer.BlockIndexer, t
 		if conn == "" {
 			return nil, nil, errors.New("the psql connection settings cannot be empty")
 		}
-		es, err := psql.NewEventSink(conn, chainID)
+		es, err := psql.NewEventSink(conn, chainID, psql.WithBatchSize(cfg.TxIndex.PsqlBatchSize))
 		if err != nil {
 			return nil, nil, err
 		}
@@ -90,6 +90,7 @@ func loadStateAndBlockStore(config *cfg.Config) (*store.BlockStore, state.Store,
 	}
 	stateStore := state.NewStore(stateDB, state.StoreOptions{
 		DiscardABCIResponses: config.Storage.DiscardABCIResponses,
+		RetainHeightJournal:  config.Storage.Pruning.RetainHeightJournal,
 	})
 
 	return blockStore, stateStore, nil
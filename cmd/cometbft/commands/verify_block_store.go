@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyFromHeight int64
+	verifyToHeight   int64
+)
+
+func init() {
+	VerifyBlockStoreCmd.Flags().Int64Var(&verifyFromHeight, "from-height", 0,
+		"the first height to verify (default: the base height of the block store)")
+	VerifyBlockStoreCmd.Flags().Int64Var(&verifyToHeight, "to-height", 0,
+		"the last height to verify (default: the latest height of the block store)")
+}
+
+// VerifyBlockStoreCmd verifies that the block store holds complete and
+// consistent data for a range of heights.
+var VerifyBlockStoreCmd = &cobra.Command{
+	Use:     "verify-block-store",
+	Aliases: []string{"verify_block_store"},
+	Short:   "verify the integrity of the block store",
+	Long: `
+verify-block-store is an offline tool that checks that every height of the block
+store has a block meta, all the parts of the block and a commit for the block,
+and that they match each other's hashes, without replaying any block. Run it
+while the node is stopped, e.g. after a crash, to find out whether the block
+store is corrupted.
+
+All the heights of the block store are verified by default. Any problem found is
+printed, and the command fails if there is at least one.
+	`,
+	Example: `
+	cometbft verify-block-store
+	cometbft verify-block-store --from-height 2 --to-height 10
+	`,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		blockStore, stateStore, err := loadStateAndBlockStore(config)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = blockStore.Close()
+			_ = stateStore.Close()
+		}()
+
+		from, to := verifyFromHeight, verifyToHeight
+		if from == 0 {
+			from = blockStore.Base()
+		}
+		if to == 0 {
+			to = blockStore.Height()
+		}
+		problems, err := blockStore.VerifyIntegrity(from, to)
+		if err != nil {
+			return fmt.Errorf("failed to verify block store: %w", err)
+		}
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("found %d problems in the block store between heights %d and %d", len(problems), from, to)
+		}
+		fmt.Printf("Verified block store between heights %d and %d, no problems found\n", from, to)
+		return nil
+	},
+}
@@ -28,6 +28,11 @@ func main() {
 		cmd.RollbackStateCmd,
 		cmd.CompactGoLevelDBCmd,
 		cmd.InspectCmd,
+		cmd.VerifyBlockStoreCmd,
+		cmd.PruneCmd,
+		cmd.ExportValidatorStateCmd,
+		cmd.ImportValidatorStateCmd,
+		cmd.ConfirmValidatorMigrationCmd,
 		debug.DebugCmd,
 		cli.NewCompletionCmd(rootCmd, true),
 	)
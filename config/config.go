@@ -45,6 +45,11 @@ const (
 
 	DefaultPruningInterval = 10 * time.Second
 
+	DefaultStatePruningRetries      = 3
+	DefaultStatePruningRetryBackoff = 100 * time.Millisecond
+
+	DefaultTransientErrorRetryBackoff = 100 * time.Millisecond
+
 	v0 = "v0"
 	v1 = "v1"
 	v2 = "v2"
@@ -104,6 +109,7 @@ type Config struct {
 	Consensus       *ConsensusConfig       `mapstructure:"consensus"`
 	Storage         *StorageConfig         `mapstructure:"storage"`
 	TxIndex         *TxIndexConfig         `mapstructure:"tx_index"`
+	KafkaSink       *KafkaSinkConfig       `mapstructure:"kafka_sink"`
 	Instrumentation *InstrumentationConfig `mapstructure:"instrumentation"`
 }
 
@@ -120,6 +126,7 @@ func DefaultConfig() *Config {
 		Consensus:       DefaultConsensusConfig(),
 		Storage:         DefaultStorageConfig(),
 		TxIndex:         DefaultTxIndexConfig(),
+		KafkaSink:       DefaultKafkaSinkConfig(),
 		Instrumentation: DefaultInstrumentationConfig(),
 	}
 }
@@ -137,6 +144,7 @@ func TestConfig() *Config {
 		Consensus:       TestConsensusConfig(),
 		Storage:         TestStorageConfig(),
 		TxIndex:         TestTxIndexConfig(),
+		KafkaSink:       TestKafkaSinkConfig(),
 		Instrumentation: TestInstrumentationConfig(),
 	}
 }
@@ -181,6 +189,12 @@ func (cfg *Config) ValidateBasic() error {
 	if err := cfg.Storage.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [storage] section: %w", err)
 	}
+	if err := cfg.TxIndex.ValidateBasic(); err != nil {
+		return ErrInSection{Section: "tx_index", Err: err}
+	}
+	if err := cfg.KafkaSink.ValidateBasic(); err != nil {
+		return ErrInSection{Section: "kafka_sink", Err: err}
+	}
 	if err := cfg.Instrumentation.ValidateBasic(); err != nil {
 		return ErrInSection{Section: "instrumentation", Err: err}
 	}
@@ -470,12 +484,37 @@ type RPCConfig struct {
 	// https://www.jsonrpc.org/specification#batch
 	MaxRequestBatchSize int `mapstructure:"max_request_batch_size"`
 
-	// Maximum size of request body, in bytes
+	// Maximum size of request body, in bytes. Larger requests are rejected
+	// with a 413 status code.
 	MaxBodyBytes int64 `mapstructure:"max_body_bytes"`
 
 	// Maximum size of request header, in bytes
 	MaxHeaderBytes int `mapstructure:"max_header_bytes"`
 
+	// Maximum duration for reading a request, including its body.
+	// 0 means no timeout.
+	ReadTimeout time.Duration `mapstructure:"read_timeout"`
+
+	// Maximum duration for writing a response. It is increased to exceed
+	// TimeoutBroadcastTxCommit if needed. 0 means no timeout.
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+
+	// Maximum duration to wait for the next request on a keep-alive
+	// connection. 0 means ReadTimeout is used.
+	IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+
+	// Compress the HTTP responses with gzip for the clients accepting it.
+	// WebSocket connections are never compressed.
+	Compression bool `mapstructure:"compression"`
+
+	// Minimum size of the HTTP responses to compress, in bytes, if Compression
+	// is enabled
+	CompressionMinBytes int `mapstructure:"compression_min_bytes"`
+
+	// Maximum number of responses to ABCI queries with proofs to cache, until
+	// a new height is committed. 0 disables the cache.
+	ABCIQueryCacheSize int `mapstructure:"abci_query_cache_size"`
+
 	// The path to a file containing certificate that is used to create the HTTPS server.
 	// Might be either absolute path or path related to CometBFT's config directory.
 	//
@@ -520,6 +559,15 @@ func DefaultRPCConfig() *RPCConfig {
 		MaxBodyBytes:        int64(1000000), // 1MB
 		MaxHeaderBytes:      1 << 20,        // same as the net/http default
 
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  0, // same as ReadTimeout
+
+		Compression:         false,
+		CompressionMinBytes: 1024, // 1KB
+
+		ABCIQueryCacheSize: 0,
+
 		TLSCertFile: "",
 		TLSKeyFile:  "",
 	}
@@ -566,6 +614,21 @@ func (cfg *RPCConfig) ValidateBasic() error {
 	if cfg.MaxHeaderBytes < 0 {
 		return cmterrors.ErrNegativeField{Field: "max_header_bytes"}
 	}
+	if cfg.ReadTimeout < 0 {
+		return cmterrors.ErrNegativeField{Field: "read_timeout"}
+	}
+	if cfg.WriteTimeout < 0 {
+		return cmterrors.ErrNegativeField{Field: "write_timeout"}
+	}
+	if cfg.IdleTimeout < 0 {
+		return cmterrors.ErrNegativeField{Field: "idle_timeout"}
+	}
+	if cfg.CompressionMinBytes < 0 {
+		return cmterrors.ErrNegativeField{Field: "compression_min_bytes"}
+	}
+	if cfg.ABCIQueryCacheSize < 0 {
+		return cmterrors.ErrNegativeField{Field: "abci_query_cache_size"}
+	}
 	return nil
 }
 
@@ -779,6 +842,11 @@ type P2PConfig struct { //nolint: maligned
 	// List of node IDs, to which a connection will be (re)established ignoring any existing limits
 	UnconditionalPeerIDs string `mapstructure:"unconditional_peer_ids"`
 
+	// Comma separated list of nodes which are always dialed and redialed,
+	// regardless of the address book, ignoring any existing limits. They are
+	// never evicted nor marked as bad.
+	PersistentAllowlistPeers string `mapstructure:"persistent_allowlist_peers"`
+
 	// Maximum pause when redialing a persistent peer (if zero, exponential backoff is used)
 	PersistentPeersMaxDialPeriod time.Duration `mapstructure:"persistent_peers_max_dial_period"`
 
@@ -810,6 +878,18 @@ type P2PConfig struct { //nolint: maligned
 	// Toggle to disable guard against peers connecting from the same ip.
 	AllowDuplicateIP bool `mapstructure:"allow_duplicate_ip"`
 
+	// Set true to evict the inbound peer with the lowest score when the
+	// maximum number of inbound peers is reached, if that score is lower than
+	// the one of the new inbound peer. Otherwise, new inbound peers are
+	// rejected when the maximum is reached.
+	PeerEviction bool `mapstructure:"peer_eviction"`
+
+	// Scores subtracted from the score of a peer, which starts at zero, for
+	// each of its misbehaviors. Zero disables the scoring of a misbehavior.
+	PeerScoreInvalidMessageWeight int64 `mapstructure:"peer_score_invalid_message_weight"`
+	PeerScoreSlowResponseWeight   int64 `mapstructure:"peer_score_slow_response_weight"`
+	PeerScoreDisconnectWeight     int64 `mapstructure:"peer_score_disconnect_weight"`
+
 	// Peer connection configuration.
 	HandshakeTimeout time.Duration `mapstructure:"handshake_timeout"`
 	DialTimeout      time.Duration `mapstructure:"dial_timeout"`
@@ -825,25 +905,29 @@ type P2PConfig struct { //nolint: maligned
 // DefaultP2PConfig returns a default configuration for the peer-to-peer layer.
 func DefaultP2PConfig() *P2PConfig {
 	return &P2PConfig{
-		ListenAddress:                "tcp://0.0.0.0:26656",
-		ExternalAddress:              "",
-		AddrBook:                     defaultAddrBookPath,
-		AddrBookStrict:               true,
-		MaxNumInboundPeers:           40,
-		MaxNumOutboundPeers:          10,
-		PersistentPeersMaxDialPeriod: 0 * time.Second,
-		FlushThrottleTimeout:         10 * time.Millisecond,
-		MaxPacketMsgPayloadSize:      1024,    // 1 kB
-		SendRate:                     5120000, // 5 mB/s
-		RecvRate:                     5120000, // 5 mB/s
-		PexReactor:                   true,
-		SeedMode:                     false,
-		AllowDuplicateIP:             false,
-		HandshakeTimeout:             20 * time.Second,
-		DialTimeout:                  3 * time.Second,
-		TestDialFail:                 false,
-		TestFuzz:                     false,
-		TestFuzzConfig:               DefaultFuzzConnConfig(),
+		ListenAddress:                 "tcp://0.0.0.0:26656",
+		ExternalAddress:               "",
+		AddrBook:                      defaultAddrBookPath,
+		AddrBookStrict:                true,
+		MaxNumInboundPeers:            40,
+		MaxNumOutboundPeers:           10,
+		PersistentPeersMaxDialPeriod:  0 * time.Second,
+		FlushThrottleTimeout:          10 * time.Millisecond,
+		MaxPacketMsgPayloadSize:       1024,    // 1 kB
+		SendRate:                      5120000, // 5 mB/s
+		RecvRate:                      5120000, // 5 mB/s
+		PexReactor:                    true,
+		SeedMode:                      false,
+		AllowDuplicateIP:              false,
+		PeerEviction:                  false,
+		PeerScoreInvalidMessageWeight: 10,
+		PeerScoreSlowResponseWeight:   5,
+		PeerScoreDisconnectWeight:     1,
+		HandshakeTimeout:              20 * time.Second,
+		DialTimeout:                   3 * time.Second,
+		TestDialFail:                  false,
+		TestFuzz:                      false,
+		TestFuzzConfig:                DefaultFuzzConnConfig(),
 	}
 }
 
@@ -884,6 +968,15 @@ func (cfg *P2PConfig) ValidateBasic() error {
 	if cfg.RecvRate < 0 {
 		return cmterrors.ErrNegativeField{Field: "recv_rate"}
 	}
+	if cfg.PeerScoreInvalidMessageWeight < 0 {
+		return cmterrors.ErrNegativeField{Field: "peer_score_invalid_message_weight"}
+	}
+	if cfg.PeerScoreSlowResponseWeight < 0 {
+		return cmterrors.ErrNegativeField{Field: "peer_score_slow_response_weight"}
+	}
+	if cfg.PeerScoreDisconnectWeight < 0 {
+		return cmterrors.ErrNegativeField{Field: "peer_score_disconnect_weight"}
+	}
 	return nil
 }
 
@@ -1063,6 +1156,20 @@ type StateSyncConfig struct {
 	DiscoveryTime       time.Duration `mapstructure:"discovery_time"`
 	ChunkRequestTimeout time.Duration `mapstructure:"chunk_request_timeout"`
 	ChunkFetchers       int32         `mapstructure:"chunk_fetchers"`
+	// The maximum number of chunk requests in flight to a single peer. Chunk
+	// requests are spread evenly across the peers offering a snapshot, up to
+	// this limit, while ChunkFetchers bounds the total number of requests in
+	// flight.
+	MaxChunkRequestsPerPeer int32 `mapstructure:"max_chunk_requests_per_peer"`
+	// The snapshot formats supported by the application. Peers are only asked
+	// for snapshots in these formats, and snapshots in other formats are
+	// rejected. If empty, snapshots in any format are accepted.
+	SnapshotFormats []uint32 `mapstructure:"snapshot_formats"`
+	// Whether to backfill the headers, commits and validator sets of the
+	// heights below the snapshot height after a state sync, as far back as
+	// evidence is valid according to the evidence parameters, so that the node
+	// can verify evidence about these heights.
+	Backfill bool `mapstructure:"backfill"`
 }
 
 func (cfg *StateSyncConfig) TrustHashBytes() []byte {
@@ -1077,10 +1184,11 @@ func (cfg *StateSyncConfig) TrustHashBytes() []byte {
 // DefaultStateSyncConfig returns a default configuration for the state sync service.
 func DefaultStateSyncConfig() *StateSyncConfig {
 	return &StateSyncConfig{
-		TrustPeriod:         168 * time.Hour,
-		DiscoveryTime:       15 * time.Second,
-		ChunkRequestTimeout: 10 * time.Second,
-		ChunkFetchers:       4,
+		TrustPeriod:             168 * time.Hour,
+		DiscoveryTime:           15 * time.Second,
+		ChunkRequestTimeout:     10 * time.Second,
+		ChunkFetchers:           4,
+		MaxChunkRequestsPerPeer: 2,
 	}
 }
 
@@ -1134,6 +1242,10 @@ func (cfg *StateSyncConfig) ValidateBasic() error {
 		if cfg.ChunkFetchers <= 0 {
 			return cmterrors.ErrRequiredField{Field: "chunk_fetchers"}
 		}
+
+		if cfg.MaxChunkRequestsPerPeer <= 0 {
+			return cmterrors.ErrRequiredField{Field: "max_chunk_requests_per_peer"}
+		}
 	}
 
 	return nil
@@ -1202,6 +1314,12 @@ type ConsensusConfig struct {
 	PeerGossipIntraloopSleepDuration time.Duration `mapstructure:"peer_gossip_intraloop_sleep_duration"` // upper bound on randomly selected values
 
 	DoubleSignCheckHeight int64 `mapstructure:"double_sign_check_height"`
+
+	// Maximum size in bytes of the blocks proposed by peers, derived from their
+	// number of parts. Larger proposals are rejected before their parts are
+	// received, whatever the consensus params allow. 0 means no limit other
+	// than the consensus params.
+	MaxProposalBytes int64 `mapstructure:"max_proposal_bytes"`
 }
 
 // DefaultConsensusConfig returns a default configuration for the consensus service.
@@ -1219,6 +1337,7 @@ func DefaultConsensusConfig() *ConsensusConfig {
 		PeerQueryMaj23SleepDuration:      2000 * time.Millisecond,
 		PeerGossipIntraloopSleepDuration: 0 * time.Second,
 		DoubleSignCheckHeight:            int64(0),
+		MaxProposalBytes:                 0,
 	}
 }
 
@@ -1311,6 +1430,9 @@ func (cfg *ConsensusConfig) ValidateBasic() error {
 	if cfg.DoubleSignCheckHeight < 0 {
 		return cmterrors.ErrNegativeField{Field: "double_sign_check_height"}
 	}
+	if cfg.MaxProposalBytes < 0 {
+		return cmterrors.ErrNegativeField{Field: "max_proposal_bytes"}
+	}
 	return nil
 }
 
@@ -1416,6 +1538,11 @@ type TxIndexConfig struct {
 	// The PostgreSQL connection configuration, the connection format:
 	// postgresql://<user>:<password>@<host>:<port>/<db>?<opts>
 	PsqlConn string `mapstructure:"psql-conn"`
+
+	// The maximum number of rows sent to PostgreSQL in a single bulk insert
+	// by the psql indexer. All rows for a block are still written within one
+	// transaction. 0 means no limit.
+	PsqlBatchSize int `mapstructure:"psql-batch-size"`
 }
 
 // DefaultTxIndexConfig returns a default configuration for the transaction indexer.
@@ -1430,6 +1557,97 @@ func TestTxIndexConfig() *TxIndexConfig {
 	return DefaultTxIndexConfig()
 }
 
+// ValidateBasic performs basic validation and returns an error if any check
+// fails.
+func (cfg *TxIndexConfig) ValidateBasic() error {
+	if cfg.PsqlBatchSize < 0 {
+		return cmterrors.ErrNegativeField{Field: "psql-batch-size"}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// KafkaSinkConfig
+
+// KafkaSinkConfig defines the configuration for the optional event sink that
+// publishes new block, transaction and validator set update events to a Kafka
+// topic.
+//
+// The sink is only available in binaries built with the "kafka" build tag.
+type KafkaSinkConfig struct {
+	// Whether events are published to Kafka.
+	Enabled bool `mapstructure:"enabled"`
+
+	// The addresses (host:port) of the Kafka brokers to connect to.
+	Brokers []string `mapstructure:"brokers"`
+
+	// The topic to which events are published.
+	Topic string `mapstructure:"topic"`
+
+	// How many brokers must acknowledge a message before it is considered
+	// published.
+	//
+	// Options:
+	//   1) "none" - do not wait for any acknowledgement.
+	//   2) "leader" - wait for the partition leader only.
+	//   3) "all" (default) - wait for all in-sync replicas.
+	Acks string `mapstructure:"acks"`
+
+	// The maximum number of events buffered while waiting to be published.
+	// Events received while the buffer is full are dropped, so publishing
+	// never blocks consensus.
+	QueueSize int `mapstructure:"queue_size"`
+
+	// The maximum number of times publishing an event is retried before the
+	// event is dropped.
+	MaxRetries int `mapstructure:"max_retries"`
+}
+
+// DefaultKafkaSinkConfig returns a default configuration for the Kafka event
+// sink.
+func DefaultKafkaSinkConfig() *KafkaSinkConfig {
+	return &KafkaSinkConfig{
+		Enabled:    false,
+		Brokers:    []string{},
+		Topic:      "cometbft-events",
+		Acks:       "all",
+		QueueSize:  1000,
+		MaxRetries: 5,
+	}
+}
+
+// TestKafkaSinkConfig returns a default configuration for the Kafka event
+// sink.
+func TestKafkaSinkConfig() *KafkaSinkConfig {
+	return DefaultKafkaSinkConfig()
+}
+
+// ValidateBasic performs basic validation and returns an error if any check
+// fails.
+func (cfg *KafkaSinkConfig) ValidateBasic() error {
+	if cfg.QueueSize < 0 {
+		return cmterrors.ErrNegativeField{Field: "queue_size"}
+	}
+	if cfg.MaxRetries < 0 {
+		return cmterrors.ErrNegativeField{Field: "max_retries"}
+	}
+	switch cfg.Acks {
+	case "none", "leader", "all":
+	default:
+		return fmt.Errorf("unknown acks mode %q, must be one of \"none\", \"leader\" or \"all\"", cfg.Acks)
+	}
+	if !cfg.Enabled {
+		return nil
+	}
+	if len(cfg.Brokers) == 0 {
+		return errors.New("brokers must not be empty when the Kafka sink is enabled")
+	}
+	if cfg.Topic == "" {
+		return errors.New("topic must not be empty when the Kafka sink is enabled")
+	}
+	return nil
+}
+
 // -----------------------------------------------------------------------------
 // InstrumentationConfig
 
@@ -1515,21 +1733,98 @@ func getDefaultMoniker() string {
 type PruningConfig struct {
 	// The time period between automated background pruning operations.
 	Interval time.Duration `mapstructure:"interval"`
+	// The time period between automated background pruning operations of ABCI
+	// results. If 0, ABCI results are pruned every Interval.
+	ABCIResponsesInterval time.Duration `mapstructure:"abci_responses_interval"`
+	// The number of times pruning the state is retried when it fails after the
+	// corresponding blocks have been pruned.
+	StatePruningRetries int `mapstructure:"state_pruning_retries"`
+	// The time to wait before the first retry of pruning the state. It doubles
+	// with every retry.
+	StatePruningRetryBackoff time.Duration `mapstructure:"state_pruning_retry_backoff"`
+	// The number of times pruning blocks or ABCI results is retried within a
+	// run of the pruner when it fails with a transient error of the database.
+	// If 0, it is retried at the next run.
+	TransientErrorRetries int `mapstructure:"transient_error_retries"`
+	// The time to wait before the first retry after a transient error. It
+	// doubles with every retry.
+	TransientErrorRetryBackoff time.Duration `mapstructure:"transient_error_retry_backoff"`
+	// The initial value for the application block retain height if the
+	// application has not yet explicitly set one. If the application has
+	// already set a block retain height, this is ignored. If 0, no initial
+	// value is set.
+	InitialApplicationRetainHeight int64 `mapstructure:"initial_application_retain_height"`
+	// The time to live of the lease the pruner takes on the state database, so
+	// that two nodes misconfigured to use the same database don't both prune
+	// it. If 0, no lease is taken.
+	LeaseTTL time.Duration `mapstructure:"lease_ttl"`
+	// The number of blocks below the tip of the block store under which an
+	// accepted block retain height is logged as an error, as it leaves almost
+	// no history for peers. If 0, no warning is logged.
+	NearTipWarnThreshold int64 `mapstructure:"near_tip_warn_threshold"`
+	// The number of heights by which the ABCI results retain height and the
+	// base of the block store may diverge before the pruner logs a warning,
+	// which indicates a misconfiguration. If 0, no warning is logged.
+	ABCIDivergenceWarnThreshold int64 `mapstructure:"abci_divergence_warn_threshold"`
+	// The maximum number of heights whose ABCI results are pruned at each run,
+	// so that a large raise of their retain height is caught up with in
+	// bounded steps. If 0, they are pruned up to their retain height at once.
+	ABCIResponsesMaxHeightsPerRun int64 `mapstructure:"abci_responses_max_heights_per_run"`
+	// The number of heights whose ABCI results are pruned at the first step of
+	// a catch-up. It doubles with every step, up to
+	// ABCIResponsesMaxHeightsPerRun. If 0, all steps are of
+	// ABCIResponsesMaxHeightsPerRun heights.
+	ABCIResponsesCatchUpStep int64 `mapstructure:"abci_responses_catch_up_step"`
+	// The minimum number of blocks below the latest height whose data needed
+	// to verify evidence is kept when pruning blocks, even if the evidence
+	// parameters let it be pruned earlier. If 0, only the evidence parameters
+	// apply.
+	EvidenceMaxAgeBlocks int64 `mapstructure:"evidence_max_age_blocks"`
+	// Whether to check that the block store holds every block from its base
+	// up to the retain height before pruning blocks, and refuse to prune them
+	// if one is missing, as the block store may be corrupted.
+	VerifyBeforePrune bool `mapstructure:"verify_before_prune"`
+	// Whether to reject the application block retain heights that would prune
+	// blocks within the evidence age window of the consensus parameters,
+	// extended by EvidenceMaxAgeBlocks.
+	CheckEvidenceWindow bool `mapstructure:"check_evidence_window"`
+	// Whether to read the blocks to prune right before deleting them, to warm
+	// the caches of the database and avoid latency spikes when deleting many
+	// blocks on a cold cache. Only beneficial with some database backends.
+	PrefetchBlocks bool `mapstructure:"prefetch_blocks"`
+	// Whether to keep the blocks at and above the height of the lowest state
+	// sync snapshot offered by the application, so that peers restoring it
+	// from this node can fetch what they need.
+	KeepServableSnapshots bool `mapstructure:"keep_servable_snapshots"`
+	// The interval between compactions of the whole state store, whatever
+	// was pruned. If 0, it is only compacted after pruning, if enabled with
+	// Compact.
+	StateCompactionInterval time.Duration `mapstructure:"state_compaction_interval"`
+	// Whether the state store journals every update of the retain heights
+	// with its time, so that the retain heights at a past time can be loaded.
+	// The journal is never pruned.
+	RetainHeightJournal bool `mapstructure:"retain_height_journal"`
 	// Data companion-related pruning configuration.
 	DataCompanion *DataCompanionPruningConfig `mapstructure:"data_companion"`
 }
 
 func DefaultPruningConfig() *PruningConfig {
 	return &PruningConfig{
-		Interval:      DefaultPruningInterval,
-		DataCompanion: DefaultDataCompanionPruningConfig(),
+		Interval:                   DefaultPruningInterval,
+		StatePruningRetries:        DefaultStatePruningRetries,
+		StatePruningRetryBackoff:   DefaultStatePruningRetryBackoff,
+		TransientErrorRetryBackoff: DefaultTransientErrorRetryBackoff,
+		DataCompanion:              DefaultDataCompanionPruningConfig(),
 	}
 }
 
 func TestPruningConfig() *PruningConfig {
 	return &PruningConfig{
-		Interval:      DefaultPruningInterval,
-		DataCompanion: TestDataCompanionPruningConfig(),
+		Interval:                   DefaultPruningInterval,
+		StatePruningRetries:        DefaultStatePruningRetries,
+		StatePruningRetryBackoff:   DefaultStatePruningRetryBackoff,
+		TransientErrorRetryBackoff: DefaultTransientErrorRetryBackoff,
+		DataCompanion:              TestDataCompanionPruningConfig(),
 	}
 }
 
@@ -1537,6 +1832,45 @@ func (cfg *PruningConfig) ValidateBasic() error {
 	if cfg.Interval <= 0 {
 		return errors.New("interval must be > 0")
 	}
+	if cfg.ABCIResponsesInterval < 0 {
+		return cmterrors.ErrNegativeField{Field: "abci_responses_interval"}
+	}
+	if cfg.StatePruningRetries < 0 {
+		return cmterrors.ErrNegativeField{Field: "state_pruning_retries"}
+	}
+	if cfg.StatePruningRetryBackoff < 0 {
+		return cmterrors.ErrNegativeField{Field: "state_pruning_retry_backoff"}
+	}
+	if cfg.TransientErrorRetries < 0 {
+		return cmterrors.ErrNegativeField{Field: "transient_error_retries"}
+	}
+	if cfg.TransientErrorRetryBackoff < 0 {
+		return cmterrors.ErrNegativeField{Field: "transient_error_retry_backoff"}
+	}
+	if cfg.InitialApplicationRetainHeight < 0 {
+		return cmterrors.ErrNegativeField{Field: "initial_application_retain_height"}
+	}
+	if cfg.LeaseTTL < 0 {
+		return cmterrors.ErrNegativeField{Field: "lease_ttl"}
+	}
+	if cfg.NearTipWarnThreshold < 0 {
+		return cmterrors.ErrNegativeField{Field: "near_tip_warn_threshold"}
+	}
+	if cfg.ABCIDivergenceWarnThreshold < 0 {
+		return cmterrors.ErrNegativeField{Field: "abci_divergence_warn_threshold"}
+	}
+	if cfg.ABCIResponsesMaxHeightsPerRun < 0 {
+		return cmterrors.ErrNegativeField{Field: "abci_responses_max_heights_per_run"}
+	}
+	if cfg.ABCIResponsesCatchUpStep < 0 {
+		return cmterrors.ErrNegativeField{Field: "abci_responses_catch_up_step"}
+	}
+	if cfg.EvidenceMaxAgeBlocks < 0 {
+		return cmterrors.ErrNegativeField{Field: "evidence_max_age_blocks"}
+	}
+	if cfg.StateCompactionInterval < 0 {
+		return cmterrors.ErrNegativeField{Field: "state_compaction_interval"}
+	}
 	if err := cfg.DataCompanion.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [data_companion] section: %w", err)
 	}
@@ -1564,6 +1898,13 @@ type DataCompanionPruningConfig struct {
 	// the data companion has not yet explicitly set one. If the data companion
 	// has already set a block results retain height, this is ignored.
 	InitialBlockResultsRetainHeight int64 `mapstructure:"initial_block_results_retain_height"`
+	// The number of blocks kept below the application block retain height,
+	// whatever the data companion block retain height, to give the data
+	// companion some breathing room. Blocks are pruned up to the minimum of the
+	// data companion block retain height and the application block retain
+	// height minus this margin. If 0, blocks are pruned up to the minimum of
+	// both.
+	SafetyMargin int64 `mapstructure:"safety_margin"`
 }
 
 func DefaultDataCompanionPruningConfig() *DataCompanionPruningConfig {
@@ -1592,5 +1933,8 @@ func (cfg *DataCompanionPruningConfig) ValidateBasic() error {
 	if cfg.InitialBlockResultsRetainHeight < 0 {
 		return errors.New("initial_block_results_retain_height cannot be negative")
 	}
+	if cfg.SafetyMargin < 0 {
+		return errors.New("safety_margin cannot be negative")
+	}
 	return nil
 }
@@ -160,11 +160,40 @@ timeout_broadcast_tx_commit = "{{ .RPC.TimeoutBroadcastTxCommit }}"
 max_request_batch_size = {{ .RPC.MaxRequestBatchSize }}
 
 # Maximum size of request body, in bytes
+# Larger requests are rejected with a 413 (Request Entity Too Large) status code.
 max_body_bytes = {{ .RPC.MaxBodyBytes }}
 
 # Maximum size of request header, in bytes
 max_header_bytes = {{ .RPC.MaxHeaderBytes }}
 
+# Maximum duration for reading a request, including its body, which protects
+# the server from clients sending their requests slowly.
+# 0 means no timeout.
+read_timeout = "{{ .RPC.ReadTimeout }}"
+
+# Maximum duration for writing a response.
+# It is increased to exceed timeout_broadcast_tx_commit if needed.
+# 0 means no timeout.
+write_timeout = "{{ .RPC.WriteTimeout }}"
+
+# Maximum duration to wait for the next request on a keep-alive connection.
+# 0 means read_timeout is used.
+idle_timeout = "{{ .RPC.IdleTimeout }}"
+
+# Compress the HTTP responses with gzip for the clients accepting it, as advertised by
+# the Accept-Encoding header of their requests. WebSocket connections are never compressed.
+compression = {{ .RPC.Compression }}
+
+# Minimum size of the HTTP responses to compress, in bytes, if compression is enabled.
+# Smaller responses are not worth the CPU cost.
+compression_min_bytes = {{ .RPC.CompressionMinBytes }}
+
+# Maximum number of responses to ABCI queries with proofs (abci_query with prove=true)
+# to cache, so that repeated queries for the same keys don't make the application
+# compute the same proofs again. The cache is purged whenever a new height is committed.
+# 0 disables the cache.
+abci_query_cache_size = {{ .RPC.ABCIQueryCacheSize }}
+
 # The path to a file containing certificate that is used to create the HTTPS server.
 # Might be either absolute path or path related to CometBFT's config directory.
 # If the certificate is signed by a certificate authority,
@@ -262,6 +291,12 @@ seeds = "{{ .P2P.Seeds }}"
 # Comma separated list of nodes to keep persistent connections to
 persistent_peers = "{{ .P2P.PersistentPeers }}"
 
+# Comma separated list of nodes which are always dialed and redialed, bypassing
+# the address book, and which are never evicted nor marked as bad. Unlike
+# persistent peers, they are redialed forever, and they are not subject to the
+# limits on the number of peers. Useful for private network topologies.
+persistent_allowlist_peers = "{{ .P2P.PersistentAllowlistPeers }}"
+
 # Path to address book
 addr_book_file = "{{ js .P2P.AddrBook }}"
 
@@ -308,6 +343,21 @@ private_peer_ids = "{{ .P2P.PrivatePeerIDs }}"
 # Toggle to disable guard against peers connecting from the same ip.
 allow_duplicate_ip = {{ .P2P.AllowDuplicateIP }}
 
+# Set true to evict the inbound peer with the lowest score when the maximum
+# number of inbound peers is reached, if that score is lower than the one of
+# the new inbound peer. Otherwise, new inbound peers are rejected when the
+# maximum is reached. Persistent and unconditional peers are never evicted.
+peer_eviction = {{ .P2P.PeerEviction }}
+
+# Scores subtracted from the score of a peer, which starts at zero, for each of
+# its misbehaviors: sending an invalid message, responding too slowly, and
+# having its connection stopped because of an error. Setting a weight to zero
+# disables the scoring of the misbehavior. The current scores are available
+# through the /peer_scores RPC endpoint.
+peer_score_invalid_message_weight = {{ .P2P.PeerScoreInvalidMessageWeight }}
+peer_score_slow_response_weight = {{ .P2P.PeerScoreSlowResponseWeight }}
+peer_score_disconnect_weight = {{ .P2P.PeerScoreDisconnectWeight }}
+
 # Peer connection configuration.
 handshake_timeout = "{{ .P2P.HandshakeTimeout }}"
 dial_timeout = "{{ .P2P.DialTimeout }}"
@@ -421,9 +471,28 @@ temp_dir = "{{ .StateSync.TempDir }}"
 # peer (default: 1 minute).
 chunk_request_timeout = "{{ .StateSync.ChunkRequestTimeout }}"
 
-# The number of concurrent chunk fetchers to run (default: 1).
+# The number of concurrent chunk fetchers to run, i.e. the maximum number of
+# chunk requests in flight across all peers (default: 4).
 chunk_fetchers = "{{ .StateSync.ChunkFetchers }}"
 
+# The maximum number of chunk requests in flight to a single peer (default: 2).
+# Chunk requests are spread evenly across the peers offering the snapshot being
+# restored, so lower this if peers are slow, and raise it together with
+# chunk_fetchers to make better use of fast peers.
+max_chunk_requests_per_peer = {{ .StateSync.MaxChunkRequestsPerPeer }}
+
+# The snapshot formats supported by the application, e.g. [1, 2]. Peers are only
+# asked for snapshots in these formats, and snapshots in other formats are
+# rejected before they are offered to the application. If empty, snapshots in
+# any format are accepted.
+snapshot_formats = [{{ range $i, $format := .StateSync.SnapshotFormats }}{{ if $i }}, {{ end }}{{ $format }}{{ end }}]
+
+# Whether to backfill the headers, commits and validator sets below the snapshot
+# height after a state sync, as far back as evidence is valid according to the
+# evidence parameters, so that the node can verify evidence about these heights.
+# They are fetched from the RPC servers.
+backfill = {{ .StateSync.Backfill }}
+
 #######################################################
 ###       Block Sync Configuration Options          ###
 #######################################################
@@ -461,6 +530,13 @@ timeout_commit = "{{ .Consensus.TimeoutCommit }}"
 # So, validators should stop the state machine, wait for some blocks, and then restart the state machine to avoid panic.
 double_sign_check_height = {{ .Consensus.DoubleSignCheckHeight }}
 
+# Maximum size in bytes of the blocks proposed by peers, derived from their number of parts.
+# Larger proposals are rejected before their parts are received, and the peers sending them are
+# scored down, whatever the consensus params allow. It must not be lower than the block max_bytes
+# consensus param, or valid proposals are rejected.
+# 0 means no limit other than the consensus params.
+max_proposal_bytes = {{ .Consensus.MaxProposalBytes }}
+
 # EmptyBlocks mode and possible interval between empty blocks
 create_empty_blocks = {{ .Consensus.CreateEmptyBlocks }}
 create_empty_blocks_interval = "{{ .Consensus.CreateEmptyBlocksInterval }}"
@@ -515,6 +591,112 @@ genesis_hash = "{{ .Storage.GenesisHash }}"
 # The time period between automated background pruning operations.
 interval = "{{ .Storage.Pruning.Interval }}"
 
+# The time period between automated background pruning operations of ABCI
+# results, which only happen if the data companion is enabled. Pruning ABCI
+# results can be much more expensive than pruning blocks, so it can be useful to
+# run it less often. If 0, ABCI results are pruned every interval.
+abci_responses_interval = "{{ .Storage.Pruning.ABCIResponsesInterval }}"
+
+# The number of times pruning the state is retried, if it fails after the
+# corresponding blocks have been pruned, before giving up until the next run of
+# the pruner. If 0, it is not retried.
+state_pruning_retries = {{ .Storage.Pruning.StatePruningRetries }}
+
+# The time to wait before the first retry of pruning the state. It doubles with
+# every retry.
+state_pruning_retry_backoff = "{{ .Storage.Pruning.StatePruningRetryBackoff }}"
+
+# The number of times pruning blocks or ABCI results is retried within a run of
+# the pruner, if it fails with a transient error of the database, before giving
+# up until the next run. If 0, it is not retried.
+transient_error_retries = {{ .Storage.Pruning.TransientErrorRetries }}
+
+# The time to wait before the first retry after a transient error. It doubles
+# with every retry.
+transient_error_retry_backoff = "{{ .Storage.Pruning.TransientErrorRetryBackoff }}"
+
+# The initial value for the application block retain height if the application
+# has not yet explicitly set one. If the application has already set a block
+# retain height, this is ignored. It is only set once the node reaches this
+# height. If 0, the application is the only one to set it.
+initial_application_retain_height = {{ .Storage.Pruning.InitialApplicationRetainHeight }}
+
+# The time to live of the lease the pruner takes on the state database when the
+# node starts, and renews while it runs. The node fails to start if another node
+# holds the lease, e.g. if both were misconfigured to use the same database. If
+# the node crashes, it can only be restarted once its lease expired. If 0, no
+# lease is taken.
+lease_ttl = "{{ .Storage.Pruning.LeaseTTL }}"
+
+# The number of blocks below the latest height under which a block retain height
+# set by the application or the data companion is logged as an error when it is
+# set, e.g. if it was mistakenly set to the latest height, which would leave
+# almost no history for peers. The retain height is accepted nonetheless. If 0,
+# nothing is logged.
+near_tip_warn_threshold = {{ .Storage.Pruning.NearTipWarnThreshold }}
+
+# The number of heights by which the ABCI results retain height and the base of
+# the block store may diverge, in either direction, before the pruner logs an
+# error at every run pruning ABCI results, as it usually indicates that a retain
+# height is misconfigured. Nothing more is done. If 0, nothing is logged.
+abci_divergence_warn_threshold = {{ .Storage.Pruning.ABCIDivergenceWarnThreshold }}
+
+# The maximum number of heights whose ABCI results are pruned at each run of the
+# pruner, so that a large raise of their retain height, e.g. when the data
+# companion starts pruning a long history, is caught up with in bounded steps.
+# If 0, ABCI results are pruned up to their retain height at once.
+abci_responses_max_heights_per_run = {{ .Storage.Pruning.ABCIResponsesMaxHeightsPerRun }}
+
+# The number of heights whose ABCI results are pruned at the first step of a
+# catch-up. It doubles with every step, up to abci_responses_max_heights_per_run.
+# If 0, all steps are of abci_responses_max_heights_per_run heights.
+abci_responses_catch_up_step = {{ .Storage.Pruning.ABCIResponsesCatchUpStep }}
+
+# The minimum number of blocks below the latest height whose data needed to
+# verify evidence is kept when pruning blocks, even if the evidence parameters
+# let it be pruned earlier. If 0, only the evidence parameters apply.
+evidence_max_age_blocks = {{ .Storage.Pruning.EvidenceMaxAgeBlocks }}
+
+# Whether to check that the block store holds every block from its base up to
+# the retain height before pruning blocks. If a block is missing, e.g. after a
+# partial write, the blocks are not pruned and an error is logged, so that
+# pruning doesn't hide the corruption. Disabled by default, as every block to
+# prune is read first.
+verify_before_prune = {{ .Storage.Pruning.VerifyBeforePrune }}
+
+# Whether to reject the application block retain heights that would prune
+# blocks within the evidence age window of the consensus parameters, extended
+# by evidence_max_age_blocks. Rejected retain heights are logged as errors, and
+# the previous one is kept. Disabled by default.
+check_evidence_window = {{ .Storage.Pruning.CheckEvidenceWindow }}
+
+# Whether to read the blocks to prune right before deleting them, to warm the
+# caches of the database, so that deleting many blocks on a cold cache doesn't
+# cause latency spikes. Only beneficial with some database backends, so
+# disabled by default.
+prefetch_blocks = {{ .Storage.Pruning.PrefetchBlocks }}
+
+# Whether to keep the blocks at and above the height of the lowest state sync
+# snapshot offered by the application, whatever the retain heights, so that
+# peers restoring a snapshot from this node can still fetch what they need. The
+# snapshots are listed at every pruning pass, so that the pruned blocks follow
+# them as new ones are taken and old ones expire. Disabled by default.
+keep_servable_snapshots = {{ .Storage.Pruning.KeepServableSnapshots }}
+
+# The interval between compactions of the whole state store, run on their own
+# schedule whatever was pruned, e.g. to reclaim the space freed by pruning
+# during off-peak hours. The duration of each compaction, and the space it
+# reclaimed if the database backend reports it, are logged. If 0, the state
+# store is only compacted after pruning, if enabled with compact.
+state_compaction_interval = "{{ .Storage.Pruning.StateCompactionInterval }}"
+
+# Whether the state store journals every update of the application, data
+# companion and ABCI results retain heights with its time, so that the retain
+# heights in effect at a past time can be loaded, e.g. when auditing what was
+# pruned. The journal grows with every update and is never pruned, so disabled
+# by default.
+retain_height_journal = {{ .Storage.Pruning.RetainHeightJournal }}
+
 #
 # Storage pruning configuration relating only to the data companion.
 #
@@ -540,6 +722,13 @@ initial_block_retain_height = {{ .Storage.Pruning.DataCompanion.InitialBlockReta
 # already set a block results retain height, this is ignored.
 initial_block_results_retain_height = {{ .Storage.Pruning.DataCompanion.InitialBlockResultsRetainHeight }}
 
+# The number of blocks kept below the application block retain height, whatever
+# the data companion block retain height, to give the data companion some
+# breathing room. Blocks are pruned up to the minimum of the data companion
+# block retain height and the application block retain height minus this
+# margin. If 0, blocks are pruned up to the minimum of both.
+safety_margin = {{ .Storage.Pruning.DataCompanion.SafetyMargin }}
+
 #######################################################
 ###   Transaction Indexer Configuration Options     ###
 #######################################################
@@ -562,6 +751,43 @@ indexer = "{{ .TxIndex.Indexer }}"
 #   postgresql://<user>:<password>@<host>:<port>/<db>?<opts>
 psql-conn = "{{ .TxIndex.PsqlConn }}"
 
+# The maximum number of rows sent to PostgreSQL in a single bulk insert by the
+# psql indexer. All rows for a block are still written within one transaction,
+# so a failure rolls back the whole block. 0 means no limit.
+psql-batch-size = {{ .TxIndex.PsqlBatchSize }}
+
+#######################################################
+###        Kafka Event Sink Configuration Options   ###
+#######################################################
+[kafka_sink]
+
+# When true, new block, transaction and validator set update events are
+# published, JSON-encoded, to the Kafka topic below. This requires a binary
+# built with the "kafka" build tag.
+enabled = {{ .KafkaSink.Enabled }}
+
+# The addresses (host:port) of the Kafka brokers to connect to.
+brokers = [{{ range .KafkaSink.Brokers }}{{ printf "%q, " . }}{{end}}]
+
+# The topic to which events are published.
+topic = "{{ .KafkaSink.Topic }}"
+
+# How many brokers must acknowledge an event before it is considered published.
+# Options:
+#   1) "none" - do not wait for any acknowledgement.
+#   2) "leader" - wait for the partition leader only.
+#   3) "all" (default) - wait for all in-sync replicas.
+acks = "{{ .KafkaSink.Acks }}"
+
+# The maximum number of events buffered while waiting to be published. Events
+# received while the buffer is full are dropped, so that publishing never
+# blocks consensus.
+queue_size = {{ .KafkaSink.QueueSize }}
+
+# The maximum number of times publishing an event is retried, with exponential
+# backoff, before the event is dropped.
+max_retries = {{ .KafkaSink.MaxRetries }}
+
 #######################################################
 ###       Instrumentation Configuration Options     ###
 #######################################################
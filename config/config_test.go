@@ -111,6 +111,11 @@ func TestRPCConfigValidateBasic(t *testing.T) {
 		"MaxBodyBytes",
 		"MaxHeaderBytes",
 		"MaxRequestBatchSize",
+		"ReadTimeout",
+		"WriteTimeout",
+		"IdleTimeout",
+		"CompressionMinBytes",
+		"ABCIQueryCacheSize",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -131,6 +136,9 @@ func TestP2PConfigValidateBasic(t *testing.T) {
 		"MaxPacketMsgPayloadSize",
 		"SendRate",
 		"RecvRate",
+		"PeerScoreInvalidMessageWeight",
+		"PeerScoreSlowResponseWeight",
+		"PeerScoreDisconnectWeight",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -199,6 +207,8 @@ func TestConsensusConfig_ValidateBasic(t *testing.T) {
 		"PeerQueryMaj23SleepDuration":          {func(c *config.ConsensusConfig) { c.PeerQueryMaj23SleepDuration = time.Second }, false},
 		"PeerQueryMaj23SleepDuration negative": {func(c *config.ConsensusConfig) { c.PeerQueryMaj23SleepDuration = -1 }, true},
 		"DoubleSignCheckHeight negative":       {func(c *config.ConsensusConfig) { c.DoubleSignCheckHeight = -1 }, true},
+		"MaxProposalBytes":                     {func(c *config.ConsensusConfig) { c.MaxProposalBytes = 1024 }, false},
+		"MaxProposalBytes negative":            {func(c *config.ConsensusConfig) { c.MaxProposalBytes = -1 }, true},
 	}
 	for desc, tc := range testcases {
 		t.Run(desc, func(t *testing.T) {
@@ -215,6 +225,108 @@ func TestConsensusConfig_ValidateBasic(t *testing.T) {
 	}
 }
 
+func TestTxIndexConfigValidateBasic(t *testing.T) {
+	cfg := config.TestTxIndexConfig()
+	require.NoError(t, cfg.ValidateBasic())
+
+	// tamper with the psql batch size
+	cfg.PsqlBatchSize = -1
+	require.Error(t, cfg.ValidateBasic())
+}
+
+func TestPruningConfigValidateBasic(t *testing.T) {
+	cfg := config.TestPruningConfig()
+	require.NoError(t, cfg.ValidateBasic())
+
+	// tamper with the ABCI responses pruning interval
+	cfg.ABCIResponsesInterval = -time.Second
+	require.Error(t, cfg.ValidateBasic())
+	cfg.ABCIResponsesInterval = 0
+
+	// tamper with the state pruning retries
+	cfg.StatePruningRetries = -1
+	require.Error(t, cfg.ValidateBasic())
+	cfg.StatePruningRetries = 0
+	require.NoError(t, cfg.ValidateBasic())
+
+	cfg.StatePruningRetryBackoff = -time.Second
+	require.Error(t, cfg.ValidateBasic())
+	cfg.StatePruningRetryBackoff = 0
+
+	// tamper with the transient error retries
+	cfg.TransientErrorRetries = -1
+	require.Error(t, cfg.ValidateBasic())
+	cfg.TransientErrorRetries = 0
+
+	cfg.TransientErrorRetryBackoff = -time.Second
+	require.Error(t, cfg.ValidateBasic())
+	cfg.TransientErrorRetryBackoff = 0
+
+	// tamper with the initial application retain height
+	cfg.InitialApplicationRetainHeight = -1
+	require.Error(t, cfg.ValidateBasic())
+	cfg.InitialApplicationRetainHeight = 0
+
+	// tamper with the lease TTL
+	cfg.LeaseTTL = -time.Second
+	require.Error(t, cfg.ValidateBasic())
+	cfg.LeaseTTL = 0
+
+	// tamper with the near tip warn threshold
+	cfg.NearTipWarnThreshold = -1
+	require.Error(t, cfg.ValidateBasic())
+	cfg.NearTipWarnThreshold = 0
+
+	// tamper with the ABCI divergence warn threshold
+	cfg.ABCIDivergenceWarnThreshold = -1
+	require.Error(t, cfg.ValidateBasic())
+	cfg.ABCIDivergenceWarnThreshold = 0
+
+	// tamper with the ABCI responses catch-up
+	cfg.ABCIResponsesMaxHeightsPerRun = -1
+	require.Error(t, cfg.ValidateBasic())
+	cfg.ABCIResponsesMaxHeightsPerRun = 0
+
+	cfg.ABCIResponsesCatchUpStep = -1
+	require.Error(t, cfg.ValidateBasic())
+	cfg.ABCIResponsesCatchUpStep = 0
+
+	// tamper with the evidence max age
+	cfg.EvidenceMaxAgeBlocks = -1
+	require.Error(t, cfg.ValidateBasic())
+	cfg.EvidenceMaxAgeBlocks = 0
+
+	// tamper with the state compaction interval
+	cfg.StateCompactionInterval = -time.Second
+	require.Error(t, cfg.ValidateBasic())
+	cfg.StateCompactionInterval = 0
+
+	// tamper with the data companion safety margin
+	cfg.DataCompanion.Enabled = true
+	cfg.DataCompanion.SafetyMargin = -1
+	require.Error(t, cfg.ValidateBasic())
+}
+
+func TestKafkaSinkConfigValidateBasic(t *testing.T) {
+	cfg := config.TestKafkaSinkConfig()
+	require.NoError(t, cfg.ValidateBasic())
+
+	// enabling the sink requires brokers
+	cfg.Enabled = true
+	require.Error(t, cfg.ValidateBasic())
+	cfg.Brokers = []string{"localhost:9092"}
+	require.NoError(t, cfg.ValidateBasic())
+
+	// tamper with the acks mode
+	cfg.Acks = "some"
+	require.Error(t, cfg.ValidateBasic())
+	cfg.Acks = "leader"
+
+	// tamper with the queue size
+	cfg.QueueSize = -1
+	require.Error(t, cfg.ValidateBasic())
+}
+
 func TestInstrumentationConfigValidateBasic(t *testing.T) {
 	cfg := config.TestInstrumentationConfig()
 	require.NoError(t, cfg.ValidateBasic())
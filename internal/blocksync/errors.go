@@ -10,6 +10,13 @@ import (
 // ErrNilMessage is returned when provided message is empty.
 var ErrNilMessage = errors.New("message cannot be nil")
 
+// ErrPeerTooSlow is returned when a peer is not sending us blocks fast enough.
+var ErrPeerTooSlow = errors.New("peer is not sending us data fast enough")
+
+// ErrPeerTimeout is returned when a peer did not respond to a block request in
+// time.
+var ErrPeerTimeout = errors.New("peer did not send us anything")
+
 // ErrInvalidBase is returned when peer informs of a status with invalid height.
 type ErrInvalidHeight struct {
 	Height int64
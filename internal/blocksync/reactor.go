@@ -1,6 +1,7 @@
 package blocksync
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"sync"
@@ -68,6 +69,9 @@ type Reactor struct {
 
 	switchToConsensusMs int
 
+	// Notifies that the base of the block store advanced. Optional.
+	baseAdvanced <-chan sm.BaseAdvancedInfo
+
 	metrics *Metrics
 }
 
@@ -123,8 +127,20 @@ func (bcR *Reactor) SetLogger(l log.Logger) {
 	bcR.pool.Logger = l
 }
 
+// SetBaseAdvancedChannel sets the channel on which the reactor is notified that
+// the base of the block store advanced (see sm.Pruner.SubscribeBaseAdvanced),
+// upon which it sends its new base and height to its peers, so that they stop
+// requesting the blocks that were pruned. Must be called before the reactor is
+// started.
+func (bcR *Reactor) SetBaseAdvancedChannel(ch <-chan sm.BaseAdvancedInfo) {
+	bcR.baseAdvanced = ch
+}
+
 // OnStart implements service.Service.
 func (bcR *Reactor) OnStart() error {
+	if bcR.baseAdvanced != nil {
+		go bcR.baseAdvancedRoutine()
+	}
 	if bcR.blockSync {
 		err := bcR.pool.Start()
 		if err != nil {
@@ -246,6 +262,7 @@ func (bcR *Reactor) respondToPeer(msg *bcproto.BlockRequest, src p2p.Peer) (queu
 func (bcR *Reactor) Receive(e p2p.Envelope) {
 	if err := ValidateMsg(e.Message); err != nil {
 		bcR.Logger.Error("Peer sent us invalid msg", "peer", e.Src, "msg", e.Message, "err", err)
+		bcR.Switch.ReportPeerBehavior(e.Src, p2p.PeerBehaviorInvalidMessage)
 		bcR.Switch.StopPeerForError(e.Src, err)
 		return
 	}
@@ -259,6 +276,7 @@ func (bcR *Reactor) Receive(e p2p.Envelope) {
 		bi, err := types.BlockFromProto(msg.Block)
 		if err != nil {
 			bcR.Logger.Error("Peer sent us invalid block", "peer", e.Src, "msg", e.Message, "err", err)
+			bcR.Switch.ReportPeerBehavior(e.Src, p2p.PeerBehaviorInvalidMessage)
 			bcR.Switch.StopPeerForError(e.Src, err)
 			return
 		}
@@ -270,6 +288,7 @@ func (bcR *Reactor) Receive(e p2p.Envelope) {
 				bcR.Logger.Error("failed to convert extended commit from proto",
 					"peer", e.Src,
 					"err", err)
+				bcR.Switch.ReportPeerBehavior(e.Src, p2p.PeerBehaviorInvalidMessage)
 				bcR.Switch.StopPeerForError(e.Src, err)
 				return
 			}
@@ -429,6 +448,31 @@ func (bcR *Reactor) BroadcastStatusRequest() {
 	})
 }
 
+// BroadcastStatusResponse broadcasts `BlockStore` base and height.
+func (bcR *Reactor) BroadcastStatusResponse() {
+	bcR.Switch.Broadcast(p2p.Envelope{
+		ChannelID: BlocksyncChannel,
+		Message: &bcproto.StatusResponse{
+			Base:   bcR.store.Base(),
+			Height: bcR.store.Height(),
+		},
+	})
+}
+
+// baseAdvancedRoutine broadcasts the status of the reactor every time the base
+// of the block store advances.
+func (bcR *Reactor) baseAdvancedRoutine() {
+	for {
+		select {
+		case <-bcR.Quit():
+			return
+		case info := <-bcR.baseAdvanced:
+			bcR.Logger.Debug("Block store base advanced", "oldBase", info.OldBase, "newBase", info.NewBase)
+			bcR.BroadcastStatusResponse()
+		}
+	}
+}
+
 func (bcR *Reactor) handleBlockRequest(request BlockRequest) {
 	peer := bcR.Switch.Peers().Get(request.PeerID)
 	if peer == nil {
@@ -458,6 +502,9 @@ func (bcR *Reactor) handleBlockRequestsRoutine() {
 		case err := <-bcR.errorsCh:
 			peer := bcR.Switch.Peers().Get(err.peerID)
 			if peer != nil {
+				if errors.Is(err.err, ErrPeerTooSlow) || errors.Is(err.err, ErrPeerTimeout) {
+					bcR.Switch.ReportPeerBehavior(peer, p2p.PeerBehaviorSlowResponse)
+				}
 				bcR.Switch.StopPeerForError(peer, err)
 			}
 		case <-statusUpdateTicker.C:
@@ -558,6 +605,7 @@ func (bcR *Reactor) processBlock(first, second *types.Block, firstParts *types.P
 		if peer != nil {
 			// NOTE: we've already removed the peer's request, but we
 			// still need to clean up the rest.
+			bcR.Switch.ReportPeerBehavior(peer, p2p.PeerBehaviorInvalidMessage)
 			bcR.Switch.StopPeerForError(peer, ErrReactorValidation{Err: err})
 		}
 		peerID2 := bcR.pool.RemovePeerAndRedoAllPeerRequests(second.Height)
@@ -565,6 +613,7 @@ func (bcR *Reactor) processBlock(first, second *types.Block, firstParts *types.P
 		if peer2 != nil && peer2 != peer {
 			// NOTE: we've already removed the peer's request, but we
 			// still need to clean up the rest.
+			bcR.Switch.ReportPeerBehavior(peer2, p2p.PeerBehaviorInvalidMessage)
 			bcR.Switch.StopPeerForError(peer2, ErrReactorValidation{Err: err})
 		}
 		return state, err
@@ -379,3 +379,49 @@ func TestCheckSwitchToConsensusLastHeightZero(t *testing.T) {
 		assert.GreaterOrEqual(t, r.reactor.store.Height(), maxBlockHeight-maxDiff)
 	}
 }
+
+func TestBaseAdvancedBroadcastsStatus(t *testing.T) {
+	config = test.ResetTestRoot("blocksync_reactor_test")
+	defer os.RemoveAll(config.RootDir)
+	genDoc, privVals := randGenesisDoc()
+
+	maxBlockHeight := int64(10)
+
+	reactorPairs := make([]ReactorPair, 2)
+	reactorPairs[0] = newReactor(t, log.TestingLogger(), genDoc, privVals, maxBlockHeight)
+	reactorPairs[1] = newReactor(t, log.TestingLogger(), genDoc, privVals, 0)
+
+	baseAdvanced := make(chan sm.BaseAdvancedInfo, 1)
+	reactorPairs[0].reactor.SetBaseAdvancedChannel(baseAdvanced)
+
+	switches := p2p.MakeConnectedSwitches(config.P2P, 2, func(i int, s *p2p.Switch) *p2p.Switch {
+		s.AddReactor("BLOCKSYNC", reactorPairs[i].reactor)
+		return s
+	}, p2p.Connect2Switches)
+
+	defer func() {
+		for _, r := range reactorPairs {
+			err := r.reactor.Stop()
+			require.NoError(t, err)
+			err = r.app.Stop()
+			require.NoError(t, err)
+		}
+	}()
+
+	peerBase := func() int64 {
+		pool := reactorPairs[1].reactor.pool
+		pool.mtx.Lock()
+		defer pool.mtx.Unlock()
+		peer := pool.peers[switches[0].NodeInfo().ID()]
+		if peer == nil {
+			return 0
+		}
+		return peer.base
+	}
+	require.Eventually(t, func() bool { return peerBase() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	_, _, err := reactorPairs[0].reactor.store.PruneBlocks(5, reactorPairs[0].reactor.initialState)
+	require.NoError(t, err)
+	baseAdvanced <- sm.BaseAdvancedInfo{OldBase: 1, NewBase: 5}
+	require.Eventually(t, func() bool { return peerBase() == 5 }, 5*time.Second, 10*time.Millisecond)
+}
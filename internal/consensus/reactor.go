@@ -245,12 +245,14 @@ func (conR *Reactor) Receive(e p2p.Envelope) {
 	msg, err := MsgFromProto(e.Message)
 	if err != nil {
 		conR.Logger.Error("Error decoding message", "src", e.Src, "chId", e.ChannelID, "err", err)
+		conR.Switch.ReportPeerBehavior(e.Src, p2p.PeerBehaviorInvalidMessage)
 		conR.Switch.StopPeerForError(e.Src, err)
 		return
 	}
 
 	if err = msg.ValidateBasic(); err != nil {
 		conR.Logger.Error("Peer sent us invalid msg", "peer", e.Src, "msg", e.Message, "err", err)
+		conR.Switch.ReportPeerBehavior(e.Src, p2p.PeerBehaviorInvalidMessage)
 		conR.Switch.StopPeerForError(e.Src, err)
 		return
 	}
@@ -272,6 +274,7 @@ func (conR *Reactor) Receive(e p2p.Envelope) {
 			conR.conS.mtx.RUnlock()
 			if err = msg.ValidateHeight(initialHeight); err != nil {
 				conR.Logger.Error("Peer sent us invalid msg", "peer", e.Src, "msg", msg, "err", err)
+				conR.Switch.ReportPeerBehavior(e.Src, p2p.PeerBehaviorInvalidMessage)
 				conR.Switch.StopPeerForError(e.Src, err)
 				return
 			}
@@ -293,6 +296,7 @@ func (conR *Reactor) Receive(e p2p.Envelope) {
 			// Peer claims to have a maj23 for some BlockID at H,R,S,
 			err := votes.SetPeerMaj23(msg.Round, msg.Type, ps.peer.ID(), msg.BlockID)
 			if err != nil {
+				conR.Switch.ReportPeerBehavior(e.Src, p2p.PeerBehaviorInvalidMessage)
 				conR.Switch.StopPeerForError(e.Src, err)
 				return
 			}
@@ -331,6 +335,12 @@ func (conR *Reactor) Receive(e p2p.Envelope) {
 		}
 		switch msg := msg.(type) {
 		case *ProposalMessage:
+			if conR.proposalTooLarge(msg.Proposal) {
+				conR.Logger.Error("Peer sent us a proposal above max_proposal_bytes", "peer", e.Src,
+					"proposal", msg.Proposal, "maxProposalBytes", conR.conS.config.MaxProposalBytes)
+				conR.Switch.ReportPeerBehavior(e.Src, p2p.PeerBehaviorInvalidMessage)
+				return
+			}
 			ps.SetHasProposal(msg.Proposal)
 			conR.conS.peerMsgQueue <- msgInfo{msg, e.Src.ID(), cmttime.Now()}
 		case *ProposalPOLMessage:
@@ -399,6 +409,19 @@ func (conR *Reactor) Receive(e p2p.Envelope) {
 	}
 }
 
+// proposalTooLarge returns true if the block of the proposal, whose size is
+// derived from its number of parts, is larger than the max_proposal_bytes set
+// in the consensus config, if any. Such proposals are rejected before their
+// parts are received, as a defensive ceiling independent of the consensus
+// params.
+func (conR *Reactor) proposalTooLarge(proposal *types.Proposal) bool {
+	maxBytes := conR.conS.config.MaxProposalBytes
+	if maxBytes == 0 {
+		return false
+	}
+	return int64(proposal.BlockID.PartSetHeader.Total) > (maxBytes-1)/int64(types.BlockPartSizeBytes)+1
+}
+
 // SetEventBus sets event bus.
 func (conR *Reactor) SetEventBus(b *types.EventBus) {
 	conR.eventBus = b
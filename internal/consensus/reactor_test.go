@@ -303,6 +303,45 @@ func TestReactorReceivePanicsIfInitPeerHasntBeenCalledYet(t *testing.T) {
 	})
 }
 
+func TestReactorRejectsProposalsAboveMaxProposalBytes(t *testing.T) {
+	n := 1
+	css, cleanup := randConsensusNet(t, n, "consensus_reactor_test", newMockTickerFunc(true), newKVStore)
+	defer cleanup()
+	css[0].config.MaxProposalBytes = 2 * int64(types.BlockPartSizeBytes)
+	reactors, _, eventBuses := startConsensusNet(t, css, n)
+	defer stopConsensusNet(log.TestingLogger(), reactors, eventBuses)
+
+	reactor := reactors[0]
+	receiveProposal := func(total uint32) p2p.Peer {
+		peer := p2pmock.NewPeer(nil)
+		reactor.InitPeer(peer)
+		reactor.AddPeer(peer)
+		blockID := types.BlockID{
+			Hash:          tmhash.Sum([]byte("block")),
+			PartSetHeader: types.PartSetHeader{Total: total, Hash: tmhash.Sum([]byte("parts"))},
+		}
+		proposal := types.NewProposal(1, 0, -1, blockID, cmttime.Now())
+		proposal.Signature = []byte("signature")
+		reactor.Receive(p2p.Envelope{
+			ChannelID: DataChannel,
+			Src:       peer,
+			Message:   &cmtcons.Proposal{Proposal: *proposal.ToProto()},
+		})
+		return peer
+	}
+
+	// The proposal is accepted by the reactor, and then rejected by the
+	// consensus state, as it is not properly signed.
+	peer := receiveProposal(2)
+	assert.NotContains(t, reactor.Switch.PeerScores(), peer.ID())
+
+	peer = receiveProposal(3)
+	assert.Less(t, reactor.Switch.PeerScores()[peer.ID()], int64(0))
+	ps, ok := peer.Get(types.PeerStateKey).(*PeerState)
+	require.True(t, ok)
+	assert.False(t, ps.GetRoundState().Proposal)
+}
+
 // TestSwitchToConsensusVoteExtensions tests that the SwitchToConsensus correctly
 // checks for vote extension data when required.
 func TestSwitchToConsensusVoteExtensions(t *testing.T) {
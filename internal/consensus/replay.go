@@ -313,13 +313,17 @@ func (h *Handshaker) ReplayBlocks(
 		validatorSet := types.NewValidatorSet(validators)
 		nextVals := types.TM2PB.ValidatorUpdates(validatorSet)
 		pbparams := h.genDoc.ConsensusParams.ToProto()
+		appState, err := h.genDoc.LoadAppState()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the genesis app state: %w", err)
+		}
 		req := &abci.InitChainRequest{
 			Time:            h.genDoc.GenesisTime,
 			ChainId:         h.genDoc.ChainID,
 			InitialHeight:   h.genDoc.InitialHeight,
 			ConsensusParams: &pbparams,
 			Validators:      nextVals,
-			AppStateBytes:   h.genDoc.AppState,
+			AppStateBytes:   appState,
 		}
 		res, err := proxyApp.Consensus().InitChain(context.TODO(), req)
 		if err != nil {
@@ -1207,6 +1207,8 @@ func (bs *mockBlockStore) PruneBlocks(height int64, _ sm.State) (uint64, int64,
 	return pruned, evidencePoint, nil
 }
 
+func (*mockBlockStore) PruneOrphanedBlockParts(int64, int64) (uint64, error) { return 0, nil }
+
 func (*mockBlockStore) DeleteLatestBlock() error { return nil }
 func (*mockBlockStore) Close() error             { return nil }
 
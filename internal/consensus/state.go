@@ -135,6 +135,9 @@ type State struct {
 	// for reporting metrics
 	metrics *Metrics
 
+	// notified of the timeouts that are handled
+	timeoutObserver TimeoutObserver
+
 	// offline state sync height indicating to which height the node synced offline
 	offlineStateSyncHeight int64
 
@@ -171,6 +174,7 @@ func NewState(
 		evpool:           evpool,
 		evsw:             cmtevents.NewEventSwitch(),
 		metrics:          NopMetrics(),
+		timeoutObserver:  NopTimeoutObserver{},
 	}
 	for _, option := range options {
 		option(cs)
@@ -220,6 +224,12 @@ func StateMetrics(metrics *Metrics) StateOption {
 	return func(cs *State) { cs.metrics = metrics }
 }
 
+// StateTimeoutObserver sets the observer notified of the timeouts that are
+// handled.
+func StateTimeoutObserver(observer TimeoutObserver) StateOption {
+	return func(cs *State) { cs.timeoutObserver = observer }
+}
+
 // OfflineStateSyncHeight indicates the height at which the node
 // statesync offline - before booting sets the metrics.
 func OfflineStateSyncHeight(height int64) StateOption {
@@ -976,6 +986,8 @@ func (cs *State) handleTimeout(ti timeoutInfo, rs cstypes.RoundState) {
 	}
 
 	// the timeout will now cause a state transition
+	cs.timeoutObserver.TimeoutFired(ti.Height, ti.Round, ti.Step, ti.Duration)
+
 	cs.mtx.Lock()
 	defer cs.mtx.Unlock()
 
@@ -161,6 +161,35 @@ func TestStateEnterProposeNoPrivValidator(t *testing.T) {
 	}
 }
 
+// timeoutRecorder is a TimeoutObserver recording the steps of the timeouts.
+type timeoutRecorder struct {
+	steps chan cstypes.RoundStepType
+}
+
+func (r *timeoutRecorder) TimeoutFired(_ int64, _ int32, step cstypes.RoundStepType, _ time.Duration) {
+	r.steps <- step
+}
+
+func TestStateTimeoutObserver(t *testing.T) {
+	cs, _ := randState(1)
+	cs.SetPrivValidator(nil)
+	recorder := &timeoutRecorder{steps: make(chan cstypes.RoundStepType, 10)}
+	cs.timeoutObserver = recorder
+	height, round := cs.Height, cs.Round
+
+	timeoutCh := subscribe(cs.eventBus, types.EventQueryTimeoutPropose)
+	startTestRound(cs, height, round)
+
+	// Without a privValidator, there's no proposal, so the propose timeout fires.
+	ensureNewTimeout(timeoutCh, height, round, cs.config.TimeoutPropose.Nanoseconds())
+	select {
+	case step := <-recorder.steps:
+		require.Equal(t, cstypes.RoundStepPropose, step)
+	case <-time.After(ensureTimeout):
+		t.Fatal("the observer was not notified of the propose timeout")
+	}
+}
+
 // a validator should not timeout of the prevote round (TODO: unless the block is really big!)
 func TestStateEnterProposeYesPrivValidator(t *testing.T) {
 	cs, _ := randState(1)
@@ -0,0 +1,37 @@
+package consensus
+
+import (
+	"time"
+
+	cstypes "github.com/cometbft/cometbft/internal/consensus/types"
+)
+
+// TimeoutObserver is notified of the timeouts handled by the consensus State,
+// e.g. to trace the timing of consensus while debugging liveness issues. It is
+// only an instrumentation point and has no effect on consensus.
+type TimeoutObserver interface {
+	// TimeoutFired is called when a timeout scheduled for the given height,
+	// round and step fires and causes a state transition, before the
+	// transition. Timeouts for a height, round or step that consensus has
+	// already moved past are ignored by the State, and not reported.
+	//
+	// The step is the one the timeout was scheduled for:
+	//   - RoundStepNewHeight for the commit timeout,
+	//   - RoundStepNewRound for the wait before proposing, e.g. when waiting
+	//     for transactions,
+	//   - RoundStepPropose for the propose timeout,
+	//   - RoundStepPrevoteWait for the prevote timeout,
+	//   - RoundStepPrecommitWait for the precommit timeout.
+	//
+	// It is called from the consensus routine, so it must return quickly, and
+	// must not call the State.
+	TimeoutFired(height int64, round int32, step cstypes.RoundStepType, duration time.Duration)
+}
+
+// NopTimeoutObserver does nothing.
+type NopTimeoutObserver struct{}
+
+var _ TimeoutObserver = NopTimeoutObserver{}
+
+// TimeoutFired implements TimeoutObserver.
+func (NopTimeoutObserver) TimeoutFired(int64, int32, cstypes.RoundStepType, time.Duration) {}
@@ -0,0 +1,133 @@
+package consensus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/cometbft/cometbft/p2p"
+	"github.com/cometbft/cometbft/types"
+)
+
+// WALEntryType is the type of a WAL entry.
+type WALEntryType string
+
+const (
+	WALEntryProposal   WALEntryType = "proposal"
+	WALEntryBlockPart  WALEntryType = "block_part"
+	WALEntryVote       WALEntryType = "vote"
+	WALEntryTimeout    WALEntryType = "timeout"
+	WALEntryRoundState WALEntryType = "round_state"
+	WALEntryEndHeight  WALEntryType = "end_height"
+	WALEntryOther      WALEntryType = "other"
+)
+
+// WALEntry is a decoded entry of the WAL, along with the height and round it
+// belongs to. The round of an EndHeightMessage is -1.
+type WALEntry struct {
+	Time   time.Time
+	Type   WALEntryType
+	Height int64
+	Round  int32
+	// The peer the message was received from, if any. Empty for the messages
+	// of the node itself.
+	PeerID p2p.ID
+	// The decoded message, i.e. a Message for proposals, block parts and
+	// votes, a types.EventDataRoundState, an EndHeightMessage, or the
+	// timeout itself.
+	Msg any
+}
+
+// WALReader reads the entries of a WAL, in order, e.g. for analyzing the WAL
+// of a node after a crash. It doesn't need the node to be running, but the
+// node must not write to the WAL while it is being read.
+type WALReader struct {
+	wal *BaseWAL
+	rd  io.ReadCloser
+	dec *WALDecoder
+}
+
+// NewWALReader returns a reader of the WAL at walFile, starting at its first
+// entry. The caller must close it.
+func NewWALReader(walFile string) (*WALReader, error) {
+	if _, err := os.Stat(walFile); err != nil {
+		return nil, fmt.Errorf("failed to open WAL: %w", err)
+	}
+	wal, err := NewWAL(walFile)
+	if err != nil {
+		return nil, err
+	}
+	gr, err := wal.Group().NewReader(wal.Group().MinIndex())
+	if err != nil {
+		wal.Group().Close()
+		return nil, err
+	}
+	return &WALReader{wal: wal, rd: gr, dec: NewWALDecoder(gr)}, nil
+}
+
+// Next returns the next entry of the WAL, or io.EOF after the last one. A
+// DataCorruptionError only affects the current entry: the following entries
+// can still be read by calling Next again.
+func (r *WALReader) Next() (*WALEntry, error) {
+	msg, err := r.dec.Decode()
+	if err != nil {
+		return nil, err
+	}
+	return newWALEntry(msg), nil
+}
+
+// SearchHeight moves the reader to the first entry of the given height, i.e.
+// right after the EndHeightMessage of height-1, and returns whether it was
+// found. If it wasn't, the reader is left where it was.
+func (r *WALReader) SearchHeight(height int64) (bool, error) {
+	if height <= 0 {
+		return false, errors.New("height must be greater than 0")
+	}
+	rd, found, err := r.wal.SearchForEndHeight(height-1, &WALSearchOptions{IgnoreDataCorruptionErrors: true})
+	if err != nil || !found {
+		return false, err
+	}
+	r.rd.Close()
+	r.rd = rd
+	r.dec = NewWALDecoder(rd)
+	return true, nil
+}
+
+// Close closes the reader and the WAL.
+func (r *WALReader) Close() error {
+	err := r.rd.Close()
+	r.wal.Group().Close()
+	return err
+}
+
+func newWALEntry(msg *TimedWALMessage) *WALEntry {
+	entry := &WALEntry{Time: msg.Time, Type: WALEntryOther, Msg: msg.Msg}
+	switch m := msg.Msg.(type) {
+	case msgInfo:
+		entry.PeerID = m.PeerID
+		entry.Msg = m.Msg
+		switch cm := m.Msg.(type) {
+		case *ProposalMessage:
+			entry.Type = WALEntryProposal
+			entry.Height, entry.Round = cm.Proposal.Height, cm.Proposal.Round
+		case *BlockPartMessage:
+			entry.Type = WALEntryBlockPart
+			entry.Height, entry.Round = cm.Height, cm.Round
+		case *VoteMessage:
+			entry.Type = WALEntryVote
+			entry.Height, entry.Round = cm.Vote.Height, cm.Vote.Round
+		}
+	case timeoutInfo:
+		entry.Type = WALEntryTimeout
+		entry.Height, entry.Round = m.Height, m.Round
+	case types.EventDataRoundState:
+		entry.Type = WALEntryRoundState
+		entry.Height, entry.Round = m.Height, m.Round
+	case EndHeightMessage:
+		entry.Type = WALEntryEndHeight
+		entry.Height, entry.Round = m.Height, -1
+	}
+	return entry
+}
@@ -0,0 +1,63 @@
+package consensus
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWALReader(t *testing.T) {
+	walBody, err := WALWithNBlocks(t, 6, getConfig(t))
+	require.NoError(t, err)
+	walFile := tempWALWithData(walBody)
+	defer os.Remove(walFile)
+
+	r, err := NewWALReader(walFile)
+	require.NoError(t, err)
+	defer r.Close()
+
+	// The WAL starts with the end of height 0, and every height ends with an
+	// EndHeightMessage.
+	entry, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, WALEntryEndHeight, entry.Type)
+	require.EqualValues(t, 0, entry.Height)
+
+	counts := make(map[WALEntryType]int)
+	for {
+		entry, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		counts[entry.Type]++
+		if entry.Type == WALEntryEndHeight {
+			require.EqualValues(t, counts[WALEntryEndHeight], entry.Height)
+		}
+	}
+	require.Equal(t, 5, counts[WALEntryEndHeight])
+	for _, typ := range []WALEntryType{WALEntryProposal, WALEntryBlockPart, WALEntryVote, WALEntryRoundState} {
+		require.Positive(t, counts[typ], typ)
+	}
+
+	// Search the first entry of a height.
+	found, err := r.SearchHeight(4)
+	require.NoError(t, err)
+	require.True(t, found)
+	entry, err = r.Next()
+	require.NoError(t, err)
+	require.EqualValues(t, 4, entry.Height)
+
+	found, err = r.SearchHeight(100)
+	require.NoError(t, err)
+	require.False(t, found)
+	entry, err = r.Next()
+	require.NoError(t, err)
+	require.EqualValues(t, 4, entry.Height, "the reader must be left where it was")
+
+	_, err = NewWALReader(walFile + ".missing")
+	require.Error(t, err)
+}
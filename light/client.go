@@ -9,6 +9,9 @@ import (
 	"sync"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/cometbft/cometbft/crypto/tmhash"
 	"github.com/cometbft/cometbft/libs/log"
 	cmtmath "github.com/cometbft/cometbft/libs/math"
 	cmtsync "github.com/cometbft/cometbft/libs/sync"
@@ -23,8 +26,9 @@ const (
 	sequential mode = iota + 1
 	skipping
 
-	defaultPruningSize      = 1000
-	defaultMaxRetryAttempts = 10
+	defaultPruningSize        = 1000
+	defaultMaxRetryAttempts   = 10
+	defaultBackwardsCacheSize = 1000
 	// For verifySkipping, when using the cache of headers from the previous batch,
 	// they will always be at a height greater than 1/2 (normal verifySkipping) so to
 	// find something in between the range, 9/16 is used.
@@ -125,6 +129,30 @@ func MaxBlockLag(d time.Duration) Option {
 	}
 }
 
+// BackwardsCacheSize sets the maximum number of headers verified by
+// VerifyBackward that are kept in memory, so that repeated backward
+// verifications over the same heights do not need to fetch them again.
+// Default: 1000.
+func BackwardsCacheSize(size int) Option {
+	return func(c *Client) {
+		c.backwardsCacheSize = size
+	}
+}
+
+// ParallelFetching makes the light client fetch the intermediate light blocks
+// needed by skipping verification in parallel, from the primary and all the
+// witnesses at once, using the first valid response for each height. Instead of
+// fetching one pivot at a time, up to maxConcurrency pivots are fetched ahead,
+// and no more than maxConcurrency requests are in flight at any time. The light
+// blocks are verified as usual, and the new header is still cross-checked
+// against all witnesses. A maxConcurrency of 1 or less disables parallel
+// fetching. Default: disabled.
+func ParallelFetching(maxConcurrency int) Option {
+	return func(c *Client) {
+		c.maxParallelFetches = maxConcurrency
+	}
+}
+
 // Client represents a light client, connected to a single chain, which gets
 // light blocks from a primary provider, verifies them either sequentially or by
 // skipping some and stores them in a trusted store (usually, a local FS).
@@ -156,6 +184,13 @@ type Client struct {
 	// See ConfirmationFunction option
 	confirmationFn func(action string) bool
 
+	// See BackwardsCacheSize option
+	backwardsCacheSize int
+	// See ParallelFetching option
+	maxParallelFetches int
+	// Headers verified by VerifyBackward, by height.
+	backwardsCache *lru.Cache[int64, *types.Header]
+
 	quit chan struct{}
 
 	logger log.Logger
@@ -218,26 +253,33 @@ func NewClientFromTrustedStore(
 	options ...Option,
 ) (*Client, error) {
 	c := &Client{
-		chainID:          chainID,
-		trustingPeriod:   trustingPeriod,
-		verificationMode: skipping,
-		trustLevel:       DefaultTrustLevel,
-		maxRetryAttempts: defaultMaxRetryAttempts,
-		maxClockDrift:    defaultMaxClockDrift,
-		maxBlockLag:      defaultMaxBlockLag,
-		primary:          primary,
-		witnesses:        witnesses,
-		trustedStore:     trustedStore,
-		pruningSize:      defaultPruningSize,
-		confirmationFn:   func(_ string) bool { return true },
-		quit:             make(chan struct{}),
-		logger:           log.NewNopLogger(),
+		chainID:            chainID,
+		trustingPeriod:     trustingPeriod,
+		verificationMode:   skipping,
+		trustLevel:         DefaultTrustLevel,
+		maxRetryAttempts:   defaultMaxRetryAttempts,
+		maxClockDrift:      defaultMaxClockDrift,
+		maxBlockLag:        defaultMaxBlockLag,
+		primary:            primary,
+		witnesses:          witnesses,
+		trustedStore:       trustedStore,
+		pruningSize:        defaultPruningSize,
+		confirmationFn:     func(_ string) bool { return true },
+		backwardsCacheSize: defaultBackwardsCacheSize,
+		quit:               make(chan struct{}),
+		logger:             log.NewNopLogger(),
 	}
 
 	for _, o := range options {
 		o(c)
 	}
 
+	backwardsCache, err := lru.New[int64, *types.Header](c.backwardsCacheSize)
+	if err != nil {
+		return nil, ErrInvalidBackwardsCacheSize{Size: c.backwardsCacheSize, Err: err}
+	}
+	c.backwardsCache = backwardsCache
+
 	// Validate the number of witnesses.
 	if len(c.witnesses) == 0 {
 		return nil, ErrNoWitnesses
@@ -702,20 +744,28 @@ func (c *Client) verifySequential(
 // requested from source is kept such that when a verification is made, and the
 // light client tries again to verify the new light block in the middle, the light
 // client does not need to ask for all the same light blocks again.
+//
+// If parallel is true, the light blocks are fetched according to the
+// ParallelFetching option, see fetchLightBlocks.
 func (c *Client) verifySkipping(
 	ctx context.Context,
 	source provider.Provider,
 	trustedBlock *types.LightBlock,
 	newLightBlock *types.LightBlock,
 	now time.Time,
+	parallel bool,
 ) ([]*types.LightBlock, error) {
 	var (
 		blockCache = []*types.LightBlock{newLightBlock}
 		depth      = 0
+		maxPivots  = 1
 
 		verifiedBlock = trustedBlock
 		trace         = []*types.LightBlock{trustedBlock}
 	)
+	if parallel {
+		maxPivots = max(c.maxParallelFetches, 1)
+	}
 
 	for {
 		c.logger.Debug("Verify non-adjacent newHeader against verifiedBlock",
@@ -745,23 +795,23 @@ func (c *Client) verifySkipping(
 		case ErrNewValSetCantBeTrusted:
 			// do add another header to the end of the cache
 			if depth == len(blockCache)-1 {
-				pivotHeight := verifiedBlock.Height + (blockCache[depth].Height-verifiedBlock.
-					Height)*verifySkippingNumerator/verifySkippingDenominator
-				interimBlock, providerErr := source.LightBlock(ctx, pivotHeight)
-				switch providerErr {
-				case nil:
-					blockCache = append(blockCache, interimBlock)
+				pivotHeights := pivotHeights(verifiedBlock.Height, blockCache[depth].Height, maxPivots)
+				interimBlocks, providerErr := c.fetchLightBlocks(ctx, source, pivotHeights, parallel)
+				switch {
+				// the pivots that could not be fetched are requested again once they are needed
+				case len(interimBlocks) > 0:
+					blockCache = append(blockCache, interimBlocks...)
 
 				// if the error is benign, the client does not need to replace the primary
-				case provider.ErrLightBlockNotFound, provider.ErrNoResponse, provider.ErrHeightTooHigh:
+				case providerErr == provider.ErrLightBlockNotFound, providerErr == provider.ErrNoResponse,
+					providerErr == provider.ErrHeightTooHigh:
 					return nil, err
 
 				// all other errors such as ErrBadLightBlock or ErrUnreliableProvider are seen as malevolent and the
 				// provider is removed
 				default:
-					return nil, ErrVerificationFailed{From: verifiedBlock.Height, To: pivotHeight, Reason: providerErr}
+					return nil, ErrVerificationFailed{From: verifiedBlock.Height, To: pivotHeights[0], Reason: providerErr}
 				}
-				blockCache = append(blockCache, interimBlock)
 			}
 			depth++
 
@@ -771,6 +821,132 @@ func (c *Client) verifySkipping(
 	}
 }
 
+// pivotHeights returns the heights of the light blocks that skipping
+// verification from trustedHeight to height requests next, in the order they
+// are requested if none of them can be trusted. At most n heights are returned.
+func pivotHeights(trustedHeight, height int64, n int) []int64 {
+	heights := make([]int64, 0, n)
+	for len(heights) < n {
+		height = trustedHeight + (height-trustedHeight)*verifySkippingNumerator/verifySkippingDenominator
+		if len(heights) > 0 && height <= trustedHeight {
+			break
+		}
+		heights = append(heights, height)
+	}
+	return heights
+}
+
+// fetchLightBlocks fetches the light blocks at the given heights. It returns
+// the light blocks up to the first height that could not be fetched, along
+// with the error for that height.
+//
+// If parallel is false, only the first light block is fetched, from source.
+// Otherwise, all light blocks are fetched concurrently, racing source against
+// the witnesses for every height (see raceLightBlock), with no more than
+// maxParallelFetches requests in flight at any time.
+func (c *Client) fetchLightBlocks(
+	ctx context.Context,
+	source provider.Provider,
+	heights []int64,
+	parallel bool,
+) ([]*types.LightBlock, error) {
+	if !parallel {
+		lightBlock, err := source.LightBlock(ctx, heights[0])
+		if err != nil {
+			return nil, err
+		}
+		return []*types.LightBlock{lightBlock}, nil
+	}
+
+	c.providerMutex.Lock()
+	providers := append([]provider.Provider{source}, c.witnesses...)
+	c.providerMutex.Unlock()
+
+	var (
+		lightBlocks = make([]*types.LightBlock, len(heights))
+		errs        = make([]error, len(heights))
+		sem         = make(chan struct{}, max(c.maxParallelFetches, 1))
+		wg          sync.WaitGroup
+	)
+	for i, height := range heights {
+		wg.Add(1)
+		go func(i int, height int64) {
+			defer wg.Done()
+			lightBlocks[i], errs[i] = c.raceLightBlock(ctx, providers, height, sem)
+		}(i, height)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return lightBlocks[:i], err
+		}
+	}
+	return lightBlocks, nil
+}
+
+// raceLightBlock requests the light block at height from all the providers at
+// once, and returns the first valid response, canceling the other requests. A
+// slot in sem is held for the duration of every request. If none of the
+// providers returns a valid light block, the error returned by the first
+// provider, the source, is returned, so that the caller can act on it like it
+// would if it had requested the light block from the source only.
+//
+// Light blocks returned by witnesses are verified like the ones returned by
+// the source, so using them does not weaken the security of the light client.
+func (c *Client) raceLightBlock(
+	ctx context.Context,
+	providers []provider.Provider,
+	height int64,
+	sem chan struct{},
+) (*types.LightBlock, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type response struct {
+		index      int
+		lightBlock *types.LightBlock
+		err        error
+	}
+	// buffered so that requests finishing after the race is over do not block
+	responses := make(chan response, len(providers))
+	go func() {
+		for i, p := range providers {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				responses <- response{index: i, err: ctx.Err()}
+				continue
+			}
+			go func(i int, p provider.Provider) {
+				defer func() { <-sem }()
+				lightBlock, err := p.LightBlock(ctx, height)
+				if err == nil {
+					if vErr := lightBlock.ValidateBasic(c.chainID); vErr != nil {
+						err = provider.ErrBadLightBlock{Reason: vErr}
+					}
+				}
+				responses <- response{index: i, lightBlock: lightBlock, err: err}
+			}(i, p)
+		}
+	}()
+
+	var sourceErr error
+	for range providers {
+		r := <-responses
+		if r.err == nil {
+			return r.lightBlock, nil
+		}
+		if r.index == 0 {
+			sourceErr = r.err
+		} else {
+			c.logger.Debug("failed to fetch light block from witness", "height", height,
+				"witness", providers[r.index], "err", r.err)
+		}
+	}
+	return nil, sourceErr
+}
+
 // verifySkippingAgainstPrimary does verifySkipping plus it compares new header with
 // witnesses and replaces primary if it sends the light client an invalid header.
 func (c *Client) verifySkippingAgainstPrimary(
@@ -779,7 +955,7 @@ func (c *Client) verifySkippingAgainstPrimary(
 	newLightBlock *types.LightBlock,
 	now time.Time,
 ) error {
-	trace, err := c.verifySkipping(ctx, c.primary, trustedBlock, newLightBlock, now)
+	trace, err := c.verifySkipping(ctx, c.primary, trustedBlock, newLightBlock, now, c.maxParallelFetches > 1)
 
 	switch errors.Unwrap(err).(type) {
 	case ErrInvalidHeader:
@@ -977,6 +1153,77 @@ func (c *Client) backwards(
 	return nil
 }
 
+// VerifyBackward verifies the header at targetHeight against a header the
+// caller already trusts, e.g. a recent checkpoint, identified by its height
+// and hash. It fetches the trusted header and every header below it down to
+// targetHeight from the primary, and checks that they form a hash chain via
+// their LastBlockID, as well as that their times are decreasing.
+//
+// Unlike VerifyHeader, this neither requires nor updates any state in the
+// trusted store, and no validator signatures are checked: trust derives solely
+// from trustedHash. Verified headers are cached (see BackwardsCacheSize), so
+// repeated backward verifications over the same heights are cheap.
+//
+// targetHeight must be > 0 and not greater than trustedHeight.
+func (c *Client) VerifyBackward(
+	ctx context.Context,
+	targetHeight int64,
+	trustedHeight int64,
+	trustedHash []byte,
+) (*types.Header, error) {
+	if targetHeight <= 0 {
+		return nil, ErrNegativeOrZeroHeight
+	}
+	if trustedHeight < targetHeight {
+		return nil, ErrTargetBlockHeightGreaterThanTrusted{Target: targetHeight, Trusted: trustedHeight}
+	}
+	if len(trustedHash) != tmhash.Size {
+		return nil, ErrInvalidHashSize{Expected: tmhash.Size, Actual: len(trustedHash)}
+	}
+
+	verifiedHeader, err := c.backwardsHeader(ctx, trustedHeight, trustedHash)
+	if err != nil {
+		return nil, err
+	}
+	if verifiedHeader.ChainID != c.chainID {
+		return nil, ErrInvalidHeader{fmt.Errorf("trusted header belongs to another chain %q", verifiedHeader.ChainID)}
+	}
+	c.backwardsCache.Add(verifiedHeader.Height, verifiedHeader)
+
+	for verifiedHeader.Height > targetHeight {
+		interimHeader, err := c.backwardsHeader(ctx, verifiedHeader.Height-1, verifiedHeader.LastBlockID.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if err := VerifyBackwards(interimHeader, verifiedHeader); err != nil {
+			return nil, err
+		}
+		c.backwardsCache.Add(interimHeader.Height, interimHeader)
+		verifiedHeader = interimHeader
+	}
+
+	c.logger.Info("Verified header backwards", "height", verifiedHeader.Height, "hash", verifiedHeader.Hash(),
+		"trustedHeight", trustedHeight, "trustedHash", fmt.Sprintf("%X", trustedHash))
+	return verifiedHeader, nil
+}
+
+// backwardsHeader returns the header at height, which is expected to have the
+// given hash. The header is taken from the cache of headers verified by
+// VerifyBackward if possible, and fetched from the primary otherwise.
+func (c *Client) backwardsHeader(ctx context.Context, height int64, expectedHash []byte) (*types.Header, error) {
+	if h, ok := c.backwardsCache.Get(height); ok && bytes.Equal(h.Hash(), expectedHash) {
+		return h, nil
+	}
+	l, err := c.lightBlockFromPrimary(ctx, height)
+	if err != nil {
+		return nil, ErrGetHeaderAtHeight{Height: height, Err: err}
+	}
+	if !bytes.Equal(l.Hash(), expectedHash) {
+		return nil, ErrHeaderHashMismatch{Expected: expectedHash, Actual: l.Hash()}
+	}
+	return l.Header, nil
+}
+
 // lightBlockFromPrimary retrieves the lightBlock from the primary provider
 // at the specified height. This method also handles provider behavior as follows:
 //
@@ -402,6 +402,49 @@ func TestClientLargeBisectionVerification(t *testing.T) {
 	assert.Equal(t, h, h2)
 }
 
+func TestClientParallelFetching(t *testing.T) {
+	// one validator changes at every height, so that bisection is needed
+	chainID, headers, vals := genMockNode(100, 3, 1, bTime)
+	witness := &countingProvider{Provider: mockp.New(chainID, headers, vals)}
+	// the primary only has the trusted and the latest light block, so the
+	// pivots can only be fetched from the witness
+	primary := mockp.New(chainID,
+		map[int64]*types.SignedHeader{1: headers[1], 100: headers[100]},
+		map[int64]*types.ValidatorSet{1: vals[1], 100: vals[100]})
+
+	newClient := func(options ...light.Option) *light.Client {
+		t.Helper()
+		c, err := light.NewClient(
+			ctx,
+			chainID,
+			light.TrustOptions{
+				Period: 4 * time.Hour,
+				Height: 1,
+				Hash:   headers[1].Hash(),
+			},
+			primary,
+			[]provider.Provider{witness},
+			dbs.New(dbm.NewMemDB(), chainID),
+			append(options, light.Logger(log.TestingLogger()))...,
+		)
+		require.NoError(t, err)
+		return c
+	}
+
+	// without parallel fetching, the primary is the only source of pivots
+	_, err := newClient().VerifyLightBlockAtHeight(ctx, 100, bTime.Add(100*time.Minute))
+	require.Error(t, err)
+
+	requests := witness.numRequests()
+	c := newClient(light.ParallelFetching(4))
+	h, err := c.VerifyLightBlockAtHeight(ctx, 100, bTime.Add(100*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, headers[100].Hash(), h.Hash())
+	// the pivots were fetched from the witness, in addition to the header used
+	// for cross-checking
+	assert.Greater(t, witness.numRequests()-requests, 1)
+}
+
 func TestClientBisectionBetweenTrustedHeaders(t *testing.T) {
 	c, err := light.NewClient(
 		ctx,
@@ -881,6 +924,114 @@ func TestClient_BackwardsVerification(t *testing.T) {
 	}
 }
 
+// countingProvider counts the light block requests made to the underlying
+// provider.
+type countingProvider struct {
+	provider.Provider
+
+	mtx      sync.Mutex
+	requests int
+}
+
+func (p *countingProvider) LightBlock(ctx context.Context, height int64) (*types.LightBlock, error) {
+	p.mtx.Lock()
+	p.requests++
+	p.mtx.Unlock()
+	return p.Provider.LightBlock(ctx, height)
+}
+
+func (p *countingProvider) numRequests() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.requests
+}
+
+func TestClient_VerifyBackward(t *testing.T) {
+	primary := &countingProvider{Provider: largeFullNode}
+	trustHeader, _ := largeFullNode.LightBlock(ctx, 1)
+	checkpoint, _ := largeFullNode.LightBlock(ctx, 9)
+	c, err := light.NewClient(
+		ctx,
+		chainID,
+		light.TrustOptions{
+			Period: 4 * time.Minute,
+			Height: trustHeader.Height,
+			Hash:   trustHeader.Hash(),
+		},
+		primary,
+		[]provider.Provider{largeFullNode},
+		dbs.New(dbm.NewMemDB(), chainID),
+		light.Logger(log.TestingLogger()),
+	)
+	require.NoError(t, err)
+
+	// 1) verify backwards from the checkpoint => expect no error
+	requests := primary.numRequests()
+	h, err := c.VerifyBackward(ctx, 4, checkpoint.Height, checkpoint.Hash())
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, h.Height)
+	expected, _ := largeFullNode.LightBlock(ctx, 4)
+	assert.Equal(t, expected.Hash(), h.Hash())
+	// headers 9 down to 4 were fetched
+	assert.Equal(t, requests+6, primary.numRequests())
+
+	// 2) the verified headers are not added to the trusted store
+	_, err = c.TrustedLightBlock(4)
+	require.Error(t, err)
+
+	// 3) repeated verification of cached heights doesn't hit the primary
+	requests = primary.numRequests()
+	h, err = c.VerifyBackward(ctx, 6, checkpoint.Height, checkpoint.Hash())
+	require.NoError(t, err)
+	assert.EqualValues(t, 6, h.Height)
+	assert.Equal(t, requests, primary.numRequests())
+
+	// 4) going further back only fetches the missing headers
+	h, err = c.VerifyBackward(ctx, 2, checkpoint.Height, checkpoint.Hash())
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, h.Height)
+	assert.Equal(t, requests+2, primary.numRequests())
+
+	// 5) wrong trusted hash => expect error
+	_, err = c.VerifyBackward(ctx, 4, checkpoint.Height, hash("wrong"))
+	require.ErrorAs(t, err, &light.ErrHeaderHashMismatch{})
+
+	// 6) invalid arguments => expect error
+	_, err = c.VerifyBackward(ctx, 0, checkpoint.Height, checkpoint.Hash())
+	require.ErrorIs(t, err, light.ErrNegativeOrZeroHeight)
+	_, err = c.VerifyBackward(ctx, 10, checkpoint.Height, checkpoint.Hash())
+	require.ErrorAs(t, err, &light.ErrTargetBlockHeightGreaterThanTrusted{})
+	_, err = c.VerifyBackward(ctx, 4, checkpoint.Height, []byte("short"))
+	require.ErrorAs(t, err, &light.ErrInvalidHashSize{})
+}
+
+func TestClient_VerifyBackwardRejectsBrokenHashChain(t *testing.T) {
+	// The header at height 2 doesn't match h3's LastBlockID.
+	primary := mockp.New(
+		chainID,
+		map[int64]*types.SignedHeader{
+			1: h1,
+			2: keys.GenSignedHeader(chainID, 2, bTime.Add(30*time.Minute), nil, vals, vals,
+				hash("app_hash2"), hash("cons_hash23"), hash("results_hash30"), 0, len(keys)),
+			3: h3,
+		},
+		valSet,
+	)
+	c, err := light.NewClient(
+		ctx,
+		chainID,
+		trustOptions,
+		primary,
+		[]provider.Provider{primary},
+		dbs.New(dbm.NewMemDB(), chainID),
+		light.Logger(log.TestingLogger()),
+	)
+	require.NoError(t, err)
+
+	_, err = c.VerifyBackward(ctx, 1, 3, h3.Hash())
+	require.ErrorAs(t, err, &light.ErrHeaderHashMismatch{})
+}
+
 func TestClient_NewClientFromTrustedStore(t *testing.T) {
 	// 1) Initiate DB and fill with a "trusted" header
 	db := dbs.New(dbm.NewMemDB(), chainID)
@@ -316,7 +316,7 @@ func (c *Client) examineConflictingHeaderAgainstTrace(
 			// before sending back the divergent block and trace we need to ensure we have verified
 			// the final gap between the previouslyVerifiedBlock and the targetBlock
 			if previouslyVerifiedBlock.Height != targetBlock.Height {
-				sourceTrace, err = c.verifySkipping(ctx, source, previouslyVerifiedBlock, targetBlock, now)
+				sourceTrace, err = c.verifySkipping(ctx, source, previouslyVerifiedBlock, targetBlock, now, false)
 				if err != nil {
 					return nil, nil, ErrVerifySkipping{Err: err}
 				}
@@ -346,7 +346,7 @@ func (c *Client) examineConflictingHeaderAgainstTrace(
 
 		// we check that the source provider can verify a block at the same height of the
 		// intermediate height
-		sourceTrace, err = c.verifySkipping(ctx, source, previouslyVerifiedBlock, sourceBlock, now)
+		sourceTrace, err = c.verifySkipping(ctx, source, previouslyVerifiedBlock, sourceBlock, now, false)
 		if err != nil {
 			return nil, nil, ErrVerifySkipping{Err: err}
 		}
@@ -61,6 +61,15 @@ func (e ErrTargetBlockHeightLessThanTrusted) Error() string {
 	return fmt.Sprintf("target block has a height lower than the trusted height (%d < %d)", e.Target, e.Trusted)
 }
 
+type ErrTargetBlockHeightGreaterThanTrusted struct {
+	Target  int64
+	Trusted int64
+}
+
+func (e ErrTargetBlockHeightGreaterThanTrusted) Error() string {
+	return fmt.Sprintf("target block has a height greater than the trusted height (%d > %d)", e.Target, e.Trusted)
+}
+
 type ErrHeaderHeightNotMonotonic struct {
 	GotHeight int64
 	OldHeight int64
@@ -252,6 +261,19 @@ func (e ErrInvalidTrustOptions) Unwrap() error {
 	return e.Err
 }
 
+type ErrInvalidBackwardsCacheSize struct {
+	Size int
+	Err  error
+}
+
+func (e ErrInvalidBackwardsCacheSize) Error() string {
+	return fmt.Sprintf("invalid backwards cache size %d: %v", e.Size, e.Err)
+}
+
+func (e ErrInvalidBackwardsCacheSize) Unwrap() error {
+	return e.Err
+}
+
 type ErrGetTrustedBlock struct {
 	Err error
 }
@@ -161,6 +161,7 @@ func (memR *Reactor) Receive(e p2p.Envelope) {
 		}
 	default:
 		memR.Logger.Error("unknown message type", "src", e.Src, "chId", e.ChannelID, "msg", e.Message)
+		memR.Switch.ReportPeerBehavior(e.Src, p2p.PeerBehaviorInvalidMessage)
 		memR.Switch.StopPeerForError(e.Src, fmt.Errorf("mempool cannot handle message of type: %T", e.Message))
 		return
 	}
@@ -129,6 +129,19 @@ func (e ErrAddUnconditionalPeerIDs) Unwrap() error {
 	return e.Err
 }
 
+// ErrAddPersistentAllowlistPeers is returned when the node fails to add peers from the persistent_allowlist_peers field.
+type ErrAddPersistentAllowlistPeers struct {
+	Err error
+}
+
+func (e ErrAddPersistentAllowlistPeers) Error() string {
+	return fmt.Sprintf("could not add peers from persistent_allowlist_peers field: %v", e.Err)
+}
+
+func (e ErrAddPersistentAllowlistPeers) Unwrap() error {
+	return e.Err
+}
+
 // ErrCreateAddrBook is returned when the node fails to create the address book.
 type ErrCreateAddrBook struct {
 	Err error
@@ -260,3 +273,17 @@ func (e ErrorLoadOrGenNodeKey) Error() string {
 func (e ErrorLoadOrGenNodeKey) Unwrap() error {
 	return e.Err
 }
+
+// ErrCreateKafkaSink is returned when the node fails to create or start the
+// Kafka event sink.
+type ErrCreateKafkaSink struct {
+	Err error
+}
+
+func (e ErrCreateKafkaSink) Error() string {
+	return fmt.Sprintf("failed to create Kafka event sink: %v", e.Err)
+}
+
+func (e ErrCreateKafkaSink) Unwrap() error {
+	return e.Err
+}
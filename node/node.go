@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -34,6 +35,7 @@ import (
 	rpcserver "github.com/cometbft/cometbft/rpc/jsonrpc/server"
 	sm "github.com/cometbft/cometbft/state"
 	"github.com/cometbft/cometbft/state/indexer"
+	"github.com/cometbft/cometbft/state/indexer/sink/kafka"
 	"github.com/cometbft/cometbft/state/txindex"
 	"github.com/cometbft/cometbft/state/txindex/null"
 	"github.com/cometbft/cometbft/statesync"
@@ -51,6 +53,7 @@ type Node struct {
 	// config
 	config        *cfg.Config
 	genesisDoc    *types.GenesisDoc   // initial validator set
+	genesisHash   []byte              // SHA-256 checksum of the genesis file
 	privValidator types.PrivValidator // local node's validator key
 
 	// network
@@ -79,9 +82,11 @@ type Node struct {
 	evidencePool      *evidence.Pool          // tracking evidence
 	proxyApp          proxy.AppConns          // connection to the application
 	rpcListeners      []net.Listener          // rpc servers
+	rpcMetrics        *rpccore.Metrics
 	txIndexer         txindex.TxIndexer
 	blockIndexer      indexer.BlockIndexer
 	indexerService    *txindex.IndexerService
+	kafkaSink         *kafka.EventSink // nil if the Kafka event sink is disabled
 	prometheusSrv     *http.Server
 	pprofSrv          *http.Server
 }
@@ -188,6 +193,7 @@ func BootstrapState(ctx context.Context, config *cfg.Config, dbProvider cfg.DBPr
 		DiscardABCIResponses: config.Storage.DiscardABCIResponses,
 		Logger:               logger,
 		DBKeyLayout:          config.Storage.ExperimentalKeyLayout,
+		RetainHeightJournal:  config.Storage.Pruning.RetainHeightJournal,
 	})
 
 	defer func() {
@@ -290,8 +296,13 @@ func NewNode(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	// The hash of the genesis file was checked and saved in the database.
+	genDocHash, err := stateDB.Get(genesisDocHashKey)
+	if err != nil {
+		return nil, ErrRetrieveGenesisDocHash{Err: err}
+	}
 
-	csMetrics, p2pMetrics, memplMetrics, smMetrics, bstMetrics, abciMetrics, bsMetrics, ssMetrics := metricsProvider(genDoc.ChainID)
+	csMetrics, p2pMetrics, memplMetrics, smMetrics, bstMetrics, abciMetrics, bsMetrics, ssMetrics, rpcMetrics := metricsProvider(genDoc.ChainID)
 	stateStore := sm.NewStore(stateDB, sm.StoreOptions{
 		DiscardABCIResponses: config.Storage.DiscardABCIResponses,
 		Metrics:              smMetrics,
@@ -299,6 +310,7 @@ func NewNode(ctx context.Context,
 		CompactionInterval:   config.Storage.CompactionInterval,
 		Logger:               logger,
 		DBKeyLayout:          config.Storage.ExperimentalKeyLayout,
+		RetainHeightJournal:  config.Storage.Pruning.RetainHeightJournal,
 	})
 
 	blockStore := store.NewBlockStore(blockStoreDB, store.WithMetrics(bstMetrics), store.WithCompaction(config.Storage.Compact, config.Storage.CompactionInterval), store.WithDBKeyLayout(config.Storage.ExperimentalKeyLayout), store.WithDBKeyLayout(config.Storage.ExperimentalKeyLayout))
@@ -336,6 +348,11 @@ func NewNode(ctx context.Context,
 		return nil, err
 	}
 
+	kafkaSink, err := createAndStartKafkaSink(config, genDoc.ChainID, eventBus, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	// If an address is provided, listen on the socket for a connection from an
 	// external signing process.
 	if config.PrivValidatorListenAddr != "" {
@@ -395,6 +412,7 @@ func NewNode(ctx context.Context,
 		blockIndexer,
 		stateStore,
 		blockStore,
+		proxyApp.Snapshot(),
 		smMetrics,
 		logger.With("module", "state"),
 	)
@@ -422,7 +440,8 @@ func NewNode(ctx context.Context,
 		}
 	}
 	// Don't start block sync if we're doing a state sync first.
-	bcReactor, err := createBlocksyncReactor(config, state, blockExec, blockStore, blockSync && !stateSync, logger, bsMetrics, offlineStateSyncHeight)
+	bcReactor, err := createBlocksyncReactor(config, state, blockExec, blockStore, blockSync && !stateSync, logger, bsMetrics,
+		offlineStateSyncHeight, pruner.SubscribeBaseAdvanced())
 	if err != nil {
 		return nil, ErrCreateBlockSyncReactor{Err: err}
 	}
@@ -445,6 +464,7 @@ func NewNode(ctx context.Context,
 		proxyApp.Snapshot(),
 		proxyApp.Query(),
 		ssMetrics,
+		statesync.ResumeDir(filepath.Join(config.DBDir(), "statesync")),
 	)
 	stateSyncReactor.SetLogger(logger.With("module", "statesync"))
 
@@ -471,6 +491,11 @@ func NewNode(ctx context.Context,
 		return nil, ErrAddUnconditionalPeerIDs{Err: err}
 	}
 
+	err = sw.AddPersistentAllowlistPeers(splitAndTrimEmpty(config.P2P.PersistentAllowlistPeers, ",", " "))
+	if err != nil {
+		return nil, ErrAddPersistentAllowlistPeers{Err: err}
+	}
+
 	addrBook, err := createAddrBookAndSetOnSwitch(config, sw, p2pLogger, nodeKey)
 	if err != nil {
 		return nil, ErrCreateAddrBook{Err: err}
@@ -499,6 +524,7 @@ func NewNode(ctx context.Context,
 	node := &Node{
 		config:        config,
 		genesisDoc:    genDoc,
+		genesisHash:   genDocHash,
 		privValidator: privValidator,
 
 		transport: transport,
@@ -523,8 +549,10 @@ func NewNode(ctx context.Context,
 		proxyApp:         proxyApp,
 		txIndexer:        txIndexer,
 		indexerService:   indexerService,
+		kafkaSink:        kafkaSink,
 		blockIndexer:     blockIndexer,
 		eventBus:         eventBus,
+		rpcMetrics:       rpcMetrics,
 	}
 	node.BaseService = *service.NewBaseService(logger, "Node", node)
 
@@ -624,6 +652,11 @@ func (n *Node) OnStop() {
 	if err := n.indexerService.Stop(); err != nil {
 		n.Logger.Error("Error closing indexerService", "err", err)
 	}
+	if n.kafkaSink != nil {
+		if err := n.kafkaSink.Stop(); err != nil {
+			n.Logger.Error("Error closing Kafka event sink", "err", err)
+		}
+	}
 
 	// now stop the reactors
 	if err := n.sw.Stop(); err != nil {
@@ -697,9 +730,11 @@ func (n *Node) ConfigureRPC() (*rpccore.Environment, error) {
 		ConsensusState: n.consensusState,
 		P2PPeers:       n.sw,
 		P2PTransport:   n,
+		Pruner:         n.pruner,
 		PubKey:         pubKey,
 
 		GenDoc:           n.genesisDoc,
+		GenDocHash:       n.genesisHash,
 		TxIndexer:        n.txIndexer,
 		BlockIndexer:     n.blockIndexer,
 		ConsensusReactor: n.consensusReactor,
@@ -709,11 +744,15 @@ func (n *Node) ConfigureRPC() (*rpccore.Environment, error) {
 
 		Logger: n.Logger.With("module", "rpc"),
 
-		Config: *n.config.RPC,
+		Config:  *n.config.RPC,
+		Metrics: n.rpcMetrics,
 	}
 	if err := rpcCoreEnv.InitGenesisChunks(); err != nil {
 		return nil, err
 	}
+	if err := rpcCoreEnv.InitABCIQueryCache(); err != nil {
+		return nil, err
+	}
 	return &rpcCoreEnv, nil
 }
 
@@ -735,10 +774,15 @@ func (n *Node) startRPC() ([]net.Listener, error) {
 	config.MaxBodyBytes = n.config.RPC.MaxBodyBytes
 	config.MaxHeaderBytes = n.config.RPC.MaxHeaderBytes
 	config.MaxOpenConnections = n.config.RPC.MaxOpenConnections
+	config.ReadTimeout = n.config.RPC.ReadTimeout
+	config.WriteTimeout = n.config.RPC.WriteTimeout
+	config.IdleTimeout = n.config.RPC.IdleTimeout
+	config.Compression = n.config.RPC.Compression
+	config.CompressionMinBytes = n.config.RPC.CompressionMinBytes
 	// If necessary adjust global WriteTimeout to ensure it's greater than
 	// TimeoutBroadcastTxCommit.
 	// See https://github.com/tendermint/tendermint/issues/3435
-	if config.WriteTimeout <= n.config.RPC.TimeoutBroadcastTxCommit {
+	if config.WriteTimeout > 0 && config.WriteTimeout <= n.config.RPC.TimeoutBroadcastTxCommit {
 		config.WriteTimeout = n.config.RPC.TimeoutBroadcastTxCommit + 1*time.Second
 	}
 
@@ -1028,6 +1072,7 @@ func createPruner(
 	blockIndexer indexer.BlockIndexer,
 	stateStore sm.Store,
 	blockStore *store.BlockStore,
+	snapshotConn proxy.AppConnSnapshot,
 	metrics *sm.Metrics,
 	logger log.Logger,
 ) (*sm.Pruner, error) {
@@ -1037,6 +1082,28 @@ func createPruner(
 
 	prunerOpts := []sm.PrunerOption{
 		sm.WithPrunerInterval(config.Storage.Pruning.Interval),
+		sm.WithABCIPruningInterval(config.Storage.Pruning.ABCIResponsesInterval),
+		sm.WithPrunerStatePruningRetries(
+			config.Storage.Pruning.StatePruningRetries,
+			config.Storage.Pruning.StatePruningRetryBackoff,
+		),
+		sm.WithPrunerTransientErrorRetries(
+			config.Storage.Pruning.TransientErrorRetries,
+			config.Storage.Pruning.TransientErrorRetryBackoff,
+		),
+		sm.WithPrunerInitialAppRetainHeight(config.Storage.Pruning.InitialApplicationRetainHeight),
+		sm.WithPrunerLease(config.Storage.Pruning.LeaseTTL),
+		sm.WithPrunerNearTipWarnThreshold(config.Storage.Pruning.NearTipWarnThreshold),
+		sm.WithPrunerABCIDivergenceWarn(config.Storage.Pruning.ABCIDivergenceWarnThreshold),
+		sm.WithPrunerABCIResCatchUp(
+			config.Storage.Pruning.ABCIResponsesCatchUpStep,
+			config.Storage.Pruning.ABCIResponsesMaxHeightsPerRun,
+		),
+		sm.WithPrunerEvidenceMaxAgeBlocks(config.Storage.Pruning.EvidenceMaxAgeBlocks),
+		sm.WithPrunerVerifyBeforePrune(config.Storage.Pruning.VerifyBeforePrune),
+		sm.WithPrunerEvidenceWindowCheck(config.Storage.Pruning.CheckEvidenceWindow),
+		sm.WithPrunerPrefetch(config.Storage.Pruning.PrefetchBlocks),
+		sm.WithPrunerABCIResponsesDiscarded(config.Storage.DiscardABCIResponses),
 		sm.WithPrunerMetrics(metrics),
 	}
 
@@ -1049,10 +1116,22 @@ func createPruner(
 		if err != nil {
 			return nil, err
 		}
-		prunerOpts = append(prunerOpts, sm.WithPrunerCompanionEnabled())
+		prunerOpts = append(prunerOpts,
+			sm.WithPrunerCompanionEnabled(),
+			sm.WithPrunerCompanionSafetyMargin(config.Storage.Pruning.DataCompanion.SafetyMargin),
+		)
+	}
+	if config.Storage.Compact {
+		prunerOpts = append(prunerOpts, sm.WithPrunerRetainHeightKeysCompaction(config.Storage.CompactionInterval))
+	}
+	if config.Storage.Pruning.StateCompactionInterval > 0 {
+		prunerOpts = append(prunerOpts, sm.WithStateCompactionInterval(config.Storage.Pruning.StateCompactionInterval))
+	}
+	if config.Storage.Pruning.KeepServableSnapshots {
+		prunerOpts = append(prunerOpts, sm.WithPrunerSnapshotHeightProvider(sm.NewAppSnapshotHeightProvider(snapshotConn)))
 	}
 
-	return sm.NewPruner(stateStore, blockStore, blockIndexer, txIndexer, logger, prunerOpts...), nil
+	return sm.NewPrunerWithValidation(stateStore, blockStore, blockIndexer, txIndexer, logger, prunerOpts...)
 }
 
 // Set the initial application retain height to 0 to avoid the data companion
@@ -620,7 +620,7 @@ func TestNodeGenesisHashFlagMatch(t *testing.T) {
 	incomingChecksum := tmhash.Sum(jsonBlob)
 	// Set genesis flag value to incorrect hash
 	config.Storage.GenesisHash = hex.EncodeToString(incomingChecksum)
-	_, err = NewNode(
+	n, err := NewNode(
 		context.Background(),
 		config,
 		privval.LoadOrGenFilePV(config.PrivValidatorKeyFile(), config.PrivValidatorStateFile()),
@@ -632,6 +632,11 @@ func TestNodeGenesisHashFlagMatch(t *testing.T) {
 		log.TestingLogger(),
 	)
 	require.NoError(t, err)
+
+	// The hash is exposed to the RPC, for the /status endpoint.
+	env, err := n.ConfigureRPC()
+	require.NoError(t, err)
+	require.Equal(t, incomingChecksum, env.GenDocHash)
 }
 
 func TestNodeGenesisHashFlagMismatch(t *testing.T) {
@@ -664,11 +669,14 @@ func TestNodeGenesisHashFlagMismatch(t *testing.T) {
 		log.TestingLogger(),
 	)
 	require.ErrorIs(t, err, ErrPassedGenesisHashMismatch, "NewNode should error when genesis flag value is incorrectly set")
+	mismatchErr := err
 
 	f, err = os.ReadFile(config.GenesisFile())
 	require.NoError(t, err)
 
 	genHash := tmhash.Sum(f)
+	// Both the expected and the computed hashes are reported.
+	require.ErrorContains(t, mismatchErr, fmt.Sprintf("expected %X, computed %X", flagHash, genHash))
 
 	genHashMismatch := bytes.Equal(genHash, flagHash)
 	require.False(t, genHashMismatch)
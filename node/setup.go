@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"strings"
@@ -29,9 +30,11 @@ import (
 	"github.com/cometbft/cometbft/p2p/pex"
 	"github.com/cometbft/cometbft/privval"
 	"github.com/cometbft/cometbft/proxy"
+	rpccore "github.com/cometbft/cometbft/rpc/core"
 	sm "github.com/cometbft/cometbft/state"
 	"github.com/cometbft/cometbft/state/indexer"
 	"github.com/cometbft/cometbft/state/indexer/block"
+	"github.com/cometbft/cometbft/state/indexer/sink/kafka"
 	"github.com/cometbft/cometbft/state/txindex"
 	"github.com/cometbft/cometbft/statesync"
 	"github.com/cometbft/cometbft/store"
@@ -57,15 +60,14 @@ type GenesisDocProvider func() (ChecksummedGenesisDoc, error)
 // the GenesisDoc from the config.GenesisFile() on the filesystem.
 func DefaultGenesisDocProviderFunc(config *cfg.Config) GenesisDocProvider {
 	return func() (ChecksummedGenesisDoc, error) {
-		// FIXME: find a way to stream the file incrementally,
-		// for the JSON	parser and the checksum computation.
-		// https://github.com/cometbft/cometbft/issues/1302
-		jsonBlob, err := os.ReadFile(config.GenesisFile())
+		// The file is streamed, for the checksum computation and the JSON
+		// parser, so that very large genesis files are never held in memory
+		// at once. The app state is only read from the file when needed.
+		incomingChecksum, err := genesisFileChecksum(config.GenesisFile())
 		if err != nil {
 			return ChecksummedGenesisDoc{}, ErrorReadingGenesisDoc{Err: err}
 		}
-		incomingChecksum := tmhash.Sum(jsonBlob)
-		genDoc, err := types.GenesisDocFromJSON(jsonBlob)
+		genDoc, err := types.GenesisDocFromFileStreaming(config.GenesisFile())
 		if err != nil {
 			return ChecksummedGenesisDoc{}, err
 		}
@@ -73,6 +75,20 @@ func DefaultGenesisDocProviderFunc(config *cfg.Config) GenesisDocProvider {
 	}
 }
 
+// genesisFileChecksum returns the SHA256 checksum of the genesis file.
+func genesisFileChecksum(genDocFile string) ([]byte, error) {
+	f, err := os.Open(genDocFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hasher := tmhash.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return nil, err
+	}
+	return hasher.Sum(nil), nil
+}
+
 // Provider takes a config and a logger and returns a ready to go Node.
 type Provider func(*cfg.Config, log.Logger) (*Node, error)
 
@@ -97,12 +113,12 @@ func DefaultNewNode(config *cfg.Config, logger log.Logger) (*Node, error) {
 }
 
 // MetricsProvider returns a consensus, p2p and mempool Metrics.
-type MetricsProvider func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics, *store.Metrics, *proxy.Metrics, *blocksync.Metrics, *statesync.Metrics)
+type MetricsProvider func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics, *store.Metrics, *proxy.Metrics, *blocksync.Metrics, *statesync.Metrics, *rpccore.Metrics)
 
 // DefaultMetricsProvider returns Metrics build using Prometheus client library
 // if Prometheus is enabled. Otherwise, it returns no-op Metrics.
 func DefaultMetricsProvider(config *cfg.InstrumentationConfig) MetricsProvider {
-	return func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics, *store.Metrics, *proxy.Metrics, *blocksync.Metrics, *statesync.Metrics) {
+	return func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics, *store.Metrics, *proxy.Metrics, *blocksync.Metrics, *statesync.Metrics, *rpccore.Metrics) {
 		if config.Prometheus {
 			return cs.PrometheusMetrics(config.Namespace, "chain_id", chainID),
 				p2p.PrometheusMetrics(config.Namespace, "chain_id", chainID),
@@ -111,9 +127,10 @@ func DefaultMetricsProvider(config *cfg.InstrumentationConfig) MetricsProvider {
 				store.PrometheusMetrics(config.Namespace, "chain_id", chainID),
 				proxy.PrometheusMetrics(config.Namespace, "chain_id", chainID),
 				blocksync.PrometheusMetrics(config.Namespace, "chain_id", chainID),
-				statesync.PrometheusMetrics(config.Namespace, "chain_id", chainID)
+				statesync.PrometheusMetrics(config.Namespace, "chain_id", chainID),
+				rpccore.PrometheusMetrics(config.Namespace, "chain_id", chainID)
 		}
-		return cs.NopMetrics(), p2p.NopMetrics(), mempl.NopMetrics(), sm.NopMetrics(), store.NopMetrics(), proxy.NopMetrics(), blocksync.NopMetrics(), statesync.NopMetrics()
+		return cs.NopMetrics(), p2p.NopMetrics(), mempl.NopMetrics(), sm.NopMetrics(), store.NopMetrics(), proxy.NopMetrics(), blocksync.NopMetrics(), statesync.NopMetrics(), rpccore.NopMetrics()
 	}
 }
 
@@ -183,6 +200,37 @@ func createAndStartIndexerService(
 	return indexerService, txIndexer, blockIndexer, nil
 }
 
+// createAndStartKafkaSink creates and starts the Kafka event sink if it is
+// enabled in the configuration. Otherwise, it returns nil.
+func createAndStartKafkaSink(
+	config *cfg.Config,
+	chainID string,
+	eventBus *types.EventBus,
+	logger log.Logger,
+) (*kafka.EventSink, error) {
+	if !config.KafkaSink.Enabled {
+		return nil, nil
+	}
+	producer, err := kafka.NewProducer(config.KafkaSink)
+	if err != nil {
+		return nil, ErrCreateKafkaSink{Err: err}
+	}
+	metrics := kafka.NopMetrics()
+	if config.Instrumentation.Prometheus {
+		metrics = kafka.PrometheusMetrics(config.Instrumentation.Namespace, "chain_id", chainID)
+	}
+	kafkaSink := kafka.NewEventSink(producer, eventBus,
+		kafka.WithMetrics(metrics),
+		kafka.WithQueueSize(config.KafkaSink.QueueSize),
+		kafka.WithMaxRetries(config.KafkaSink.MaxRetries),
+	)
+	kafkaSink.SetLogger(logger.With("module", "kafka_sink"))
+	if err := kafkaSink.Start(); err != nil {
+		return nil, ErrCreateKafkaSink{Err: err}
+	}
+	return kafkaSink, nil
+}
+
 func doHandshake(
 	ctx context.Context,
 	stateStore sm.Store,
@@ -304,10 +352,13 @@ func createBlocksyncReactor(config *cfg.Config,
 	logger log.Logger,
 	metrics *blocksync.Metrics,
 	offlineStateSyncHeight int64,
+	baseAdvanced <-chan sm.BaseAdvancedInfo,
 ) (bcReactor p2p.Reactor, err error) {
 	switch config.BlockSync.Version {
 	case "v0":
-		bcReactor = blocksync.NewReactor(state.Copy(), blockExec, blockStore, blockSync, metrics, offlineStateSyncHeight)
+		bcR := blocksync.NewReactor(state.Copy(), blockExec, blockStore, blockSync, metrics, offlineStateSyncHeight)
+		bcR.SetBaseAdvancedChannel(baseAdvanced)
+		bcReactor = bcR
 	case "v1", "v2":
 		return nil, fmt.Errorf("block sync version %s has been deprecated. Please use v0", config.BlockSync.Version)
 	default:
@@ -416,7 +467,9 @@ func createTransport(
 	p2p.MultiplexTransportConnFilters(connFilters...)(transport)
 
 	// Limit the number of incoming connections.
-	max := config.P2P.MaxNumInboundPeers + len(splitAndTrimEmpty(config.P2P.UnconditionalPeerIDs, ",", " "))
+	max := config.P2P.MaxNumInboundPeers +
+		len(splitAndTrimEmpty(config.P2P.UnconditionalPeerIDs, ",", " ")) +
+		len(splitAndTrimEmpty(config.P2P.PersistentAllowlistPeers, ",", " "))
 	p2p.MultiplexTransportMaxIncomingConnections(max)(transport)
 
 	return transport, peerFilters
@@ -558,6 +611,13 @@ func startStateSync(
 			ssR.Logger.Error("Failed to switch to block sync", "err", err)
 			return
 		}
+
+		if config.Backfill {
+			err = ssR.Backfill(stateProvider, state, stateStore, blockStore)
+			if err != nil {
+				ssR.Logger.Error("Backfill failed", "err", err)
+			}
+		}
 	}()
 	return nil
 }
@@ -596,7 +656,8 @@ func LoadStateFromDBOrGenesisDocProviderWithConfig(
 			return sm.State{}, nil, ErrGenesisHashDecode
 		}
 		if !bytes.Equal(csGenDoc.Sha256Checksum, decodedOperatorGenesisHash) {
-			return sm.State{}, nil, ErrPassedGenesisHashMismatch
+			return sm.State{}, nil, fmt.Errorf("%w: expected %X, computed %X",
+				ErrPassedGenesisHashMismatch, decodedOperatorGenesisHash, csGenDoc.Sha256Checksum)
 		}
 	}
 
@@ -607,7 +668,8 @@ func LoadStateFromDBOrGenesisDocProviderWithConfig(
 		}
 	} else {
 		if !bytes.Equal(genDocHash, csGenDoc.Sha256Checksum) {
-			return sm.State{}, nil, ErrLoadedGenesisDocHashMismatch
+			return sm.State{}, nil, fmt.Errorf("%w: expected %X, computed %X",
+				ErrLoadedGenesisDocHashMismatch, genDocHash, csGenDoc.Sha256Checksum)
 		}
 	}
 
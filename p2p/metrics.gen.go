@@ -56,17 +56,45 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "message_send_bytes_total",
 			Help:      "Number of bytes of each message type sent.",
 		}, append(labels, "message_type")).With(labelsAndValues...),
+		ReactorReceiveBytesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "reactor_receive_bytes_total",
+			Help:      "Number of bytes received on each channel, labeled with the name of the reactor owning the channel.",
+		}, append(labels, "chID", "reactor")).With(labelsAndValues...),
+		ReactorSendBytesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "reactor_send_bytes_total",
+			Help:      "Number of bytes sent on each channel, labeled with the name of the reactor owning the channel.",
+		}, append(labels, "chID", "reactor")).With(labelsAndValues...),
+		ReactorReceiveMessagesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "reactor_receive_messages_total",
+			Help:      "Number of messages received on each channel, labeled with the name of the reactor owning the channel.",
+		}, append(labels, "chID", "reactor")).With(labelsAndValues...),
+		ReactorSendMessagesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "reactor_send_messages_total",
+			Help:      "Number of messages sent on each channel, labeled with the name of the reactor owning the channel.",
+		}, append(labels, "chID", "reactor")).With(labelsAndValues...),
 	}
 }
 
 func NopMetrics() *Metrics {
 	return &Metrics{
-		Peers:                    discard.NewGauge(),
-		PeerReceiveBytesTotal:    discard.NewCounter(),
-		PeerSendBytesTotal:       discard.NewCounter(),
-		PeerPendingSendBytes:     discard.NewGauge(),
-		NumTxs:                   discard.NewGauge(),
-		MessageReceiveBytesTotal: discard.NewCounter(),
-		MessageSendBytesTotal:    discard.NewCounter(),
+		Peers:                       discard.NewGauge(),
+		PeerReceiveBytesTotal:       discard.NewCounter(),
+		PeerSendBytesTotal:          discard.NewCounter(),
+		PeerPendingSendBytes:        discard.NewGauge(),
+		NumTxs:                      discard.NewGauge(),
+		MessageReceiveBytesTotal:    discard.NewCounter(),
+		MessageSendBytesTotal:       discard.NewCounter(),
+		ReactorReceiveBytesTotal:    discard.NewCounter(),
+		ReactorSendBytesTotal:       discard.NewCounter(),
+		ReactorReceiveMessagesTotal: discard.NewCounter(),
+		ReactorSendMessagesTotal:    discard.NewCounter(),
 	}
 }
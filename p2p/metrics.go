@@ -38,18 +38,33 @@ type Metrics struct {
 	MessageReceiveBytesTotal metrics.Counter `metrics_labels:"message_type"`
 	// Number of bytes of each message type sent.
 	MessageSendBytesTotal metrics.Counter `metrics_labels:"message_type"`
+	// Number of bytes received on each channel, labeled with the name of the
+	// reactor owning the channel.
+	ReactorReceiveBytesTotal metrics.Counter `metrics_labels:"chID,reactor"`
+	// Number of bytes sent on each channel, labeled with the name of the
+	// reactor owning the channel.
+	ReactorSendBytesTotal metrics.Counter `metrics_labels:"chID,reactor"`
+	// Number of messages received on each channel, labeled with the name of
+	// the reactor owning the channel.
+	ReactorReceiveMessagesTotal metrics.Counter `metrics_labels:"chID,reactor"`
+	// Number of messages sent on each channel, labeled with the name of the
+	// reactor owning the channel.
+	ReactorSendMessagesTotal metrics.Counter `metrics_labels:"chID,reactor"`
 }
 
 type metricsLabelCache struct {
 	mtx               *sync.RWMutex
 	messageLabelNames map[reflect.Type]string
 	chIDLabelNames    map[byte]string
+	reactorLabelNames map[byte]string
 }
 
-// RegisterChID pre-allocates the metric label for a chID.
+// RegisterChID pre-allocates the metric labels for a chID and the name of the
+// reactor owning it.
 // Labels are populated by the switch, before the p2p layer is started.
-func (m *metricsLabelCache) RegisterChID(chID byte) {
+func (m *metricsLabelCache) RegisterChID(chID byte, reactorName string) {
 	m.chIDLabelNames[chID] = fmt.Sprintf("%#x", chID)
+	m.reactorLabelNames[chID] = reactorName
 }
 
 // ChIDToMetricLabel returns the metric label for a chID.
@@ -58,6 +73,12 @@ func (m *metricsLabelCache) ChIDToMetricLabel(chID byte) string {
 	return m.chIDLabelNames[chID]
 }
 
+// ReactorToMetricLabel returns the metric label for the reactor owning a chID.
+// No need for synchronization, as labels, once populated, never change.
+func (m *metricsLabelCache) ReactorToMetricLabel(chID byte) string {
+	return m.reactorLabelNames[chID]
+}
+
 // ValueToMetricLabel is a method that is used to produce a prometheus label value of the golang
 // type that is passed in.
 // This method uses a map on the Metrics struct so that each label name only needs
@@ -86,5 +107,6 @@ func newMetricsLabelCache() *metricsLabelCache {
 		mtx:               &sync.RWMutex{},
 		messageLabelNames: map[reflect.Type]string{},
 		chIDLabelNames:    map[byte]string{},
+		reactorLabelNames: map[byte]string{},
 	}
 }
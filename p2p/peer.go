@@ -288,6 +288,9 @@ func (p *peer) send(chID byte, msg proto.Message, sendFunc func(byte, []byte) bo
 		p.metrics.MessageSendBytesTotal.
 			With("message_type", metricLabelValue).
 			Add(float64(len(msgBytes)))
+		reactorLabels := []string{"chID", p.mlc.ChIDToMetricLabel(chID), "reactor", p.mlc.ReactorToMetricLabel(chID)}
+		p.metrics.ReactorSendBytesTotal.With(reactorLabels...).Add(float64(len(msgBytes)))
+		p.metrics.ReactorSendMessagesTotal.With(reactorLabels...).Add(1)
 	}
 	return res
 }
@@ -426,6 +429,9 @@ func createMConnection(
 		p.metrics.MessageReceiveBytesTotal.
 			With("message_type", p.mlc.ValueToMetricLabel(msg)).
 			Add(float64(len(msgBytes)))
+		reactorLabels := []string{"chID", p.mlc.ChIDToMetricLabel(chID), "reactor", p.mlc.ReactorToMetricLabel(chID)}
+		p.metrics.ReactorReceiveBytesTotal.With(reactorLabels...).Add(float64(len(msgBytes)))
+		p.metrics.ReactorReceiveMessagesTotal.With(reactorLabels...).Add(1)
 		reactor.Receive(Envelope{
 			ChannelID: chID,
 			Src:       p,
@@ -0,0 +1,114 @@
+package p2p
+
+import (
+	"github.com/cometbft/cometbft/config"
+	cmtsync "github.com/cometbft/cometbft/libs/sync"
+)
+
+// maxPeerScores is the maximum number of peers whose score is remembered.
+const maxPeerScores = 10000
+
+// PeerBehavior is a misbehavior of a peer, which lowers its score.
+type PeerBehavior int
+
+const (
+	// PeerBehaviorInvalidMessage is reported when a peer sends a message that
+	// can't be decoded or is invalid.
+	PeerBehaviorInvalidMessage PeerBehavior = iota
+	// PeerBehaviorSlowResponse is reported when a peer doesn't respond to a
+	// request in time.
+	PeerBehaviorSlowResponse
+	// PeerBehaviorDisconnect is reported when the connection to a peer is
+	// stopped because of an error.
+	PeerBehaviorDisconnect
+)
+
+// String returns a string representation of the PeerBehavior.
+func (b PeerBehavior) String() string {
+	switch b {
+	case PeerBehaviorInvalidMessage:
+		return "invalid_message"
+	case PeerBehaviorSlowResponse:
+		return "slow_response"
+	case PeerBehaviorDisconnect:
+		return "disconnect"
+	default:
+		return "unknown"
+	}
+}
+
+// peerScores keeps the scores of peers, which start at zero and decrease with
+// each misbehavior reported, according to the weights of the configuration.
+// Scores are kept by ID, so that they survive reconnections.
+type peerScores struct {
+	mtx    cmtsync.Mutex
+	cfg    *config.P2PConfig
+	scores map[ID]int64
+}
+
+func newPeerScores(cfg *config.P2PConfig) *peerScores {
+	return &peerScores{
+		cfg:    cfg,
+		scores: make(map[ID]int64),
+	}
+}
+
+// weight returns the score subtracted for behavior b.
+func (ps *peerScores) weight(b PeerBehavior) int64 {
+	switch b {
+	case PeerBehaviorInvalidMessage:
+		return ps.cfg.PeerScoreInvalidMessageWeight
+	case PeerBehaviorSlowResponse:
+		return ps.cfg.PeerScoreSlowResponseWeight
+	case PeerBehaviorDisconnect:
+		return ps.cfg.PeerScoreDisconnectWeight
+	default:
+		return 0
+	}
+}
+
+// report lowers the score of peer id for behavior b, and returns its new
+// score. If the scores of too many peers are kept, the score closest to zero
+// is forgotten.
+func (ps *peerScores) report(id ID, b PeerBehavior) int64 {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	w := ps.weight(b)
+	if w == 0 {
+		return ps.scores[id]
+	}
+	if _, ok := ps.scores[id]; !ok && len(ps.scores) >= maxPeerScores {
+		var (
+			forget  ID
+			highest int64
+			found   bool
+		)
+		for pid, score := range ps.scores {
+			if !found || score > highest {
+				forget, highest, found = pid, score, true
+			}
+		}
+		delete(ps.scores, forget)
+	}
+	ps.scores[id] -= w
+	return ps.scores[id]
+}
+
+// score returns the score of peer id.
+func (ps *peerScores) score(id ID) int64 {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+	return ps.scores[id]
+}
+
+// copy returns a copy of the scores of all peers with a non-zero score.
+func (ps *peerScores) copy() map[ID]int64 {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+	scores := make(map[ID]int64, len(ps.scores))
+	for id, score := range ps.scores {
+		scores[id] = score
+	}
+	return scores
+}
@@ -0,0 +1,43 @@
+package p2p
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+)
+
+func TestPeerScores(t *testing.T) {
+	cfg := config.DefaultP2PConfig()
+	cfg.PeerScoreSlowResponseWeight = 0
+	ps := newPeerScores(cfg)
+
+	require.Zero(t, ps.score("a"))
+	require.EqualValues(t, -cfg.PeerScoreInvalidMessageWeight, ps.report("a", PeerBehaviorInvalidMessage))
+	require.EqualValues(t, -cfg.PeerScoreInvalidMessageWeight-cfg.PeerScoreDisconnectWeight,
+		ps.report("a", PeerBehaviorDisconnect))
+	require.EqualValues(t, -cfg.PeerScoreDisconnectWeight, ps.report("b", PeerBehaviorDisconnect))
+
+	// Disabled behaviors don't change the score.
+	require.Zero(t, ps.report("c", PeerBehaviorSlowResponse))
+	require.Equal(t, map[ID]int64{
+		"a": -cfg.PeerScoreInvalidMessageWeight - cfg.PeerScoreDisconnectWeight,
+		"b": -cfg.PeerScoreDisconnectWeight,
+	}, ps.copy())
+}
+
+func TestPeerScoresForgetsScoreClosestToZero(t *testing.T) {
+	ps := newPeerScores(config.DefaultP2PConfig())
+	for i := 0; i < maxPeerScores; i++ {
+		ps.report(ID(fmt.Sprintf("peer%d", i)), PeerBehaviorInvalidMessage)
+	}
+	forgotten := ID(fmt.Sprintf("peer%d", maxPeerScores/2))
+	ps.scores[forgotten] = -1
+
+	ps.report("new", PeerBehaviorInvalidMessage)
+	require.Len(t, ps.copy(), maxPeerScores)
+	require.Zero(t, ps.score(forgotten))
+	require.Negative(t, ps.score("new"))
+}
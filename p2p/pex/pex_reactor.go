@@ -258,7 +258,7 @@ func (r *Reactor) Receive(e p2p.Envelope) {
 			// Check we're not receiving requests too frequently.
 			if err := r.receiveRequest(e.Src); err != nil {
 				r.Switch.StopPeerForError(e.Src, err)
-				r.book.MarkBad(e.Src.SocketAddr(), defaultBanTime)
+				r.markBad(e.Src.SocketAddr())
 				return
 			}
 			r.SendAddrs(e.Src, r.book.GetSelection())
@@ -268,15 +268,17 @@ func (r *Reactor) Receive(e p2p.Envelope) {
 		// If we asked for addresses, add them to the book
 		addrs, err := p2p.NetAddressesFromProto(msg.Addrs)
 		if err != nil {
+			r.Switch.ReportPeerBehavior(e.Src, p2p.PeerBehaviorInvalidMessage)
 			r.Switch.StopPeerForError(e.Src, err)
-			r.book.MarkBad(e.Src.SocketAddr(), defaultBanTime)
+			r.markBad(e.Src.SocketAddr())
 			return
 		}
 		err = r.ReceiveAddrs(addrs, e.Src)
 		if err != nil {
+			r.Switch.ReportPeerBehavior(e.Src, p2p.PeerBehaviorInvalidMessage)
 			r.Switch.StopPeerForError(e.Src, err)
 			if errors.Is(err, ErrUnsolicitedList) {
-				r.book.MarkBad(e.Src.SocketAddr(), defaultBanTime)
+				r.markBad(e.Src.SocketAddr())
 			}
 			return
 		}
@@ -538,7 +540,7 @@ func (r *Reactor) dialAttemptsInfo(addr *p2p.NetAddress) (attempts int, lastDial
 func (r *Reactor) dialPeer(addr *p2p.NetAddress) error {
 	attempts, lastDialed := r.dialAttemptsInfo(addr)
 	if !r.Switch.IsPeerPersistent(addr) && attempts > maxAttemptsToDial {
-		r.book.MarkBad(addr, defaultBanTime)
+		r.markBad(addr)
 		return ErrMaxAttemptsToDial{Max: maxAttemptsToDial}
 	}
 
@@ -737,6 +739,15 @@ func (r *Reactor) cleanupCrawlPeerInfos() {
 	}
 }
 
+// markBad marks addr as bad in the address book, unless it is the address of a
+// persistent allowlist peer, which must never be marked bad.
+func (r *Reactor) markBad(addr *p2p.NetAddress) {
+	if r.Switch.IsPeerAllowlisted(addr.ID) {
+		return
+	}
+	r.book.MarkBad(addr, defaultBanTime)
+}
+
 // attemptDisconnects checks if we've been with each peer long enough to disconnect.
 func (r *Reactor) attemptDisconnects() {
 	for _, peer := range r.Switch.Peers().Copy() {
@@ -29,6 +29,10 @@ const (
 	// ie. 3**10 = 16hrs.
 	reconnectBackOffAttempts    = 10
 	reconnectBackOffBaseSeconds = 3
+
+	// persistent allowlist peers are redialed forever, with an exponential
+	// backoff capped at this interval.
+	allowlistReconnectMaxInterval = time.Minute
 )
 
 // MConnConfig returns an MConnConfig with fields updated
@@ -86,6 +90,9 @@ type Switch struct {
 	// peers addresses with whom we'll maintain constant connection
 	persistentPeersAddrs []*NetAddress
 	unconditionalPeerIDs map[ID]struct{}
+	// peers addresses which are always dialed and retained, regardless of the
+	// address book
+	allowlistPeersAddrs map[ID]*NetAddress
 
 	transport Transport
 
@@ -96,6 +103,8 @@ type Switch struct {
 
 	metrics *Metrics
 	mlc     *metricsLabelCache
+
+	peerScores *peerScores
 }
 
 // NetAddress returns the address the switch is listening on.
@@ -127,7 +136,9 @@ func NewSwitch(
 		filterTimeout:        defaultFilterTimeout,
 		persistentPeersAddrs: make([]*NetAddress, 0),
 		unconditionalPeerIDs: make(map[ID]struct{}),
+		allowlistPeersAddrs:  make(map[ID]*NetAddress),
 		mlc:                  newMetricsLabelCache(),
+		peerScores:           newPeerScores(cfg),
 	}
 
 	// Ensure we have a completely undeterministic PRNG.
@@ -172,7 +183,7 @@ func (sw *Switch) AddReactor(name string, reactor Reactor) Reactor {
 		sw.chDescs = append(sw.chDescs, chDesc)
 		sw.reactorsByCh[chID] = reactor
 		sw.msgTypeByChID[chID] = chDesc.MessageType
-		sw.mlc.RegisterChID(chID)
+		sw.mlc.RegisterChID(chID, name)
 	}
 	sw.reactors[name] = reactor
 	reactor.SetSwitch(sw)
@@ -243,6 +254,11 @@ func (sw *Switch) OnStart() error {
 	// Start accepting Peers.
 	go sw.acceptRoutine()
 
+	// Dial the persistent allowlist peers.
+	for _, addr := range sw.allowlistPeersAddrs {
+		go sw.reconnectToAllowlistPeer(addr)
+	}
+
 	return nil
 }
 
@@ -311,6 +327,13 @@ func (sw *Switch) Peers() IPeerSet {
 	return sw.peers
 }
 
+// IsPeerAllowlisted returns true if the peer with the given ID is in the
+// persistent allowlist.
+func (sw *Switch) IsPeerAllowlisted(id ID) bool {
+	_, ok := sw.allowlistPeersAddrs[id]
+	return ok
+}
+
 // StopPeerForError disconnects from a peer due to external error.
 // If the peer is persistent, it will attempt to reconnect.
 // TODO: make record depending on reason.
@@ -320,8 +343,13 @@ func (sw *Switch) StopPeerForError(peer Peer, reason any) {
 	}
 
 	sw.Logger.Error("Stopping peer for error", "peer", peer, "err", reason)
+	sw.ReportPeerBehavior(peer, PeerBehaviorDisconnect)
 	sw.stopAndRemovePeer(peer, reason)
 
+	if addr, ok := sw.allowlistPeersAddrs[peer.ID()]; ok {
+		go sw.reconnectToAllowlistPeer(addr)
+		return
+	}
 	if peer.IsPersistent() {
 		var addr *NetAddress
 		if peer.IsOutbound() { // socket address for outbound peers
@@ -339,11 +367,31 @@ func (sw *Switch) StopPeerForError(peer Peer, reason any) {
 	}
 }
 
+// ReportPeerBehavior lowers the score of the peer for the given misbehavior,
+// according to the weights of the configuration. When the maximum number of
+// inbound peers is reached and peer eviction is enabled, the inbound peer with
+// the lowest score is evicted first.
+func (sw *Switch) ReportPeerBehavior(peer Peer, behavior PeerBehavior) {
+	score := sw.peerScores.report(peer.ID(), behavior)
+	sw.Logger.Debug("Peer misbehaved", "peer", peer.ID(), "behavior", behavior, "score", score)
+}
+
+// PeerScores returns the scores of the peers which misbehaved, connected or
+// not. Peers without a score have a score of zero.
+func (sw *Switch) PeerScores() map[ID]int64 {
+	return sw.peerScores.copy()
+}
+
 // StopPeerGracefully disconnects from a peer gracefully.
+// If the peer is in the persistent allowlist, it will attempt to reconnect.
 // TODO: handle graceful disconnects.
 func (sw *Switch) StopPeerGracefully(peer Peer) {
 	sw.Logger.Info("Stopping peer gracefully")
 	sw.stopAndRemovePeer(peer, nil)
+
+	if addr, ok := sw.allowlistPeersAddrs[peer.ID()]; ok && sw.IsRunning() {
+		go sw.reconnectToAllowlistPeer(addr)
+	}
 }
 
 func (sw *Switch) stopAndRemovePeer(peer Peer, reason any) {
@@ -430,6 +478,32 @@ func (sw *Switch) reconnectToPeer(addr *NetAddress) {
 	sw.Logger.Error("Failed to reconnect to peer. Giving up", "addr", addr, "elapsed", time.Since(start))
 }
 
+// reconnectToAllowlistPeer dials the persistent allowlist peer at addr until
+// it is connected or the switch is stopped, first immediately, then with an
+// exponential backoff capped at allowlistReconnectMaxInterval. Unlike
+// reconnectToPeer, it never gives up.
+func (sw *Switch) reconnectToAllowlistPeer(addr *NetAddress) {
+	if sw.reconnecting.Has(string(addr.ID)) {
+		return
+	}
+	sw.reconnecting.Set(string(addr.ID), addr)
+	defer sw.reconnecting.Delete(string(addr.ID))
+
+	interval := reconnectInterval
+	for i := 0; sw.IsRunning(); i++ {
+		err := sw.DialPeerWithAddress(addr)
+		if err == nil {
+			return // success
+		} else if _, ok := err.(ErrCurrentlyDialingOrExistingAddress); ok {
+			return
+		}
+
+		sw.Logger.Info("Error dialing allowlist peer. Trying again", "tries", i, "err", err, "addr", addr)
+		sw.randomSleep(interval)
+		interval = min(2*interval, allowlistReconnectMaxInterval)
+	}
+}
+
 // SetAddrBook allows to set address book on Switch.
 func (sw *Switch) SetAddrBook(addrBook AddrBook) {
 	sw.addrBook = addrBook
@@ -580,6 +654,36 @@ func (sw *Switch) AddPersistentPeers(addrs []string) error {
 	return nil
 }
 
+// AddPersistentAllowlistPeers allows you to set the persistent allowlist peers,
+// which are always dialed and redialed, bypassing the address book. They are
+// also persistent and unconditional peers, so they are not subject to the
+// limits on the number of peers and are never evicted. It must be called after
+// AddPersistentPeers. It ignores ErrNetAddressLookup. However, if there are
+// other errors, first encounter is returned.
+func (sw *Switch) AddPersistentAllowlistPeers(addrs []string) error {
+	sw.Logger.Info("Adding persistent allowlist peers", "addrs", addrs)
+	netAddrs, errs := NewNetAddressStrings(addrs)
+	// report all the errors
+	for _, err := range errs {
+		sw.Logger.Error("Error in peer's address", "err", err)
+	}
+	// return first non-ErrNetAddressLookup error
+	for _, err := range errs {
+		if errors.As(err, &ErrNetAddressLookup{}) {
+			continue
+		}
+		return err
+	}
+	for _, addr := range netAddrs {
+		sw.allowlistPeersAddrs[addr.ID] = addr
+		sw.unconditionalPeerIDs[addr.ID] = struct{}{}
+		if !sw.IsPeerPersistent(addr) {
+			sw.persistentPeersAddrs = append(sw.persistentPeersAddrs, addr)
+		}
+	}
+	return nil
+}
+
 func (sw *Switch) AddUnconditionalPeerIDs(ids []string) error {
 	sw.Logger.Info("Adding unconditional peer ids", "ids", ids)
 	for _, id := range ids {
@@ -679,6 +783,10 @@ func (sw *Switch) acceptRoutine() {
 		if !sw.IsPeerUnconditional(p.NodeInfo().ID()) {
 			// Ignore connection if we already have enough peers.
 			_, in, _ := sw.NumPeers()
+			if in >= sw.config.MaxNumInboundPeers && sw.config.PeerEviction {
+				sw.evictInboundPeer(p)
+				_, in, _ = sw.NumPeers()
+			}
 			if in >= sw.config.MaxNumInboundPeers {
 				sw.Logger.Info(
 					"Ignoring inbound connection: already have enough inbound peers",
@@ -707,6 +815,31 @@ func (sw *Switch) acceptRoutine() {
 	}
 }
 
+// evictInboundPeer stops the inbound peer with the lowest score to make room
+// for the new inbound peer p, if that score is lower than the one of p.
+// Persistent and unconditional peers are never evicted.
+func (sw *Switch) evictInboundPeer(p Peer) {
+	var (
+		lowest      Peer
+		lowestScore int64
+	)
+	for _, peer := range sw.peers.Copy() {
+		if peer.IsOutbound() || peer.IsPersistent() || sw.IsPeerUnconditional(peer.ID()) {
+			continue
+		}
+		if score := sw.peerScores.score(peer.ID()); lowest == nil || score < lowestScore {
+			lowest, lowestScore = peer, score
+		}
+	}
+	if lowest == nil || lowestScore >= sw.peerScores.score(p.ID()) {
+		return
+	}
+
+	sw.Logger.Info("Evicting inbound peer with the lowest score",
+		"peer", lowest.ID(), "score", lowestScore, "newPeer", p.ID())
+	sw.stopAndRemovePeer(lowest, nil)
+}
+
 // dial the peer; make secret connection; authenticate against the dialed ID;
 // add the peer.
 // if dialing fails, start the reconnect loop. If handshake fails, it's over.
@@ -467,6 +467,102 @@ func TestSwitchStopPeerForError(t *testing.T) {
 	assert.EqualValues(t, 0, peersMetricValue())
 }
 
+func TestSwitchReactorMetrics(t *testing.T) {
+	s := httptest.NewServer(promhttp.Handler())
+	defer s.Close()
+
+	// Use a namespace of our own, as the metrics are registered globally.
+	namespace := config.TestInstrumentationConfig().Namespace + "_reactor"
+	metricValue := func(name, chID, reactor string) float64 {
+		resp, err := http.Get(s.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		re := regexp.MustCompile(namespace + `_` + MetricsSubsystem + `_` + name +
+			`\{chID="` + chID + `",reactor="` + reactor + `"\} ([0-9\.]+)`)
+		matches := re.FindStringSubmatch(string(buf))
+		if matches == nil {
+			return 0
+		}
+		f, _ := strconv.ParseFloat(matches[1], 64)
+		return f
+	}
+
+	p2pMetrics := PrometheusMetrics(namespace)
+	sw1, sw2 := MakeSwitchPair(func(i int, sw *Switch) *Switch {
+		opt := WithMetrics(p2pMetrics)
+		opt(sw)
+		return initSwitchFunc(i, sw)
+	})
+	t.Cleanup(func() {
+		if err := sw1.Stop(); err != nil {
+			t.Error(err)
+		}
+		if err := sw2.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	msg := &p2pproto.PexAddrs{Addrs: []p2pproto.NetAddress{{ID: "1"}}}
+	msgBytes, err := proto.Marshal(msg.Wrap())
+	require.NoError(t, err)
+	p := sw1.Peers().Copy()[0]
+	require.True(t, p.Send(Envelope{ChannelID: 0x02, Message: msg}))
+	require.True(t, p.Send(Envelope{ChannelID: 0x02, Message: msg}))
+	require.True(t, p.Send(Envelope{ChannelID: 0x00, Message: msg}))
+
+	// Both switches share the metrics, so these are the totals of both.
+	require.Eventually(t, func() bool {
+		return metricValue("reactor_receive_messages_total", "0x2", "bar") == 2 &&
+			metricValue("reactor_receive_messages_total", "0x0", "foo") == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.EqualValues(t, 2, metricValue("reactor_send_messages_total", "0x2", "bar"))
+	assert.EqualValues(t, 1, metricValue("reactor_send_messages_total", "0x0", "foo"))
+	assert.EqualValues(t, 2*len(msgBytes), metricValue("reactor_send_bytes_total", "0x2", "bar"))
+	assert.EqualValues(t, 2*len(msgBytes), metricValue("reactor_receive_bytes_total", "0x2", "bar"))
+	assert.EqualValues(t, len(msgBytes), metricValue("reactor_receive_bytes_total", "0x0", "foo"))
+	assert.Zero(t, metricValue("reactor_send_messages_total", "0x1", "foo"))
+}
+
+func TestSwitchPersistentAllowlistPeer(t *testing.T) {
+	rp := &remotePeer{PrivKey: ed25519.GenPrivKey(), Config: cfg}
+	rp.Start()
+	defer rp.Stop()
+
+	sw := MakeSwitch(cfg, 1, initSwitchFunc)
+	require.NoError(t, sw.AddPersistentAllowlistPeers([]string{rp.Addr().String()}))
+	require.True(t, sw.IsPeerAllowlisted(rp.ID()))
+	require.True(t, sw.IsPeerUnconditional(rp.ID()))
+	require.True(t, sw.IsPeerPersistent(rp.Addr()))
+
+	// The peer is dialed when the switch starts.
+	err := sw.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := sw.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	require.Eventually(t, func() bool { return sw.Peers().Has(rp.ID()) }, 5*time.Second, 10*time.Millisecond)
+	p := sw.Peers().Get(rp.ID())
+	require.True(t, p.IsPersistent())
+
+	// And redialed when it is stopped, whether for an error or gracefully.
+	sw.StopPeerForError(p, errors.New("some err"))
+	require.Eventually(t, func() bool {
+		newPeer := sw.Peers().Get(rp.ID())
+		return newPeer != nil && newPeer != p
+	}, 5*time.Second, 10*time.Millisecond)
+
+	p = sw.Peers().Get(rp.ID())
+	sw.StopPeerGracefully(p)
+	require.Eventually(t, func() bool {
+		newPeer := sw.Peers().Get(rp.ID())
+		return newPeer != nil && newPeer != p
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
 func TestSwitchReconnectsToOutboundPersistentPeer(t *testing.T) {
 	sw := MakeSwitch(cfg, 1, initSwitchFunc)
 	err := sw.Start()
@@ -690,6 +786,73 @@ func TestSwitchAcceptRoutine(t *testing.T) {
 	}
 }
 
+func TestSwitchAcceptRoutineEvictsLowestScoredPeer(t *testing.T) {
+	evictionCfg := *cfg
+	evictionCfg.MaxNumInboundPeers = 2
+	evictionCfg.PeerEviction = true
+
+	sw := MakeSwitch(&evictionCfg, 1, initSwitchFunc)
+	err := sw.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := sw.Stop()
+		require.NoError(t, err)
+	})
+
+	dial := func() (*remotePeer, net.Conn) {
+		peer := &remotePeer{PrivKey: ed25519.GenPrivKey(), Config: &evictionCfg}
+		peer.Start()
+		t.Cleanup(peer.Stop)
+		c, err := peer.Dial(sw.NetAddress())
+		require.NoError(t, err)
+		return peer, c
+	}
+	keepOpen := func(c net.Conn) {
+		// spawn a reading routine to prevent connection from closing
+		go func() {
+			for {
+				one := make([]byte, 1)
+				if _, err := c.Read(one); err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	peers := make([]*remotePeer, evictionCfg.MaxNumInboundPeers)
+	for i := range peers {
+		var c net.Conn
+		peers[i], c = dial()
+		keepOpen(c)
+	}
+	require.Eventually(t, func() bool {
+		return sw.Peers().Size() == evictionCfg.MaxNumInboundPeers
+	}, time.Second, 10*time.Millisecond)
+
+	// New peers are rejected, as no peer has a lower score than them.
+	_, c := dial()
+	one := make([]byte, 1)
+	_ = c.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = c.Read(one)
+	require.Error(t, err)
+	require.Equal(t, evictionCfg.MaxNumInboundPeers, sw.Peers().Size())
+
+	// A peer which misbehaved is evicted for a new peer.
+	misbehaving := sw.Peers().Get(peers[0].ID())
+	require.NotNil(t, misbehaving)
+	sw.ReportPeerBehavior(misbehaving, PeerBehaviorInvalidMessage)
+	require.EqualValues(t, -evictionCfg.PeerScoreInvalidMessageWeight, sw.PeerScores()[peers[0].ID()])
+
+	newPeer, c := dial()
+	keepOpen(c)
+	require.Eventually(t, func() bool {
+		return sw.Peers().Has(newPeer.ID())
+	}, time.Second, 10*time.Millisecond)
+	require.False(t, sw.Peers().Has(peers[0].ID()))
+	require.True(t, sw.Peers().Has(peers[1].ID()))
+	require.Equal(t, evictionCfg.MaxNumInboundPeers, sw.Peers().Size())
+}
+
 type errorTransport struct {
 	acceptErr error
 }
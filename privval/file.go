@@ -78,6 +78,10 @@ type FilePVLastSignState struct {
 	Step      int8              `json:"step"`
 	Signature []byte            `json:"signature,omitempty"`
 	SignBytes cmtbytes.HexBytes `json:"signbytes,omitempty"`
+	// The time at which the state was exported from the node the validator is
+	// migrated from, if it was imported (see FilePV.ImportState) and the
+	// migration is not yet confirmed. Nothing is signed until then.
+	MigrationExportTime *time.Time `json:"migration_export_time,omitempty"`
 
 	filePath string
 }
@@ -88,6 +92,7 @@ func (lss *FilePVLastSignState) reset() {
 	lss.Step = 0
 	lss.Signature = nil
 	lss.SignBytes = nil
+	lss.MigrationExportTime = nil
 }
 
 // CheckHRS checks the given height, round, step (HRS) against that of the
@@ -268,7 +273,7 @@ func (pv *FilePV) GetPubKey() (crypto.PubKey, error) {
 // chainID. Implements PrivValidator.
 func (pv *FilePV) SignVote(chainID string, vote *cmtproto.Vote, signExtension bool) error {
 	if err := pv.signVote(chainID, vote, signExtension); err != nil {
-		return fmt.Errorf("error signing vote: %v", err)
+		return fmt.Errorf("error signing vote: %w", err)
 	}
 	return nil
 }
@@ -277,7 +282,7 @@ func (pv *FilePV) SignVote(chainID string, vote *cmtproto.Vote, signExtension bo
 // the chainID. Implements PrivValidator.
 func (pv *FilePV) SignProposal(chainID string, proposal *cmtproto.Proposal) error {
 	if err := pv.signProposal(chainID, proposal); err != nil {
-		return fmt.Errorf("error signing proposal: %v", err)
+		return fmt.Errorf("error signing proposal: %w", err)
 	}
 	return nil
 }
@@ -322,6 +327,10 @@ func (pv *FilePV) signVote(chainID string, vote *cmtproto.Vote, signExtension bo
 
 	lss := pv.LastSignState
 
+	if lss.MigrationExportTime != nil {
+		return ErrMigrationNotConfirmed
+	}
+
 	sameHRS, err := lss.CheckHRS(height, round, step)
 	if err != nil {
 		return err
@@ -388,6 +397,10 @@ func (pv *FilePV) signProposal(chainID string, proposal *cmtproto.Proposal) erro
 
 	lss := pv.LastSignState
 
+	if lss.MigrationExportTime != nil {
+		return ErrMigrationNotConfirmed
+	}
+
 	sameHRS, err := lss.CheckHRS(height, round, step)
 	if err != nil {
 		return err
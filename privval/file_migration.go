@@ -0,0 +1,134 @@
+package privval
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cometbft/cometbft/crypto/tmhash"
+	"github.com/cometbft/cometbft/internal/tempfile"
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/types"
+	cmttime "github.com/cometbft/cometbft/types/time"
+)
+
+// ErrMigrationNotConfirmed is returned when signing with a FilePV whose state
+// was imported, before the migration is confirmed (see FilePV.ImportState).
+var ErrMigrationNotConfirmed = errors.New("validator migration not confirmed: " +
+	"make sure that the node the state was exported from is down, then confirm the migration")
+
+// FilePVStateExport is the last sign state of a FilePV, exported to migrate a
+// validator to another node, along with the time of the export and a checksum
+// of the other fields.
+type FilePVStateExport struct {
+	Address    types.Address     `json:"address"`
+	Height     int64             `json:"height"`
+	Round      int32             `json:"round"`
+	Step       int8              `json:"step"`
+	Signature  []byte            `json:"signature,omitempty"`
+	SignBytes  cmtbytes.HexBytes `json:"signbytes,omitempty"`
+	ExportTime time.Time         `json:"export_time"`
+	Checksum   cmtbytes.HexBytes `json:"checksum,omitempty"`
+}
+
+// checksum returns the SHA-256 hash of the JSON encoding of the export,
+// without its checksum.
+func (e FilePVStateExport) checksum() ([]byte, error) {
+	e.Checksum = nil
+	bz, err := cmtjson.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return tmhash.Sum(bz), nil
+}
+
+// ExportState atomically writes the last sign state of the FilePV to filePath,
+// to be imported by the FilePV of the node the validator is migrated to with
+// ImportState. The node must not sign anything after the export, so it must be
+// stopped first.
+func (pv *FilePV) ExportState(filePath string) error {
+	lss := pv.LastSignState
+	export := FilePVStateExport{
+		Address:    pv.Key.Address,
+		Height:     lss.Height,
+		Round:      lss.Round,
+		Step:       lss.Step,
+		Signature:  lss.Signature,
+		SignBytes:  lss.SignBytes,
+		ExportTime: cmttime.Now(),
+	}
+	checksum, err := export.checksum()
+	if err != nil {
+		return err
+	}
+	export.Checksum = checksum
+	jsonBytes, err := cmtjson.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	return tempfile.WriteFileAtomic(filePath, jsonBytes, 0o600)
+}
+
+// ImportState replaces the last sign state of the FilePV with the one exported
+// to filePath by ExportState, and saves it. The state must have been exported
+// by the same validator, and must not be behind the current last sign state.
+//
+// Nothing is signed after the import until the migration is confirmed with
+// ConfirmMigration, which the operator must only do once they made sure that
+// the node the state was exported from is down, to prevent double signing.
+func (pv *FilePV) ImportState(filePath string) error {
+	jsonBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	var export FilePVStateExport
+	if err := cmtjson.Unmarshal(jsonBytes, &export); err != nil {
+		return fmt.Errorf("error reading exported state from %v: %w", filePath, err)
+	}
+	checksum, err := export.checksum()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(checksum, export.Checksum) {
+		return fmt.Errorf("checksum mismatch: expected %X, got %X", checksum, export.Checksum)
+	}
+	if !bytes.Equal(export.Address, pv.Key.Address) {
+		return fmt.Errorf("state was exported by validator %v, not %v", export.Address, pv.Key.Address)
+	}
+	lss := &pv.LastSignState
+	if isHRSBehind(export.Height, export.Round, export.Step, lss.Height, lss.Round, lss.Step) {
+		return fmt.Errorf("exported state %d/%d/%d is behind the current state %d/%d/%d",
+			export.Height, export.Round, export.Step, lss.Height, lss.Round, lss.Step)
+	}
+
+	lss.Height = export.Height
+	lss.Round = export.Round
+	lss.Step = export.Step
+	lss.Signature = export.Signature
+	lss.SignBytes = export.SignBytes
+	lss.MigrationExportTime = &export.ExportTime
+	lss.Save()
+	return nil
+}
+
+// ConfirmMigration confirms that the node the last sign state was exported
+// from is down, allowing the FilePV to sign again after ImportState.
+func (pv *FilePV) ConfirmMigration() {
+	pv.LastSignState.MigrationExportTime = nil
+	pv.LastSignState.Save()
+}
+
+// isHRSBehind returns true if the first height, round, step is before the
+// second one.
+func isHRSBehind(height int64, round int32, step int8, height2 int64, round2 int32, step2 int8) bool {
+	if height != height2 {
+		return height < height2
+	}
+	if round != round2 {
+		return round < round2
+	}
+	return step < step2
+}
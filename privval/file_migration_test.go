@@ -0,0 +1,62 @@
+package privval
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/crypto/tmhash"
+	cmtrand "github.com/cometbft/cometbft/internal/rand"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestExportImportState(t *testing.T) {
+	chainID := "mychainid"
+	blockID := types.BlockID{Hash: cmtrand.Bytes(tmhash.Size), PartSetHeader: types.PartSetHeader{}}
+
+	oldPV, _, _ := newTestFilePV(t)
+	vote := newVote(oldPV.Key.Address, 10, 0, types.PrecommitType, blockID)
+	require.NoError(t, oldPV.SignVote(chainID, vote.ToProto(), false))
+
+	exportFile := filepath.Join(t.TempDir(), "export.json")
+	require.NoError(t, oldPV.ExportState(exportFile))
+
+	newPV, newKeyFile, newStateFile := newTestFilePV(t)
+	newPV = NewFilePV(oldPV.Key.PrivKey, newKeyFile, newStateFile)
+	newPV.Save()
+
+	// The state can't be imported by another validator.
+	otherPV, _, _ := newTestFilePV(t)
+	require.Error(t, otherPV.ImportState(exportFile))
+
+	require.NoError(t, newPV.ImportState(exportFile))
+	require.Equal(t, oldPV.LastSignState.Height, newPV.LastSignState.Height)
+	require.Equal(t, oldPV.LastSignState.SignBytes, newPV.LastSignState.SignBytes)
+	require.NotNil(t, newPV.LastSignState.MigrationExportTime)
+
+	// Nothing is signed until the migration is confirmed, even after a restart.
+	newPV = LoadFilePV(newKeyFile, newStateFile)
+	vote = newVote(newPV.Key.Address, 11, 0, types.PrevoteType, blockID)
+	require.ErrorIs(t, newPV.SignVote(chainID, vote.ToProto(), false), ErrMigrationNotConfirmed)
+	proposal := newProposal(11, 0, blockID)
+	require.ErrorIs(t, newPV.SignProposal(chainID, proposal.ToProto()), ErrMigrationNotConfirmed)
+
+	newPV.ConfirmMigration()
+	newPV = LoadFilePV(newKeyFile, newStateFile)
+	require.Nil(t, newPV.LastSignState.MigrationExportTime)
+	require.NoError(t, newPV.SignVote(chainID, vote.ToProto(), false))
+
+	// The imported state must not be behind the current one.
+	require.Error(t, newPV.ImportState(exportFile))
+
+	// The checksum must match.
+	bz, err := os.ReadFile(exportFile)
+	require.NoError(t, err)
+	tampered := strings.Replace(string(bz), `"height": "10"`, `"height": "100"`, 1)
+	require.NotEqual(t, string(bz), tampered)
+	require.NoError(t, os.WriteFile(exportFile, []byte(tampered), 0o600))
+	require.ErrorContains(t, newPV.ImportState(exportFile), "checksum mismatch")
+}
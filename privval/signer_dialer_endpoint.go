@@ -3,6 +3,7 @@ package privval
 import (
 	"time"
 
+	cmtrand "github.com/cometbft/cometbft/internal/rand"
 	"github.com/cometbft/cometbft/libs/log"
 	"github.com/cometbft/cometbft/libs/service"
 )
@@ -33,6 +34,19 @@ func SignerDialerEndpointRetryWaitInterval(interval time.Duration) SignerService
 	return func(ss *SignerDialerEndpoint) { ss.retryWait = interval }
 }
 
+// SignerDialerEndpointRetryBackoff enables an exponential backoff between the
+// retries to connect: the retry wait interval (see
+// SignerDialerEndpointRetryWaitInterval) doubles with every failed attempt, up
+// to maxInterval, and a random jitter of up to half of it is subtracted, so that
+// the validators that lost their connection at the same time, e.g. when a
+// shared signer restarted, don't all retry at the same time. The backoff starts
+// over every time the endpoint has to reconnect. By default, or if maxInterval
+// is not greater than the retry wait interval, the endpoint retries at a fixed
+// interval.
+func SignerDialerEndpointRetryBackoff(maxInterval time.Duration) SignerServiceEndpointOption {
+	return func(ss *SignerDialerEndpoint) { ss.maxRetryWait = maxInterval }
+}
+
 // SignerDialerEndpoint dials using its dialer and responds to any signature
 // requests using its privVal.
 type SignerDialerEndpoint struct {
@@ -41,6 +55,7 @@ type SignerDialerEndpoint struct {
 	dialer SocketDialer
 
 	retryWait      time.Duration
+	maxRetryWait   time.Duration
 	maxConnRetries int
 }
 
@@ -80,7 +95,7 @@ func (sd *SignerDialerEndpoint) ensureConnection() error {
 			retries++
 			sd.Logger.Debug("SignerDialer: Reconnection failed", "retries", retries, "max", sd.maxConnRetries, "err", err)
 			// Wait between retries
-			time.Sleep(sd.retryWait)
+			time.Sleep(sd.retryWaitInterval(retries))
 			continue
 		}
 
@@ -93,3 +108,20 @@ func (sd *SignerDialerEndpoint) ensureConnection() error {
 
 	return ErrNoConnection
 }
+
+// retryWaitInterval returns how long to wait after the given number of failed
+// attempts to connect.
+func (sd *SignerDialerEndpoint) retryWaitInterval(retries int) time.Duration {
+	if sd.maxRetryWait <= sd.retryWait {
+		return sd.retryWait
+	}
+	wait := sd.retryWait
+	for i := 1; i < retries && wait < sd.maxRetryWait; i++ {
+		wait *= 2
+	}
+	wait = min(wait, sd.maxRetryWait)
+	if jitter := int64(wait / 2); jitter > 0 {
+		wait -= time.Duration(cmtrand.Int63n(jitter))
+	}
+	return wait
+}
@@ -0,0 +1,39 @@
+package privval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/libs/log"
+)
+
+func TestSignerDialerEndpointRetryWaitInterval(t *testing.T) {
+	// The endpoint retries at a fixed interval by default.
+	sd := NewSignerDialerEndpoint(log.TestingLogger(), nil,
+		SignerDialerEndpointRetryWaitInterval(100*time.Millisecond))
+	for retries := 1; retries <= 10; retries++ {
+		require.Equal(t, 100*time.Millisecond, sd.retryWaitInterval(retries))
+	}
+
+	// With a backoff, the interval doubles up to the maximum, with a jitter of
+	// up to half of it.
+	sd = NewSignerDialerEndpoint(log.TestingLogger(), nil,
+		SignerDialerEndpointRetryWaitInterval(100*time.Millisecond),
+		SignerDialerEndpointRetryBackoff(time.Second))
+	for retries, expected := range map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		3:  400 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		50: time.Second,
+	} {
+		for i := 0; i < 10; i++ {
+			wait := sd.retryWaitInterval(retries)
+			require.LessOrEqual(t, wait, expected, "retries %d", retries)
+			require.Greater(t, wait, expected/2, "retries %d", retries)
+		}
+	}
+}
@@ -459,6 +459,35 @@ func (c *baseRPCClient) BlockResults(
 	return result, nil
 }
 
+// BlockResultsBatch returns the block results for minHeight <= height <=
+// maxHeight. The node returns at most a fixed number of them, starting at
+// minHeight.
+func (c *baseRPCClient) BlockResultsBatch(
+	ctx context.Context,
+	minHeight,
+	maxHeight int64,
+) (*ctypes.ResultBlockResultsBatch, error) {
+	result := new(ctypes.ResultBlockResultsBatch)
+	_, err := c.caller.Call(ctx, "block_results_batch",
+		map[string]any{"minHeight": minHeight, "maxHeight": maxHeight},
+		result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RetainHeights returns the retain heights of the node, and the range of
+// heights held by its block store.
+func (c *baseRPCClient) RetainHeights(ctx context.Context) (*ctypes.ResultRetainHeights, error) {
+	result := new(ctypes.ResultRetainHeights)
+	_, err := c.caller.Call(ctx, "retain_heights", map[string]any{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *baseRPCClient) Header(ctx context.Context, height *int64) (*ctypes.ResultHeader, error) {
 	result := new(ctypes.ResultHeader)
 	params := make(map[string]any)
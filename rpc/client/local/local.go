@@ -143,7 +143,7 @@ func (c *Local) ConsensusParams(_ context.Context, height *int64) (*ctypes.Resul
 }
 
 func (c *Local) Health(context.Context) (*ctypes.ResultHealth, error) {
-	return c.env.Health(c.ctx)
+	return c.env.Health(c.ctx, nil)
 }
 
 func (c *Local) DialSeeds(_ context.Context, seeds []string) (*ctypes.ResultDialSeeds, error) {
@@ -184,6 +184,14 @@ func (c *Local) BlockResults(_ context.Context, height *int64) (*ctypes.ResultBl
 	return c.env.BlockResults(c.ctx, height)
 }
 
+func (c *Local) BlockResultsBatch(_ context.Context, minHeight, maxHeight int64) (*ctypes.ResultBlockResultsBatch, error) {
+	return c.env.BlockResultsBatch(c.ctx, minHeight, maxHeight)
+}
+
+func (c *Local) RetainHeights(context.Context) (*ctypes.ResultRetainHeights, error) {
+	return c.env.RetainHeights(c.ctx)
+}
+
 func (c *Local) Header(_ context.Context, height *int64) (*ctypes.ResultHeader, error) {
 	return c.env.Header(c.ctx, height)
 }
@@ -212,7 +220,7 @@ func (c *Local) TxSearch(
 	perPage *int,
 	orderBy string,
 ) (*ctypes.ResultTxSearch, error) {
-	return c.env.TxSearch(c.ctx, query, prove, page, perPage, orderBy)
+	return c.env.TxSearch(c.ctx, query, prove, page, perPage, orderBy, "")
 }
 
 func (c *Local) BlockSearch(
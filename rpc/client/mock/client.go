@@ -137,7 +137,7 @@ func (c Client) ConsensusParams(_ context.Context, height *int64) (*ctypes.Resul
 }
 
 func (c Client) Health(_ context.Context) (*ctypes.ResultHealth, error) {
-	return c.env.Health(&rpctypes.Context{})
+	return c.env.Health(&rpctypes.Context{}, nil)
 }
 
 func (c Client) DialSeeds(_ context.Context, seeds []string) (*ctypes.ResultDialSeeds, error) {
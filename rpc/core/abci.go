@@ -2,6 +2,9 @@ package core
 
 import (
 	"context"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru/v2"
 
 	abci "github.com/cometbft/cometbft/abci/types"
 	"github.com/cometbft/cometbft/libs/bytes"
@@ -11,6 +14,8 @@ import (
 )
 
 // ABCIQuery queries the application for some information.
+// If the ABCI query cache is enabled (see InitABCIQueryCache), the successful
+// responses to queries with proofs are cached until a new height is committed.
 // More: https://docs.cometbft.com/main/rpc/#/ABCI/abci_query
 func (env *Environment) ABCIQuery(
 	_ *rpctypes.Context,
@@ -19,19 +24,102 @@ func (env *Environment) ABCIQuery(
 	height int64,
 	prove bool,
 ) (*ctypes.ResultABCIQuery, error) {
-	resQuery, err := env.ProxyAppQuery.Query(context.TODO(), &abci.QueryRequest{
+	req := &abci.QueryRequest{
 		Path:   path,
 		Data:   data,
 		Height: height,
 		Prove:  prove,
-	})
+	}
+	// While syncing, the consensus state doesn't follow the heights
+	// committed by the application, so cached responses can't be invalidated.
+	if !prove || env.abciQueryCache == nil || env.ConsensusReactor.WaitSync() {
+		resQuery, err := env.ProxyAppQuery.Query(context.TODO(), req)
+		if err != nil {
+			return nil, err
+		}
+		return &ctypes.ResultABCIQuery{Response: *resQuery}, nil
+	}
+
+	// The last height of the consensus state is only updated once the
+	// application has committed it, so that responses to queries for the
+	// latest height are never cached for a height they are older than.
+	lastHeight := env.ConsensusState.GetLastHeight()
+	key := abciQueryCacheKey{height: height, path: path, data: string(data)}
+	if resQuery, ok := env.abciQueryCache.get(lastHeight, key); ok {
+		env.Metrics.ABCIQueryCacheHits.Add(1)
+		return &ctypes.ResultABCIQuery{Response: *resQuery}, nil
+	}
+	env.Metrics.ABCIQueryCacheMisses.Add(1)
+
+	resQuery, err := env.ProxyAppQuery.Query(context.TODO(), req)
 	if err != nil {
 		return nil, err
 	}
-
+	if resQuery.IsOK() {
+		env.abciQueryCache.add(lastHeight, key, resQuery)
+	}
 	return &ctypes.ResultABCIQuery{Response: *resQuery}, nil
 }
 
+// abciQueryCacheKey identifies an ABCI query with a proof.
+type abciQueryCacheKey struct {
+	height int64
+	path   string
+	data   string
+}
+
+// abciQueryCache is an LRU cache of the responses to ABCI queries with proofs,
+// purged whenever a new height is committed.
+type abciQueryCache struct {
+	mtx sync.Mutex
+	// The last committed height when the cached responses were received.
+	height    int64
+	responses *lru.Cache[abciQueryCacheKey, *abci.QueryResponse]
+}
+
+func newABCIQueryCache(size int) (*abciQueryCache, error) {
+	responses, err := lru.New[abciQueryCacheKey, *abci.QueryResponse](size)
+	if err != nil {
+		return nil, err
+	}
+	return &abciQueryCache{responses: responses}, nil
+}
+
+// get returns the cached response to the query identified by key, if any,
+// given the last committed height.
+func (c *abciQueryCache) get(lastHeight int64, key abciQueryCacheKey) (*abci.QueryResponse, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if !c.advance(lastHeight) {
+		return nil, false
+	}
+	return c.responses.Get(key)
+}
+
+// add caches the response to the query identified by key, received when the
+// last committed height was lastHeight.
+func (c *abciQueryCache) add(lastHeight int64, key abciQueryCacheKey, res *abci.QueryResponse) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.advance(lastHeight) {
+		c.responses.Add(key, res)
+	}
+}
+
+// advance purges the cache if a new height was committed since the responses
+// were cached. It returns false if lastHeight is older than the cached
+// responses, which happens if the height was committed concurrently.
+func (c *abciQueryCache) advance(lastHeight int64) bool {
+	if lastHeight < c.height {
+		return false
+	}
+	if lastHeight > c.height {
+		c.responses.Purge()
+		c.height = lastHeight
+	}
+	return true
+}
+
 // ABCIInfo gets some info about the application.
 // More: https://docs.cometbft.com/main/rpc/#/ABCI/abci_info
 func (env *Environment) ABCIInfo(_ *rpctypes.Context) (*ctypes.ResultABCIInfo, error) {
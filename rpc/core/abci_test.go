@@ -0,0 +1,84 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/config"
+	proxymocks "github.com/cometbft/cometbft/proxy/mocks"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// lastHeightConsensus is a consensus state reporting a given last height.
+type lastHeightConsensus struct {
+	Consensus
+	lastHeight int64
+}
+
+func (c *lastHeightConsensus) GetLastHeight() int64 { return c.lastHeight }
+
+// staticSyncReactor is a reactor that is syncing or not.
+type staticSyncReactor struct {
+	syncing bool
+}
+
+func (r *staticSyncReactor) WaitSync() bool { return r.syncing }
+
+func TestABCIQueryCache(t *testing.T) {
+	appConn := &proxymocks.AppConnQuery{}
+	consensus := &lastHeightConsensus{lastHeight: 10}
+	reactor := &staticSyncReactor{}
+	rpcConfig := config.DefaultRPCConfig()
+	rpcConfig.ABCIQueryCacheSize = 2
+	env := &Environment{
+		ProxyAppQuery:    appConn,
+		ConsensusState:   consensus,
+		ConsensusReactor: reactor,
+		Config:           *rpcConfig,
+	}
+	require.NoError(t, env.InitABCIQueryCache())
+
+	queried := func(data string, prove bool) {
+		appConn.On("Query", mock.Anything, &abci.QueryRequest{Path: "/key", Data: []byte(data), Prove: prove}).
+			Return(&abci.QueryResponse{Value: []byte(data)}, nil).Once()
+	}
+	query := func(data string, prove bool) {
+		res, err := env.ABCIQuery(&rpctypes.Context{}, "/key", []byte(data), 0, prove)
+		require.NoError(t, err)
+		require.Equal(t, []byte(data), res.Response.Value)
+		appConn.AssertExpectations(t)
+	}
+
+	// Queries with proofs are cached ...
+	queried("a", true)
+	query("a", true)
+	query("a", true)
+
+	// ... but not the ones without.
+	queried("a", false)
+	query("a", false)
+	queried("a", false)
+	query("a", false)
+
+	// The least recently used responses are evicted.
+	queried("b", true)
+	query("b", true)
+	queried("c", true)
+	query("c", true)
+	queried("a", true)
+	query("a", true)
+
+	// The cache is purged once a new height is committed.
+	consensus.lastHeight = 11
+	queried("c", true)
+	query("c", true)
+	query("c", true)
+
+	// The cache is bypassed while syncing.
+	reactor.syncing = true
+	queried("c", true)
+	query("c", true)
+}
@@ -1,13 +1,16 @@
 package core
 
 import (
+	"errors"
 	"sort"
 
+	abci "github.com/cometbft/cometbft/abci/types"
 	"github.com/cometbft/cometbft/libs/bytes"
 	cmtmath "github.com/cometbft/cometbft/libs/math"
 	cmtquery "github.com/cometbft/cometbft/libs/pubsub/query"
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
 	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	sm "github.com/cometbft/cometbft/state"
 	blockidxnull "github.com/cometbft/cometbft/state/indexer/block/null"
 	"github.com/cometbft/cometbft/types"
 )
@@ -187,13 +190,71 @@ func (env *Environment) BlockResults(_ *rpctypes.Context, heightPtr *int64) (*ct
 		return nil, err
 	}
 
+	return newResultBlockResults(height, results), nil
+}
+
+// maxBlockResultsBatch is the maximum number of heights whose results are
+// returned by BlockResultsBatch.
+const maxBlockResultsBatch int64 = 20
+
+// BlockResultsBatch gets the block results for minHeight <= height <=
+// maxHeight, e.g. to backfill an indexer with fewer round trips than with
+// BlockResults.
+//
+// At most 20 items will be returned, starting at minHeight: the next batch
+// starts at the height following the last one returned. Block results are
+// returned in ascending order (lowest first). It fails if the block results of
+// minHeight were pruned.
+//
+// More: https://docs.cometbft.com/main/rpc/#/Info/block_results_batch
+func (env *Environment) BlockResultsBatch(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+) (*ctypes.ResultBlockResultsBatch, error) {
+	latestHeight := env.BlockStore.Height()
+	if minHeight <= 0 || maxHeight <= 0 {
+		return nil, ErrZeroOrNegativeHeight
+	}
+	if minHeight > maxHeight {
+		return nil, ErrHeightMinGTMax{Min: minHeight, Max: maxHeight}
+	}
+	if maxHeight > latestHeight {
+		return nil, ErrHeightAboveLatest{Height: maxHeight, Latest: latestHeight}
+	}
+	maxHeight = cmtmath.MinInt64(maxHeight, minHeight+maxBlockResultsBatch-1)
+
+	retainHeight, err := env.StateStore.GetABCIResRetainHeight()
+	if err != nil && !errors.Is(err, sm.ErrKeyNotFound) {
+		return nil, err
+	}
+	retainHeight = cmtmath.MaxInt64(retainHeight, env.BlockStore.Base())
+	if minHeight < retainHeight {
+		return nil, ErrBlockResultsPruned{Height: minHeight, RetainHeight: retainHeight}
+	}
+
+	batch := make([]*ctypes.ResultBlockResults, 0, maxHeight-minHeight+1)
+	for height := minHeight; height <= maxHeight; height++ {
+		results, err := env.StateStore.LoadFinalizeBlockResponse(height)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, newResultBlockResults(height, results))
+	}
+
+	return &ctypes.ResultBlockResultsBatch{
+		LastHeight:   latestHeight,
+		BlockResults: batch,
+	}, nil
+}
+
+func newResultBlockResults(height int64, results *abci.FinalizeBlockResponse) *ctypes.ResultBlockResults {
 	return &ctypes.ResultBlockResults{
 		Height:                height,
 		TxResults:             results.TxResults,
 		FinalizeBlockEvents:   results.Events,
 		ValidatorUpdates:      results.ValidatorUpdates,
 		ConsensusParamUpdates: results.ConsensusParamUpdates,
-	}, nil
+	}
 }
 
 // BlockSearch searches for a paginated set of blocks matching
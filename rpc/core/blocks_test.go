@@ -113,3 +113,48 @@ func TestBlockResults(t *testing.T) {
 		}
 	}
 }
+
+func TestBlockResultsBatch(t *testing.T) {
+	env := &Environment{}
+	env.StateStore = sm.NewStore(dbm.NewMemDB(), sm.StoreOptions{
+		DiscardABCIResponses: false,
+	})
+	for h := int64(1); h <= 100; h++ {
+		err := env.StateStore.SaveFinalizeBlockResponse(h, &abci.FinalizeBlockResponse{
+			TxResults: []*abci.ExecTxResult{{Code: 0, Data: []byte{byte(h)}, Log: "ok"}},
+		})
+		require.NoError(t, err)
+	}
+	mockstore := &mocks.BlockStore{}
+	mockstore.On("Height").Return(int64(100))
+	mockstore.On("Base").Return(int64(1))
+	env.BlockStore = mockstore
+
+	res, err := env.BlockResultsBatch(&rpctypes.Context{}, 10, 12)
+	require.NoError(t, err)
+	require.EqualValues(t, 100, res.LastHeight)
+	require.Len(t, res.BlockResults, 3)
+	for i, r := range res.BlockResults {
+		assert.EqualValues(t, 10+i, r.Height)
+		assert.Equal(t, []byte{byte(10 + i)}, r.TxResults[0].Data)
+	}
+
+	// The batch is capped.
+	res, err = env.BlockResultsBatch(&rpctypes.Context{}, 1, 100)
+	require.NoError(t, err)
+	require.Len(t, res.BlockResults, int(maxBlockResultsBatch))
+	assert.EqualValues(t, maxBlockResultsBatch, res.BlockResults[len(res.BlockResults)-1].Height)
+
+	for _, tc := range []struct{ min, max int64 }{{0, 10}, {-1, 10}, {12, 10}, {90, 101}} {
+		_, err := env.BlockResultsBatch(&rpctypes.Context{}, tc.min, tc.max)
+		require.Error(t, err, tc)
+	}
+
+	// Heights below the retain height of the block results were pruned.
+	require.NoError(t, env.StateStore.SaveABCIResRetainHeight(50))
+	_, err = env.BlockResultsBatch(&rpctypes.Context{}, 49, 60)
+	require.ErrorIs(t, err, ErrBlockResultsPruned{Height: 49, RetainHeight: 50})
+	res, err = env.BlockResultsBatch(&rpctypes.Context{}, 50, 60)
+	require.NoError(t, err)
+	require.Len(t, res.BlockResults, 11)
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"encoding/base64"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	cfg "github.com/cometbft/cometbft/config"
@@ -54,6 +55,15 @@ type peers interface {
 	AddPrivatePeerIDs(peerIDs []string) error
 	DialPeersAsync(peers []string) error
 	Peers() p2p.IPeerSet
+	PeerScores() map[p2p.ID]int64
+}
+
+// The pruner of the node.
+type pruner interface {
+	RetainHeightSnapshot() (sm.RetainHeights, error)
+	Pause()
+	Resume()
+	IsPaused() bool
 }
 
 // A reactor that transitions from block sync or state sync to consensus mode.
@@ -77,10 +87,12 @@ type Environment struct {
 	MempoolReactor   syncReactor
 	P2PPeers         peers
 	P2PTransport     transport
+	Pruner           pruner
 
 	// objects
 	PubKey       crypto.PubKey
 	GenDoc       *types.GenesisDoc // cache the genesis structure
+	GenDocHash   []byte            // SHA-256 checksum of the genesis file
 	TxIndexer    txindex.TxIndexer
 	BlockIndexer indexer.BlockIndexer
 	EventBus     *types.EventBus // thread safe
@@ -90,8 +102,17 @@ type Environment struct {
 
 	Config cfg.RPCConfig
 
+	// Metrics are set to no-op metrics by InitABCIQueryCache if left nil.
+	Metrics *Metrics
+
 	// cache of chunked genesis data.
 	genChunks []string
+
+	// cache of the responses to ABCI queries with proofs, if enabled.
+	abciQueryCache *abciQueryCache
+
+	// whether the node has been found ready to serve queries (see Health).
+	ready atomic.Bool
 }
 
 func validatePage(pagePtr *int, perPage, totalCount int) (int, error) {
@@ -140,7 +161,11 @@ func (env *Environment) InitGenesisChunks() error {
 		return nil
 	}
 
-	data, err := cmtjson.Marshal(env.GenDoc)
+	genDoc, err := env.genesisDocWithAppState()
+	if err != nil {
+		return err
+	}
+	data, err := cmtjson.Marshal(genDoc)
 	if err != nil {
 		return err
 	}
@@ -158,6 +183,25 @@ func (env *Environment) InitGenesisChunks() error {
 	return nil
 }
 
+// InitABCIQueryCache sets up the cache of the responses to ABCI queries with
+// proofs, if enabled by Config.ABCIQueryCacheSize, and should be called on
+// service startup. The cache relies on ConsensusState and ConsensusReactor.
+func (env *Environment) InitABCIQueryCache() error {
+	if env.Metrics == nil {
+		env.Metrics = NopMetrics()
+	}
+	if env.abciQueryCache != nil || env.Config.ABCIQueryCacheSize == 0 {
+		return nil
+	}
+
+	cache, err := newABCIQueryCache(env.Config.ABCIQueryCacheSize)
+	if err != nil {
+		return err
+	}
+	env.abciQueryCache = cache
+	return nil
+}
+
 func validateSkipCount(page, perPage int) int {
 	skipCount := (page - 1) * perPage
 	if skipCount < 0 {
@@ -195,3 +239,19 @@ func (env *Environment) latestUncommittedHeight() int64 {
 	}
 	return env.BlockStore.Height() + 1
 }
+
+// genesisDocWithAppState returns the genesis doc along with its app state,
+// which is read from the genesis file if it was loaded with
+// types.GenesisDocFromFileStreaming.
+func (env *Environment) genesisDocWithAppState() (*types.GenesisDoc, error) {
+	if env.GenDoc == nil {
+		return nil, nil
+	}
+	appState, err := env.GenDoc.LoadAppState()
+	if err != nil {
+		return nil, err
+	}
+	genDoc := *env.GenDoc
+	genDoc.AppState = appState
+	return &genDoc, nil
+}
@@ -3,10 +3,12 @@ package core
 import (
 	"errors"
 	"fmt"
+	"net/http"
 )
 
 var (
 	ErrNegativeHeight          = errors.New("negative height")
+	ErrZeroOrNegativeHeight    = errors.New("height must be greater than 0")
 	ErrBlockIndexing           = errors.New("block indexing is disabled")
 	ErrTxIndexingDisabled      = errors.New("transaction indexing is disabled")
 	ErrNoEvidence              = errors.New("no evidence was provided")
@@ -18,8 +20,26 @@ var (
 	ErrGenesisRespSize         = errors.New("genesis response is too large, please use the genesis_chunked API instead")
 	ErrChunkNotInitialized     = errors.New("genesis chunks are not initialized")
 	ErrNoChunks                = errors.New("no chunks")
+	ErrPageWithCursor          = errors.New("page and cursor are mutually exclusive")
+	ErrPrunerUnavailable       = errors.New("the pruner is not available")
 )
 
+// ErrNotReady is returned by Health when asked whether the node is ready to
+// serve queries, and it isn't yet.
+type ErrNotReady struct {
+	Reason string
+}
+
+func (e ErrNotReady) Error() string {
+	return "node is not ready: " + e.Reason
+}
+
+// HTTPStatusCode makes URI requests fail with 503 Service Unavailable, so
+// that load balancers and orchestrators don't send traffic to the node yet.
+func (ErrNotReady) HTTPStatusCode() int {
+	return http.StatusServiceUnavailable
+}
+
 type ErrMaxSubscription struct {
 	Max int
 }
@@ -45,6 +65,27 @@ func (e ErrHeightMinGTMax) Error() string {
 	return fmt.Sprintf("min height %d can't be greater than max height %d", e.Min, e.Max)
 }
 
+type ErrHeightAboveLatest struct {
+	Height int64
+	Latest int64
+}
+
+func (e ErrHeightAboveLatest) Error() string {
+	return fmt.Sprintf("height %d must be less than or equal to the current blockchain height %d", e.Height, e.Latest)
+}
+
+// ErrBlockResultsPruned is returned when the block results of a height were
+// pruned, i.e. when the height is below the retain height of the block
+// results, or the base of the block store.
+type ErrBlockResultsPruned struct {
+	Height       int64
+	RetainHeight int64
+}
+
+func (e ErrBlockResultsPruned) Error() string {
+	return fmt.Sprintf("block results for height %d were pruned, lowest available height is %d", e.Height, e.RetainHeight)
+}
+
 type ErrQueryLength struct {
 	length    int
 	maxLength int
@@ -150,6 +191,20 @@ func (e ErrInvalidOrderBy) Error() string {
 	return "invalid order_by: maxLength either `asc` or `desc` or an empty value but got " + e.OrderBy
 }
 
+// ErrInvalidCursor is returned when the cursor passed to TxSearch was not
+// returned by a previous call.
+type ErrInvalidCursor struct {
+	Source error
+}
+
+func (e ErrInvalidCursor) Error() string {
+	return "invalid cursor: " + e.Source.Error()
+}
+
+func (e ErrInvalidCursor) Unwrap() error {
+	return e.Source
+}
+
 type ErrInvalidNodeType struct {
 	PeerID   string
 	Expected string
@@ -1,13 +1,56 @@
 package core
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/cometbft/cometbft/proxy"
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
 	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
 )
 
 // Health gets node health. Returns empty result (200 OK) on success, no
 // response - in case of an error.
+//
+// If ready is true, it also checks that the node is ready to serve queries,
+// i.e. that the application has finished initializing, and returns ErrNotReady
+// (503 Service Unavailable) until then.
 // More: https://docs.cometbft.com/main/rpc/#/Info/health
-func (*Environment) Health(*rpctypes.Context) (*ctypes.ResultHealth, error) {
+func (env *Environment) Health(_ *rpctypes.Context, ready *bool) (*ctypes.ResultHealth, error) {
+	if ready != nil && *ready {
+		if err := env.checkReady(); err != nil {
+			return nil, err
+		}
+	}
 	return &ctypes.ResultHealth{}, nil
 }
+
+// checkReady checks whether the node is ready to serve queries. The node is
+// ready once the application reports, via ABCI Info, the last block height
+// stored in the state store, which means the application has finished
+// initializing, and replaying or syncing blocks. Once ready, the node remains
+// ready, and the application is no longer queried.
+func (env *Environment) checkReady() error {
+	if env.ready.Load() {
+		return nil
+	}
+	if env.ProxyAppQuery == nil || env.StateStore == nil {
+		return ErrNotReady{Reason: "not connected to the application"}
+	}
+
+	resInfo, err := env.ProxyAppQuery.Info(context.TODO(), proxy.InfoRequest)
+	if err != nil {
+		return ErrNotReady{Reason: fmt.Sprintf("failed to query application info: %v", err)}
+	}
+	state, err := env.StateStore.Load()
+	if err != nil {
+		return ErrNotReady{Reason: fmt.Sprintf("failed to load state: %v", err)}
+	}
+	if resInfo.LastBlockHeight < 0 || resInfo.LastBlockHeight != state.LastBlockHeight {
+		return ErrNotReady{Reason: fmt.Sprintf("application reports last block height %d, expected %d",
+			resInfo.LastBlockHeight, state.LastBlockHeight)}
+	}
+
+	env.ready.Store(true)
+	return nil
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	proxymocks "github.com/cometbft/cometbft/proxy/mocks"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	sm "github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/state/mocks"
+)
+
+func TestHealthReady(t *testing.T) {
+	stateStore := &mocks.Store{}
+	stateStore.On("Load").Return(sm.State{LastBlockHeight: 10}, nil)
+	appConn := &proxymocks.AppConnQuery{}
+	env := &Environment{ProxyAppQuery: appConn, StateStore: stateStore}
+	ready := true
+
+	// Without asking for readiness, the node is always healthy.
+	_, err := env.Health(&rpctypes.Context{}, nil)
+	require.NoError(t, err)
+
+	// The node is not ready while the app can't be queried ...
+	call := appConn.On("Info", mock.Anything, mock.Anything).Return(nil, errors.New("app is starting"))
+	_, err = env.Health(&rpctypes.Context{}, &ready)
+	require.ErrorAs(t, err, &ErrNotReady{})
+	call.Unset()
+
+	// ... or reports a height other than the one of the state.
+	call = appConn.On("Info", mock.Anything, mock.Anything).Return(&abci.InfoResponse{LastBlockHeight: 9}, nil)
+	_, err = env.Health(&rpctypes.Context{}, &ready)
+	require.ErrorAs(t, err, &ErrNotReady{})
+	call.Unset()
+
+	appConn.On("Info", mock.Anything, mock.Anything).Return(&abci.InfoResponse{LastBlockHeight: 10}, nil).Once()
+	_, err = env.Health(&rpctypes.Context{}, &ready)
+	require.NoError(t, err)
+
+	// Once ready, the node remains ready without querying the app again.
+	_, err = env.Health(&rpctypes.Context{}, &ready)
+	require.NoError(t, err)
+	appConn.AssertExpectations(t)
+}
@@ -0,0 +1,37 @@
+// Code generated by metricsgen. DO NOT EDIT.
+
+package core
+
+import (
+	"github.com/go-kit/kit/metrics/discard"
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &Metrics{
+		ABCIQueryCacheHits: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "abci_query_cache_hits",
+			Help:      "Number of ABCI queries with proofs served from the cache.",
+		}, labels).With(labelsAndValues...),
+		ABCIQueryCacheMisses: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "abci_query_cache_misses",
+			Help:      "Number of ABCI queries with proofs that were not found in the cache.",
+		}, labels).With(labelsAndValues...),
+	}
+}
+
+func NopMetrics() *Metrics {
+	return &Metrics{
+		ABCIQueryCacheHits:   discard.NewCounter(),
+		ABCIQueryCacheMisses: discard.NewCounter(),
+	}
+}
@@ -0,0 +1,21 @@
+package core
+
+import (
+	"github.com/go-kit/kit/metrics"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this
+	// package.
+	MetricsSubsystem = "rpc"
+)
+
+//go:generate go run ../../scripts/metricsgen -struct=Metrics
+
+// Metrics contains the metrics exposed by the RPC core package.
+type Metrics struct {
+	// Number of ABCI queries with proofs served from the cache.
+	ABCIQueryCacheHits metrics.Counter `metrics_name:"abci_query_cache_hits"`
+	// Number of ABCI queries with proofs that were not found in the cache.
+	ABCIQueryCacheMisses metrics.Counter `metrics_name:"abci_query_cache_misses"`
+}
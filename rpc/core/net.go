@@ -3,6 +3,7 @@ package core
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/cometbft/cometbft/p2p"
@@ -46,6 +47,28 @@ func (env *Environment) NetInfo(*rpctypes.Context) (*ctypes.ResultNetInfo, error
 	}, nil
 }
 
+// PeerScores returns the scores of the peers which misbehaved, connected or
+// not, from the lowest. Peers which are not listed have a score of zero.
+// More: https://docs.cometbft.com/main/rpc/#/Info/peer_scores
+func (env *Environment) PeerScores(*rpctypes.Context) (*ctypes.ResultPeerScores, error) {
+	peerSet := env.P2PPeers.Peers()
+	scores := make([]ctypes.PeerScore, 0)
+	for id, score := range env.P2PPeers.PeerScores() {
+		scores = append(scores, ctypes.PeerScore{
+			NodeID:    id,
+			Score:     score,
+			Connected: peerSet.Has(id),
+		})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score < scores[j].Score
+		}
+		return scores[i].NodeID < scores[j].NodeID
+	})
+	return &ctypes.ResultPeerScores{Scores: scores}, nil
+}
+
 // UnsafeDialSeeds dials the given seeds (comma-separated id@IP:PORT).
 func (env *Environment) UnsafeDialSeeds(_ *rpctypes.Context, seeds []string) (*ctypes.ResultDialSeeds, error) {
 	if len(seeds) == 0 {
@@ -109,7 +132,11 @@ func (env *Environment) Genesis(*rpctypes.Context) (*ctypes.ResultGenesis, error
 		return nil, ErrGenesisRespSize
 	}
 
-	return &ctypes.ResultGenesis{Genesis: env.GenDoc}, nil
+	genDoc, err := env.genesisDocWithAppState()
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultGenesis{Genesis: genDoc}, nil
 }
 
 func (env *Environment) GenesisChunked(_ *rpctypes.Context, chunk uint) (*ctypes.ResultGenesisChunk, error) {
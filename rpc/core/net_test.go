@@ -9,6 +9,7 @@ import (
 	cfg "github.com/cometbft/cometbft/config"
 	"github.com/cometbft/cometbft/libs/log"
 	"github.com/cometbft/cometbft/p2p"
+	"github.com/cometbft/cometbft/p2p/mock"
 	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
 )
 
@@ -87,3 +88,29 @@ func TestUnsafeDialPeers(t *testing.T) {
 		}
 	}
 }
+
+func TestPeerScores(t *testing.T) {
+	p2pCfg := cfg.DefaultP2PConfig()
+	sw := p2p.MakeSwitch(p2pCfg, 1,
+		func(_ int, sw *p2p.Switch) *p2p.Switch { return sw })
+
+	env := &Environment{}
+	env.Logger = log.TestingLogger()
+	env.P2PPeers = sw
+
+	res, err := env.PeerScores(&rpctypes.Context{})
+	require.NoError(t, err)
+	assert.Empty(t, res.Scores)
+
+	peers := []p2p.Peer{mock.NewPeer(nil), mock.NewPeer(nil)}
+	sw.ReportPeerBehavior(peers[0], p2p.PeerBehaviorDisconnect)
+	sw.ReportPeerBehavior(peers[1], p2p.PeerBehaviorInvalidMessage)
+
+	res, err = env.PeerScores(&rpctypes.Context{})
+	require.NoError(t, err)
+	require.Len(t, res.Scores, 2)
+	assert.Equal(t, peers[1].ID(), res.Scores[0].NodeID)
+	assert.EqualValues(t, -p2pCfg.PeerScoreInvalidMessageWeight, res.Scores[0].Score)
+	assert.False(t, res.Scores[0].Connected)
+	assert.Equal(t, peers[0].ID(), res.Scores[1].NodeID)
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// RetainHeights returns the retain heights set by the application and the data
+// companion, the height below which blocks are pruned, and the range of
+// heights held by the block store. Retain heights that have not been set are
+// 0.
+// More: https://docs.cometbft.com/main/rpc/#/Info/retain_heights
+func (env *Environment) RetainHeights(*rpctypes.Context) (*ctypes.ResultRetainHeights, error) {
+	if env.Pruner == nil {
+		return nil, ErrPrunerUnavailable
+	}
+	rhs, err := env.Pruner.RetainHeightSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultRetainHeights{
+		ApplicationBlockRetainHeight: rhs.ApplicationBlock.Height,
+		CompanionBlockRetainHeight:   rhs.CompanionBlock.Height,
+		ABCIResultsRetainHeight:      rhs.ABCIResults.Height,
+		TxIndexerRetainHeight:        rhs.TxIndexer.Height,
+		BlockIndexerRetainHeight:     rhs.BlockIndexer.Height,
+		EffectiveBlockRetainHeight:   rhs.EffectiveBlock,
+		Base:                         rhs.Base,
+		Height:                       rhs.Height,
+	}, nil
+}
+
+// UnsafePausePruning pauses the pruning of the node until
+// UnsafeResumePruning is called, e.g. during a heavy migration, without
+// restarting the node. Retain heights can still be set while paused.
+func (env *Environment) UnsafePausePruning(*rpctypes.Context) (*ctypes.ResultPruningPaused, error) {
+	if env.Pruner == nil {
+		return nil, ErrPrunerUnavailable
+	}
+	env.Pruner.Pause()
+	return &ctypes.ResultPruningPaused{Paused: env.Pruner.IsPaused()}, nil
+}
+
+// UnsafeResumePruning resumes the pruning of the node paused by
+// UnsafePausePruning, right away.
+func (env *Environment) UnsafeResumePruning(*rpctypes.Context) (*ctypes.ResultPruningPaused, error) {
+	if env.Pruner == nil {
+		return nil, ErrPrunerUnavailable
+	}
+	env.Pruner.Resume()
+	return &ctypes.ResultPruningPaused{Paused: env.Pruner.IsPaused()}, nil
+}
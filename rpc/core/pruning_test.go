@@ -0,0 +1,69 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	sm "github.com/cometbft/cometbft/state"
+)
+
+type snapshotPruner struct {
+	rhs    sm.RetainHeights
+	paused bool
+}
+
+func (p snapshotPruner) RetainHeightSnapshot() (sm.RetainHeights, error) {
+	return p.rhs, nil
+}
+
+func (p *snapshotPruner) Pause()         { p.paused = true }
+func (p *snapshotPruner) Resume()        { p.paused = false }
+func (p *snapshotPruner) IsPaused() bool { return p.paused }
+
+func TestRetainHeights(t *testing.T) {
+	env := &Environment{}
+	_, err := env.RetainHeights(&rpctypes.Context{})
+	require.ErrorIs(t, err, ErrPrunerUnavailable)
+
+	env.Pruner = &snapshotPruner{rhs: sm.RetainHeights{
+		ApplicationBlock: sm.RetainHeight{Height: 3, Set: true},
+		ABCIResults:      sm.RetainHeight{Height: 5, Set: true},
+		TxIndexer:        sm.RetainHeight{Height: 6, Set: true},
+		Base:             2,
+		Height:           10,
+		EffectiveBlock:   3,
+	}}
+	res, err := env.RetainHeights(&rpctypes.Context{})
+	require.NoError(t, err)
+	require.Equal(t, &ctypes.ResultRetainHeights{
+		ApplicationBlockRetainHeight: 3,
+		ABCIResultsRetainHeight:      5,
+		TxIndexerRetainHeight:        6,
+		EffectiveBlockRetainHeight:   3,
+		Base:                         2,
+		Height:                       10,
+	}, res)
+}
+
+func TestPauseResumePruning(t *testing.T) {
+	env := &Environment{}
+	_, err := env.UnsafePausePruning(&rpctypes.Context{})
+	require.ErrorIs(t, err, ErrPrunerUnavailable)
+	_, err = env.UnsafeResumePruning(&rpctypes.Context{})
+	require.ErrorIs(t, err, ErrPrunerUnavailable)
+
+	pruner := &snapshotPruner{}
+	env.Pruner = pruner
+	res, err := env.UnsafePausePruning(&rpctypes.Context{})
+	require.NoError(t, err)
+	require.Equal(t, &ctypes.ResultPruningPaused{Paused: true}, res)
+	require.True(t, pruner.paused)
+
+	res, err = env.UnsafeResumePruning(&rpctypes.Context{})
+	require.NoError(t, err)
+	require.Equal(t, &ctypes.ResultPruningPaused{Paused: false}, res)
+	require.False(t, pruner.paused)
+}
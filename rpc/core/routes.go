@@ -17,21 +17,24 @@ func (env *Environment) GetRoutes() RoutesMap {
 		"unsubscribe_all": rpc.NewWSRPCFunc(env.UnsubscribeAll, ""),
 
 		// info AP
-		"health":               rpc.NewRPCFunc(env.Health, ""),
+		"health":               rpc.NewRPCFunc(env.Health, "ready"),
 		"status":               rpc.NewRPCFunc(env.Status, ""),
 		"net_info":             rpc.NewRPCFunc(env.NetInfo, ""),
+		"peer_scores":          rpc.NewRPCFunc(env.PeerScores, ""),
+		"retain_heights":       rpc.NewRPCFunc(env.RetainHeights, ""),
 		"blockchain":           rpc.NewRPCFunc(env.BlockchainInfo, "minHeight,maxHeight", rpc.Cacheable()),
 		"genesis":              rpc.NewRPCFunc(env.Genesis, "", rpc.Cacheable()),
 		"genesis_chunked":      rpc.NewRPCFunc(env.GenesisChunked, "chunk", rpc.Cacheable()),
 		"block":                rpc.NewRPCFunc(env.Block, "height", rpc.Cacheable("height")),
 		"block_by_hash":        rpc.NewRPCFunc(env.BlockByHash, "hash", rpc.Cacheable()),
 		"block_results":        rpc.NewRPCFunc(env.BlockResults, "height", rpc.Cacheable("height")),
+		"block_results_batch":  rpc.NewRPCFunc(env.BlockResultsBatch, "minHeight,maxHeight"),
 		"commit":               rpc.NewRPCFunc(env.Commit, "height", rpc.Cacheable("height")),
 		"header":               rpc.NewRPCFunc(env.Header, "height", rpc.Cacheable("height")),
 		"header_by_hash":       rpc.NewRPCFunc(env.HeaderByHash, "hash", rpc.Cacheable()),
 		"check_tx":             rpc.NewRPCFunc(env.CheckTx, "tx"),
 		"tx":                   rpc.NewRPCFunc(env.Tx, "hash,prove", rpc.Cacheable()),
-		"tx_search":            rpc.NewRPCFunc(env.TxSearch, "query,prove,page,per_page,order_by"),
+		"tx_search":            rpc.NewRPCFunc(env.TxSearch, "query,prove,page,per_page,order_by,cursor"),
 		"block_search":         rpc.NewRPCFunc(env.BlockSearch, "query,page,per_page,order_by"),
 		"validators":           rpc.NewRPCFunc(env.Validators, "height,page,per_page", rpc.Cacheable("height")),
 		"dump_consensus_state": rpc.NewRPCFunc(env.DumpConsensusState, ""),
@@ -61,4 +64,6 @@ func (env *Environment) AddUnsafeRoutes(routes RoutesMap) {
 	routes["dial_seeds"] = rpc.NewRPCFunc(env.UnsafeDialSeeds, "seeds")
 	routes["dial_peers"] = rpc.NewRPCFunc(env.UnsafeDialPeers, "peers,persistent,unconditional,private")
 	routes["unsafe_flush_mempool"] = rpc.NewRPCFunc(env.UnsafeFlushMempool, "")
+	routes["unsafe_pause_pruning"] = rpc.NewRPCFunc(env.UnsafePausePruning, "")
+	routes["unsafe_resume_pruning"] = rpc.NewRPCFunc(env.UnsafeResumePruning, "")
 }
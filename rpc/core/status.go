@@ -69,6 +69,7 @@ func (env *Environment) Status(*rpctypes.Context) (*ctypes.ResultStatus, error)
 			PubKey:      env.PubKey,
 			VotingPower: votingPower,
 		},
+		GenesisHash: env.GenDocHash,
 	}
 
 	return result, nil
@@ -1,6 +1,10 @@
 package core
 
 import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+
 	cmtquery "github.com/cometbft/cometbft/libs/pubsub/query"
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
 	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
@@ -51,6 +55,10 @@ func (env *Environment) Tx(_ *rpctypes.Context, hash []byte, prove bool) (*ctype
 
 // TxSearch allows you to query for multiple transactions results. It returns a
 // list of transactions (maximum ?per_page entries) and the total count.
+//
+// Instead of a page, the caller can pass the cursor returned with the previous
+// page, in which case the page starts right after the last transaction of the
+// previous page, even if new transactions were indexed in the meantime.
 // More: https://docs.cometbft.com/main/rpc/#/Info/tx_search
 func (env *Environment) TxSearch(
 	ctx *rpctypes.Context,
@@ -58,6 +66,7 @@ func (env *Environment) TxSearch(
 	prove bool,
 	pagePtr, perPagePtr *int,
 	orderBy string,
+	cursor string,
 ) (*ctypes.ResultTxSearch, error) {
 	// if index is disabled, return error
 	if _, ok := env.TxIndexer.(*null.TxIndex); ok {
@@ -76,6 +85,17 @@ func (env *Environment) TxSearch(
 		return nil, err
 	}
 
+	var after *txindex.Cursor
+	if cursor != "" {
+		if pagePtr != nil {
+			return nil, ErrPageWithCursor
+		}
+		after, err = decodeTxSearchCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Validate number of results per page
 	perPage := env.validatePerPage(perPagePtr)
 	if pagePtr == nil {
@@ -89,6 +109,7 @@ func (env *Environment) TxSearch(
 		IsPaginated: true,
 		Page:        *pagePtr,
 		PerPage:     perPage,
+		After:       after,
 	}
 
 	results, totalCount, err := env.TxIndexer.Search(ctx.Context(), q, pagSettings)
@@ -114,5 +135,35 @@ func (env *Environment) TxSearch(
 		})
 	}
 
-	return &ctypes.ResultTxSearch{Txs: apiResults, TotalCount: totalCount}, nil
+	var nextCursor string
+	if len(results) > 0 {
+		last := apiResults[len(apiResults)-1]
+		nextCursor = encodeTxSearchCursor(txindex.Cursor{Height: last.Height, Hash: last.Hash})
+	}
+
+	return &ctypes.ResultTxSearch{Txs: apiResults, TotalCount: totalCount, NextCursor: nextCursor}, nil
+}
+
+// encodeTxSearchCursor encodes the cursor of a tx_search page into an opaque
+// token: the big endian height of the transaction followed by its hash, in
+// URL-safe base64.
+func encodeTxSearchCursor(c txindex.Cursor) string {
+	bz := make([]byte, 8, 8+len(c.Hash))
+	binary.BigEndian.PutUint64(bz, uint64(c.Height))
+	return base64.RawURLEncoding.EncodeToString(append(bz, c.Hash...))
+}
+
+func decodeTxSearchCursor(token string) (*txindex.Cursor, error) {
+	bz, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor{Source: err}
+	}
+	if len(bz) <= 8 {
+		return nil, ErrInvalidCursor{Source: errors.New("cursor is too short")}
+	}
+	height := int64(binary.BigEndian.Uint64(bz[:8]))
+	if height <= 0 {
+		return nil, ErrInvalidCursor{Source: ErrNegativeHeight}
+	}
+	return &txindex.Cursor{Height: height, Hash: bz[8:]}, nil
 }
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/crypto/tmhash"
+	"github.com/cometbft/cometbft/state/txindex"
+)
+
+func TestTxSearchCursor(t *testing.T) {
+	cursor := txindex.Cursor{Height: 1000, Hash: tmhash.Sum([]byte("tx"))}
+	decoded, err := decodeTxSearchCursor(encodeTxSearchCursor(cursor))
+	require.NoError(t, err)
+	assert.Equal(t, cursor, *decoded)
+
+	for _, token := range []string{
+		"not base64!",
+		"AAAAAAAAA-g", // no hash
+		encodeTxSearchCursor(txindex.Cursor{Height: 0, Hash: cursor.Hash}),  // zero height
+		encodeTxSearchCursor(txindex.Cursor{Height: -1, Hash: cursor.Hash}), // negative height
+	} {
+		_, err := decodeTxSearchCursor(token)
+		require.ErrorAs(t, err, &ErrInvalidCursor{}, token)
+	}
+}
@@ -60,6 +60,31 @@ type ResultBlockResults struct {
 	AppHash               []byte                      `json:"app_hash"`
 }
 
+// Block results for a range of heights.
+type ResultBlockResultsBatch struct {
+	LastHeight   int64                 `json:"last_height"`
+	BlockResults []*ResultBlockResults `json:"block_results"`
+}
+
+// Retain heights of the node, and range of heights held by its block store.
+// Retain heights that have not been set are 0.
+type ResultRetainHeights struct {
+	ApplicationBlockRetainHeight int64 `json:"application_block_retain_height"`
+	CompanionBlockRetainHeight   int64 `json:"companion_block_retain_height"`
+	ABCIResultsRetainHeight      int64 `json:"abci_results_retain_height"`
+	TxIndexerRetainHeight        int64 `json:"tx_indexer_retain_height"`
+	BlockIndexerRetainHeight     int64 `json:"block_indexer_retain_height"`
+	// The height below which blocks are pruned.
+	EffectiveBlockRetainHeight int64 `json:"effective_block_retain_height"`
+	Base                       int64 `json:"base"`
+	Height                     int64 `json:"height"`
+}
+
+// Whether the pruner is paused.
+type ResultPruningPaused struct {
+	Paused bool `json:"paused"`
+}
+
 // NewResultCommit is a helper to initialize the ResultCommit with
 // the embedded struct.
 func NewResultCommit(header *types.Header, commit *types.Commit,
@@ -101,6 +126,9 @@ type ResultStatus struct {
 	NodeInfo      p2p.DefaultNodeInfo `json:"node_info"`
 	SyncInfo      SyncInfo            `json:"sync_info"`
 	ValidatorInfo ValidatorInfo       `json:"validator_info"`
+	// The SHA-256 checksum of the genesis file the node was started with, to
+	// check that it matches the agreed genesis file of the network.
+	GenesisHash bytes.HexBytes `json:"genesis_hash"`
 }
 
 // Is TxIndexing enabled.
@@ -137,6 +165,18 @@ type Peer struct {
 	RemoteIP         string               `json:"remote_ip"`
 }
 
+// Scores of the peers which misbehaved, from the lowest.
+type ResultPeerScores struct {
+	Scores []PeerScore `json:"scores"`
+}
+
+// The score of a peer.
+type PeerScore struct {
+	NodeID    p2p.ID `json:"node_id"`
+	Score     int64  `json:"score"`
+	Connected bool   `json:"connected"`
+}
+
 // Validators for a height.
 type ResultValidators struct {
 	BlockHeight int64              `json:"block_height"`
@@ -208,6 +248,9 @@ type ResultTx struct {
 type ResultTxSearch struct {
 	Txs        []*ResultTx `json:"txs"`
 	TotalCount int         `json:"total_count"`
+	// Cursor to pass to get the next page, set unless the page is empty. The
+	// next page may be empty.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // ResultBlockSearch defines the RPC response type for a block search by events.
@@ -2,10 +2,13 @@ package pruningservice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	pbsvc "github.com/cometbft/cometbft/api/cometbft/services/pruning/v1"
@@ -41,7 +44,7 @@ func (s *pruningServiceServer) SetBlockIndexerRetainHeight(_ context.Context, re
 	}
 	if err := s.pruner.SetBlockIndexerRetainHeight(int64(height)); err != nil {
 		logger.Error("Cannot set block indexer retain height", "err", err, "traceID", traceID)
-		return nil, status.Errorf(codes.Internal, "Failed to set block indexer retain height (see logs for trace ID: %s)", traceID)
+		return nil, status.Errorf(setRetainHeightErrorCode(err), "Failed to set block indexer retain height (see logs for trace ID: %s)", traceID)
 	}
 	return &pbsvc.SetBlockIndexerRetainHeightResponse{}, nil
 }
@@ -75,7 +78,7 @@ func (s *pruningServiceServer) SetTxIndexerRetainHeight(_ context.Context, reque
 	}
 	if err := s.pruner.SetTxIndexerRetainHeight(int64(height)); err != nil {
 		logger.Error("Cannot set tx indexer retain height", "err", err, "traceID", traceID)
-		return nil, status.Errorf(codes.Internal, "Failed to set tx indexer retain height (see logs for trace ID: %s)", traceID)
+		return nil, status.Errorf(setRetainHeightErrorCode(err), "Failed to set tx indexer retain height (see logs for trace ID: %s)", traceID)
 	}
 	return &pbsvc.SetTxIndexerRetainHeightResponse{}, nil
 }
@@ -95,8 +98,11 @@ func (s *pruningServiceServer) GetTxIndexerRetainHeight(_ context.Context, _ *pb
 	return &pbsvc.GetTxIndexerRetainHeightResponse{Height: uint64(height)}, nil
 }
 
-// SetBlockRetainHeight implements v1.PruningServiceServer.
-func (s *pruningServiceServer) SetBlockRetainHeight(_ context.Context, req *pbsvc.SetBlockRetainHeightRequest) (*pbsvc.SetBlockRetainHeightResponse, error) {
+// SetBlockRetainHeight implements v1.PruningServiceServer. If the retain height
+// is close to the tip of the block store, as configured with
+// WithPrunerNearTipWarnThreshold, the response carries a warning in its
+// "warning" header.
+func (s *pruningServiceServer) SetBlockRetainHeight(ctx context.Context, req *pbsvc.SetBlockRetainHeightRequest) (*pbsvc.SetBlockRetainHeightResponse, error) {
 	height := req.Height
 	// Because we can't agree on a single type to represent block height.
 	if height > uint64(math.MaxInt64) {
@@ -110,7 +116,13 @@ func (s *pruningServiceServer) SetBlockRetainHeight(_ context.Context, req *pbsv
 	}
 	if err := s.pruner.SetCompanionBlockRetainHeight(int64(height)); err != nil {
 		logger.Error("Cannot set block retain height", "err", err, "traceID", traceID)
-		return nil, status.Errorf(codes.Internal, "Failed to set block retain height (see logs for trace ID: %s)", traceID)
+		return nil, status.Errorf(setRetainHeightErrorCode(err), "Failed to set block retain height (see logs for trace ID: %s)", traceID)
+	}
+	if s.pruner.IsRetainHeightNearTip(int64(height)) {
+		warning := fmt.Sprintf("block retain height %d is close to the latest height, almost no history will be left for peers", height)
+		if err := grpc.SetHeader(ctx, metadata.Pairs("warning", warning)); err != nil {
+			logger.Error("Cannot set warning header", "err", err, "traceID", traceID)
+		}
 	}
 	return &pbsvc.SetBlockRetainHeightResponse{}, nil
 }
@@ -154,7 +166,7 @@ func (s *pruningServiceServer) SetBlockResultsRetainHeight(_ context.Context, re
 	}
 	if err := s.pruner.SetABCIResRetainHeight(int64(height)); err != nil {
 		logger.Error("Cannot set block results retain height", "err", err, "traceID", traceID)
-		return nil, status.Errorf(codes.Internal, "Failed to set block results retain height (see logs for trace ID: %s)", traceID)
+		return nil, status.Errorf(setRetainHeightErrorCode(err), "Failed to set block results retain height (see logs for trace ID: %s)", traceID)
 	}
 	return &pbsvc.SetBlockResultsRetainHeightResponse{}, nil
 }
@@ -174,3 +186,13 @@ func (s *pruningServiceServer) GetBlockResultsRetainHeight(_ context.Context, _
 	}
 	return &pbsvc.GetBlockResultsRetainHeightResponse{PruningServiceRetainHeight: uint64(height)}, nil
 }
+
+// setRetainHeightErrorCode returns the code of the error returned when setting
+// a retain height fails with err: Unavailable if the store is temporarily
+// read-only, so that clients try again later, and Internal otherwise.
+func setRetainHeightErrorCode(err error) codes.Code {
+	if errors.Is(err, sm.ErrStoreReadOnly) {
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
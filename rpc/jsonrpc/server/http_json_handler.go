@@ -25,7 +25,7 @@ func makeJSONRPCHandler(funcMap map[string]*RPCFunc, logger log.Logger) http.Han
 			res := types.RPCInvalidRequestError(nil,
 				fmt.Errorf("error reading request body: %w", err),
 			)
-			if wErr := WriteRPCResponseHTTPError(w, http.StatusBadRequest, res); wErr != nil {
+			if wErr := WriteRPCResponseHTTPError(w, readBodyErrorStatus(err), res); wErr != nil {
 				logger.Error("failed to write response", "err", wErr)
 			}
 			return
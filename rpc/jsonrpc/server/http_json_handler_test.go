@@ -20,6 +20,9 @@ func testMux() *http.ServeMux {
 	funcMap := map[string]*RPCFunc{
 		"c":     NewRPCFunc(func(_ *types.Context, _ string, _ int) (string, error) { return "foo", nil }, "s,i"),
 		"block": NewRPCFunc(func(_ *types.Context, _ int) (string, error) { return "block", nil }, "height", Cacheable("height")),
+		"unavailable": NewRPCFunc(func(_ *types.Context) (string, error) {
+			return "", unavailableError{}
+		}, ""),
 	}
 	mux := http.NewServeMux()
 	buf := new(bytes.Buffer)
@@ -29,6 +32,13 @@ func testMux() *http.ServeMux {
 	return mux
 }
 
+// unavailableError is an error reported to URI requests with 503 Service
+// Unavailable.
+type unavailableError struct{}
+
+func (unavailableError) Error() string       { return "unavailable" }
+func (unavailableError) HTTPStatusCode() int { return http.StatusServiceUnavailable }
+
 func statusOK(code int) bool { return code >= 200 && code <= 299 }
 
 // Ensure that nefarious/unintended inputs to `params`
@@ -228,6 +238,21 @@ func TestUnknownRPCPath(t *testing.T) {
 	res.Body.Close()
 }
 
+func TestURIErrorStatusCode(t *testing.T) {
+	mux := testMux()
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost/unavailable", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	res := rec.Result()
+	defer res.Body.Close()
+
+	// The status code is set by the error.
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	blob, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(blob), "unavailable")
+}
+
 func TestRPCResponseCache(t *testing.T) {
 	mux := testMux()
 	body := strings.NewReader(`{"jsonrpc": "2.0","method":"block","id": 0, "params": ["1"]}`)
@@ -4,6 +4,7 @@ package server
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -32,6 +33,8 @@ type Config struct {
 	ReadTimeout time.Duration
 	// mirrors http.Server#WriteTimeout
 	WriteTimeout time.Duration
+	// mirrors http.Server#IdleTimeout
+	IdleTimeout time.Duration
 	// MaxBodyBytes controls the maximum number of bytes the
 	// server will read parsing the request body.
 	MaxBodyBytes int64
@@ -39,6 +42,10 @@ type Config struct {
 	MaxHeaderBytes int
 	// maximum number of requests in a batch request
 	MaxRequestBatchSize int
+	// gzip the responses to the clients accepting it, see CompressionHandler
+	Compression bool
+	// minimum size of the responses to compress, in bytes
+	CompressionMinBytes int
 }
 
 // DefaultConfig returns a default configuration.
@@ -47,24 +54,28 @@ func DefaultConfig() *Config {
 		MaxOpenConnections:  0, // unlimited
 		ReadTimeout:         10 * time.Second,
 		WriteTimeout:        10 * time.Second,
+		IdleTimeout:         0,              // same as ReadTimeout
 		MaxBodyBytes:        int64(1000000), // 1MB
 		MaxHeaderBytes:      1 << 20,        // same as the net/http default
 		MaxRequestBatchSize: 10,             // default to max 10 requests per batch
+		Compression:         false,
+		CompressionMinBytes: 1024, // 1KB
 	}
 }
 
 // Serve creates a http.Server and calls Serve with the given listener. It
-// wraps handler with RecoverAndLogHandler and a handler, which limits the max
-// body size to config.MaxBodyBytes.
+// wraps handler with RecoverAndLogHandler, CompressionHandler and a handler,
+// which limits the max body size to config.MaxBodyBytes.
 //
 // NOTE: This function blocks - you may want to call it in a go-routine.
 func Serve(listener net.Listener, handler http.Handler, logger log.Logger, config *Config) error {
 	logger.Info("serve", "msg", log.NewLazySprintf("Starting RPC HTTP server on %s", listener.Addr()))
 	s := &http.Server{
-		Handler:           PreChecksHandler(RecoverAndLogHandler(defaultHandler{h: handler}, logger), config),
+		Handler:           PreChecksHandler(CompressionHandler(RecoverAndLogHandler(defaultHandler{h: handler}, logger), config), config),
 		ReadTimeout:       config.ReadTimeout,
 		ReadHeaderTimeout: config.ReadTimeout,
 		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
 		MaxHeaderBytes:    config.MaxHeaderBytes,
 	}
 	err := s.Serve(listener)
@@ -73,8 +84,9 @@ func Serve(listener net.Listener, handler http.Handler, logger log.Logger, confi
 }
 
 // ServeTLS creates a http.Server and calls ServeTLS with the given listener,
-// certFile and keyFile. It wraps handler with RecoverAndLogHandler and a
-// handler, which limits the max body size to config.MaxBodyBytes.
+// certFile and keyFile. It wraps handler with RecoverAndLogHandler,
+// CompressionHandler and a handler, which limits the max body size to
+// config.MaxBodyBytes.
 //
 // NOTE: This function blocks - you may want to call it in a go-routine.
 func ServeTLS(
@@ -87,10 +99,11 @@ func ServeTLS(
 	logger.Info("serve tls", "msg", log.NewLazySprintf("Starting RPC HTTPS server on %s (cert: %q, key: %q)",
 		listener.Addr(), certFile, keyFile))
 	s := &http.Server{
-		Handler:           PreChecksHandler(RecoverAndLogHandler(defaultHandler{h: handler}, logger), config),
+		Handler:           PreChecksHandler(CompressionHandler(RecoverAndLogHandler(defaultHandler{h: handler}, logger), config), config),
 		ReadTimeout:       config.ReadTimeout,
 		ReadHeaderTimeout: config.ReadTimeout,
 		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
 		MaxHeaderBytes:    config.MaxHeaderBytes,
 	}
 	err := s.ServeTLS(listener, certFile, keyFile)
@@ -281,6 +294,17 @@ func Listen(addr string, maxOpenConnections int) (listener net.Listener, err err
 	return listener, nil
 }
 
+// readBodyErrorStatus returns the HTTP status code of the response to a
+// request whose body failed to be read with err: 413 if it exceeds
+// config.MaxBodyBytes, 400 otherwise.
+func readBodyErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
 // Middleware
 
 // PreChecksHandler is a middleware function that checks the size of batch requests and returns an error
@@ -302,7 +326,7 @@ func PreChecksHandler(next http.Handler, config *Config) http.Handler {
 			data, err := io.ReadAll(r.Body)
 			if err != nil {
 				res := types.RPCInvalidRequestError(nil, fmt.Errorf("error reading request body: %w", err))
-				_ = WriteRPCResponseHTTPError(w, http.StatusBadRequest, res)
+				_ = WriteRPCResponseHTTPError(w, readBodyErrorStatus(err), res)
 				return
 			}
 
@@ -327,3 +351,113 @@ func PreChecksHandler(next http.Handler, config *Config) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// CompressionHandler is a middleware function that gzips the responses of at
+// least config.CompressionMinBytes bytes if config.Compression is enabled and
+// the client accepts it, as advertised by the Accept-Encoding header of the
+// request. WebSocket upgrades are left untouched, as their connection is
+// hijacked.
+func CompressionHandler(next http.Handler, config *Config) http.Handler {
+	if !config.Compression {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w, minBytes: config.CompressionMinBytes, status: http.StatusOK}
+		next.ServeHTTP(gw, r)
+		// Failing to write the response means the client is gone, or will
+		// notice that the response is truncated.
+		_ = gw.close()
+	})
+}
+
+// acceptsGzip returns true if the given Accept-Encoding header value accepts
+// gzip, i.e. lists gzip or * without a zero quality value.
+func acceptsGzip(acceptEncoding string) bool {
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(coding, ";")
+		name = strings.TrimSpace(name)
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		q, found := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q=")
+		if !found {
+			return true
+		}
+		if quality, err := strconv.ParseFloat(q, 64); err == nil && quality > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter buffers the response until it reaches minBytes, then
+// gzips it. Smaller responses are written as is when the writer is closed.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minBytes int
+
+	status      int
+	buf         bytes.Buffer
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if w.gz != nil {
+		return w.gz.Write(b)
+	}
+	if w.wroteHeader {
+		return w.ResponseWriter.Write(b)
+	}
+	w.buf.Write(b)
+	if w.buf.Len() < w.minBytes {
+		return len(b), nil
+	}
+	if err := w.flushBuffer(w.ResponseWriter.Header().Get("Content-Encoding") == ""); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// flushBuffer writes the header and the buffered response, compressed if
+// compress is true.
+func (w *gzipResponseWriter) flushBuffer(compress bool) error {
+	w.wroteHeader = true
+	if compress {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+		w.ResponseWriter.WriteHeader(w.status)
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+		_, err := w.gz.Write(w.buf.Bytes())
+		return err
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	return err
+}
+
+// close writes the response if it is smaller than minBytes, or terminates the
+// compressed stream otherwise.
+func (w *gzipResponseWriter) close() error {
+	if w.gz != nil {
+		return w.gz.Close()
+	}
+	if w.wroteHeader {
+		return nil
+	}
+	return w.flushBuffer(false)
+}
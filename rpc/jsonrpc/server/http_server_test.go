@@ -1,6 +1,7 @@
 package server
 
 import (
+	"compress/gzip"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -158,3 +160,87 @@ func TestWriteRPCResponseHTTPError(t *testing.T) {
 	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
 	assert.Equal(t, `{"jsonrpc":"2.0","id":-1,"error":{"code":-32603,"message":"Internal error","data":"foo"}}`, string(body))
 }
+
+func TestCompressionHandler(t *testing.T) {
+	large := strings.Repeat(`{"value":"hello"}`, 100)
+	small := `{"value":"hello"}`
+
+	config := DefaultConfig()
+	config.Compression = true
+	mux := http.NewServeMux()
+	mux.HandleFunc("/large", func(w http.ResponseWriter, _ *http.Request) {
+		// Written in several chunks, across the threshold.
+		for i := 0; i < len(large); i += 100 {
+			fmt.Fprint(w, large[i:min(i+100, len(large))])
+		}
+	})
+	mux.HandleFunc("/small", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, small)
+	})
+	handler := CompressionHandler(mux, config)
+
+	testCases := []struct {
+		path           string
+		headers        map[string]string
+		expectedStatus int
+		expectedBody   string
+		compressed     bool
+	}{
+		{"/large", map[string]string{"Accept-Encoding": "gzip"}, http.StatusOK, large, true},
+		{"/large", map[string]string{"Accept-Encoding": "deflate, gzip;q=0.5"}, http.StatusOK, large, true},
+		{"/large", map[string]string{"Accept-Encoding": "*"}, http.StatusOK, large, true},
+		{"/large", map[string]string{"Accept-Encoding": "gzip;q=0"}, http.StatusOK, large, false},
+		{"/large", map[string]string{}, http.StatusOK, large, false},
+		{"/large", map[string]string{"Accept-Encoding": "gzip", "Upgrade": "websocket"}, http.StatusOK, large, false},
+		{"/small", map[string]string{"Accept-Encoding": "gzip"}, http.StatusAccepted, small, false},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s %v", tc.path, tc.headers), func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			for name, value := range tc.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+
+			var body io.Reader = resp.Body
+			if tc.compressed {
+				assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+				gr, err := gzip.NewReader(resp.Body)
+				require.NoError(t, err)
+				body = gr
+			} else {
+				assert.Empty(t, resp.Header.Get("Content-Encoding"))
+			}
+			data, err := io.ReadAll(body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedBody, string(data))
+		})
+	}
+
+	// Disabled by default.
+	assert.Equal(t, mux, CompressionHandler(mux, DefaultConfig()))
+}
+
+func TestPreChecksHandlerMaxBodyBytes(t *testing.T) {
+	body := `{"jsonrpc":"2.0","id":1,"method":"block","params":{"height":"1"}}`
+
+	for _, batchSize := range []int{0, 10} {
+		config := DefaultConfig()
+		config.MaxRequestBatchSize = batchSize
+		config.MaxBodyBytes = int64(len(body))
+		handler := PreChecksHandler(testMux(), config)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		assert.Equal(t, http.StatusOK, w.Code, "batch size %d", batchSize)
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body+" ")))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, "batch size %d", batchSize)
+	}
+}
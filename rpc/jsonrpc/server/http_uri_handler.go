@@ -2,6 +2,7 @@ package server
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -17,6 +18,13 @@ import (
 
 var reInt = regexp.MustCompile(`^-?[0-9]+$`)
 
+// HTTPStatusCoder is implemented by errors returned by RPC functions which must
+// be reported to URI (GET) requests with a specific HTTP status code, instead
+// of 500 Internal Server Error.
+type HTTPStatusCoder interface {
+	HTTPStatusCode() int
+}
+
 // convert from a function name to the http handler.
 func makeHTTPHandler(rpcFunc *RPCFunc, logger log.Logger) func(http.ResponseWriter, *http.Request) {
 	// Always return -1 as there's no ID here.
@@ -66,7 +74,12 @@ func makeHTTPHandler(rpcFunc *RPCFunc, logger log.Logger) func(http.ResponseWrit
 		result, err := unreflectResult(returns)
 		logger.Debug("HTTPRestRPC", "method", r.URL.Path, "args", logArgs, "result", result, "error", err)
 		if err != nil {
-			if err := WriteRPCResponseHTTPError(w, http.StatusInternalServerError,
+			code := http.StatusInternalServerError
+			var coder HTTPStatusCoder
+			if errors.As(err, &coder) {
+				code = coder.HTTPStatusCode()
+			}
+			if err := WriteRPCResponseHTTPError(w, code,
 				types.RPCInternalError(dummyID, err)); err != nil {
 				logger.Error("failed to write response", "err", err)
 				return
@@ -3,6 +3,7 @@ package state
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 type (
@@ -74,6 +75,34 @@ type (
 	ErrCannotLoadState struct {
 		Err error
 	}
+
+	ErrFailedToPruneTxIndexer struct {
+		Height int64
+		Err    error
+	}
+
+	ErrFailedToPruneBlockIndexer struct {
+		Height int64
+		Err    error
+	}
+
+	ErrPrunerLeaseHeld struct {
+		Owner  string
+		Expiry time.Time
+	}
+
+	ErrBlockStoreHole struct {
+		Height       int64
+		Base         int64
+		RetainHeight int64
+	}
+
+	ErrRetainHeightBelowEvidenceWindow struct {
+		RetainHeight    int64
+		LastBlockHeight int64
+		MaxAgeNumBlocks int64
+		MaxAgeDuration  time.Duration
+	}
 )
 
 func (e ErrUnknownBlock) Error() string {
@@ -158,10 +187,64 @@ func (e ErrFailedToPruneStates) Unwrap() error {
 	return e.Err
 }
 
+func (e ErrFailedToPruneTxIndexer) Error() string {
+	return fmt.Sprintf("failed to prune tx indexer to height %d: %s", e.Height, e.Err.Error())
+}
+
+func (e ErrFailedToPruneTxIndexer) Unwrap() error {
+	return e.Err
+}
+
+func (e ErrFailedToPruneBlockIndexer) Error() string {
+	return fmt.Sprintf("failed to prune block indexer to height %d: %s", e.Height, e.Err.Error())
+}
+
+func (e ErrFailedToPruneBlockIndexer) Unwrap() error {
+	return e.Err
+}
+
+func (e ErrBlockStoreHole) Error() string {
+	return fmt.Sprintf("the block store has no block at height %d, between its base %d and the retain height %d: "+
+		"refusing to prune blocks, as it may be corrupted", e.Height, e.Base, e.RetainHeight)
+}
+
+func (e ErrRetainHeightBelowEvidenceWindow) Error() string {
+	return fmt.Sprintf("retain height %d would prune block %d, whose evidence can still be submitted at height %d, "+
+		"as it is not older than both %d blocks and %v", e.RetainHeight, e.RetainHeight-1, e.LastBlockHeight,
+		e.MaxAgeNumBlocks, e.MaxAgeDuration)
+}
+
+func (e ErrPrunerLeaseHeld) Error() string {
+	return fmt.Sprintf("another pruner (%s) is pruning the same database, its lease expires at %v: "+
+		"make sure that no other node uses the same database, or wait for the lease to expire if that node crashed",
+		e.Owner, e.Expiry)
+}
+
 var (
 	ErrFinalizeBlockResponsesNotPersisted = errors.New("node is not persisting finalize block responses")
 	ErrPrunerCannotLowerRetainHeight      = errors.New("cannot set a height lower than previously requested - heights might have already been pruned")
 	ErrInvalidRetainHeight                = errors.New("retain height cannot be less or equal than 0")
+	ErrPrunerRunning                      = errors.New("cannot prune now while the pruner is running")
+	ErrNoBlocksToPrune                    = errors.New("cannot set a retain height while the block store is empty, there are no blocks to prune yet")
+	ErrBlockStoreEmpty                    = errors.New("the block store is empty")
+	ErrRetainLeaseNotFound                = errors.New("retain lease not found, it may have expired")
+	ErrInvalidRetainLeaseTTL              = errors.New("retain lease TTL must be positive")
+	// ErrInvalidPrunerOptions is wrapped by the error returned by
+	// NewPrunerWithValidation when the options of the pruner are invalid.
+	ErrInvalidPrunerOptions = errors.New("invalid pruner options")
+	// ErrTransientStoreError can be wrapped by the errors of the stores, or
+	// of a PruneStrategy, that are worth retrying right away, e.g. a
+	// database temporarily locked by a compaction. See
+	// WithPrunerTransientErrorRetries.
+	ErrTransientStoreError = errors.New("transient store error")
+	// ErrStoreReadOnly is returned when setting a retain height fails because
+	// the store is temporarily read-only, e.g. during a backup, so that
+	// callers can try again later.
+	ErrStoreReadOnly = errors.New("the store is temporarily read-only, try again later")
+	// ErrNotSupported is returned by the methods of a store that it wasn't
+	// set up to support, e.g. Store.LoadRetainHeightAt without
+	// StoreOptions.RetainHeightJournal.
+	ErrNotSupported = errors.New("not supported by the store")
 )
 
 func (e ErrCannotLoadState) Error() string {
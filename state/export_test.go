@@ -68,19 +68,38 @@ func (p *Pruner) FindMinRetainHeight() int64 {
 }
 
 func (p *Pruner) PruneABCIResToRetainHeight(lastRetainHeight int64) int64 {
-	return p.pruneABCIResToRetainHeight(lastRetainHeight)
+	var step int64
+	newRetainHeight, _, _, _ := p.pruneABCIResToRetainHeight(lastRetainHeight, &step)
+	return newRetainHeight
+}
+
+func (p *Pruner) PruneABCIResToRetainHeightStep(lastRetainHeight int64, step *int64) (int64, bool) {
+	newRetainHeight, _, _, catchingUp := p.pruneABCIResToRetainHeight(lastRetainHeight, step)
+	return newRetainHeight, catchingUp
 }
 
 func (p *Pruner) PruneTxIndexerToRetainHeight(lastRetainHeight int64) int64 {
-	return p.pruneTxIndexerToRetainHeight(lastRetainHeight)
+	newRetainHeight, _, _ := p.pruneTxIndexerToRetainHeight(lastRetainHeight)
+	return newRetainHeight
 }
 
 func (p *Pruner) PruneBlockIndexerToRetainHeight(lastRetainHeight int64) int64 {
-	return p.pruneBlockIndexerToRetainHeight(lastRetainHeight)
+	newRetainHeight, _, _ := p.pruneBlockIndexerToRetainHeight(lastRetainHeight)
+	return newRetainHeight
+}
+
+func RemainingHeights(targetRetainHeight, newRetainHeight int64) int64 {
+	return remainingHeights(targetRetainHeight, newRetainHeight)
+}
+
+func (p *Pruner) PruneBlocksToRetainHeight(lastRetainHeight int64) (int64, error) {
+	newRetainHeight, _, _, err := p.pruneBlocksToRetainHeight(lastRetainHeight)
+	return newRetainHeight, err
 }
 
 func (p *Pruner) PruneBlocksToHeight(height int64) (uint64, int64, error) {
-	return p.pruneBlocksToHeight(height)
+	pruned, evRetainHeight, _, err := p.pruneBlocksToHeight(height)
+	return pruned, evRetainHeight, err
 }
 
 func Int64ToBytes(val int64) []byte {
@@ -27,6 +27,8 @@ type BlockIndexer interface {
 
 	Prune(retainHeight int64) (int64, int64, error)
 
+	// SetRetainHeight persists the retain height of the indexer. It is durable
+	// once it returns.
 	SetRetainHeight(retainHeight int64) error
 
 	GetRetainHeight() (int64, error)
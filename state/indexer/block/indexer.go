@@ -34,7 +34,7 @@ func IndexerFromConfig(cfg *config.Config, dbProvider config.DBProvider, chainID
 		if conn == "" {
 			return nil, nil, errors.New("the psql connection settings cannot be empty")
 		}
-		es, err := psql.NewEventSink(cfg.TxIndex.PsqlConn, chainID)
+		es, err := psql.NewEventSink(cfg.TxIndex.PsqlConn, chainID, psql.WithBatchSize(cfg.TxIndex.PsqlBatchSize))
 		if err != nil {
 			return nil, nil, fmt.Errorf("creating psql indexer: %w", err)
 		}
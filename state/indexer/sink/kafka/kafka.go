@@ -0,0 +1,289 @@
+// Package kafka implements an event sink that publishes events from the event
+// bus to a Kafka topic.
+//
+// The sink subscribes to new block, transaction and validator set update
+// events, encodes them as JSON and hands them to a Producer. Publishing happens
+// in the background and never blocks the event bus: events are buffered in a
+// bounded queue, and dropped if the queue is full or if publishing keeps failing
+// after all retries.
+//
+// Support for talking to an actual Kafka cluster is only compiled in when
+// building with the "kafka" build tag, so that the Kafka client library is not
+// forced on everyone. Without the build tag, NewProducer reports
+// ErrKafkaNotBuilt.
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	cmtpubsub "github.com/cometbft/cometbft/libs/pubsub"
+	"github.com/cometbft/cometbft/libs/service"
+	"github.com/cometbft/cometbft/types"
+)
+
+const (
+	subscriber = "KafkaEventSink"
+
+	defaultQueueSize      = 1000
+	defaultMaxRetries     = 5
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+)
+
+// ErrKafkaNotBuilt is returned by NewProducer if the binary was built without
+// the "kafka" build tag.
+var ErrKafkaNotBuilt = errors.New("the Kafka event sink requires building with the \"kafka\" build tag")
+
+// Message is a single encoded event to be published to Kafka.
+type Message struct {
+	// The key of the message. It is set to the type of the event, so that
+	// events of the same type end up in the same partition and their ordering
+	// is preserved.
+	Key []byte
+	// The JSON-encoded event data.
+	Value []byte
+}
+
+// Producer publishes messages to a Kafka topic.
+type Producer interface {
+	// Publish synchronously publishes msg, returning an error if the message
+	// could not be published.
+	Publish(ctx context.Context, msg Message) error
+	// Close flushes any pending messages and closes the connection to Kafka.
+	Close() error
+}
+
+// EventSink is a service that publishes new block, transaction and validator
+// set update events from the event bus to Kafka via a Producer.
+type EventSink struct {
+	service.BaseService
+
+	producer Producer
+	eventBus *types.EventBus
+	metrics  *Metrics
+
+	queueSize      int
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	queue  chan Message
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+type Option func(*EventSink)
+
+// WithMetrics sets the metrics.
+func WithMetrics(metrics *Metrics) Option {
+	return func(es *EventSink) { es.metrics = metrics }
+}
+
+// WithQueueSize sets the maximum number of events buffered while waiting to be
+// published.
+func WithQueueSize(size int) Option {
+	return func(es *EventSink) { es.queueSize = size }
+}
+
+// WithMaxRetries sets the maximum number of times publishing an event is
+// retried before the event is dropped.
+func WithMaxRetries(retries int) Option {
+	return func(es *EventSink) { es.maxRetries = retries }
+}
+
+// WithRetryBackoff sets the delay before the first retry of a failed publish,
+// and the upper bound of the delay, which is doubled after every failed
+// attempt.
+func WithRetryBackoff(initial, maximum time.Duration) Option {
+	return func(es *EventSink) {
+		es.initialBackoff = initial
+		es.maxBackoff = maximum
+	}
+}
+
+// NewEventSink returns a new event sink publishing events from eventBus via
+// producer. The sink takes ownership of the producer and closes it when
+// stopped.
+func NewEventSink(producer Producer, eventBus *types.EventBus, options ...Option) *EventSink {
+	es := &EventSink{
+		producer:       producer,
+		eventBus:       eventBus,
+		metrics:        NopMetrics(),
+		queueSize:      defaultQueueSize,
+		maxRetries:     defaultMaxRetries,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+	}
+	for _, option := range options {
+		option(es)
+	}
+	es.queue = make(chan Message, es.queueSize)
+	es.ctx, es.cancel = context.WithCancel(context.Background())
+	es.BaseService = *service.NewBaseService(nil, "KafkaEventSink", es)
+	return es
+}
+
+// OnStart implements service.Service by subscribing to the events published
+// to Kafka and starting the background publishing routine.
+func (es *EventSink) OnStart() error {
+	queries := []cmtpubsub.Query{
+		types.EventQueryNewBlock,
+		types.EventQueryTx,
+		types.EventQueryValidatorSetUpdates,
+	}
+	subs := make([]types.Subscription, 0, len(queries))
+	for _, q := range queries {
+		// The subscriptions are buffered as much as the queue, so that a burst
+		// of events (e.g. the transactions of a large block) does not cause
+		// them to be canceled while events are being encoded.
+		sub, err := es.eventBus.Subscribe(es.ctx, subscriber, q, max(es.queueSize, 1))
+		if err != nil {
+			return err
+		}
+		subs = append(subs, sub)
+	}
+
+	for i, sub := range subs {
+		go es.receiveRoutine(queries[i], sub)
+	}
+	go es.publishRoutine()
+	return nil
+}
+
+// OnStop implements service.Service by unsubscribing from all events and
+// closing the producer. Events still in the queue are dropped.
+func (es *EventSink) OnStop() {
+	es.cancel()
+	if es.eventBus.IsRunning() {
+		_ = es.eventBus.UnsubscribeAll(context.Background(), subscriber)
+	}
+	if err := es.producer.Close(); err != nil {
+		es.Logger.Error("Failed to close Kafka producer", "err", err)
+	}
+}
+
+// receiveRoutine encodes the events received on sub, the subscription to q,
+// and adds them to the queue, without ever blocking on the publishing routine.
+// If the subscription is canceled by the event bus, e.g. because the sink fell
+// behind, it subscribes to q again.
+func (es *EventSink) receiveRoutine(q cmtpubsub.Query, sub types.Subscription) {
+	for {
+		select {
+		case <-es.Quit():
+			return
+		case <-sub.Canceled():
+			// The event bus cancels the subscriptions without an error when
+			// it stops.
+			if err := sub.Err(); err == nil || errors.Is(err, cmtpubsub.ErrUnsubscribed) {
+				return
+			}
+			es.Logger.Error("Kafka event sink subscription canceled, resubscribing", "query", q, "err", sub.Err())
+			es.metrics.CanceledSubscriptions.Add(1)
+			if sub = es.resubscribe(q); sub == nil {
+				return
+			}
+		case msg := <-sub.Out():
+			m, err := encodeEvent(msg.Data())
+			if err != nil {
+				es.Logger.Error("Failed to encode event", "err", err)
+				es.metrics.DroppedEvents.With("reason", "encoding").Add(1)
+				continue
+			}
+			select {
+			case es.queue <- m:
+				es.metrics.QueueSize.Set(float64(len(es.queue)))
+			default:
+				es.Logger.Debug("Kafka event sink queue full, dropping event", "type", string(m.Key))
+				es.metrics.DroppedEvents.With("reason", "queue_full").Add(1)
+			}
+		}
+	}
+}
+
+// resubscribe subscribes to q again, once its subscription was canceled,
+// retrying with exponential backoff until it succeeds. It returns nil if the
+// sink stops first. Events published in the meantime are missed.
+func (es *EventSink) resubscribe(q cmtpubsub.Query) types.Subscription {
+	backoff := es.initialBackoff
+	for {
+		select {
+		case <-es.Quit():
+			return nil
+		case <-time.After(backoff):
+		}
+		// The event bus refuses to subscribe to q again until the canceled
+		// subscription is removed.
+		if err := es.eventBus.Unsubscribe(es.ctx, subscriber, q); err != nil && !errors.Is(err, cmtpubsub.ErrSubscriptionNotFound) {
+			es.Logger.Error("Failed to remove canceled Kafka event sink subscription", "query", q, "err", err)
+		}
+		sub, err := es.eventBus.Subscribe(es.ctx, subscriber, q, max(es.queueSize, 1))
+		if err == nil {
+			return sub
+		}
+		es.Logger.Error("Failed to resubscribe Kafka event sink, retrying", "query", q, "backoff", backoff, "err", err)
+		backoff = min(2*backoff, es.maxBackoff)
+	}
+}
+
+func (es *EventSink) publishRoutine() {
+	for {
+		select {
+		case <-es.Quit():
+			return
+		case msg := <-es.queue:
+			es.metrics.QueueSize.Set(float64(len(es.queue)))
+			es.publish(msg)
+		}
+	}
+}
+
+// publish publishes msg, retrying with exponential backoff up to the
+// configured maximum number of retries.
+func (es *EventSink) publish(msg Message) {
+	backoff := es.initialBackoff
+	for attempt := 0; ; attempt++ {
+		err := es.producer.Publish(es.ctx, msg)
+		if err == nil {
+			es.metrics.PublishedEvents.With("event_type", string(msg.Key)).Add(1)
+			return
+		}
+		es.metrics.PublishErrors.Add(1)
+		if attempt >= es.maxRetries {
+			es.Logger.Error("Failed to publish event to Kafka, dropping it", "type", string(msg.Key), "attempts", attempt+1, "err", err)
+			es.metrics.DroppedEvents.With("reason", "publish_failed").Add(1)
+			return
+		}
+		es.Logger.Debug("Failed to publish event to Kafka, retrying", "type", string(msg.Key), "backoff", backoff, "err", err)
+		select {
+		case <-es.Quit():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, es.maxBackoff)
+	}
+}
+
+// encodeEvent encodes the data of an event received from the event bus into a
+// message.
+func encodeEvent(data any) (Message, error) {
+	var eventType string
+	switch data.(type) {
+	case types.EventDataNewBlock:
+		eventType = types.EventNewBlock
+	case types.EventDataTx:
+		eventType = types.EventTx
+	case types.EventDataValidatorSetUpdates:
+		eventType = types.EventValidatorSetUpdates
+	default:
+		return Message{}, fmt.Errorf("unexpected event data type %T", data)
+	}
+	value, err := cmtjson.Marshal(data)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{Key: []byte(eventType), Value: value}, nil
+}
@@ -0,0 +1,190 @@
+package kafka_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics/generic"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/state/indexer/sink/kafka"
+	"github.com/cometbft/cometbft/types"
+)
+
+// testProducer records published messages, failing the first failures
+// attempts.
+type testProducer struct {
+	mtx      sync.Mutex
+	failures int
+	attempts int
+	messages []kafka.Message
+	closed   bool
+}
+
+var _ kafka.Producer = (*testProducer)(nil)
+
+func (p *testProducer) Publish(_ context.Context, msg kafka.Message) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.attempts++
+	if p.attempts <= p.failures {
+		return errors.New("broker unavailable")
+	}
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *testProducer) Close() error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.closed = true
+	return nil
+}
+
+func (p *testProducer) published() []kafka.Message {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return append([]kafka.Message(nil), p.messages...)
+}
+
+func (p *testProducer) numAttempts() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.attempts
+}
+
+func startEventSink(t *testing.T, producer kafka.Producer, options ...kafka.Option) *types.EventBus {
+	t.Helper()
+	eventBus := types.NewEventBus()
+	eventBus.SetLogger(log.TestingLogger())
+	require.NoError(t, eventBus.Start())
+	t.Cleanup(func() {
+		if err := eventBus.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	sink := kafka.NewEventSink(producer, eventBus, options...)
+	sink.SetLogger(log.TestingLogger())
+	require.NoError(t, sink.Start())
+	t.Cleanup(func() {
+		if err := sink.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	return eventBus
+}
+
+func TestEventSinkPublishesEvents(t *testing.T) {
+	producer := &testProducer{}
+	eventBus := startEventSink(t, producer)
+
+	require.NoError(t, eventBus.PublishEventTx(types.EventDataTx{TxResult: abci.TxResult{
+		Height: 1,
+		Tx:     types.Tx("foo"),
+	}}))
+	require.NoError(t, eventBus.PublishEventValidatorSetUpdates(types.EventDataValidatorSetUpdates{}))
+	// Events the sink is not subscribed to are not published.
+	require.NoError(t, eventBus.PublishEventNewBlockHeader(types.EventDataNewBlockHeader{}))
+
+	require.Eventually(t, func() bool {
+		return len(producer.published()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	keys := make([]string, 0, 2)
+	for _, msg := range producer.published() {
+		keys = append(keys, string(msg.Key))
+		require.NotEmpty(t, msg.Value)
+	}
+	require.ElementsMatch(t, []string{types.EventTx, types.EventValidatorSetUpdates}, keys)
+}
+
+func TestEventSinkRetriesFailedPublish(t *testing.T) {
+	producer := &testProducer{failures: 2}
+	eventBus := startEventSink(t, producer,
+		kafka.WithMaxRetries(2),
+		kafka.WithRetryBackoff(time.Millisecond, 2*time.Millisecond),
+	)
+
+	require.NoError(t, eventBus.PublishEventValidatorSetUpdates(types.EventDataValidatorSetUpdates{}))
+
+	require.Eventually(t, func() bool {
+		return len(producer.published()) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, 3, producer.numAttempts())
+}
+
+func TestEventSinkDropsEventAfterMaxRetries(t *testing.T) {
+	producer := &testProducer{failures: 3}
+	eventBus := startEventSink(t, producer,
+		kafka.WithMaxRetries(2),
+		kafka.WithRetryBackoff(time.Millisecond, 2*time.Millisecond),
+	)
+
+	require.NoError(t, eventBus.PublishEventValidatorSetUpdates(types.EventDataValidatorSetUpdates{}))
+	require.Eventually(t, func() bool {
+		return producer.numAttempts() == 3
+	}, time.Second, 10*time.Millisecond)
+
+	// The first event was dropped, so the next one is the first to make it.
+	require.NoError(t, eventBus.PublishEventValidatorSetUpdates(types.EventDataValidatorSetUpdates{}))
+	require.Eventually(t, func() bool {
+		return len(producer.published()) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, 4, producer.numAttempts())
+}
+
+func TestEventSinkResubscribesWhenCanceled(t *testing.T) {
+	producer := &testProducer{}
+	metrics := kafka.NopMetrics()
+	canceled := generic.NewCounter("canceled_subscriptions")
+	metrics.CanceledSubscriptions = canceled
+	eventBus := startEventSink(t, producer,
+		kafka.WithMetrics(metrics),
+		kafka.WithQueueSize(1),
+		kafka.WithRetryBackoff(time.Millisecond, 2*time.Millisecond),
+	)
+
+	// Publishing transactions faster than the sink receives them makes the
+	// event bus cancel its subscription.
+	require.Eventually(t, func() bool {
+		for i := 0; i < 100; i++ {
+			_ = eventBus.PublishEventTx(types.EventDataTx{TxResult: abci.TxResult{Height: 1, Tx: types.Tx("burst")}})
+		}
+		return canceled.Value() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The sink subscribes again, and publishes the transactions that follow.
+	tx := types.Tx("after")
+	require.Eventually(t, func() bool {
+		_ = eventBus.PublishEventTx(types.EventDataTx{TxResult: abci.TxResult{Height: 2, Tx: tx}})
+		for _, msg := range producer.published() {
+			if strings.Contains(string(msg.Value), base64.StdEncoding.EncodeToString(tx)) {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestEventSinkClosesProducerOnStop(t *testing.T) {
+	eventBus := types.NewEventBus()
+	require.NoError(t, eventBus.Start())
+	defer func() { _ = eventBus.Stop() }()
+
+	producer := &testProducer{}
+	sink := kafka.NewEventSink(producer, eventBus)
+	require.NoError(t, sink.Start())
+	require.NoError(t, sink.Stop())
+
+	producer.mtx.Lock()
+	defer producer.mtx.Unlock()
+	require.True(t, producer.closed)
+}
@@ -0,0 +1,58 @@
+// Code generated by metricsgen. DO NOT EDIT.
+
+package kafka
+
+import (
+	"github.com/go-kit/kit/metrics/discard"
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &Metrics{
+		PublishedEvents: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "published_events",
+			Help:      "Number of events successfully published to Kafka.",
+		}, append(labels, "event_type")).With(labelsAndValues...),
+		PublishErrors: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "publish_errors",
+			Help:      "Number of failed attempts to publish an event to Kafka.",
+		}, labels).With(labelsAndValues...),
+		DroppedEvents: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "dropped_events",
+			Help:      "Number of events dropped, either because the queue was full or because publishing kept failing after all retries.",
+		}, append(labels, "reason")).With(labelsAndValues...),
+		QueueSize: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "queue_size",
+			Help:      "Number of events waiting to be published.",
+		}, labels).With(labelsAndValues...),
+		CanceledSubscriptions: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "canceled_subscriptions",
+			Help:      "Number of times a subscription of the sink to the event bus was canceled, e.g. because the sink fell behind, and had to be renewed.",
+		}, labels).With(labelsAndValues...),
+	}
+}
+
+func NopMetrics() *Metrics {
+	return &Metrics{
+		PublishedEvents:       discard.NewCounter(),
+		PublishErrors:         discard.NewCounter(),
+		DroppedEvents:         discard.NewCounter(),
+		QueueSize:             discard.NewGauge(),
+		CanceledSubscriptions: discard.NewCounter(),
+	}
+}
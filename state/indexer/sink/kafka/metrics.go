@@ -0,0 +1,33 @@
+package kafka
+
+import (
+	"github.com/go-kit/kit/metrics"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this
+	// package.
+	MetricsSubsystem = "kafka_sink"
+)
+
+//go:generate go run ../../../../scripts/metricsgen -struct=Metrics
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Number of events successfully published to Kafka.
+	PublishedEvents metrics.Counter `metrics_labels:"event_type"`
+
+	// Number of failed attempts to publish an event to Kafka.
+	PublishErrors metrics.Counter
+
+	// Number of events dropped, either because the queue was full or because
+	// publishing kept failing after all retries.
+	DroppedEvents metrics.Counter `metrics_labels:"reason"`
+
+	// Number of events waiting to be published.
+	QueueSize metrics.Gauge
+
+	// Number of times a subscription of the sink to the event bus was
+	// canceled, e.g. because the sink fell behind, and had to be renewed.
+	CanceledSubscriptions metrics.Counter
+}
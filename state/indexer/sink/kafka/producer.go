@@ -0,0 +1,57 @@
+//go:build kafka
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"github.com/cometbft/cometbft/config"
+)
+
+type producer struct {
+	writer *kafkago.Writer
+}
+
+var _ Producer = (*producer)(nil)
+
+// NewProducer returns a Producer publishing to the brokers and topic specified
+// in cfg.
+func NewProducer(cfg *config.KafkaSinkConfig) (Producer, error) {
+	var acks kafkago.RequiredAcks
+	switch cfg.Acks {
+	case "none":
+		acks = kafkago.RequireNone
+	case "leader":
+		acks = kafkago.RequireOne
+	case "all":
+		acks = kafkago.RequireAll
+	default:
+		return nil, fmt.Errorf("unknown acks mode %q", cfg.Acks)
+	}
+	return &producer{
+		writer: &kafkago.Writer{
+			Addr:         kafkago.TCP(cfg.Brokers...),
+			Topic:        cfg.Topic,
+			Balancer:     &kafkago.Hash{},
+			RequiredAcks: acks,
+			// Retries are handled by the event sink, with backoff.
+			MaxAttempts: 1,
+			// Messages are published one at a time, so do not wait for a
+			// batch to fill up.
+			BatchSize: 1,
+		},
+	}, nil
+}
+
+// Publish implements Producer.
+func (p *producer) Publish(ctx context.Context, msg Message) error {
+	return p.writer.WriteMessages(ctx, kafkago.Message{Key: msg.Key, Value: msg.Value})
+}
+
+// Close implements Producer.
+func (p *producer) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,13 @@
+//go:build !kafka
+
+package kafka
+
+import (
+	"github.com/cometbft/cometbft/config"
+)
+
+// NewProducer always returns ErrKafkaNotBuilt, since this binary was built
+// without the "kafka" build tag.
+func NewProducer(*config.KafkaSinkConfig) (Producer, error) {
+	return nil, ErrKafkaNotBuilt
+}
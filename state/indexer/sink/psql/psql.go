@@ -33,20 +33,39 @@ const (
 type EventSink struct {
 	store   *sql.DB
 	chainID string
+
+	// The maximum number of rows written by a single bulk insert statement.
+	// 0 means no limit.
+	batchSize int
+}
+
+type EventSinkOption func(*EventSink)
+
+// WithBatchSize sets the maximum number of rows written by a single bulk
+// insert statement. Rows belonging to one block are always written within the
+// same transaction, regardless of the batch size. A value <= 0 means no limit.
+func WithBatchSize(batchSize int) EventSinkOption {
+	return func(es *EventSink) {
+		es.batchSize = batchSize
+	}
 }
 
 // NewEventSink constructs an event sink associated with the PostgreSQL
 // database specified by connStr. Events written to the sink are attributed to
 // the specified chainID.
-func NewEventSink(connStr, chainID string) (*EventSink, error) {
+func NewEventSink(connStr, chainID string, options ...EventSinkOption) (*EventSink, error) {
 	db, err := sql.Open(driverName, connStr)
 	if err != nil {
 		return nil, err
 	}
-	return &EventSink{
+	es := &EventSink{
 		store:   db,
 		chainID: chainID,
-	}, nil
+	}
+	for _, option := range options {
+		option(es)
+	}
+	return es, nil
 }
 
 // DB returns the underlying Postgres connection used by the sink.
@@ -69,23 +88,37 @@ func runInTransaction(db *sql.DB, query func(*sql.Tx) error) error {
 	return dbtx.Commit()
 }
 
-func runBulkInsert(db *sql.DB, tableName string, columns []string, inserts [][]any) error {
-	return runInTransaction(db, func(tx *sql.Tx) error {
-		stmt, err := tx.Prepare(pq.CopyIn(tableName, columns...))
-		if err != nil {
-			return fmt.Errorf("preparing bulk insert statement: %w", err)
-		}
-		defer stmt.Close()
-		for _, insert := range inserts {
-			if _, err := stmt.Exec(insert...); err != nil {
-				return fmt.Errorf("executing insert statement: %w", err)
-			}
+// runBulkInsert writes inserts into tableName within the transaction tx,
+// using one COPY statement per batch of at most batchSize rows. If batchSize
+// is <= 0, all rows are written by a single statement.
+func runBulkInsert(tx *sql.Tx, batchSize int, tableName string, columns []string, inserts [][]any) error {
+	if batchSize <= 0 {
+		batchSize = len(inserts)
+	}
+	for start := 0; start < len(inserts); start += batchSize {
+		end := min(start+batchSize, len(inserts))
+		if err := copyIn(tx, tableName, columns, inserts[start:end]); err != nil {
+			return err
 		}
-		if _, err := stmt.Exec(); err != nil {
-			return fmt.Errorf("flushing bulk insert: %w", err)
+	}
+	return nil
+}
+
+func copyIn(tx *sql.Tx, tableName string, columns []string, inserts [][]any) error {
+	stmt, err := tx.Prepare(pq.CopyIn(tableName, columns...))
+	if err != nil {
+		return fmt.Errorf("preparing bulk insert statement: %w", err)
+	}
+	defer stmt.Close()
+	for _, insert := range inserts {
+		if _, err := stmt.Exec(insert...); err != nil {
+			return fmt.Errorf("executing insert statement: %w", err)
 		}
-		return nil
-	})
+	}
+	if _, err := stmt.Exec(); err != nil {
+		return fmt.Errorf("flushing bulk insert: %w", err)
+	}
+	return nil
 }
 
 func randomBigserial() int64 {
@@ -138,36 +171,41 @@ func makeIndexedEvent(compositeKey, value string) abci.Event {
 
 // IndexBlockEvents indexes the specified block header, part of the
 // indexer.EventSink interface.
+//
+// The block header, its events and their attributes are written in a single
+// transaction, so that a failure leaves no partially indexed block behind.
 func (es *EventSink) IndexBlockEvents(h types.EventDataNewBlockEvents) error {
 	ts := time.Now().UTC()
 
-	// Add the block to the blocks table and report back its row ID for use
-	// in indexing the events for the block.
-	var blockID int64
-	//nolint:execinquery
-	err := es.store.QueryRow(`
+	return runInTransaction(es.store, func(dbtx *sql.Tx) error {
+		// Add the block to the blocks table and report back its row ID for use
+		// in indexing the events for the block.
+		var blockID int64
+		//nolint:execinquery
+		err := dbtx.QueryRow(`
 INSERT INTO `+tableBlocks+` (height, chain_id, created_at)
   VALUES ($1, $2, $3)
   ON CONFLICT DO NOTHING
   RETURNING rowid;
 `, h.Height, es.chainID, ts).Scan(&blockID)
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil // we already saw this block; quietly succeed
-	} else if err != nil {
-		return fmt.Errorf("indexing block header: %w", err)
-	}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil // we already saw this block; quietly succeed
+		} else if err != nil {
+			return fmt.Errorf("indexing block header: %w", err)
+		}
 
-	// Insert the special block meta-event for height.
-	events := append([]abci.Event{makeIndexedEvent(types.BlockHeightKey, strconv.FormatInt(h.Height, 10))}, h.Events...)
-	// Insert all the block events. Order is important here,
-	eventInserts, attrInserts := bulkInsertEvents(blockID, 0, events)
-	if err := runBulkInsert(es.store, tableEvents, eventInsertColumns, eventInserts); err != nil {
-		return fmt.Errorf("failed bulk insert of events: %w", err)
-	}
-	if err := runBulkInsert(es.store, tableAttributes, attrInsertColumns, attrInserts); err != nil {
-		return fmt.Errorf("failed bulk insert of attributes: %w", err)
-	}
-	return nil
+		// Insert the special block meta-event for height.
+		events := append([]abci.Event{makeIndexedEvent(types.BlockHeightKey, strconv.FormatInt(h.Height, 10))}, h.Events...)
+		// Insert all the block events. Order is important here,
+		eventInserts, attrInserts := bulkInsertEvents(blockID, 0, events)
+		if err := runBulkInsert(dbtx, es.batchSize, tableEvents, eventInsertColumns, eventInserts); err != nil {
+			return fmt.Errorf("failed bulk insert of events: %w", err)
+		}
+		if err := runBulkInsert(dbtx, es.batchSize, tableAttributes, attrInsertColumns, attrInserts); err != nil {
+			return fmt.Errorf("failed bulk insert of attributes: %w", err)
+		}
+		return nil
+	})
 }
 
 // getBlockIDs returns corresponding block ids for the provided heights.
@@ -195,6 +233,11 @@ SELECT array_agg((
 	return existence, nil
 }
 
+// IndexTxEvents indexes the specified transaction results, part of the
+// indexer.EventSink interface.
+//
+// All rows are written in a single transaction, so that a failure to index
+// any of the results rolls back the indexing of the whole batch.
 func (es *EventSink) IndexTxEvents(txrs []*abci.TxResult) error {
 	ts := time.Now().UTC()
 	heights := make([]int64, len(txrs))
@@ -239,16 +282,18 @@ func (es *EventSink) IndexTxEvents(txrs []*abci.TxResult) error {
 		eventInserts = append(eventInserts, newEventInserts...)
 		attrInserts = append(attrInserts, newAttrInserts...)
 	}
-	if err := runBulkInsert(es.store, tableTxResults, txrInsertColumns, txrInserts); err != nil {
-		return fmt.Errorf("bulk inserting txrs: %w", err)
-	}
-	if err := runBulkInsert(es.store, tableEvents, eventInsertColumns, eventInserts); err != nil {
-		return fmt.Errorf("bulk inserting events: %w", err)
-	}
-	if err := runBulkInsert(es.store, tableAttributes, attrInsertColumns, attrInserts); err != nil {
-		return fmt.Errorf("bulk inserting attributes: %w", err)
-	}
-	return nil
+	return runInTransaction(es.store, func(dbtx *sql.Tx) error {
+		if err := runBulkInsert(dbtx, es.batchSize, tableTxResults, txrInsertColumns, txrInserts); err != nil {
+			return fmt.Errorf("bulk inserting txrs: %w", err)
+		}
+		if err := runBulkInsert(dbtx, es.batchSize, tableEvents, eventInsertColumns, eventInserts); err != nil {
+			return fmt.Errorf("bulk inserting events: %w", err)
+		}
+		if err := runBulkInsert(dbtx, es.batchSize, tableAttributes, attrInsertColumns, attrInserts); err != nil {
+			return fmt.Errorf("bulk inserting attributes: %w", err)
+		}
+		return nil
+	})
 }
 
 // SearchBlockEvents is not implemented by this sink, and reports an error for all queries.
@@ -9,6 +9,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"testing"
 	"time"
 
@@ -201,6 +202,47 @@ func TestIndexing(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("IndexTxEventsBatched", func(t *testing.T) {
+		indexer := &EventSink{store: testDB(), chainID: chainID, batchSize: 2}
+
+		txResults := make([]*abci.TxResult, 0, 5)
+		for i := 0; i < 5; i++ {
+			txResult := txResultWithEvents([]abci.Event{
+				makeIndexedEvent("account.number", strconv.Itoa(i)),
+				makeIndexedEvent("account.owner", "Ivan"),
+				makeIndexedEvent("account.owner", "Yulieta"),
+			})
+			txResult.Index = uint32(i + 1)
+			txResult.Tx = types.Tx(fmt.Sprintf("batched-%d", i))
+			txResults = append(txResults, txResult)
+		}
+		require.NoError(t, indexer.IndexTxEvents(txResults))
+
+		for _, txResult := range txResults {
+			txr, err := loadTxResult(types.Tx(txResult.Tx).Hash())
+			require.NoError(t, err)
+			assert.Equal(t, txResult, txr)
+		}
+	})
+
+	t.Run("IndexTxEventsRollback", func(t *testing.T) {
+		indexer := &EventSink{store: testDB(), chainID: chainID, batchSize: 1}
+
+		// Both results share the same height and index, so the second batch
+		// violates the uniqueness constraint after the first one was written.
+		txResult1 := txResultWithEvents(nil)
+		txResult1.Index = 100
+		txResult1.Tx = types.Tx("rollback-1")
+		txResult2 := txResultWithEvents(nil)
+		txResult2.Index = 100
+		txResult2.Tx = types.Tx("rollback-2")
+		require.Error(t, indexer.IndexTxEvents([]*abci.TxResult{txResult1, txResult2}))
+
+		// The first batch must have been rolled back too.
+		_, err := loadTxResult(types.Tx(txResult1.Tx).Hash())
+		require.Error(t, err)
+	})
+
 	t.Run("IndexerService", func(t *testing.T) {
 		indexer := &EventSink{store: testDB(), chainID: chainID}
 
@@ -58,6 +58,12 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "pruning_service_block_indexer_retain_height",
 			Help:      "PruningServiceBlockIndexerRetainHeight is the accepted blocks indices retain height set by the data companion",
 		}, labels).With(labelsAndValues...),
+		CompanionBlockRetainHeight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "companion_block_retain_height",
+			Help:      "CompanionBlockRetainHeight is the accepted block retain height set by each of the named data companions, by name",
+		}, append(labels, "name")).With(labelsAndValues...),
 		ApplicationBlockRetainHeight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
@@ -88,6 +94,30 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "block_indexer_base_height",
 			Help:      "BlockIndexerBaseHeight shows the first height at which block indices are available",
 		}, labels).With(labelsAndValues...),
+		BlocksBehindRetainTarget: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "blocks_behind_retain_target",
+			Help:      "BlocksBehindRetainTarget is the number of heights between the base of the block store and the block retain height targeted by the pruner, updated at every pass of the pruner. It stays high if pruning can't keep up with the retain height.",
+		}, labels).With(labelsAndValues...),
+		EvidencePinnedHeights: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "evidence_pinned_heights",
+			Help:      "EvidencePinnedHeights is the number of heights below the base of the block store whose header and commit were kept by the last pass of the pruner over the blocks, as they are within the evidence age window.",
+		}, labels).With(labelsAndValues...),
+		PrunerThrottledCycles: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "pruner_throttled_cycles",
+			Help:      "PrunerThrottledCycles is the number of passes of the pruner over the blocks that ended without reaching the block retain height they targeted. If it grows much faster than PrunerSatisfiedCycles, the pruner can't keep up, and its limits or interval should be raised.",
+		}, labels).With(labelsAndValues...),
+		PrunerSatisfiedCycles: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "pruner_satisfied_cycles",
+			Help:      "PrunerSatisfiedCycles is the number of passes of the pruner over the blocks that pruned them up to the block retain height they targeted.",
+		}, labels).With(labelsAndValues...),
 		StoreAccessDurationSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
@@ -96,6 +126,14 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 
 			Buckets: stdprometheus.ExponentialBuckets(0.0002, 10, 5),
 		}, append(labels, "method")).With(labelsAndValues...),
+		PruningDurationSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "pruning_duration_seconds",
+			Help:      "The duration of each phase of the pruner, labeled by phase: blocks, states, abci_responses, tx_indexer or block_indexer.",
+
+			Buckets: stdprometheus.ExponentialBuckets(0.01, 2, 12),
+		}, append(labels, "phase")).With(labelsAndValues...),
 	}
 }
 
@@ -108,11 +146,17 @@ func NopMetrics() *Metrics {
 		PruningServiceBlockResultsRetainHeight: discard.NewGauge(),
 		PruningServiceTxIndexerRetainHeight:    discard.NewGauge(),
 		PruningServiceBlockIndexerRetainHeight: discard.NewGauge(),
+		CompanionBlockRetainHeight:             discard.NewGauge(),
 		ApplicationBlockRetainHeight:           discard.NewGauge(),
 		BlockStoreBaseHeight:                   discard.NewGauge(),
 		ABCIResultsBaseHeight:                  discard.NewGauge(),
 		TxIndexerBaseHeight:                    discard.NewGauge(),
 		BlockIndexerBaseHeight:                 discard.NewGauge(),
+		BlocksBehindRetainTarget:               discard.NewGauge(),
+		EvidencePinnedHeights:                  discard.NewGauge(),
+		PrunerThrottledCycles:                  discard.NewCounter(),
+		PrunerSatisfiedCycles:                  discard.NewCounter(),
 		StoreAccessDurationSeconds:             discard.NewHistogram(),
+		PruningDurationSeconds:                 discard.NewHistogram(),
 	}
 }
@@ -43,6 +43,10 @@ type Metrics struct {
 	// retain height set by the data companion
 	PruningServiceBlockIndexerRetainHeight metrics.Gauge
 
+	// CompanionBlockRetainHeight is the accepted block retain height set by
+	// each of the named data companions, by name
+	CompanionBlockRetainHeight metrics.Gauge `metrics_labels:"name"`
+
 	// ApplicationBlockRetainHeight is the accepted block
 	// retain height set by the application
 	ApplicationBlockRetainHeight metrics.Gauge
@@ -63,7 +67,32 @@ type Metrics struct {
 	// block indices are available
 	BlockIndexerBaseHeight metrics.Gauge
 
+	// BlocksBehindRetainTarget is the number of heights between the base of
+	// the block store and the block retain height targeted by the pruner,
+	// updated at every pass of the pruner. It stays high if pruning can't keep
+	// up with the retain height.
+	BlocksBehindRetainTarget metrics.Gauge
+
+	// EvidencePinnedHeights is the number of heights below the base of the
+	// block store whose header and commit were kept by the last pass of the
+	// pruner over the blocks, as they are within the evidence age window.
+	EvidencePinnedHeights metrics.Gauge
+
+	// PrunerThrottledCycles is the number of passes of the pruner over the
+	// blocks that ended without reaching the block retain height they
+	// targeted. If it grows much faster than PrunerSatisfiedCycles, the
+	// pruner can't keep up, and its limits or interval should be raised.
+	PrunerThrottledCycles metrics.Counter
+
+	// PrunerSatisfiedCycles is the number of passes of the pruner over the
+	// blocks that pruned them up to the block retain height they targeted.
+	PrunerSatisfiedCycles metrics.Counter
+
 	// The duration of accesses to the state store labeled by which method
 	// was called on the store.
 	StoreAccessDurationSeconds metrics.Histogram `metrics_bucketsizes:"0.0002, 10, 5" metrics_buckettype:"exp" metrics_labels:"method"`
+
+	// The duration of each phase of the pruner, labeled by phase: blocks,
+	// states, abci_responses, tx_indexer or block_indexer.
+	PruningDurationSeconds metrics.Histogram `metrics_bucketsizes:"0.01, 2, 12" metrics_buckettype:"exp" metrics_labels:"phase"`
 }
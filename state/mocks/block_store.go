@@ -325,6 +325,34 @@ func (_m *BlockStore) PruneBlocks(height int64, _a1 state.State) (uint64, int64,
 	return r0, r1, r2
 }
 
+// PruneOrphanedBlockParts provides a mock function with given fields: from, to
+func (_m *BlockStore) PruneOrphanedBlockParts(from int64, to int64) (uint64, error) {
+	ret := _m.Called(from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PruneOrphanedBlockParts")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int64) (uint64, error)); ok {
+		return rf(from, to)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int64) uint64); ok {
+		r0 = rf(from, to)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SaveBlock provides a mock function with given fields: block, blockParts, seenCommit
 func (_m *BlockStore) SaveBlock(block *types.Block, blockParts *types.PartSet, seenCommit *types.Commit) {
 	_m.Called(block, blockParts, seenCommit)
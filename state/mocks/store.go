@@ -6,6 +6,8 @@ import (
 	state "github.com/cometbft/cometbft/state"
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	types "github.com/cometbft/cometbft/types"
 
 	v1 "github.com/cometbft/cometbft/api/cometbft/abci/v1"
@@ -52,6 +54,60 @@ func (_m *Store) Close() error {
 	return r0
 }
 
+// CompactRetainHeightKeys provides a mock function with given fields:
+func (_m *Store) CompactRetainHeightKeys() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompactRetainHeightKeys")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePruneJournal provides a mock function with given fields:
+func (_m *Store) DeletePruneJournal() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePruneJournal")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePrunerLease provides a mock function with given fields:
+func (_m *Store) DeletePrunerLease() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePrunerLease")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetABCIResRetainHeight provides a mock function with given fields:
 func (_m *Store) GetABCIResRetainHeight() (int64, error) {
 	ret := _m.Called()
@@ -108,6 +164,34 @@ func (_m *Store) GetApplicationRetainHeight() (int64, error) {
 	return r0, r1
 }
 
+// GetCompanionABCIResRetainHeight provides a mock function with given fields:
+func (_m *Store) GetCompanionABCIResRetainHeight() (int64, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCompanionABCIResRetainHeight")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (int64, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCompanionBlockRetainHeight provides a mock function with given fields:
 func (_m *Store) GetCompanionBlockRetainHeight() (int64, error) {
 	ret := _m.Called()
@@ -136,6 +220,64 @@ func (_m *Store) GetCompanionBlockRetainHeight() (int64, error) {
 	return r0, r1
 }
 
+// GetLastABCIResponsesRetainHeight provides a mock function with given fields:
+func (_m *Store) GetLastABCIResponsesRetainHeight() (int64, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLastABCIResponsesRetainHeight")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (int64, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNamedCompanionBlockRetainHeights provides a mock function with given fields:
+func (_m *Store) GetNamedCompanionBlockRetainHeights() (map[string]int64, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetNamedCompanionBlockRetainHeights")
+	}
+
+	var r0 map[string]int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (map[string]int64, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() map[string]int64); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetOfflineStateSyncHeight provides a mock function with given fields:
 func (_m *Store) GetOfflineStateSyncHeight() (int64, error) {
 	ret := _m.Called()
@@ -164,6 +306,62 @@ func (_m *Store) GetOfflineStateSyncHeight() (int64, error) {
 	return r0, r1
 }
 
+// GetPruneJournal provides a mock function with given fields:
+func (_m *Store) GetPruneJournal() (state.PruneJournalEntry, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPruneJournal")
+	}
+
+	var r0 state.PruneJournalEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (state.PruneJournalEntry, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() state.PruneJournalEntry); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(state.PruneJournalEntry)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPrunerLease provides a mock function with given fields:
+func (_m *Store) GetPrunerLease() (state.PrunerLease, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPrunerLease")
+	}
+
+	var r0 state.PrunerLease
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (state.PrunerLease, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() state.PrunerLease); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(state.PrunerLease)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Load provides a mock function with given fields:
 func (_m *Store) Load() (state.State, error) {
 	ret := _m.Called()
@@ -336,6 +534,48 @@ func (_m *Store) LoadLastFinalizeBlockResponse(height int64) (*v1.FinalizeBlockR
 	return r0, r1
 }
 
+// LoadRetainHeightAt provides a mock function with given fields: t
+func (_m *Store) LoadRetainHeightAt(t time.Time) (int64, int64, int64, error) {
+	ret := _m.Called(t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoadRetainHeightAt")
+	}
+
+	var r0 int64
+	var r1 int64
+	var r2 int64
+	var r3 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int64, int64, int64, error)); ok {
+		return rf(t)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(t)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) int64); ok {
+		r1 = rf(t)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(time.Time) int64); ok {
+		r2 = rf(t)
+	} else {
+		r2 = ret.Get(2).(int64)
+	}
+
+	if rf, ok := ret.Get(3).(func(time.Time) error); ok {
+		r3 = rf(t)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
 // LoadValidators provides a mock function with given fields: height
 func (_m *Store) LoadValidators(height int64) (*types.ValidatorSet, error) {
 	ret := _m.Called(height)
@@ -402,22 +642,22 @@ func (_m *Store) PruneABCIResponses(targetRetainHeight int64, forceCompact bool)
 }
 
 // PruneStates provides a mock function with given fields: fromHeight, toHeight, evidenceThresholdHeight, previouslyPrunedStates
-func (_m *Store) PruneStates(fromHeight int64, toHeight int64, evidenceThresholdHeight int64, previouslyPrunedStates uint64) (uint64, error) {
+func (_m *Store) PruneStates(fromHeight int64, toHeight int64, evidenceThresholdHeight int64, previouslyPrunedStates uint64) (state.PrunedStatesCount, error) {
 	ret := _m.Called(fromHeight, toHeight, evidenceThresholdHeight, previouslyPrunedStates)
 
 	if len(ret) == 0 {
 		panic("no return value specified for PruneStates")
 	}
 
-	var r0 uint64
+	var r0 state.PrunedStatesCount
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int64, int64, int64, uint64) (uint64, error)); ok {
+	if rf, ok := ret.Get(0).(func(int64, int64, int64, uint64) (state.PrunedStatesCount, error)); ok {
 		return rf(fromHeight, toHeight, evidenceThresholdHeight, previouslyPrunedStates)
 	}
-	if rf, ok := ret.Get(0).(func(int64, int64, int64, uint64) uint64); ok {
+	if rf, ok := ret.Get(0).(func(int64, int64, int64, uint64) state.PrunedStatesCount); ok {
 		r0 = rf(fromHeight, toHeight, evidenceThresholdHeight, previouslyPrunedStates)
 	} else {
-		r0 = ret.Get(0).(uint64)
+		r0 = ret.Get(0).(state.PrunedStatesCount)
 	}
 
 	if rf, ok := ret.Get(1).(func(int64, int64, int64, uint64) error); ok {
@@ -483,6 +723,24 @@ func (_m *Store) SaveApplicationRetainHeight(height int64) error {
 	return r0
 }
 
+// SaveCompanionABCIResRetainHeight provides a mock function with given fields: height
+func (_m *Store) SaveCompanionABCIResRetainHeight(height int64) error {
+	ret := _m.Called(height)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveCompanionABCIResRetainHeight")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(height)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SaveCompanionBlockRetainHeight provides a mock function with given fields: height
 func (_m *Store) SaveCompanionBlockRetainHeight(height int64) error {
 	ret := _m.Called(height)
@@ -519,6 +777,78 @@ func (_m *Store) SaveFinalizeBlockResponse(height int64, res *v1.FinalizeBlockRe
 	return r0
 }
 
+// SaveNamedCompanionBlockRetainHeight provides a mock function with given fields: name, height
+func (_m *Store) SaveNamedCompanionBlockRetainHeight(name string, height int64) error {
+	ret := _m.Called(name, height)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveNamedCompanionBlockRetainHeight")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, int64) error); ok {
+		r0 = rf(name, height)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SavePruneJournal provides a mock function with given fields: entry
+func (_m *Store) SavePruneJournal(entry state.PruneJournalEntry) error {
+	ret := _m.Called(entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePruneJournal")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(state.PruneJournalEntry) error); ok {
+		r0 = rf(entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SavePrunerLease provides a mock function with given fields: lease
+func (_m *Store) SavePrunerLease(lease state.PrunerLease) error {
+	ret := _m.Called(lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePrunerLease")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(state.PrunerLease) error); ok {
+		r0 = rf(lease)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveValidatorSets provides a mock function with given fields: lowerHeight, upperHeight, vals
+func (_m *Store) SaveValidatorSets(lowerHeight int64, upperHeight int64, vals *types.ValidatorSet) error {
+	ret := _m.Called(lowerHeight, upperHeight, vals)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveValidatorSets")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, int64, *types.ValidatorSet) error); ok {
+		r0 = rf(lowerHeight, upperHeight, vals)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetOfflineStateSyncHeight provides a mock function with given fields: height
 func (_m *Store) SetOfflineStateSyncHeight(height int64) error {
 	ret := _m.Called(height)
@@ -1,23 +1,57 @@
 package state
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/go-kit/kit/metrics"
+
 	"github.com/cometbft/cometbft/config"
+	cmtrand "github.com/cometbft/cometbft/internal/rand"
 	"github.com/cometbft/cometbft/libs/log"
 	"github.com/cometbft/cometbft/libs/service"
 	"github.com/cometbft/cometbft/state/indexer"
 	"github.com/cometbft/cometbft/state/txindex"
 )
 
+// defaultMaxStateLoadFailures is the default number of consecutive failures to
+// load the state after which a pruner that fails fast stops.
+const defaultMaxStateLoadFailures = 5
+
+// defaultStatePruningRetries and defaultStatePruningRetryBackoff are the
+// defaults for WithPrunerStatePruningRetries.
+const (
+	defaultStatePruningRetries      = 3
+	defaultStatePruningRetryBackoff = 100 * time.Millisecond
+)
+
 var (
 	AppRetainHeightKey            = []byte("AppRetainHeightKey")
 	CompanionBlockRetainHeightKey = []byte("DCBlockRetainHeightKey")
 	ABCIResultsRetainHeightKey    = []byte("ABCIResRetainHeightKey")
+	// CompanionABCIResultsRetainHeightKey is the key of the ABCI results retain
+	// height set by a data companion archiving the ABCI results, see
+	// Pruner.SetCompanionABCIResRetainHeight.
+	CompanionABCIResultsRetainHeightKey = []byte("DCABCIResRetainHeightKey")
+
+	// NamedCompanionBlockRetainHeightPrefix prefixes the name of a data
+	// companion in the key of its block retain height. The retain height of
+	// the default data companion is saved at CompanionBlockRetainHeightKey.
+	NamedCompanionBlockRetainHeightPrefix = []byte("DCBlockRetainHeightKey:")
 )
 
+// DefaultCompanionName is the name of the data companion whose block retain
+// height is set with SetCompanionBlockRetainHeight.
+const DefaultCompanionName = "default"
+
 // Pruner is a service that reads the retain heights for blocks, state and ABCI
 // results from the database and prunes the corresponding data based on the
 // minimum retain height set. The service sleeps between each run based on the
@@ -29,34 +63,283 @@ type Pruner struct {
 	mtx sync.Mutex
 	// Must the pruner respect the retain heights set by the data companion?
 	dcEnabled bool
+	// See WithPrunerCompanionSafetyMargin.
+	companionSafetyMargin int64
 	// DB to which we save the retain heights
-	bs BlockStore
+	bs PrunableBlockStore
 	// State store to prune state from
 	stateStore   Store
 	blockIndexer indexer.BlockIndexer
 	txIndexer    txindex.TxIndexer
 	interval     time.Duration
-	observer     PrunerObserver
+	abciInterval time.Duration
+	observer     prunerObservers
 	metrics      *Metrics
+	clock        PrunerClock
+	strategy     PruneStrategy
+	// Lowers the block retain height targeted by the pruner, if set.
+	targetOverride RetainHeightOverride
+	// Provides the height of the lowest snapshot served, below which blocks
+	// are pruned, if set.
+	snapshotHeights SnapshotHeightProvider
+	// Must the ABCI results be pruned alongside the blocks?
+	coupleABCIToBlocks bool
+	// Are the ABCI results discarded by the state store instead of persisted,
+	// so that there are none to prune?
+	abciResponsesDiscarded bool
+	// The number of heights whose ABCI results are pruned by the first run
+	// catching up with their retain height, and the maximum number of heights
+	// pruned by a run. The ABCI results are pruned at once if the maximum is 0.
+	abciResInitialStep      int64
+	abciResMaxHeightsPerRun int64
+	// The ABCI results of the blocks within this duration of the tip of the
+	// block store are retained, if positive.
+	abciResRetainDuration time.Duration
+	// Must the pruner stop after failing to load the state
+	// maxStateLoadFailures times in a row?
+	failFast             bool
+	maxStateLoadFailures int
+	// How many times, and after how long, pruning the state is retried when
+	// it fails after the blocks have been pruned.
+	statePruningRetries      int
+	statePruningRetryBackoff time.Duration
+	// How many times, and after how long, pruning blocks or ABCI results is
+	// retried within a pass when it fails with one of the transient errors.
+	transientRetries      int
+	transientRetryBackoff time.Duration
+	transientErrors       []error
+	// The application retain height to seed the state store with, if the
+	// application has not set one yet. 0 once seeded, or if there is none.
+	initialAppRetainHeight int64
+	// The error that caused the pruner to stop, if it failed fast or lost its
+	// lease.
+	err error
+	// Are the background routines paused? See Pause.
+	paused atomic.Bool
+	// Closed by Resume to wake the background routines up, and then replaced.
+	resumedMtx sync.Mutex
+	resumed    chan struct{}
+
+	// Serializes the pruning of the indexers by the background routine and
+	// PruneIndexesNow.
+	indexerMtx sync.Mutex
 
 	// Preserve the number of state entries pruned.
 	// Used to calculated correctly when to trigger compactions
 	prunedStates uint64
+
+	// Subscribers to the advances of the block store base.
+	baseSubsMtx sync.Mutex
+	baseSubs    []chan BaseAdvancedInfo
+
+	// The time to live of the lease on the state store, and the ID with which
+	// the pruner holds it. No lease is taken if leaseTTL is 0.
+	leaseTTL   time.Duration
+	leaseOwner string
+
+	// The phases run in sequence by a single routine, if set. Otherwise, each
+	// phase runs in its own routine.
+	phaseOrder []PrunePhase
+
+	// Block retain heights accepted within this many blocks of the tip of the
+	// block store are warned about. 0 if disabled.
+	nearTipWarnThreshold int64
+
+	// A warning is logged at every run of the ABCI results phase if the ABCI
+	// results retain height and the base of the block store are further apart
+	// than this many heights. 0 if disabled.
+	abciDivergenceWarn int64
+
+	// Reports the disk pressure at the start of each cycle, if set, so that
+	// the pruner prunes more aggressively under high pressure.
+	diskPressure func() DiskPressure
+
+	// The audit log of the pruning actions, if set, and the sequence number
+	// of its last record.
+	auditWriter io.Writer
+	auditMtx    sync.Mutex
+	auditSeq    uint64
+
+	// The number of retain height updates after which the retain height keys
+	// are compacted, 0 if they are never compacted on their own, and the
+	// number of updates since they were last compacted, guarded by mtx.
+	retainHeightKeysCompactionInterval int64
+	retainHeightUpdates                int64
+
+	// The interval between compactions of the whole state store, 0 if it is
+	// not compacted on its own schedule.
+	stateCompactionInterval time.Duration
+
+	// The retain leases acquired with AcquireRetainLease, and the ID of the
+	// last one.
+	retainLeasesMtx   sync.Mutex
+	retainLeases      map[RetainLeaseID]retainLease
+	lastRetainLeaseID RetainLeaseID
+
+	// Must the pruner check that the block store has no hole from its base up
+	// to the retain height before pruning blocks?
+	verifyBeforePrune bool
+
+	// Must the pruner reject application block retain heights that would
+	// prune blocks within the evidence age window?
+	evidenceWindowCheck bool
+
+	// Must the pruner read the blocks to prune ahead of deleting them?
+	prefetch bool
+
+	// Must the observer be notified of each pruned height, besides each
+	// pruned range?
+	perHeightCallbacks bool
+
+	// The height below which the orphaned block parts have been swept. Only
+	// accessed while pruning blocks, which is never done concurrently.
+	blockPartsSweptTo int64
+
+	// The minimum number of blocks below the latest height whose evidence
+	// data is kept, whatever the evidence parameters. 0 if only the evidence
+	// parameters apply.
+	evidenceMaxAgeBlocks int64
+
+	// Are the states pruned by a dedicated worker, instead of by the routine
+	// pruning the blocks? If so, the next range of states to prune is
+	// coalesced into statePruneTarget, and the worker is woken up through
+	// statePruneSignal until statePruneStop is closed, after which it drains
+	// the pending range and closes statePruneDone.
+	asyncStatePruning bool
+	statePruneMtx     sync.Mutex
+	statePruneTarget  *statePruneTarget
+	statePruneSignal  chan struct{}
+	statePruneStop    chan struct{}
+	statePruneDone    chan struct{}
+}
+
+// PrunePhase is a phase of a run of the pruner, i.e. the pruning of one kind
+// of data.
+type PrunePhase int
+
+const (
+	// PrunePhaseBlocks prunes the blocks and the states.
+	PrunePhaseBlocks PrunePhase = iota
+	// PrunePhaseABCI prunes the ABCI results.
+	PrunePhaseABCI
+	// PrunePhaseIndexer prunes the tx and block indexers.
+	PrunePhaseIndexer
+)
+
+// DiskPressure is the pressure on the disk space of the node, as reported by
+// the function supplied with WithPrunerDiskPressureFunc.
+type DiskPressure int
+
+const (
+	// DiskPressureNormal lets the pruner prune as configured.
+	DiskPressureNormal DiskPressure = iota
+	// DiskPressureHigh makes the pruner reclaim disk space as fast as it can.
+	DiskPressureHigh
+)
+
+// String returns a string representation of the DiskPressure.
+func (dp DiskPressure) String() string {
+	switch dp {
+	case DiskPressureNormal:
+		return "normal"
+	case DiskPressureHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// String returns a string representation of the PrunePhase.
+func (ph PrunePhase) String() string {
+	switch ph {
+	case PrunePhaseBlocks:
+		return "blocks"
+	case PrunePhaseABCI:
+		return "abci"
+	case PrunePhaseIndexer:
+		return "indexer"
+	default:
+		return "unknown"
+	}
+}
+
+// BaseAdvancedInfo is sent to the subscribers of SubscribeBaseAdvanced when
+// the pruner advances the base of the block store.
+type BaseAdvancedInfo struct {
+	OldBase int64
+	NewBase int64
 }
 
 type prunerConfig struct {
-	dcEnabled bool
-	interval  time.Duration
-	observer  PrunerObserver
-	metrics   *Metrics
+	dcEnabled            bool
+	dcSafetyMargin       int64
+	interval             time.Duration
+	abciInterval         time.Duration
+	observer             prunerObservers
+	metrics              *Metrics
+	clock                PrunerClock
+	strategy             PruneStrategy
+	targetOverride       RetainHeightOverride
+	snapshotHeights      SnapshotHeightProvider
+	coupleABCIToBlocks   bool
+	failFast             bool
+	maxStateLoadFailures int
+
+	abciResponsesDiscarded bool
+
+	abciResInitialStep      int64
+	abciResMaxHeightsPerRun int64
+	abciResRetainDuration   time.Duration
+
+	statePruningRetries      int
+	statePruningRetryBackoff time.Duration
+
+	transientRetries      int
+	transientRetryBackoff time.Duration
+	transientErrors       []error
+
+	initialAppRetainHeight int64
+
+	leaseTTL time.Duration
+
+	phaseOrder []PrunePhase
+
+	nearTipWarnThreshold int64
+
+	abciDivergenceWarn int64
+
+	diskPressure func() DiskPressure
+
+	auditWriter io.Writer
+
+	evidenceMaxAgeBlocks int64
+
+	verifyBeforePrune bool
+
+	evidenceWindowCheck bool
+
+	prefetch bool
+
+	perHeightCallbacks bool
+
+	retainHeightKeysCompactionInterval int64
+
+	stateCompactionInterval time.Duration
+
+	asyncStatePruning bool
 }
 
 func defaultPrunerConfig() *prunerConfig {
 	return &prunerConfig{
-		dcEnabled: false,
-		interval:  config.DefaultPruningInterval,
-		observer:  &NoopPrunerObserver{},
-		metrics:   NopMetrics(),
+		dcEnabled:            false,
+		interval:             config.DefaultPruningInterval,
+		observer:             newPrunerObservers(NoopPrunerObserver{}),
+		metrics:              NopMetrics(),
+		clock:                realPrunerClock{},
+		maxStateLoadFailures: defaultMaxStateLoadFailures,
+
+		statePruningRetries:      defaultStatePruningRetries,
+		statePruningRetryBackoff: defaultStatePruningRetryBackoff,
 	}
 }
 
@@ -72,14 +355,367 @@ func WithPrunerCompanionEnabled() PrunerOption {
 	}
 }
 
+// WithPrunerCompanionSafetyMargin makes the pruner keep at least margin blocks
+// below the application block retain height when the data companion is
+// enabled, i.e. prune the blocks below the minimum of the data companion block
+// retain height and the application block retain height minus margin, instead
+// of the minimum of both, so that the data companion keeps some breathing room
+// when it follows the application closely. The data companion block retain
+// height still applies as is, so blocks are never pruned above it, and the
+// margin only applies once both retain heights are set. It doesn't apply to
+// PruneToHeight, which ignores the application block retain height, nor if the
+// data companion is disabled. If not supplied, or if margin is not positive,
+// blocks are pruned up to the minimum of both retain heights.
+func WithPrunerCompanionSafetyMargin(margin int64) PrunerOption {
+	return func(p *prunerConfig) {
+		if margin > 0 {
+			p.dcSafetyMargin = margin
+		}
+	}
+}
+
 // WithPrunerInterval allows control over the interval between each run of the
 // pruner.
 func WithPrunerInterval(t time.Duration) PrunerOption {
 	return func(p *prunerConfig) { p.interval = t }
 }
 
+// WithABCIPruningInterval allows control over the interval between each run of
+// the ABCI results pruner, independently of the interval between each run of
+// the block pruner. If not supplied, or if d is 0, the ABCI results pruner runs
+// at the interval set by WithPrunerInterval.
+func WithABCIPruningInterval(d time.Duration) PrunerOption {
+	return func(p *prunerConfig) { p.abciInterval = d }
+}
+
+// WithPrunerCoupleABCIToBlocks indicates to the pruner whether it must keep
+// the ABCI results of a height only as long as its block. ABCI results are
+// only useful alongside their block, but the ABCI results retain height is set
+// independently of the block retain heights, so by default it can keep the ABCI
+// results of heights whose blocks were already pruned. If coupled, the pruner
+// raises an ABCI results retain height set below the block retain height, i.e.
+// the minimum of the application and data companion block retain heights, to
+// the block retain height, and prunes the ABCI results of the heights whose
+// blocks were pruned, even if the ABCI results retain height is lower, e.g.
+// because the block retain height was raised afterwards. ABCI results can still
+// be pruned before their blocks. By default, they are not coupled.
+func WithPrunerCoupleABCIToBlocks(couple bool) PrunerOption {
+	return func(p *prunerConfig) { p.coupleABCIToBlocks = couple }
+}
+
+// WithPrunerABCIResponsesDiscarded indicates to the pruner whether the state
+// store discards the ABCI results instead of persisting them, i.e. whether it
+// was created with DiscardABCIResponses. If so, there are no ABCI results to
+// prune, so the pruner doesn't run its ABCI results phase, even if the data
+// companion is enabled. By default, ABCI results are assumed to be persisted.
+func WithPrunerABCIResponsesDiscarded(discarded bool) PrunerOption {
+	return func(p *prunerConfig) { p.abciResponsesDiscarded = discarded }
+}
+
+// WithPrunerABCIResCatchUp limits the number of heights whose ABCI results are
+// pruned at each run to maxHeightsPerRun, so that the first runs after their
+// retain height is raised a lot, e.g. when the data companion starts pruning a
+// long history, catch up with it in bounded steps, instead of deleting millions
+// of ABCI results at once. The first step is of initialStep heights, and every
+// following step is twice as large as the previous one, up to
+// maxHeightsPerRun, until the retain height is reached. If initialStep is not
+// positive or above maxHeightsPerRun, all steps are of maxHeightsPerRun
+// heights. If not supplied, or if maxHeightsPerRun is not positive, ABCI
+// results are pruned up to their retain height at once.
+func WithPrunerABCIResCatchUp(initialStep, maxHeightsPerRun int64) PrunerOption {
+	return func(p *prunerConfig) {
+		if maxHeightsPerRun <= 0 {
+			return
+		}
+		if initialStep <= 0 || initialStep > maxHeightsPerRun {
+			initialStep = maxHeightsPerRun
+		}
+		p.abciResInitialStep = initialStep
+		p.abciResMaxHeightsPerRun = maxHeightsPerRun
+	}
+}
+
+// WithABCIResponseRetainDuration indicates to the pruner that it must retain
+// the ABCI results of the blocks within d of the tip of the block store, i.e.
+// whose time is at most d before the time of the latest block, whatever the
+// ABCI results retain height. The ABCI results are then pruned up to the oldest
+// of these blocks, or up to the ABCI results retain height if it is set and
+// lower, so that the more conservative of both is used. As the time of a block
+// is only known while it is stored, the ABCI results of pruned blocks are not
+// retained. If not supplied, or if d is not positive, only the ABCI results
+// retain height is used.
+func WithABCIResponseRetainDuration(d time.Duration) PrunerOption {
+	return func(p *prunerConfig) { p.abciResRetainDuration = d }
+}
+
+// WithPrunerFailFast indicates to the pruner whether it must stop when it keeps
+// failing to load the state, which it needs to prune blocks, instead of logging
+// the error and retrying at the next run, so that a broken state store gets
+// noticed by whoever runs the node. The number of consecutive failures after
+// which the pruner stops is set by WithPrunerMaxStateLoadFailures. The error is
+// then returned by Err. By default, the pruner never stops.
+func WithPrunerFailFast(failFast bool) PrunerOption {
+	return func(p *prunerConfig) { p.failFast = failFast }
+}
+
+// WithPrunerMaxStateLoadFailures sets the number of consecutive failures to
+// load the state after which the pruner stops, if WithPrunerFailFast is
+// enabled. If not supplied, or if n is not positive, it defaults to 5.
+func WithPrunerMaxStateLoadFailures(n int) PrunerOption {
+	return func(p *prunerConfig) {
+		if n > 0 {
+			p.maxStateLoadFailures = n
+		}
+	}
+}
+
+// WithPrunerTransientErrorRetries sets how many times pruning blocks or ABCI
+// results is retried within a pass, waiting backoff before the first retry and
+// doubling it with every retry, when it fails with a transient error, instead
+// of waiting for the next pass. Errors are transient if they match, with
+// errors.Is, ErrTransientStoreError, ErrStoreReadOnly, syscall.EAGAIN,
+// syscall.EBUSY, syscall.EINTR, or one of retryable. Other errors fail the
+// pass right away. If not supplied, or if retries is not positive, failures
+// are not retried within a pass.
+func WithPrunerTransientErrorRetries(retries int, backoff time.Duration, retryable ...error) PrunerOption {
+	return func(p *prunerConfig) {
+		if retries > 0 {
+			p.transientRetries = retries
+		}
+		if backoff >= 0 {
+			p.transientRetryBackoff = backoff
+		}
+		p.transientErrors = retryable
+	}
+}
+
+// WithPrunerStatePruningRetries sets how many times pruning the state is
+// retried, waiting backoff before the first retry and doubling it with every
+// retry, when it fails after the corresponding blocks have been pruned. This
+// keeps transient state store errors from leaving the state behind the blocks
+// until the next run of the pruner. If not supplied, it is retried 3 times,
+// starting with a 100ms backoff. Negative values are ignored.
+func WithPrunerStatePruningRetries(retries int, backoff time.Duration) PrunerOption {
+	return func(p *prunerConfig) {
+		if retries >= 0 {
+			p.statePruningRetries = retries
+		}
+		if backoff >= 0 {
+			p.statePruningRetryBackoff = backoff
+		}
+	}
+}
+
+// WithPrunerInitialAppRetainHeight sets an application retain height with which
+// the pruner seeds the state store if the application has not set one yet, so
+// that blocks get pruned without the application having to set retain heights.
+// A retain height already set is never overridden. As retain heights above the
+// height of the block store are invalid, the store is only seeded once the
+// block store reaches height. If not supplied, or if height is not positive,
+// the store is not seeded.
+func WithPrunerInitialAppRetainHeight(height int64) PrunerOption {
+	return func(p *prunerConfig) {
+		if height > 0 {
+			p.initialAppRetainHeight = height
+		}
+	}
+}
+
+// WithPrunerLease makes the pruner take an advisory lock on the state store
+// when it starts, in the form of a lease that expires after ttl unless the
+// pruner renews it, which it does every third of ttl. Starting the pruner fails
+// with ErrPrunerLeaseHeld if another pruner holds an unexpired lease, e.g. if
+// two nodes were misconfigured to use the same database, instead of both
+// pruning it at the same time. The lease is released when the pruner stops, but
+// if the node crashes, the pruner can only be started again once the lease
+// expires. If not supplied, or if ttl is not positive, no lease is taken.
+func WithPrunerLease(ttl time.Duration) PrunerOption {
+	return func(p *prunerConfig) {
+		if ttl > 0 {
+			p.leaseTTL = ttl
+		}
+	}
+}
+
+// WithPrunerPhaseOrder makes the pruner run the given phases in sequence, in the
+// given order, at every run, instead of running each phase in its own routine,
+// e.g. so that ABCI results are pruned before blocks when the state store is
+// under more pressure, without the phases contending for I/O. All phases then
+// run at the interval set by WithPrunerInterval. Phases that are not listed
+// are not run, and, as when they run in their own routines, the ABCI and
+// indexer phases only run if the data companion is enabled, and the ABCI phase
+// only if ABCI results are persisted (see WithPrunerABCIResponsesDiscarded). If
+// not supplied, or if phases is empty, each phase runs in its own routine.
+func WithPrunerPhaseOrder(phases []PrunePhase) PrunerOption {
+	return func(p *prunerConfig) { p.phaseOrder = phases }
+}
+
+// WithPrunerNearTipWarnThreshold makes the pruner warn, in its logs and through
+// PrunerRetainHeightNearTip of its observer, whenever it accepts an application
+// or data companion block retain height that leaves fewer than blocks blocks
+// below the tip of the block store, e.g. a retain height mistakenly set to the
+// tip, which would leave almost no history for peers. The warning is emitted
+// when the retain height is set, not when blocks are pruned. The retain height
+// is accepted nonetheless. If not supplied, or if blocks is not positive, no
+// warning is emitted.
+func WithPrunerNearTipWarnThreshold(blocks int64) PrunerOption {
+	return func(p *prunerConfig) {
+		if blocks > 0 {
+			p.nearTipWarnThreshold = blocks
+		}
+	}
+}
+
+// WithPrunerABCIDivergenceWarn makes the pruner log a warning at every run of
+// its ABCI results phase if the retain height up to which it pruned the ABCI
+// results and the base of the block store are more than n heights apart, in
+// either direction, which usually indicates that the data companion or the
+// application misconfigured one of the retain heights. This is only a
+// diagnostic aid: both are pruned as configured nonetheless. If not supplied,
+// or if n is not positive, no warning is logged.
+func WithPrunerABCIDivergenceWarn(n int64) PrunerOption {
+	return func(p *prunerConfig) {
+		if n > 0 {
+			p.abciDivergenceWarn = n
+		}
+	}
+}
+
+// WithPrunerDiskPressureFunc makes the pruner call f at every cycle to learn
+// the pressure on the disk space of the node, e.g. from the free space left on
+// its data directory. Under DiskPressureHigh, the pruner reclaims disk space as
+// fast as it can: the ABCI results are pruned up to their retain height at
+// once, ignoring WithPrunerABCIResCatchUp, and at the interval set by
+// WithPrunerInterval if WithABCIPruningInterval sets a longer one, and, if
+// WithPrunerPhaseOrder is supplied, the ABCI phase runs first, as the ABCI
+// results usually take the most space per height. Under DiskPressureNormal, the
+// pruner prunes as configured. As the routines of the pruner run concurrently,
+// f may be called concurrently, and must return quickly. If not supplied, the
+// pressure is always normal.
+func WithPrunerDiskPressureFunc(f func() DiskPressure) PrunerOption {
+	return func(p *prunerConfig) { p.diskPressure = f }
+}
+
+// WithPrunerVerifyBeforePrune makes the pruner check, before pruning blocks,
+// that the block store holds the meta of every block from its base up to the
+// retain height, and fail with ErrBlockStoreHole instead of pruning if one is
+// missing, e.g. after a partial write, so that pruning doesn't mask the
+// corruption by deleting the blocks around it. This reads every block meta in
+// the range, so it is not done by default.
+func WithPrunerVerifyBeforePrune(verify bool) PrunerOption {
+	return func(p *prunerConfig) { p.verifyBeforePrune = verify }
+}
+
+// WithPrunerEvidenceWindowCheck makes SetApplicationBlockRetainHeight reject,
+// with ErrRetainHeightBelowEvidenceWindow, a retain height that would prune
+// blocks within the evidence age window, i.e. blocks not older than both the
+// maximum age in blocks and the maximum age duration of the evidence
+// parameters of the latest state, extended by WithPrunerEvidenceMaxAgeBlocks,
+// so that an overly aggressive application can't prune blocks that evidence
+// being gossiped refers to. The block store keeps the headers and commits
+// needed to verify evidence whatever the retain height, but not the rest of
+// the blocks. By default, such retain heights are accepted.
+func WithPrunerEvidenceWindowCheck(check bool) PrunerOption {
+	return func(p *prunerConfig) { p.evidenceWindowCheck = check }
+}
+
+// WithPrunerPrefetch makes the pruner read the metas of the blocks to prune,
+// which the block store reads to find the keys to delete, in a separate
+// routine right before deleting them. On a cold cache, deleting thousands of
+// blocks otherwise pages them in one by one while the deletion holds the
+// block store, which causes latency spikes. This only pays off with some
+// database backends, so it is not done by default. The durations of the
+// prefetch and of the deletion are logged at the debug level, to compare them
+// with and without it.
+func WithPrunerPrefetch(prefetch bool) PrunerOption {
+	return func(p *prunerConfig) { p.prefetch = prefetch }
+}
+
+// WithPrunerPerHeightCallbacks indicates to the pruner whether it must call
+// the PrunerBlockPruned method of its observer for each height whose block is
+// pruned, besides PrunerPrunedBlockRange for each range of pruned heights, so
+// that data kept outside of CometBFT for each height can be cleaned up one
+// height at a time. Pruning thousands of blocks at once then makes as many
+// calls, so by default only PrunerPrunedBlockRange is called.
+func WithPrunerPerHeightCallbacks(enabled bool) PrunerOption {
+	return func(p *prunerConfig) { p.perHeightCallbacks = enabled }
+}
+
+// WithPrunerRetainHeightKeysCompaction makes the pruner compact the keys
+// holding the retain heights in the state store, and only them, once they have
+// been set updates times, at the start of its next run of the blocks phase.
+// Retain heights may be set at every block, so these few keys get rewritten
+// far more often than any other, which fragments the database. Compacting them
+// alone is much cheaper than compacting the whole database. If not supplied,
+// or if updates is not positive, they are not compacted on their own.
+func WithPrunerRetainHeightKeysCompaction(updates int64) PrunerOption {
+	return func(p *prunerConfig) {
+		if updates > 0 {
+			p.retainHeightKeysCompactionInterval = updates
+		}
+	}
+}
+
+// WithStateCompactionInterval makes the pruner compact the whole state store
+// every interval, in a routine of its own, whatever was pruned, so that
+// operators can reclaim the space freed by pruning on their own schedule, e.g.
+// during off-peak hours, as the database otherwise keeps growing. The duration
+// of each compaction, and the space it reclaimed if the database reports it,
+// are logged. Compactions are skipped while pruning is paused or vetoed by the
+// observer, and if the state store can't be compacted. If not supplied, or if
+// interval is not positive, the state store is only compacted as configured
+// in its options, after pruning.
+func WithStateCompactionInterval(interval time.Duration) PrunerOption {
+	return func(p *prunerConfig) { p.stateCompactionInterval = interval }
+}
+
+// WithPrunerAuditWriter makes the pruner record every pruning action, i.e.
+// every pass that pruned blocks and their states, ABCI results, or, with
+// WithAsyncStatePruning, states, in an append-only audit log written to w,
+// independently of the logger. Each action is written as a line of JSON with
+// the fields of the PrunedInfo reported to PruningDidFinish, the time of the
+// action, the error it failed with, if any, and a sequence number increasing
+// by one with every record, starting at 1 when the pruner is created. If w has
+// a Flush or a Sync method, e.g. a *bufio.Writer or an *os.File, it is called
+// after each record, so that the records survive a crash. Records are written
+// sequentially. Failing to write a record is logged, but doesn't stop pruning.
+// If not supplied, no audit log is written.
+func WithPrunerAuditWriter(w io.Writer) PrunerOption {
+	return func(p *prunerConfig) { p.auditWriter = w }
+}
+
+// WithPrunerEvidenceMaxAgeBlocks makes the pruner keep the data needed to
+// verify evidence, i.e. the headers, commits and validator sets, of at least
+// the last blocks blocks, even when the evidence parameters let it be pruned
+// earlier, so that evidence of misbehavior in these blocks can still be
+// verified and gossiped. The evidence parameters still apply when they keep
+// more blocks. If not supplied, or if blocks is not positive, only the
+// evidence parameters apply.
+func WithPrunerEvidenceMaxAgeBlocks(blocks int64) PrunerOption {
+	return func(p *prunerConfig) {
+		if blocks > 0 {
+			p.evidenceMaxAgeBlocks = blocks
+		}
+	}
+}
+
+// WithAsyncStatePruning makes the pruner prune the states in a dedicated
+// routine, instead of right after the corresponding blocks, so that a long
+// pruning of the states doesn't delay the next phases. The states pruned
+// asynchronously lag behind the blocks: if the blocks are pruned again before
+// the routine catches up, the ranges of states to prune are coalesced, so that
+// at most one is pending. Completion is reported by PrunerPrunedStates of the
+// observer. When the pruner stops, it waits for the pending range to be
+// pruned, without retrying. By default, states are pruned synchronously.
+func WithAsyncStatePruning(async bool) PrunerOption {
+	return func(p *prunerConfig) { p.asyncStatePruning = async }
+}
+
+// WithPrunerObserver sets the observer notified of the events of the pruner.
+// The optional observer interfaces it implements, such as PrunerPassObserver,
+// are detected here, and the events of the others are not reported.
 func WithPrunerObserver(obs PrunerObserver) PrunerOption {
-	return func(p *prunerConfig) { p.observer = obs }
+	return func(p *prunerConfig) { p.observer = newPrunerObservers(obs) }
 }
 
 func WithPrunerMetrics(metrics *Metrics) PrunerOption {
@@ -88,13 +724,57 @@ func WithPrunerMetrics(metrics *Metrics) PrunerOption {
 	}
 }
 
+// WithPrunerClock sets the clock the pruner waits on between the cycles of its
+// background routines and between retries, e.g. to let tests drive the pruner
+// cycle by cycle. If not supplied, the pruner waits in real time.
+func WithPrunerClock(clock PrunerClock) PrunerOption {
+	return func(p *prunerConfig) { p.clock = clock }
+}
+
+// WithPrunerStrategy sets the strategy the pruner removes the pruned blocks
+// from the block store with, e.g. to move them to cold storage instead of
+// deleting them. If not supplied, they are deleted with PruneBlocks of the
+// block store.
+func WithPrunerStrategy(strategy PruneStrategy) PrunerOption {
+	return func(p *prunerConfig) { p.strategy = strategy }
+}
+
+// RetainHeightOverride is given the block retain height computed by the
+// pruner, and returns the one to target instead. See WithPrunerTargetOverride.
+type RetainHeightOverride func(computedTarget int64) int64
+
+// WithPrunerTargetOverride makes the pruner call override with the block
+// retain height it targets, every time it computes it from the stored retain
+// heights and the retain leases, i.e. at every pass over the blocks, and
+// target the height it returns instead, e.g. to keep blocks below the retain
+// height under a compliance hold. It also applies to PruneToHeight. The
+// override may only lower the target: if it returns a higher one, the error
+// is logged and the computed target is kept. Returning 0 keeps all the blocks.
+// As the retain heights may also be read concurrently, e.g. through the RPC,
+// override may be called concurrently, and must return quickly.
+func WithPrunerTargetOverride(override RetainHeightOverride) PrunerOption {
+	return func(p *prunerConfig) { p.targetOverride = override }
+}
+
+// WithPrunerSnapshotHeightProvider makes the pruner keep the blocks at and
+// above the height of the lowest state sync snapshot served by the node, as
+// returned by provider, whatever the block retain heights, so that peers
+// restoring the snapshots can still fetch what they need. The provider is
+// queried at every pass over the blocks, so that the pruner follows the
+// snapshots as new ones are taken and old ones expire. It also applies to
+// PruneToHeight. If the provider fails, no block is pruned until it succeeds
+// again. See NewAppSnapshotHeightProvider.
+func WithPrunerSnapshotHeightProvider(provider SnapshotHeightProvider) PrunerOption {
+	return func(p *prunerConfig) { p.snapshotHeights = provider }
+}
+
 // NewPruner creates a service that controls background pruning of node data.
 //
 // Assumes that the initial application and data companion retain heights have
 // already been configured in the state store.
 func NewPruner(
 	stateStore Store,
-	bs BlockStore,
+	bs PrunableBlockStore,
 	blockIndexer indexer.BlockIndexer,
 	txIndexer txindex.TxIndexer,
 	logger log.Logger,
@@ -104,6 +784,88 @@ func NewPruner(
 	for _, opt := range options {
 		opt(cfg)
 	}
+	return newPruner(stateStore, bs, blockIndexer, txIndexer, logger, cfg)
+}
+
+// NewPrunerWithValidation creates a pruner like NewPruner, but first checks
+// that the supplied options make sense together, and returns an error wrapping
+// ErrInvalidPrunerOptions that describes the first problem found otherwise,
+// instead of a pruner that silently ignores some of them.
+func NewPrunerWithValidation(
+	stateStore Store,
+	bs PrunableBlockStore,
+	blockIndexer indexer.BlockIndexer,
+	txIndexer txindex.TxIndexer,
+	logger log.Logger,
+	options ...PrunerOption,
+) (*Pruner, error) {
+	cfg := defaultPrunerConfig()
+	for _, opt := range options {
+		opt(cfg)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return newPruner(stateStore, bs, blockIndexer, txIndexer, logger, cfg), nil
+}
+
+// validate returns an error if the options of the pruner are invalid, or
+// conflict with one another.
+func (cfg *prunerConfig) validate() error {
+	switch {
+	case cfg.interval <= 0:
+		return fmt.Errorf("%w: the pruning interval must be positive, got %v", ErrInvalidPrunerOptions, cfg.interval)
+	case cfg.abciInterval < 0:
+		return fmt.Errorf("%w: the ABCI results pruning interval can't be negative, got %v",
+			ErrInvalidPrunerOptions, cfg.abciInterval)
+	case cfg.abciResRetainDuration < 0:
+		return fmt.Errorf("%w: the ABCI results retain duration can't be negative, got %v",
+			ErrInvalidPrunerOptions, cfg.abciResRetainDuration)
+	case cfg.stateCompactionInterval < 0:
+		return fmt.Errorf("%w: the state compaction interval can't be negative, got %v",
+			ErrInvalidPrunerOptions, cfg.stateCompactionInterval)
+	case cfg.observer.PrunerObserver == nil:
+		return fmt.Errorf("%w: the observer can't be nil", ErrInvalidPrunerOptions)
+	case cfg.metrics == nil:
+		return fmt.Errorf("%w: the metrics can't be nil", ErrInvalidPrunerOptions)
+	case cfg.clock == nil:
+		return fmt.Errorf("%w: the clock can't be nil", ErrInvalidPrunerOptions)
+	}
+
+	// The ABCI results are only pruned if the data companion is enabled and
+	// they are persisted, otherwise the options that only apply to them have
+	// no effect.
+	abciResPruned := cfg.dcEnabled && !cfg.abciResponsesDiscarded
+	if !abciResPruned && cfg.abciResRetainDuration > 0 {
+		return fmt.Errorf("%w: an ABCI results retain duration is set, but ABCI results are not pruned, "+
+			"as the data companion is disabled or they are discarded", ErrInvalidPrunerOptions)
+	}
+	if !abciResPruned && cfg.coupleABCIToBlocks {
+		return fmt.Errorf("%w: ABCI results are coupled to blocks, but ABCI results are not pruned, "+
+			"as the data companion is disabled or they are discarded", ErrInvalidPrunerOptions)
+	}
+
+	seen := make(map[PrunePhase]struct{}, len(cfg.phaseOrder))
+	for _, phase := range cfg.phaseOrder {
+		if phase < PrunePhaseBlocks || phase > PrunePhaseIndexer {
+			return fmt.Errorf("%w: unknown phase %d in the phase order", ErrInvalidPrunerOptions, phase)
+		}
+		if _, ok := seen[phase]; ok {
+			return fmt.Errorf("%w: phase %v appears more than once in the phase order", ErrInvalidPrunerOptions, phase)
+		}
+		seen[phase] = struct{}{}
+	}
+	return nil
+}
+
+func newPruner(
+	stateStore Store,
+	bs PrunableBlockStore,
+	blockIndexer indexer.BlockIndexer,
+	txIndexer txindex.TxIndexer,
+	logger log.Logger,
+	cfg *prunerConfig,
+) *Pruner {
 	p := &Pruner{
 		bs:           bs,
 		txIndexer:    txIndexer,
@@ -111,30 +873,333 @@ func NewPruner(
 		stateStore:   stateStore,
 		logger:       logger,
 		interval:     cfg.interval,
+		abciInterval: cfg.abciInterval,
 		observer:     cfg.observer,
 		metrics:      cfg.metrics,
-		dcEnabled:    cfg.dcEnabled,
+		clock:        cfg.clock,
+		strategy:     cfg.strategy,
+
+		targetOverride:  cfg.targetOverride,
+		snapshotHeights: cfg.snapshotHeights,
+		dcEnabled:       cfg.dcEnabled,
+
+		companionSafetyMargin: cfg.dcSafetyMargin,
+
+		coupleABCIToBlocks: cfg.coupleABCIToBlocks,
+
+		abciResponsesDiscarded: cfg.abciResponsesDiscarded,
+
+		abciResInitialStep:      cfg.abciResInitialStep,
+		abciResMaxHeightsPerRun: cfg.abciResMaxHeightsPerRun,
+		abciResRetainDuration:   cfg.abciResRetainDuration,
+
+		failFast:             cfg.failFast,
+		maxStateLoadFailures: cfg.maxStateLoadFailures,
+
+		statePruningRetries:      cfg.statePruningRetries,
+		statePruningRetryBackoff: cfg.statePruningRetryBackoff,
+
+		transientRetries:      cfg.transientRetries,
+		transientRetryBackoff: cfg.transientRetryBackoff,
+		transientErrors:       cfg.transientErrors,
+
+		initialAppRetainHeight: cfg.initialAppRetainHeight,
+
+		leaseTTL: cfg.leaseTTL,
+
+		phaseOrder: cfg.phaseOrder,
+
+		nearTipWarnThreshold: cfg.nearTipWarnThreshold,
+
+		abciDivergenceWarn: cfg.abciDivergenceWarn,
+
+		diskPressure: cfg.diskPressure,
+
+		auditWriter: cfg.auditWriter,
+
+		evidenceMaxAgeBlocks: cfg.evidenceMaxAgeBlocks,
+
+		verifyBeforePrune: cfg.verifyBeforePrune,
+
+		evidenceWindowCheck: cfg.evidenceWindowCheck,
+
+		prefetch: cfg.prefetch,
+
+		perHeightCallbacks: cfg.perHeightCallbacks,
+
+		retainHeightKeysCompactionInterval: cfg.retainHeightKeysCompactionInterval,
+
+		stateCompactionInterval: cfg.stateCompactionInterval,
+
+		asyncStatePruning: cfg.asyncStatePruning,
+
+		resumed: make(chan struct{}),
+	}
+	if p.abciInterval == 0 {
+		p.abciInterval = p.interval
+	}
+	if p.strategy == nil {
+		p.strategy = blockStorePruneStrategy{bs: bs}
+	}
+	if p.leaseTTL > 0 {
+		p.leaseOwner = newPrunerLeaseOwner()
 	}
 	p.BaseService = *service.NewBaseService(logger, "Pruner", p)
 	return p
 }
 
 func (p *Pruner) SetObserver(obs PrunerObserver) {
-	p.observer = obs
+	p.observer = newPrunerObservers(obs)
+}
+
+// Err returns the error that caused the pruner to stop, if it was stopped
+// after failing to load the state too many times in a row (see
+// WithPrunerFailFast), or after losing its lease (see WithPrunerLease).
+// Otherwise, it returns nil.
+func (p *Pruner) Err() error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.err
+}
+
+// Pause pauses the background routines of the pruner until Resume is called,
+// e.g. during a maintenance window, without stopping the pruner. While paused,
+// the routines keep cycling without pruning, but retain heights can still be
+// set, and PruneOnce, PruneToHeight and PruneIndexesNow still prune when
+// called. A pass already pruning is not interrupted, so pruning only stops
+// once it is done. It is safe to call concurrently with the routines, and a
+// no-op if already paused.
+func (p *Pruner) Pause() {
+	if !p.paused.Swap(true) {
+		p.logger.Info("Paused pruning")
+		p.observer.PrunerPauseChanged(true)
+	}
+}
+
+// Resume resumes the background routines of the pruner paused by Pause. The
+// routines waiting for their next cycle are woken up, so that they prune right
+// away. It is a no-op if the pruner is not paused.
+func (p *Pruner) Resume() {
+	if !p.paused.Swap(false) {
+		return
+	}
+	p.logger.Info("Resumed pruning")
+	p.observer.PrunerPauseChanged(false)
+	p.resumedMtx.Lock()
+	close(p.resumed)
+	p.resumed = make(chan struct{})
+	p.resumedMtx.Unlock()
+}
+
+// resumedChan returns the channel closed by the next call to Resume.
+func (p *Pruner) resumedChan() <-chan struct{} {
+	p.resumedMtx.Lock()
+	defer p.resumedMtx.Unlock()
+	return p.resumed
+}
+
+// IsPaused returns true if the background routines of the pruner are paused.
+func (p *Pruner) IsPaused() bool {
+	return p.paused.Load()
+}
+
+// PruningStatus is the status of the pruner, as returned by GetPruningStatus.
+type PruningStatus struct {
+	// Is the pruner running, i.e. started and not stopped?
+	Running bool
+	// Are its background routines paused? See Pruner.Pause.
+	Paused bool
+	// The error that caused the pruner to stop, if any. See Pruner.Err.
+	Err error
+}
+
+// GetPruningStatus returns the status of the pruner.
+func (p *Pruner) GetPruningStatus() PruningStatus {
+	return PruningStatus{
+		Running: p.IsRunning(),
+		Paused:  p.IsPaused(),
+		Err:     p.Err(),
+	}
 }
 
 func (p *Pruner) OnStart() error {
+	if p.leaseTTL > 0 {
+		if err := p.acquireLease(); err != nil {
+			return err
+		}
+		go p.renewLeaseRoutine()
+	}
+	p.completeInterruptedPrune()
+	p.downgradeRetainHeightsAboveTip()
+	p.raiseRetainHeightsBelowBase()
+	p.logBacklog()
+	if p.asyncStatePruning {
+		p.statePruneSignal = make(chan struct{}, 1)
+		p.statePruneStop = make(chan struct{})
+		p.statePruneDone = make(chan struct{})
+		go p.pruneStatesRoutine()
+	}
+	if p.dcEnabled && p.abciResponsesDiscarded {
+		p.logger.Error("ABCI results are discarded instead of persisted, so they will not be pruned " +
+			"even though the data companion is enabled; disable discard_abci_responses to prune them")
+	}
+	if p.stateCompactionInterval > 0 {
+		if compactor, ok := p.stateStore.(stateStoreCompactor); ok {
+			go p.compactStateRoutine(compactor)
+		} else {
+			p.logger.Info("The state store can't be compacted, not compacting it periodically")
+		}
+	}
+	if len(p.phaseOrder) > 0 {
+		go p.prunePhasesRoutine()
+		p.observer.PrunerStarted(p.interval)
+		return nil
+	}
 	go p.pruneBlocks()
-	// We only care about pruning ABCI results if the data companion has been
-	// enabled.
-	if p.dcEnabled {
+	if p.abciResPhaseEnabled() {
 		go p.pruneABCIResponses()
+	}
+	if p.indexerPhaseEnabled() {
 		go p.pruneIndexesRoutine()
 	}
 	p.observer.PrunerStarted(p.interval)
 	return nil
 }
 
+// abciResPhaseEnabled returns true if the ABCI results must be pruned. We only
+// care about pruning them if the data companion has been enabled, and if they
+// are persisted.
+func (p *Pruner) abciResPhaseEnabled() bool {
+	return p.dcEnabled && !p.abciResponsesDiscarded
+}
+
+// indexerPhaseEnabled returns true if the indexers must be pruned, i.e. if the
+// data companion has been enabled.
+func (p *Pruner) indexerPhaseEnabled() bool {
+	return p.dcEnabled
+}
+
+// enabledPhases returns the phases set by WithPrunerPhaseOrder that are run.
+func (p *Pruner) enabledPhases() []PrunePhase {
+	phases := make([]PrunePhase, 0, len(p.phaseOrder))
+	for _, phase := range p.phaseOrder {
+		switch {
+		case phase == PrunePhaseABCI && !p.abciResPhaseEnabled():
+		case phase == PrunePhaseIndexer && !p.indexerPhaseEnabled():
+		default:
+			phases = append(phases, phase)
+		}
+	}
+	return phases
+}
+
+func (p *Pruner) OnStop() {
+	if p.asyncStatePruning {
+		close(p.statePruneStop)
+		<-p.statePruneDone
+	}
+	if p.leaseTTL > 0 {
+		p.releaseLease()
+	}
+}
+
+// newPrunerLeaseOwner returns an ID identifying the process of the pruner, so
+// that the owner of a lease can be found if it is held by another node.
+func newPrunerLeaseOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s/%d/%s", hostname, os.Getpid(), cmtrand.Str(8))
+}
+
+// acquireLease takes the lease on the state store, or renews it, unless another
+// pruner holds an unexpired lease.
+func (p *Pruner) acquireLease() error {
+	lease, err := p.stateStore.GetPrunerLease()
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return fmt.Errorf("failed to get pruner lease: %w", err)
+	}
+	now := time.Now()
+	if err == nil && lease.Owner != p.leaseOwner && lease.Expiry.After(now) {
+		return ErrPrunerLeaseHeld{Owner: lease.Owner, Expiry: lease.Expiry}
+	}
+	return p.stateStore.SavePrunerLease(PrunerLease{Owner: p.leaseOwner, Expiry: now.Add(p.leaseTTL)})
+}
+
+func (p *Pruner) renewLeaseRoutine() {
+	ticker := time.NewTicker(p.leaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.Quit():
+			return
+		case <-ticker.C:
+			err := p.acquireLease()
+			var heldErr ErrPrunerLeaseHeld
+			if errors.As(err, &heldErr) {
+				// The lease expired before it was renewed, and another pruner
+				// took it.
+				p.logger.Error("Lost pruner lease, stopping pruner", "err", err)
+				p.mtx.Lock()
+				p.err = err
+				p.mtx.Unlock()
+				if err := p.Stop(); err != nil {
+					p.logger.Error("Failed to stop pruner", "err", err)
+				}
+				return
+			} else if err != nil {
+				p.logger.Error("Failed to renew pruner lease", "err", err)
+			}
+		}
+	}
+}
+
+// releaseLease deletes the lease on the state store, if the pruner holds it.
+func (p *Pruner) releaseLease() {
+	lease, err := p.stateStore.GetPrunerLease()
+	if err != nil || lease.Owner != p.leaseOwner {
+		return
+	}
+	if err := p.stateStore.DeletePrunerLease(); err != nil {
+		p.logger.Error("Failed to release pruner lease", "err", err)
+	}
+}
+
+// SubscribeBaseAdvanced returns a channel on which the pruner sends the old and
+// new base of the block store every time it prunes blocks, so that reactors can
+// update the range of blocks they advertise to their peers without polling the
+// block store. The pruner never blocks on it: if the subscriber hasn't received
+// the previous advance yet, both advances are merged into one.
+func (p *Pruner) SubscribeBaseAdvanced() <-chan BaseAdvancedInfo {
+	p.baseSubsMtx.Lock()
+	defer p.baseSubsMtx.Unlock()
+	ch := make(chan BaseAdvancedInfo, 1)
+	p.baseSubs = append(p.baseSubs, ch)
+	return ch
+}
+
+func (p *Pruner) publishBaseAdvanced(oldBase, newBase int64) {
+	p.baseSubsMtx.Lock()
+	defer p.baseSubsMtx.Unlock()
+	for _, ch := range p.baseSubs {
+		info := BaseAdvancedInfo{OldBase: oldBase, NewBase: newBase}
+		select {
+		case ch <- info:
+			continue
+		default:
+		}
+		// Only the pruner sends on the channel, so there is room for the
+		// merged advance once the previous one is taken out.
+		select {
+		case prev := <-ch:
+			info.OldBase = prev.OldBase
+		default:
+		}
+		ch <- info
+	}
+}
+
 // SetApplicationBlockRetainHeight sets the application block retain height
 // with some basic checks on the requested height.
 //
@@ -146,8 +1211,8 @@ func (p *Pruner) SetApplicationBlockRetainHeight(height int64) error {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
 
-	if !p.heightWithinBounds(height) {
-		return ErrInvalidHeightValue
+	if err := p.checkHeightWithinBounds(height); err != nil {
+		return err
 	}
 	curRetainHeight, err := p.stateStore.GetApplicationRetainHeight()
 	if err != nil {
@@ -156,75 +1221,405 @@ func (p *Pruner) SetApplicationBlockRetainHeight(height int64) error {
 	if height < curRetainHeight {
 		return ErrPrunerCannotLowerRetainHeight
 	}
+	if p.evidenceWindowCheck {
+		if err := p.checkEvidenceWindow(height); err != nil {
+			return err
+		}
+	}
 	if err := p.stateStore.SaveApplicationRetainHeight(height); err != nil {
 		return err
 	}
-	p.metrics.ApplicationBlockRetainHeight.Set(float64(height))
-	return nil
+	p.metrics.ApplicationBlockRetainHeight.Set(float64(height))
+	p.retainHeightUpdates++
+	p.warnIfNearTip("application block", height)
+	return nil
+}
+
+// checkEvidenceWindow returns ErrRetainHeightBelowEvidenceWindow if retaining
+// the blocks from height would prune a block within the evidence age window,
+// relative to the latest state, as computed by the block store when pruning.
+// As the blocks below height are older than the one right below it, only that
+// one is checked.
+func (p *Pruner) checkEvidenceWindow(height int64) error {
+	if height <= p.bs.Base() {
+		return nil
+	}
+	meta := p.bs.LoadBlockMeta(height - 1)
+	if meta == nil {
+		return nil
+	}
+	state, err := p.stateStore.Load()
+	if err != nil {
+		return ErrPrunerFailedToLoadState{Err: err}
+	}
+	params := p.evidenceRetentionState(state).ConsensusParams.Evidence
+	if state.LastBlockTime.Sub(meta.Header.Time) > params.MaxAgeDuration &&
+		state.LastBlockHeight-meta.Header.Height > params.MaxAgeNumBlocks {
+		return nil
+	}
+	return ErrRetainHeightBelowEvidenceWindow{
+		RetainHeight:    height,
+		LastBlockHeight: state.LastBlockHeight,
+		MaxAgeNumBlocks: params.MaxAgeNumBlocks,
+		MaxAgeDuration:  params.MaxAgeDuration,
+	}
+}
+
+// checkHeightWithinBounds returns ErrInvalidHeightValue if height is not within
+// the range of heights held by the block store, or ErrNoBlocksToPrune if it is
+// positive while the block store is empty.
+func (p *Pruner) checkHeightWithinBounds(height int64) error {
+	if height > 0 && p.bs.Height() == 0 {
+		return ErrNoBlocksToPrune
+	}
+	if height < p.bs.Base() || height > p.bs.Height() {
+		return ErrInvalidHeightValue
+	}
+	return nil
+}
+
+// SetCompanionBlockRetainHeight sets the application block retain height with
+// some basic checks on the requested height.
+//
+// If a higher retain height is already set, we cannot accept the requested
+// height because the blocks might have been pruned.
+func (p *Pruner) SetCompanionBlockRetainHeight(height int64) error {
+	// Ensure that all requests to set retain heights via the pruner are
+	// serialized.
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if err := p.checkHeightWithinBounds(height); err != nil {
+		return err
+	}
+	curRetainHeight, err := p.stateStore.GetCompanionBlockRetainHeight()
+	if err != nil {
+		return ErrPrunerFailedToGetRetainHeight{Which: "companion block", Err: err}
+	}
+	if height < curRetainHeight {
+		return ErrPrunerCannotLowerRetainHeight
+	}
+	if err := p.stateStore.SaveCompanionBlockRetainHeight(height); err != nil {
+		return err
+	}
+	p.metrics.PruningServiceBlockRetainHeight.Set(float64(height))
+	p.retainHeightUpdates++
+	p.warnIfNearTip("companion block", height)
+	return nil
+}
+
+// SetNamedCompanionRetainHeight sets the block retain height of the data
+// companion name, for nodes running several data companions, e.g. one for
+// analytics and one for archival, each needing its own retain height. If the
+// data companion is enabled, blocks are only pruned below the minimum of the
+// block retain heights of the application and of all the data companions that
+// have set one. The default data companion, named DefaultCompanionName or "",
+// is the one whose retain height is set with SetCompanionBlockRetainHeight.
+//
+// As with the other retain heights, a data companion cannot lower its retain
+// height, as the blocks might have been pruned.
+func (p *Pruner) SetNamedCompanionRetainHeight(name string, height int64) error {
+	if name == "" || name == DefaultCompanionName {
+		return p.SetCompanionBlockRetainHeight(height)
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if err := p.checkHeightWithinBounds(height); err != nil {
+		return err
+	}
+	heights, err := p.stateStore.GetNamedCompanionBlockRetainHeights()
+	if err != nil {
+		return ErrPrunerFailedToGetRetainHeight{Which: "named companion block", Err: err}
+	}
+	if curRetainHeight, ok := heights[name]; ok && height < curRetainHeight {
+		return ErrPrunerCannotLowerRetainHeight
+	}
+	if err := p.stateStore.SaveNamedCompanionBlockRetainHeight(name, height); err != nil {
+		return err
+	}
+	p.metrics.CompanionBlockRetainHeight.With("name", name).Set(float64(height))
+	p.retainHeightUpdates++
+	p.warnIfNearTip("companion block "+name, height)
+	return nil
+}
+
+// minNamedCompanionRetainHeight returns the minimum of the block retain
+// heights of the named data companions, or 0 if none has set one.
+func (p *Pruner) minNamedCompanionRetainHeight() (int64, error) {
+	heights, err := p.stateStore.GetNamedCompanionBlockRetainHeights()
+	if err != nil {
+		return 0, err
+	}
+	var minHeight int64
+	for _, height := range heights {
+		if minHeight == 0 || height < minHeight {
+			minHeight = height
+		}
+	}
+	return minHeight, nil
+}
+
+// IsRetainHeightNearTip returns true if the given block retain height leaves
+// fewer blocks below the tip of the block store than the threshold set with
+// WithPrunerNearTipWarnThreshold. It always returns false if no threshold is
+// set.
+func (p *Pruner) IsRetainHeightNearTip(height int64) bool {
+	return p.nearTipWarnThreshold > 0 && p.bs.Height()-height < p.nearTipWarnThreshold
+}
+
+// OldestRetainedTime returns the time of the oldest block retained by the block
+// store, i.e. of the block at its base, from which data is available. It
+// returns ErrBlockStoreEmpty if the block store holds no blocks.
+func (p *Pruner) OldestRetainedTime() (time.Time, error) {
+	base := p.bs.Base()
+	if base == 0 {
+		return time.Time{}, ErrBlockStoreEmpty
+	}
+	return p.blockTime(base)
+}
+
+// EstimateBacklog returns the number of heights of blocks, and of ABCI results,
+// between the current bases and the retain heights targeted by the pruner,
+// i.e. how many heights the pruner will prune to catch up, without pruning
+// anything. ABCI results are only counted if they are pruned, i.e. if the data
+// companion is enabled and they are persisted.
+func (p *Pruner) EstimateBacklog() (blocks, abciResponses int64, err error) {
+	blocks = remainingHeights(p.findMinBlockRetainHeight(), p.bs.Base())
+	if !p.abciResPhaseEnabled() {
+		return blocks, 0, nil
+	}
+	target, err := p.stateStore.GetABCIResRetainHeight()
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return 0, 0, ErrPrunerFailedToGetRetainHeight{Which: "ABCI results", Err: err}
+	}
+	if target, err = p.abciResTargetRetainHeight(target); err != nil {
+		return 0, 0, err
+	}
+	if p.coupleABCIToBlocks {
+		target = max(target, p.bs.Base())
+	}
+	base, err := p.stateStore.GetLastABCIResponsesRetainHeight()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get the last ABCI results retain height: %w", err)
+	}
+	return blocks, remainingHeights(target, max(base, 1)), nil
+}
+
+// logBacklog logs the estimate of the heights the pruner will prune to catch
+// up with the retain heights.
+func (p *Pruner) logBacklog() {
+	blocks, abciResponses, err := p.EstimateBacklog()
+	if err != nil {
+		p.logger.Error("Failed to estimate the pruning backlog", "err", err)
+		return
+	}
+	p.logger.Info("Estimated the heights to prune to reach the retain heights", "blocks", blocks,
+		"abciResponses", abciResponses)
+}
+
+// blockTime returns the time of the block at height, read from its meta.
+func (p *Pruner) blockTime(height int64) (time.Time, error) {
+	meta := p.bs.LoadBlockMeta(height)
+	if meta == nil {
+		return time.Time{}, fmt.Errorf("no block meta at height %d", height)
+	}
+	return meta.Header.Time, nil
+}
+
+// oldestHeightWithin returns the height of the oldest block stored by the block
+// store whose time is at most d before the time of the latest block, found by
+// a binary search over the block times, as they increase with the height. It
+// returns 0 if the block store is empty.
+func (p *Pruner) oldestHeightWithin(d time.Duration) (int64, error) {
+	base, height := p.bs.Base(), p.bs.Height()
+	if base == 0 || height == 0 {
+		return 0, nil
+	}
+	tipTime, err := p.blockTime(height)
+	if err != nil {
+		return 0, err
+	}
+	oldest := tipTime.Add(-d)
+	// Find the lowest height in [base, height] whose block is not older.
+	lo, hi := base, height
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		t, err := p.blockTime(mid)
+		if err != nil {
+			return 0, err
+		}
+		if t.Before(oldest) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// applyABCIResRetainDuration returns the ABCI results retain height to prune
+// to, given the one set, 0 if none, and the retain duration set with
+// WithABCIResponseRetainDuration: the lower of both if both are set.
+func (p *Pruner) applyABCIResRetainDuration(height int64) (int64, error) {
+	if p.abciResRetainDuration <= 0 {
+		return height, nil
+	}
+	durationHeight, err := p.oldestHeightWithin(p.abciResRetainDuration)
+	if err != nil {
+		return 0, err
+	}
+	if height == 0 {
+		return durationHeight, nil
+	}
+	return min(height, durationHeight), nil
+}
+
+// abciResTargetRetainHeight returns the retain height up to which the ABCI
+// results are pruned, given the ABCI results retain height, 0 if none is set:
+// after applying the retain duration set with WithABCIResponseRetainDuration,
+// the minimum of it and of the companion ABCI results retain height, if set,
+// so that the data companion archiving the ABCI results can pin them until it
+// has exported them, as findMinBlockRetainHeight does for the blocks.
+func (p *Pruner) abciResTargetRetainHeight(height int64) (int64, error) {
+	height, err := p.applyABCIResRetainDuration(height)
+	if err != nil || height == 0 {
+		return height, err
+	}
+	companionHeight, err := p.stateStore.GetCompanionABCIResRetainHeight()
+	if errors.Is(err, ErrKeyNotFound) {
+		return height, nil
+	}
+	if err != nil {
+		return 0, ErrPrunerFailedToGetRetainHeight{Which: "companion ABCI results", Err: err}
+	}
+	return min(height, companionHeight), nil
 }
 
-func (p *Pruner) heightWithinBounds(height int64) bool {
-	if height < p.bs.Base() || height > p.bs.Height() {
-		return false
+func (p *Pruner) warnIfNearTip(which string, height int64) {
+	if !p.IsRetainHeightNearTip(height) {
+		return
 	}
-	return true
+	tip := p.bs.Height()
+	p.logger.Error("Accepted a block retain height close to the tip of the block store, "+
+		"almost no history will be left for peers",
+		"which", which, "retainHeight", height, "tip", tip, "threshold", p.nearTipWarnThreshold)
+	p.observer.PrunerRetainHeightNearTip(&RetainHeightNearTipInfo{
+		Which:        which,
+		RetainHeight: height,
+		TipHeight:    tip,
+	})
 }
 
-// SetCompanionBlockRetainHeight sets the application block retain height with
-// some basic checks on the requested height.
+// SetABCIResRetainHeight sets the retain height for ABCI responses.
 //
-// If a higher retain height is already set, we cannot accept the requested
-// height because the blocks might have been pruned.
-func (p *Pruner) SetCompanionBlockRetainHeight(height int64) error {
+// If the application has set the DiscardABCIResponses flag to true, nothing
+// will be pruned. If the ABCI results are coupled to the blocks (see
+// WithPrunerCoupleABCIToBlocks), a height below the block retain height is
+// raised to it.
+func (p *Pruner) SetABCIResRetainHeight(height int64) error {
 	// Ensure that all requests to set retain heights via the pruner are
 	// serialized.
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
 
-	if !p.heightWithinBounds(height) {
-		return ErrInvalidHeightValue
+	if err := p.checkHeightWithinBounds(height); err != nil {
+		return err
 	}
-	curRetainHeight, err := p.stateStore.GetCompanionBlockRetainHeight()
+	curRetainHeight, err := p.stateStore.GetABCIResRetainHeight()
 	if err != nil {
-		return ErrPrunerFailedToGetRetainHeight{Which: "companion block", Err: err}
+		return ErrPrunerFailedToGetRetainHeight{Which: "ABCI results", Err: err}
+	}
+	if p.coupleABCIToBlocks {
+		blockRetainHeight, err := p.storedBlockRetainHeight()
+		if err != nil {
+			return err
+		}
+		if height < blockRetainHeight {
+			p.logger.Info("Raised ABCI results retain height to the block retain height, "+
+				"as ABCI results are pruned alongside blocks",
+				"retainHeight", height, "blockRetainHeight", blockRetainHeight)
+			height = blockRetainHeight
+		}
 	}
 	if height < curRetainHeight {
 		return ErrPrunerCannotLowerRetainHeight
 	}
-	if err := p.stateStore.SaveCompanionBlockRetainHeight(height); err != nil {
+	if err := p.stateStore.SaveABCIResRetainHeight(height); err != nil {
 		return err
 	}
-	p.metrics.PruningServiceBlockRetainHeight.Set(float64(height))
+	p.metrics.PruningServiceBlockResultsRetainHeight.Set(float64(height))
+	p.retainHeightUpdates++
 	return nil
 }
 
-// SetABCIResRetainHeight sets the retain height for ABCI responses.
-//
-// If the application has set the DiscardABCIResponses flag to true, nothing
-// will be pruned.
-func (p *Pruner) SetABCIResRetainHeight(height int64) error {
+// SetCompanionABCIResRetainHeight sets the retain height for ABCI responses
+// requested by a data companion archiving them, e.g. a block results exporter,
+// independently of the ABCI results retain height set with
+// SetABCIResRetainHeight. The ABCI results are pruned up to the minimum of
+// both, so that the data companion can pin them until it has exported them.
+// Until it is first set, only the ABCI results retain height is used. It can't
+// be set below the ABCI results that were already pruned.
+func (p *Pruner) SetCompanionABCIResRetainHeight(height int64) error {
 	// Ensure that all requests to set retain heights via the pruner are
 	// serialized.
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
 
-	if !p.heightWithinBounds(height) {
-		return ErrInvalidHeightValue
+	if err := p.checkHeightWithinBounds(height); err != nil {
+		return err
 	}
-	curRetainHeight, err := p.stateStore.GetABCIResRetainHeight()
+	// The ABCI results below the last ABCI results retain height are already
+	// pruned.
+	lastRetainHeight, err := p.stateStore.GetLastABCIResponsesRetainHeight()
 	if err != nil {
-		return ErrPrunerFailedToGetRetainHeight{Which: "ABCI results", Err: err}
+		return ErrPrunerFailedToGetRetainHeight{Which: "last ABCI results", Err: err}
+	}
+	if height < lastRetainHeight {
+		return ErrInvalidHeightValue
+	}
+	curRetainHeight, err := p.stateStore.GetCompanionABCIResRetainHeight()
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return ErrPrunerFailedToGetRetainHeight{Which: "companion ABCI results", Err: err}
 	}
 	if height < curRetainHeight {
 		return ErrPrunerCannotLowerRetainHeight
 	}
-	if err := p.stateStore.SaveABCIResRetainHeight(height); err != nil {
+	if err := p.stateStore.SaveCompanionABCIResRetainHeight(height); err != nil {
 		return err
 	}
-	p.metrics.PruningServiceBlockResultsRetainHeight.Set(float64(height))
+	p.retainHeightUpdates++
 	return nil
 }
 
+// storedBlockRetainHeight returns the block retain height stored in the
+// database, i.e. the minimum of the application block retain height and, if
+// the data companion is enabled, of the block retain heights of the data
+// companions, keeping the safety margin set with
+// WithPrunerCompanionSafetyMargin. Unlike findMinBlockRetainHeight, it doesn't
+// lock the mutex, so that the setters can call it.
+func (p *Pruner) storedBlockRetainHeight() (int64, error) {
+	appRetainHeight, err := p.stateStore.GetApplicationRetainHeight()
+	if err != nil {
+		return 0, ErrPrunerFailedToGetRetainHeight{Which: "application block", Err: err}
+	}
+	if !p.dcEnabled {
+		return appRetainHeight, nil
+	}
+	dcRetainHeight, err := p.stateStore.GetCompanionBlockRetainHeight()
+	if err != nil {
+		return 0, ErrPrunerFailedToGetRetainHeight{Which: "companion block", Err: err}
+	}
+	namedRetainHeight, err := p.minNamedCompanionRetainHeight()
+	if err != nil {
+		return 0, ErrPrunerFailedToGetRetainHeight{Which: "named companion block", Err: err}
+	}
+	if namedRetainHeight != 0 {
+		dcRetainHeight = min(dcRetainHeight, namedRetainHeight)
+	}
+	return p.minAppCompanionRetainHeight(appRetainHeight, dcRetainHeight), nil
+}
+
 func (p *Pruner) SetTxIndexerRetainHeight(height int64) error {
 	// Ensure that all requests to set retain heights via the application are
 	// serialized.
@@ -239,13 +1634,13 @@ func (p *Pruner) SetTxIndexerRetainHeight(height int64) error {
 		if !errors.Is(err, ErrKeyNotFound) {
 			return err
 		}
-		return p.txIndexer.SetRetainHeight(height)
+		return checkReadOnly(p.txIndexer.SetRetainHeight(height))
 	}
 	if currentRetainHeight > height {
 		return ErrPrunerCannotLowerRetainHeight
 	}
 	if err := p.txIndexer.SetRetainHeight(height); err != nil {
-		return err
+		return checkReadOnly(err)
 	}
 	p.metrics.PruningServiceTxIndexerRetainHeight.Set(float64(height))
 	return nil
@@ -265,13 +1660,13 @@ func (p *Pruner) SetBlockIndexerRetainHeight(height int64) error {
 		if !errors.Is(err, ErrKeyNotFound) {
 			return err
 		}
-		return p.blockIndexer.SetRetainHeight(height)
+		return checkReadOnly(p.blockIndexer.SetRetainHeight(height))
 	}
 	if currentRetainHeight > height {
 		return ErrPrunerCannotLowerRetainHeight
 	}
 	if err := p.blockIndexer.SetRetainHeight(height); err != nil {
-		return err
+		return checkReadOnly(err)
 	}
 	p.metrics.PruningServiceBlockIndexerRetainHeight.Set(float64(height))
 	return nil
@@ -295,6 +1690,12 @@ func (p *Pruner) GetABCIResRetainHeight() (int64, error) {
 	return p.stateStore.GetABCIResRetainHeight()
 }
 
+// GetCompanionABCIResRetainHeight is a convenience method for accessing the
+// GetCompanionABCIResRetainHeight method of the underlying state store.
+func (p *Pruner) GetCompanionABCIResRetainHeight() (int64, error) {
+	return p.stateStore.GetCompanionABCIResRetainHeight()
+}
+
 // GetTxIndexerRetainHeight is a convenience method for accessing the
 // GetTxIndexerRetainHeight method of the underlying indexer.
 func (p *Pruner) GetTxIndexerRetainHeight() (int64, error) {
@@ -307,214 +1708,1694 @@ func (p *Pruner) GetBlockIndexerRetainHeight() (int64, error) {
 	return p.blockIndexer.GetRetainHeight()
 }
 
+// HasApplicationRetainHeight returns whether an application retain height has
+// ever been set. Unlike GetApplicationRetainHeight, it doesn't return
+// ErrKeyNotFound if none has been set.
+func (p *Pruner) HasApplicationRetainHeight() (bool, error) {
+	return hasRetainHeight(p.stateStore.GetApplicationRetainHeight)
+}
+
+// HasCompanionBlockRetainHeight returns whether a data companion block retain
+// height has ever been set. Unlike GetCompanionBlockRetainHeight, it doesn't
+// return ErrKeyNotFound if none has been set.
+func (p *Pruner) HasCompanionBlockRetainHeight() (bool, error) {
+	return hasRetainHeight(p.stateStore.GetCompanionBlockRetainHeight)
+}
+
+// HasABCIResRetainHeight returns whether an ABCI results retain height has ever
+// been set. Unlike GetABCIResRetainHeight, it doesn't return ErrKeyNotFound if
+// none has been set.
+func (p *Pruner) HasABCIResRetainHeight() (bool, error) {
+	return hasRetainHeight(p.stateStore.GetABCIResRetainHeight)
+}
+
+func hasRetainHeight(get func() (int64, error)) (bool, error) {
+	_, err := get()
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+		return false, nil
+	case err != nil:
+		return false, err
+	default:
+		return true, nil
+	}
+}
+
+// RetainHeight is a retain height read from the database.
+type RetainHeight struct {
+	Height int64
+	// Set is false if no retain height has been set yet, in which case Height
+	// is 0.
+	Set bool
+}
+
+// RetainHeights is a snapshot of all the retain heights known to the pruner,
+// along with the range of heights held by the block store.
+type RetainHeights struct {
+	ApplicationBlock RetainHeight
+	CompanionBlock   RetainHeight
+	ABCIResults      RetainHeight
+	TxIndexer        RetainHeight
+	BlockIndexer     RetainHeight
+
+	// The block retain heights of the named data companions, by name. Only
+	// set by Pruner.RetainHeightSnapshot.
+	NamedCompanionBlocks map[string]int64
+
+	// The base and height of the block store.
+	Base   int64
+	Height int64
+
+	// The height below which blocks are pruned, i.e. the minimum of the
+	// application block retain height and, if the data companion is enabled,
+	// of the companion block retain heights, lowered to the heights pinned by
+	// retain leases, and clamped to the range of heights held by the block
+	// store. 0 if no block retain height has been set yet. Only set by
+	// Pruner.RetainHeightSnapshot.
+	EffectiveBlock int64
+}
+
+// RetainHeightSnapshot returns all the retain heights known to the pruner in
+// one call, for diagnostic purposes. Retain heights that have not been set yet
+// are reported as such, and only other errors are returned.
+func (p *Pruner) RetainHeightSnapshot() (RetainHeights, error) {
+	// Make sure that no retain height is changed while taking the snapshot.
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	rhs, err := ReadRetainHeights(p.stateStore)
+	if err != nil {
+		return RetainHeights{}, err
+	}
+	rhs.Base, rhs.Height = p.bs.Base(), p.bs.Height()
+	named, err := p.stateStore.GetNamedCompanionBlockRetainHeights()
+	if err != nil {
+		return RetainHeights{}, ErrPrunerFailedToGetRetainHeight{Which: "named companion block", Err: err}
+	}
+	if len(named) > 0 {
+		rhs.NamedCompanionBlocks = named
+	}
+	err = readRetainHeights([]retainHeightGetter{
+		{"tx indexer", p.txIndexer.GetRetainHeight, &rhs.TxIndexer},
+		{"block indexer", p.blockIndexer.GetRetainHeight, &rhs.BlockIndexer},
+	})
+	if err != nil {
+		return RetainHeights{}, err
+	}
+	rhs.EffectiveBlock = p.effectiveBlockRetainHeight(rhs)
+	return rhs, nil
+}
+
+// effectiveBlockRetainHeight returns the height below which blocks are pruned
+// given the retain heights rhs, as findMinBlockRetainHeight, but without
+// downgrading the retain heights above the tip of the block store.
+func (p *Pruner) effectiveBlockRetainHeight(rhs RetainHeights) int64 {
+	if !rhs.ApplicationBlock.Set {
+		return 0
+	}
+	height := rhs.ApplicationBlock.Height
+	if p.dcEnabled {
+		if !rhs.CompanionBlock.Set {
+			return 0
+		}
+		height = p.minAppCompanionRetainHeight(height, rhs.CompanionBlock.Height)
+		for _, namedHeight := range rhs.NamedCompanionBlocks {
+			height = min(height, namedHeight)
+		}
+	}
+	height = p.clampToBlockStore(height)
+	if leaseHeight := p.retainLeaseHeight(); leaseHeight != 0 && leaseHeight < height {
+		height = p.clampToBlockStore(leaseHeight)
+	}
+	return p.overrideTarget(p.snapshotTarget(height))
+}
+
+// RetainHeightReader is the part of Store that the retain heights saved in the
+// state store are read from.
+type RetainHeightReader interface {
+	GetApplicationRetainHeight() (int64, error)
+	GetCompanionBlockRetainHeight() (int64, error)
+	GetABCIResRetainHeight() (int64, error)
+}
+
+// ReadRetainHeights reads the retain heights saved in the state store without
+// a pruner, and never writes to it, so that diagnostic tools can inspect the
+// database of a node that is offline without risking to modify it. Retain
+// heights that have not been set yet are reported as such, and only other
+// errors are returned. The retain heights of the indexers and the range of
+// heights held by the block store are not saved in the state store, so they
+// are not set; see Pruner.RetainHeightSnapshot.
+func ReadRetainHeights(store RetainHeightReader) (RetainHeights, error) {
+	var rhs RetainHeights
+	err := readRetainHeights([]retainHeightGetter{
+		{"application block", store.GetApplicationRetainHeight, &rhs.ApplicationBlock},
+		{"companion block", store.GetCompanionBlockRetainHeight, &rhs.CompanionBlock},
+		{"ABCI results", store.GetABCIResRetainHeight, &rhs.ABCIResults},
+	})
+	if err != nil {
+		return RetainHeights{}, err
+	}
+	return rhs, nil
+}
+
+// retainHeightGetter gets the retain height described by which into rh.
+type retainHeightGetter struct {
+	which string
+	get   func() (int64, error)
+	rh    *RetainHeight
+}
+
+func readRetainHeights(getters []retainHeightGetter) error {
+	for _, g := range getters {
+		height, err := g.get()
+		switch {
+		case errors.Is(err, ErrKeyNotFound):
+		case err != nil:
+			return ErrPrunerFailedToGetRetainHeight{Which: g.which, Err: err}
+		default:
+			*g.rh = RetainHeight{Height: height, Set: true}
+		}
+	}
+	return nil
+}
+
+// pruningCursors are the retain heights up to which each phase last pruned, and
+// the number of consecutive failures to load the state when pruning blocks.
+type pruningCursors struct {
+	blocks            int64
+	stateLoadFailures int
+	abciRes           int64
+	// The next step catching up with the ABCI results retain height, see
+	// WithPrunerABCIResCatchUp. 0 if not catching up.
+	abciResStep  int64
+	txIndexer    int64
+	blockIndexer int64
+}
+
 func (p *Pruner) pruneABCIResponses() {
-	p.logger.Info("Started pruning ABCI responses", "interval", p.interval.String())
-	lastRetainHeight := int64(0)
+	p.logger.Info("Started pruning ABCI responses", "interval", p.abciInterval.String())
+	var c pruningCursors
+	for {
+		select {
+		case <-p.Quit():
+			return
+		default:
+			if !p.vetoed("ABCI responses") {
+				p.pruneABCIResPass(&c)
+			}
+			interval := p.abciInterval
+			if p.underDiskPressure() && p.interval < interval {
+				interval = p.interval
+			}
+			p.sleep(interval)
+		}
+	}
+}
+
+// pruneABCIResPass prunes ABCI results once from the cursors c, and returns
+// what was pruned, if anything.
+func (p *Pruner) pruneABCIResPass(c *pruningCursors) *ABCIResponsesPrunedInfo {
+	newRetainHeight, targetRetainHeight, pruned, catchingUp := p.pruneABCIResToRetainHeight(c.abciRes, &c.abciResStep)
+	var info *ABCIResponsesPrunedInfo
+	// The cursor also moves when the ABCI results up to the retain height
+	// were pruned before, e.g. before a restart, which is not reported.
+	if newRetainHeight > c.abciRes && pruned > 0 {
+		info = &ABCIResponsesPrunedInfo{
+			FromHeight:       c.abciRes,
+			ToHeight:         newRetainHeight - 1,
+			RemainingHeights: remainingHeights(targetRetainHeight, newRetainHeight),
+			CatchingUp:       catchingUp,
+		}
+		p.observer.PrunerPrunedABCIRes(info)
+	}
+	c.abciRes = newRetainHeight
+	p.warnIfABCIDiverges(newRetainHeight)
+	return info
+}
+
+// warnIfABCIDiverges logs a warning if the ABCI results retain height reached
+// by the pruner and the base of the block store are further apart than the
+// threshold set with WithPrunerABCIDivergenceWarn. It is skipped until ABCI
+// results have been pruned.
+func (p *Pruner) warnIfABCIDiverges(abciRetainHeight int64) {
+	if p.abciDivergenceWarn == 0 || abciRetainHeight == 0 {
+		return
+	}
+	base := p.bs.Base()
+	if base == 0 {
+		return
+	}
+	divergence := abciRetainHeight - base
+	if divergence < 0 {
+		divergence = -divergence
+	}
+	if divergence > p.abciDivergenceWarn {
+		p.logger.Error("ABCI results retain height and block store base diverge, check the retain heights",
+			"abciRetainHeight", abciRetainHeight, "base", base, "threshold", p.abciDivergenceWarn)
+	}
+}
+
+func (p *Pruner) pruneBlocks() {
+	p.logger.Info("Started pruning blocks", "interval", p.interval.String())
+	var c pruningCursors
+	for {
+		select {
+		case <-p.Quit():
+			return
+		default:
+			if !p.vetoed("blocks") {
+				p.reportTargets()
+				if _, ok, _ := p.pruneBlocksPass(&c); !ok {
+					return
+				}
+			}
+			p.observer.PrunerHeartbeat()
+			p.sleep(p.interval)
+		}
+	}
+}
+
+// pruneBlocksPass prunes blocks and their states once from the cursors c, and
+// returns what was pruned, along with the error, if any. It returns false if
+// the pruner failed fast and was stopped.
+func (p *Pruner) pruneBlocksPass(c *pruningCursors) (*PrunedInfo, bool, error) {
+	p.compactRetainHeightKeys()
+	newRetainHeight, targetRetainHeight, reported, err := p.pruneBlocksToRetainHeight(c.blocks)
+	var loadErr ErrPrunerFailedToLoadState
+	if errors.As(err, &loadErr) {
+		c.stateLoadFailures++
+	} else {
+		c.stateLoadFailures = 0
+	}
+	if p.failFast && p.IsRunning() && c.stateLoadFailures >= p.maxStateLoadFailures {
+		p.failWith(err, c.stateLoadFailures)
+		return nil, false, err
+	}
+	info := &PrunedInfo{}
+	if reported != nil {
+		info.States, info.OrphanedBlockParts = reported.States, reported.OrphanedBlockParts
+		info.EvidencePinnedHeights = reported.EvidencePinnedHeights
+	}
+	if newRetainHeight > c.blocks {
+		info.Blocks = &BlocksPrunedInfo{
+			FromHeight:       c.blocks,
+			ToHeight:         newRetainHeight - 1,
+			RemainingHeights: remainingHeights(targetRetainHeight, newRetainHeight),
+		}
+		p.observer.PrunerPrunedBlocks(info.Blocks)
+	}
+	c.blocks = newRetainHeight
+	return info, true, err
+}
+
+// stateStoreCompactor is implemented by the state stores that can be compacted
+// as a whole, see WithStateCompactionInterval.
+type stateStoreCompactor interface {
+	CompactDatabase() (sizeBefore, sizeAfter int64, err error)
+}
+
+// compactStateRoutine compacts the state store with compactor every interval
+// set with WithStateCompactionInterval, until the pruner stops.
+func (p *Pruner) compactStateRoutine(compactor stateStoreCompactor) {
+	p.logger.Info("Started compacting the state store", "interval", p.stateCompactionInterval.String())
+	for {
+		select {
+		case <-p.Quit():
+			return
+		case <-p.clock.After(p.stateCompactionInterval):
+			if !p.vetoed("state compaction") {
+				p.compactState(compactor)
+			}
+		}
+	}
+}
+
+// compactState compacts the state store with compactor once, and logs how long
+// it took and the space it reclaimed, if known. Failing to compact it is only
+// logged.
+func (p *Pruner) compactState(compactor stateStoreCompactor) {
+	start := time.Now()
+	sizeBefore, sizeAfter, err := compactor.CompactDatabase()
+	addTimeSample(p.metrics.PruningDurationSeconds.With("phase", "state_compaction"), start)()
+	if err != nil {
+		p.logger.Error("Failed to compact the state store", "err", err, "duration", time.Since(start))
+		return
+	}
+	if sizeBefore < 0 || sizeAfter < 0 {
+		p.logger.Info("Compacted the state store", "duration", time.Since(start))
+		return
+	}
+	p.logger.Info("Compacted the state store", "duration", time.Since(start),
+		"reclaimedBytes", sizeBefore-sizeAfter, "sizeBytes", sizeAfter)
+}
+
+// compactRetainHeightKeys compacts the retain height keys if they have been
+// set at least as many times as set by WithPrunerRetainHeightKeysCompaction
+// since they were last compacted. Failing to compact them is only logged.
+func (p *Pruner) compactRetainHeightKeys() {
+	if p.retainHeightKeysCompactionInterval == 0 {
+		return
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.retainHeightUpdates < p.retainHeightKeysCompactionInterval {
+		return
+	}
+	if err := p.stateStore.CompactRetainHeightKeys(); err != nil {
+		p.logger.Error("Failed to compact retain height keys", "err", err)
+		return
+	}
+	p.retainHeightUpdates = 0
+}
+
+// prunePhasesRoutine runs the phases set by WithPrunerPhaseOrder in sequence,
+// with the same passes as PruneOnce.
+func (p *Pruner) prunePhasesRoutine() {
+	p.logger.Info("Started pruning", "interval", p.interval.String(), "phases", p.enabledPhases())
+	var c pruningCursors
+	for {
+		select {
+		case <-p.Quit():
+			return
+		default:
+			if !p.vetoed("all phases") && !p.prunePhases(&c) {
+				return
+			}
+			p.observer.PrunerHeartbeat()
+			p.sleep(p.interval)
+		}
+	}
+}
+
+// prunePhases runs the phases set by WithPrunerPhaseOrder once, in order, or
+// with the ABCI phase first under high disk pressure, and returns false if the
+// pruner failed fast and was stopped.
+func (p *Pruner) prunePhases(c *pruningCursors) bool {
+	p.reportTargets()
+	phases := p.phaseOrder
+	if p.underDiskPressure() {
+		phases = abciPhaseFirst(phases)
+	}
+	_, ok, _ := p.prunePass(context.Background(), phases, c)
+	return ok
+}
+
+// prunePass runs phases once, in order, from the cursors c, and returns what
+// was pruned, along with the errors of the phases, if any. The ABCI and indexer
+// phases are skipped if they are not enabled. It stops before the next phase
+// if ctx is done. It returns false if the pruner failed fast and was stopped.
+func (p *Pruner) prunePass(ctx context.Context, phases []PrunePhase, c *pruningCursors) (*PrunedInfo, bool, error) {
+	info := &PrunedInfo{}
+	var errs []error
+	for _, phase := range phases {
+		if err := ctx.Err(); err != nil {
+			return info, true, errors.Join(append(errs, err)...)
+		}
+		switch phase {
+		case PrunePhaseBlocks:
+			blocksInfo, ok, err := p.pruneBlocksPass(c)
+			if !ok {
+				return info, false, err
+			}
+			info.Blocks, info.States = blocksInfo.Blocks, blocksInfo.States
+			info.OrphanedBlockParts = blocksInfo.OrphanedBlockParts
+			info.EvidencePinnedHeights = blocksInfo.EvidencePinnedHeights
+			if err != nil {
+				errs = append(errs, err)
+			}
+		case PrunePhaseABCI:
+			if p.abciResPhaseEnabled() {
+				info.ABCIResponses = p.pruneABCIResPass(c)
+			}
+		case PrunePhaseIndexer:
+			if p.indexerPhaseEnabled() {
+				indexerInfo, err := p.pruneIndexesPass(c)
+				info.Indexer = indexerInfo
+				if err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return info, true, errors.Join(errs...)
+}
+
+// abciPhaseFirst returns phases with PrunePhaseABCI moved first, if listed.
+func abciPhaseFirst(phases []PrunePhase) []PrunePhase {
+	i := slices.Index(phases, PrunePhaseABCI)
+	if i <= 0 {
+		return phases
+	}
+	reordered := make([]PrunePhase, 0, len(phases))
+	reordered = append(reordered, PrunePhaseABCI)
+	reordered = append(reordered, phases[:i]...)
+	return append(reordered, phases[i+1:]...)
+}
+
+// underDiskPressure returns true if the function supplied with
+// WithPrunerDiskPressureFunc reports high disk pressure.
+func (p *Pruner) underDiskPressure() bool {
+	return p.diskPressure != nil && p.diskPressure() >= DiskPressureHigh
+}
+
+// reportTargets computes the retain heights targeted by the current cycle and
+// reports them to the observer with TargetComputed. The ABCI results and
+// indexers may actually be pruned by their own routines, which read their
+// retain heights again.
+func (p *Pruner) reportTargets() {
+	p.seedApplicationRetainHeight()
+	blockTarget := p.findMinBlockRetainHeight()
+
+	var abciTarget int64
+	if p.abciResPhaseEnabled() {
+		height, err := p.stateStore.GetABCIResRetainHeight()
+		if err == nil {
+			abciTarget = min(height, p.bs.Height())
+		}
+		if height, err := p.abciResTargetRetainHeight(abciTarget); err == nil {
+			abciTarget = height
+		}
+		if p.coupleABCIToBlocks {
+			abciTarget = max(abciTarget, blockTarget)
+		}
+	}
+
+	var indexerTarget int64
+	if p.indexerPhaseEnabled() {
+		txHeight, txErr := p.GetTxIndexerRetainHeight()
+		blockHeight, blockErr := p.GetBlockIndexerRetainHeight()
+		switch {
+		case txErr == nil && blockErr == nil:
+			indexerTarget = min(txHeight, blockHeight)
+		case txErr == nil:
+			indexerTarget = txHeight
+		case blockErr == nil:
+			indexerTarget = blockHeight
+		}
+	}
+
+	p.observer.TargetComputed(blockTarget, abciTarget, indexerTarget)
+}
+
+// sleep waits for d on the clock of the pruner, or until the pruner is resumed
+// or stops.
+func (p *Pruner) sleep(d time.Duration) {
+	resumed := p.resumedChan()
+	select {
+	case <-p.clock.After(d):
+	case <-resumed:
+	case <-p.Quit():
+	}
+}
+
+// vetoed returns true if the pruner is paused, or if the observer vetoes the
+// current cycle of the routine pruning what, in which case the cycle must be
+// skipped.
+func (p *Pruner) vetoed(what string) bool {
+	if p.IsPaused() {
+		p.logger.Debug("Pruning paused, retrying at the next interval", "pruning", what)
+		return true
+	}
+	if p.observer.ShouldPrune() {
+		return false
+	}
+	p.logger.Debug("Pruning pass vetoed by observer, retrying at the next interval", "pruning", what)
+	return true
+}
+
+// failWith stops the pruner because of err, which is then returned by Err.
+func (p *Pruner) failWith(err error, failures int) {
+	p.logger.Error("Failed to load state too many times in a row, stopping pruner", "failures", failures, "err", err)
+	p.mtx.Lock()
+	p.err = err
+	p.mtx.Unlock()
+	if err := p.Stop(); err != nil {
+		p.logger.Error("Failed to stop pruner", "err", err)
+	}
+}
+
+func (p *Pruner) pruneIndexesRoutine() {
+	p.logger.Info("Index pruner started", "interval", p.interval.String())
+	var c pruningCursors
 	for {
 		select {
 		case <-p.Quit():
 			return
 		default:
-			newRetainHeight := p.pruneABCIResToRetainHeight(lastRetainHeight)
-			if newRetainHeight != lastRetainHeight {
-				p.observer.PrunerPrunedABCIRes(&ABCIResponsesPrunedInfo{
-					FromHeight: lastRetainHeight,
-					ToHeight:   newRetainHeight - 1,
-				})
+			if !p.vetoed("indexes") {
+				p.pruneIndexesPass(&c)
 			}
-			lastRetainHeight = newRetainHeight
-			time.Sleep(p.interval)
+			p.sleep(p.interval)
+		}
+	}
+}
+
+// pruneIndexesPass prunes the tx and block indexers once from the cursors c,
+// and returns what was pruned, nil if nothing, and the errors returned by the
+// indexers, if any.
+func (p *Pruner) pruneIndexesPass(c *pruningCursors) (*IndexerPrunedInfo, error) {
+	var (
+		txHeights, blockHeights int64
+		txErr, blockErr         error
+	)
+	c.txIndexer, txHeights, txErr = p.pruneTxIndexerToRetainHeight(c.txIndexer)
+	c.blockIndexer, blockHeights, blockErr = p.pruneBlockIndexerToRetainHeight(c.blockIndexer)
+	return p.reportIndexerPruned(c.txIndexer, c.blockIndexer, txHeights, blockHeights, errors.Join(txErr, blockErr))
+}
+
+// reportIndexerPruned reports to the observer with IndexerPruned that the
+// indexers were pruned up to the given retain heights, unless nothing was
+// pruned and there was no error, and returns what was pruned, nil if nothing,
+// along with err.
+func (p *Pruner) reportIndexerPruned(txRetainHeight, blockRetainHeight, txHeights, blockHeights int64, err error) (*IndexerPrunedInfo, error) {
+	if txHeights == 0 && blockHeights == 0 && err == nil {
+		return nil, nil
+	}
+	// Report the height below which both indexers are pruned, ignoring an
+	// indexer whose retain height was not set.
+	retainHeight := max(txRetainHeight, blockRetainHeight)
+	if txRetainHeight > 0 && blockRetainHeight > 0 {
+		retainHeight = min(txRetainHeight, blockRetainHeight)
+	}
+	p.observer.IndexerPruned(retainHeight, txHeights+blockHeights, err)
+	if txHeights == 0 && blockHeights == 0 {
+		return nil, err
+	}
+	return &IndexerPrunedInfo{
+		RetainHeight:        retainHeight,
+		TxIndexerHeights:    txHeights,
+		BlockIndexerHeights: blockHeights,
+	}, err
+}
+
+// PruneIndexesNow prunes the tx and block indexers once, up to their retain
+// heights, and returns the errors returned by the indexers, if any. It lets
+// callers prune the indexers on their own schedule, e.g. after backing them
+// up, and can be called whether or not the pruner is running, since it is
+// serialized with the background pruning of the indexers.
+func (p *Pruner) PruneIndexesNow() error {
+	txRetainHeight, txHeights, txErr := p.pruneTxIndexerToRetainHeight(0)
+	blockRetainHeight, blockHeights, blockErr := p.pruneBlockIndexerToRetainHeight(0)
+	_, err := p.reportIndexerPruned(txRetainHeight, blockRetainHeight, txHeights, blockHeights, errors.Join(txErr, blockErr))
+	return err
+}
+
+// PruneOnce runs one synchronous pass of pruning: it prunes the blocks and
+// their states, the ABCI responses and the indexers once, up to their retain
+// heights, and returns what was pruned, along with the errors of the phases,
+// if any. The ABCI responses and the indexers are only pruned if they would be
+// by the running pruner. It stops before the next phase if ctx is done. It lets
+// operators reclaim disk space on a node that is not running, and tests prune
+// deterministically, so it fails with ErrPrunerRunning if the pruner has been
+// started. If WithPrunerLease is supplied, the lease is held while pruning, so
+// that it fails with ErrPrunerLeaseHeld if another pruner holds it. The
+// background routine reuses the same pass if WithPrunerPhaseOrder is supplied.
+func (p *Pruner) PruneOnce(ctx context.Context) (*PrunedInfo, error) {
+	if p.IsRunning() {
+		return nil, ErrPrunerRunning
+	}
+	if p.leaseTTL > 0 {
+		if err := p.acquireLease(); err != nil {
+			return nil, err
+		}
+		defer p.releaseLease()
+	}
+	c := pruningCursors{blocks: p.bs.Base()}
+	info, _, err := p.prunePass(ctx, []PrunePhase{PrunePhaseBlocks, PrunePhaseABCI, PrunePhaseIndexer}, &c)
+	return info, err
+}
+
+// PruneToHeight prunes the blocks below height, and their states, once,
+// whatever the application block retain height, and returns what was pruned. It lets
+// operators reclaim disk space down to a height of their choosing, e.g. during
+// a maintenance window, without changing the stored retain heights. If the data
+// companion is enabled, the blocks it still needs are kept, i.e. height is
+// lowered to the data companion block retain height. Like PruneOnce, it fails
+// with ErrPrunerRunning if the pruner has been started, and holds the lease if
+// WithPrunerLease is supplied.
+func (p *Pruner) PruneToHeight(height int64) (*PrunedInfo, error) {
+	if p.IsRunning() {
+		return nil, ErrPrunerRunning
+	}
+	if height <= 0 {
+		return nil, ErrInvalidRetainHeight
+	}
+	if p.bs.Height() == 0 {
+		return nil, ErrNoBlocksToPrune
+	}
+	if height > p.bs.Height() {
+		return nil, ErrInvalidHeightValue
+	}
+	if p.leaseTTL > 0 {
+		if err := p.acquireLease(); err != nil {
+			return nil, err
+		}
+		defer p.releaseLease()
+	}
+
+	height, err := p.limitPruneTarget(height, p.logger.Info)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &PrunedInfo{}
+	base := p.bs.Base()
+	if height <= base {
+		return info, nil
+	}
+	p.observer.PruningWillStart(height)
+	_, evRetainHeight, statesInfo, err := p.pruneBlocksToHeight(height)
+	newBase := p.bs.Base()
+	if newBase > base {
+		info.Blocks = &BlocksPrunedInfo{
+			FromHeight:       base,
+			ToHeight:         newBase - 1,
+			RemainingHeights: remainingHeights(height, newBase),
 		}
+		p.metrics.BlockStoreBaseHeight.Set(float64(newBase))
 	}
+	info.States = statesInfo
+	if err == nil {
+		info.OrphanedBlockParts = p.sweepOrphanedBlockParts()
+		info.EvidencePinnedHeights = evidencePinnedHeights(height, evRetainHeight)
+		p.metrics.EvidencePinnedHeights.Set(float64(info.EvidencePinnedHeights))
+	}
+	p.pruningDidFinish(info, err)
+	return info, err
 }
 
-func (p *Pruner) pruneBlocks() {
-	p.logger.Info("Started pruning blocks", "interval", p.interval.String())
-	lastRetainHeight := int64(0)
-	for {
-		select {
-		case <-p.Quit():
-			return
-		default:
-			newRetainHeight := p.pruneBlocksToRetainHeight(lastRetainHeight)
-			if newRetainHeight != lastRetainHeight {
-				p.observer.PrunerPrunedBlocks(&BlocksPrunedInfo{
-					FromHeight: lastRetainHeight,
-					ToHeight:   newRetainHeight - 1,
-				})
-			}
-			lastRetainHeight = newRetainHeight
-			time.Sleep(p.interval)
+// limitPruneTarget lowers height, up to which PruneToHeight prunes the blocks,
+// to keep the blocks still needed by the data companion, pinned by retain
+// leases, needed by the snapshots served, or held by the target override,
+// logging why with logf.
+func (p *Pruner) limitPruneTarget(height int64, logf func(msg string, keyvals ...any)) (int64, error) {
+	if p.dcEnabled {
+		dcRetainHeight, err := p.stateStore.GetCompanionBlockRetainHeight()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get the companion block retain height: %w", err)
+		}
+		namedRetainHeight, err := p.minNamedCompanionRetainHeight()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get the named companion block retain heights: %w", err)
+		}
+		if namedRetainHeight != 0 {
+			dcRetainHeight = min(dcRetainHeight, namedRetainHeight)
 		}
+		if dcRetainHeight < height {
+			logf("Keeping the blocks needed by the data companion",
+				"height", height, "companionRetainHeight", dcRetainHeight)
+			height = dcRetainHeight
+		}
+	}
+	if leaseHeight := p.retainLeaseHeight(); leaseHeight != 0 && leaseHeight < height {
+		logf("Keeping the blocks pinned by retain leases", "height", height, "leaseHeight", leaseHeight)
+		height = leaseHeight
+	}
+	if snapshotHeight := p.snapshotTarget(height); snapshotHeight < height {
+		logf("Keeping the blocks needed by the snapshots served", "height", height, "snapshotHeight", snapshotHeight)
+		height = snapshotHeight
+	}
+	if overridden := p.overrideTarget(height); overridden < height {
+		logf("Keeping the blocks held by the target override", "height", height, "override", overridden)
+		height = overridden
 	}
+	return height, nil
 }
 
-func (p *Pruner) pruneIndexesRoutine() {
-	p.logger.Info("Index pruner started", "interval", p.interval.String())
-	lastTxIndexerRetainHeight := int64(0)
-	lastBlockIndexerRetainHeight := int64(0)
-	for {
-		select {
-		case <-p.Quit():
-			return
-		default:
-			lastTxIndexerRetainHeight = p.pruneTxIndexerToRetainHeight(lastTxIndexerRetainHeight)
-			lastBlockIndexerRetainHeight = p.pruneBlockIndexerToRetainHeight(lastBlockIndexerRetainHeight)
-			// TODO call observer
-			time.Sleep(p.interval)
-		}
+// PruningEstimate is what pruning the blocks up to a height would prune, as
+// estimated by EstimatePruning.
+type PruningEstimate struct {
+	// RetainHeight is the height up to which the blocks would be pruned, once
+	// lowered to keep the blocks still needed, as PruneToHeight does.
+	RetainHeight int64
+	// Heights is the number of heights whose blocks would be pruned.
+	Heights int64
+	// Bytes is an estimate of the disk space the blocks would free, or -1 if
+	// the block store can't estimate it.
+	Bytes int64
+}
+
+// pruneBytesEstimator is implemented by the block stores that can estimate the
+// disk space freed by pruning blocks, see EstimatePruning.
+type pruneBytesEstimator interface {
+	EstimatePruneBytes(from, to int64) (uint64, error)
+}
+
+// EstimatePruning returns what PruneToHeight would prune with the same height,
+// including an estimate of the disk space it would free if the block store
+// supports it, without pruning anything, e.g. to plan the capacity of a node
+// before raising its retain height. Unlike PruneToHeight, it can be called
+// while the pruner is running.
+func (p *Pruner) EstimatePruning(height int64) (*PruningEstimate, error) {
+	if height <= 0 {
+		return nil, ErrInvalidRetainHeight
+	}
+	if p.bs.Height() == 0 {
+		return nil, ErrNoBlocksToPrune
+	}
+	if height > p.bs.Height() {
+		return nil, ErrInvalidHeightValue
+	}
+	height, err := p.limitPruneTarget(height, p.logger.Debug)
+	if err != nil {
+		return nil, err
+	}
+	estimate := &PruningEstimate{RetainHeight: height}
+	base := p.bs.Base()
+	if height <= base {
+		return estimate, nil
+	}
+	estimate.Heights = height - base
+	estimator, ok := p.bs.(pruneBytesEstimator)
+	if !ok {
+		estimate.Bytes = -1
+		return estimate, nil
 	}
+	bytes, err := estimator.EstimatePruneBytes(base, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate the bytes freed by pruning: %w", err)
+	}
+	estimate.Bytes = int64(bytes)
+	return estimate, nil
 }
 
-func (p *Pruner) pruneTxIndexerToRetainHeight(lastRetainHeight int64) int64 {
+func (p *Pruner) pruneTxIndexerToRetainHeight(lastRetainHeight int64) (int64, int64, error) {
 	targetRetainHeight, err := p.GetTxIndexerRetainHeight()
 	if err != nil {
 		// Indexer retain height has not yet been set - do not log any
 		// errors at this time.
 		if errors.Is(err, ErrKeyNotFound) {
-			return 0
+			return 0, 0, nil
 		}
 		p.logger.Error("Failed to get Indexer retain height", "err", err)
-		return lastRetainHeight
+		return lastRetainHeight, 0, err
 	}
 
 	if lastRetainHeight >= targetRetainHeight {
-		return lastRetainHeight
+		return lastRetainHeight, 0, nil
 	}
 
+	p.indexerMtx.Lock()
+	defer p.indexerMtx.Unlock()
+	start := time.Now()
 	numPrunedTxIndexer, newTxIndexerRetainHeight, err := p.txIndexer.Prune(targetRetainHeight)
+	addTimeSample(p.metrics.PruningDurationSeconds.With("phase", "tx_indexer"), start)()
 	if err != nil {
 		p.logger.Error("Failed to prune tx indexer", "err", err, "targetRetainHeight", targetRetainHeight, "newTxIndexerRetainHeight", newTxIndexerRetainHeight)
-	} else if numPrunedTxIndexer > 0 {
+		return newTxIndexerRetainHeight, numPrunedTxIndexer, ErrFailedToPruneTxIndexer{Height: targetRetainHeight, Err: err}
+	}
+	if numPrunedTxIndexer > 0 {
 		p.metrics.TxIndexerBaseHeight.Set(float64(newTxIndexerRetainHeight))
 		p.logger.Debug("Pruned tx indexer", "count", numPrunedTxIndexer, "newTxIndexerRetainHeight", newTxIndexerRetainHeight)
 	}
-	return newTxIndexerRetainHeight
+	return newTxIndexerRetainHeight, numPrunedTxIndexer, nil
 }
 
-func (p *Pruner) pruneBlockIndexerToRetainHeight(lastRetainHeight int64) int64 {
+func (p *Pruner) pruneBlockIndexerToRetainHeight(lastRetainHeight int64) (int64, int64, error) {
 	targetRetainHeight, err := p.GetBlockIndexerRetainHeight()
 	if err != nil {
 		// Indexer retain height has not yet been set - do not log any
 		// errors at this time.
 		if errors.Is(err, ErrKeyNotFound) {
-			return 0
+			return 0, 0, nil
 		}
 		p.logger.Error("Failed to get Indexer retain height", "err", err)
-		return lastRetainHeight
+		return lastRetainHeight, 0, err
 	}
 
 	if lastRetainHeight >= targetRetainHeight {
-		return lastRetainHeight
+		return lastRetainHeight, 0, nil
 	}
 
+	p.indexerMtx.Lock()
+	defer p.indexerMtx.Unlock()
+	start := time.Now()
 	numPrunedBlockIndexer, newBlockIndexerRetainHeight, err := p.blockIndexer.Prune(targetRetainHeight)
+	addTimeSample(p.metrics.PruningDurationSeconds.With("phase", "block_indexer"), start)()
 	if err != nil {
 		p.logger.Error("Failed to prune block indexer", "err", err, "targetRetainHeight", targetRetainHeight, "newBlockIndexerRetainHeight", newBlockIndexerRetainHeight)
-	} else if numPrunedBlockIndexer > 0 {
+		return newBlockIndexerRetainHeight, numPrunedBlockIndexer, ErrFailedToPruneBlockIndexer{Height: targetRetainHeight, Err: err}
+	}
+	if numPrunedBlockIndexer > 0 {
 		p.metrics.BlockIndexerBaseHeight.Set(float64(newBlockIndexerRetainHeight))
 		p.logger.Debug("Pruned block indexer", "count", numPrunedBlockIndexer, "newBlockIndexerRetainHeight", newBlockIndexerRetainHeight)
 	}
-	return newBlockIndexerRetainHeight
+	return newBlockIndexerRetainHeight, numPrunedBlockIndexer, nil
 }
 
-func (p *Pruner) pruneBlocksToRetainHeight(lastRetainHeight int64) int64 {
+// pruneBlocksToRetainHeight prunes blocks and states up to the minimum block
+// retain height, and then the orphaned block parts below the new base. It
+// returns the new retain height, i.e. the new base of the block store, the
+// target retain height, the information reported to PruningDidFinish, if
+// pruning started, and the error that occurred while pruning, if any.
+func (p *Pruner) pruneBlocksToRetainHeight(lastRetainHeight int64) (int64, int64, *PrunedInfo, error) {
+	p.seedApplicationRetainHeight()
 	targetRetainHeight := p.findMinBlockRetainHeight()
-	if targetRetainHeight == lastRetainHeight {
-		return lastRetainHeight
+	// A target of 0 means that no block retain height has been set.
+	if targetRetainHeight == 0 || targetRetainHeight == lastRetainHeight {
+		if targetRetainHeight == 0 {
+			p.logNothingToPrune("blocks", "no retain height set", targetRetainHeight, lastRetainHeight)
+		} else {
+			p.logNothingToPrune("blocks", "already at target", targetRetainHeight, lastRetainHeight)
+		}
+		p.metrics.BlocksBehindRetainTarget.Set(float64(remainingHeights(targetRetainHeight, p.bs.Base())))
+		return lastRetainHeight, targetRetainHeight, nil, nil
+	}
+	if base := p.bs.Base(); targetRetainHeight <= base {
+		p.logNothingToPrune("blocks", "target below base", targetRetainHeight, base)
+		p.metrics.BlocksBehindRetainTarget.Set(0)
+		return lastRetainHeight, targetRetainHeight, nil, nil
 	}
-	pruned, evRetainHeight, err := p.pruneBlocksToHeight(targetRetainHeight)
+	p.observer.PruningWillStart(targetRetainHeight)
+	pruned, evRetainHeight, statesInfo, err := p.pruneBlocksToHeight(targetRetainHeight)
 	// The new retain height is the current lowest point of the block store
 	// indicated by Base()
 	newRetainHeight := p.bs.Base()
+	p.metrics.BlocksBehindRetainTarget.Set(float64(remainingHeights(targetRetainHeight, newRetainHeight)))
+	info := &PrunedInfo{
+		Blocks: &BlocksPrunedInfo{
+			FromHeight:       lastRetainHeight,
+			ToHeight:         newRetainHeight - 1,
+			RemainingHeights: remainingHeights(targetRetainHeight, newRetainHeight),
+		},
+		States: statesInfo,
+	}
+	if err == nil {
+		info.OrphanedBlockParts = p.sweepOrphanedBlockParts()
+		info.EvidencePinnedHeights = evidencePinnedHeights(targetRetainHeight, evRetainHeight)
+		p.metrics.EvidencePinnedHeights.Set(float64(info.EvidencePinnedHeights))
+	}
+	p.pruningDidFinish(info, err)
 	if err != nil {
-		p.logger.Error("Failed to prune blocks", "err", err, "targetRetainHeight", targetRetainHeight, "newRetainHeight", newRetainHeight)
+		p.logPruneError("Failed to prune blocks", err, "targetRetainHeight", targetRetainHeight, "newRetainHeight", newRetainHeight)
 	} else if pruned > 0 {
 		p.metrics.BlockStoreBaseHeight.Set(float64(newRetainHeight))
 		p.logger.Debug("Pruned blocks", "count", pruned, "evidenceRetainHeight", evRetainHeight, "newRetainHeight", newRetainHeight)
 	}
-	return newRetainHeight
+	if err == nil {
+		if newRetainHeight < targetRetainHeight {
+			p.metrics.PrunerThrottledCycles.Add(1)
+		} else {
+			p.metrics.PrunerSatisfiedCycles.Add(1)
+		}
+	}
+	return newRetainHeight, targetRetainHeight, info, err
 }
 
-func (p *Pruner) pruneABCIResToRetainHeight(lastRetainHeight int64) int64 {
-	targetRetainHeight, err := p.stateStore.GetABCIResRetainHeight()
+// logNothingToPrune logs at the debug level why a pass of the pruner over what
+// is pruning nothing, so that operators can tell an idle pruner from a stuck
+// one: no retain height has been set, the base already is at the target, or
+// the target is below the base.
+func (p *Pruner) logNothingToPrune(what, reason string, target, base int64) {
+	p.logger.Debug("Nothing to prune", "what", what, "reason", reason, "target", target, "base", base)
+}
+
+// evidencePinnedHeights returns the number of heights below height whose
+// evidence data was kept when pruning the blocks below it, given the evidence
+// retain height returned by the block store, from which it was kept.
+func evidencePinnedHeights(height, evRetainHeight int64) int64 {
+	if evRetainHeight <= 0 {
+		return 0
+	}
+	return max(0, height-evRetainHeight)
+}
+
+// sweepOrphanedBlockParts removes the block parts left below the base of the
+// block store, which don't belong to any block, and returns how many it
+// removed. Only the heights below the base that were not swept yet are swept,
+// i.e. all of them at the first sweep after the pruner is created.
+func (p *Pruner) sweepOrphanedBlockParts() uint64 {
+	base := p.bs.Base()
+	from := max(p.blockPartsSweptTo, 1)
+	if base <= from {
+		return 0
+	}
+	start := time.Now()
+	count, err := p.bs.PruneOrphanedBlockParts(from, base)
+	addTimeSample(p.metrics.PruningDurationSeconds.With("phase", "orphaned_block_parts"), start)()
 	if err != nil {
+		p.logger.Error("Failed to prune orphaned block parts", "from", from, "to", base, "parts", count, "err", err)
+		return count
+	}
+	p.blockPartsSweptTo = base
+	if count > 0 {
+		p.logger.Info("Pruned orphaned block parts", "from", from, "to", base, "parts", count)
+	}
+	return count
+}
+
+// pruneABCIResToRetainHeight prunes ABCI responses up to the ABCI results
+// retain height, or up to the next step catching up with it, see
+// WithPrunerABCIResCatchUp, in which case step is updated. It returns the new
+// retain height, i.e. the height just after the last pruned one, the target
+// retain height, the number of heights pruned, and whether it only pruned up
+// to the next step.
+func (p *Pruner) pruneABCIResToRetainHeight(lastRetainHeight int64, step *int64) (int64, int64, int64, bool) {
+	targetRetainHeight, err := p.stateStore.GetABCIResRetainHeight()
+	switch {
+	case errors.Is(err, ErrKeyNotFound) && p.abciResRetainDuration > 0:
+		// Only the retain duration is set.
+		targetRetainHeight = 0
+	case err != nil:
 		p.logger.Error("Failed to get ABCI response retain height", "err", err)
 		if errors.Is(err, ErrKeyNotFound) {
-			return 0
+			p.logNothingToPrune("ABCI results", "no retain height set", 0, lastRetainHeight)
+			return lastRetainHeight, 0, 0, false
 		}
-		return lastRetainHeight
+		return lastRetainHeight, lastRetainHeight, 0, false
+	default:
+		targetRetainHeight = p.downgradeRetainHeightAboveTip("ABCI results", targetRetainHeight,
+			p.stateStore.GetABCIResRetainHeight, p.stateStore.SaveABCIResRetainHeight)
+	}
+	targetRetainHeight, err = p.abciResTargetRetainHeight(targetRetainHeight)
+	if err != nil {
+		p.logger.Error("Failed to compute the ABCI results target retain height", "err", err)
+		return lastRetainHeight, lastRetainHeight, 0, false
+	}
+	if p.coupleABCIToBlocks {
+		// Prune the ABCI results of the heights whose blocks were pruned.
+		targetRetainHeight = max(targetRetainHeight, p.bs.Base())
 	}
 
 	if lastRetainHeight == targetRetainHeight {
-		return lastRetainHeight
+		p.logNothingToPrune("ABCI results", "already at target", targetRetainHeight, lastRetainHeight)
+		return lastRetainHeight, targetRetainHeight, 0, false
+	}
+	if targetRetainHeight < lastRetainHeight {
+		p.logNothingToPrune("ABCI results", "target below base", targetRetainHeight, lastRetainHeight)
+		return lastRetainHeight, 0, 0, false
 	}
+	runRetainHeight := p.abciResRunRetainHeight(targetRetainHeight, step)
+	catchingUp := runRetainHeight < targetRetainHeight
 
 	// If the block retain height is 0, pruning of the block and state stores might be disabled
 	// This should not prevent Comet from pruning ABCI results if needed.
 	// We could by default always compact when pruning the responses, but in case the state store
 	// is being compacted we introduce an overhead that might cause performance penalties.
 	forceCompact := p.findMinBlockRetainHeight() == 0
+	p.observer.PruningWillStart(runRetainHeight)
 	// newRetainHeight is the height just after that which we have successfully
-	// pruned. In case of an error it will be 0, but then it will also be
-	// ignored.
-	numPruned, newRetainHeight, err := p.stateStore.PruneABCIResponses(targetRetainHeight, forceCompact)
+	// pruned. In case of an error, it reflects the heights pruned before the
+	// error, if any.
+	start := time.Now()
+	var numPruned, newRetainHeight int64
+	err = p.retryTransient("ABCI results", func() error {
+		pruned, retainHeight, err := p.stateStore.PruneABCIResponses(runRetainHeight, forceCompact)
+		numPruned += pruned
+		newRetainHeight = max(newRetainHeight, retainHeight)
+		return err
+	})
+	addTimeSample(p.metrics.PruningDurationSeconds.With("phase", "abci_responses"), start)()
+	if err != nil && (numPruned == 0 || newRetainHeight <= lastRetainHeight) {
+		newRetainHeight = lastRetainHeight
+	}
+	info := &ABCIResponsesPrunedInfo{FromHeight: lastRetainHeight, ToHeight: newRetainHeight - 1, CatchingUp: catchingUp}
+	info.RemainingHeights = remainingHeights(targetRetainHeight, newRetainHeight)
+	p.pruningDidFinish(&PrunedInfo{ABCIResponses: info}, err)
 	if err != nil {
-		p.logger.Error("Failed to prune ABCI responses", "err", err, "targetRetainHeight", targetRetainHeight)
-		return lastRetainHeight
+		p.logPruneError("Failed to prune ABCI responses", err, "targetRetainHeight", targetRetainHeight,
+			"heights", numPruned, "newRetainHeight", newRetainHeight)
+		if newRetainHeight > lastRetainHeight {
+			p.metrics.ABCIResultsBaseHeight.Set(float64(newRetainHeight))
+		}
+		return newRetainHeight, targetRetainHeight, numPruned, catchingUp
 	}
 	if numPruned > 0 {
-		p.logger.Info("Pruned ABCI responses", "heights", numPruned, "newRetainHeight", newRetainHeight)
+		p.logger.Info("Pruned ABCI responses", "heights", numPruned, "newRetainHeight", newRetainHeight,
+			"targetRetainHeight", targetRetainHeight)
 		p.metrics.ABCIResultsBaseHeight.Set(float64(newRetainHeight))
 	}
-	return newRetainHeight
+	return newRetainHeight, targetRetainHeight, numPruned, catchingUp
+}
+
+// abciResRunRetainHeight returns the retain height up to which the current run
+// prunes the ABCI results. It is below targetRetainHeight if the run is a step
+// catching up with it, see WithPrunerABCIResCatchUp, in which case step is
+// doubled for the next run, and reset once the target is reached, or under high
+// disk pressure.
+func (p *Pruner) abciResRunRetainHeight(targetRetainHeight int64, step *int64) int64 {
+	if p.abciResMaxHeightsPerRun == 0 {
+		return targetRetainHeight
+	}
+	if p.underDiskPressure() {
+		*step = 0
+		return targetRetainHeight
+	}
+	// The ABCI results are pruned from the height up to which they were last
+	// pruned, or from height 1.
+	base, err := p.stateStore.GetLastABCIResponsesRetainHeight()
+	if err != nil {
+		p.logger.Error("Failed to get the height up to which ABCI responses were pruned", "err", err)
+		return targetRetainHeight
+	}
+	base = max(base, 1)
+	if *step == 0 {
+		*step = p.abciResInitialStep
+	}
+	if targetRetainHeight-base <= *step {
+		*step = 0
+		return targetRetainHeight
+	}
+	runRetainHeight := base + *step
+	*step = min(2**step, p.abciResMaxHeightsPerRun)
+	return runRetainHeight
+}
+
+// remainingHeights returns the number of heights that still need to be pruned
+// to reach targetRetainHeight, given that all heights below newRetainHeight
+// have already been pruned.
+func remainingHeights(targetRetainHeight, newRetainHeight int64) int64 {
+	if targetRetainHeight <= newRetainHeight {
+		return 0
+	}
+	return targetRetainHeight - newRetainHeight
+}
+
+// seedApplicationRetainHeight saves the initial application retain height set
+// by WithPrunerInitialAppRetainHeight, if the application has not set a retain
+// height yet and the block store has reached it, raised to the base of the
+// block store if it is below it.
+func (p *Pruner) seedApplicationRetainHeight() {
+	if p.initialAppRetainHeight == 0 || p.bs.Height() < p.initialAppRetainHeight {
+		return
+	}
+	// Serialize with the setters, so that a retain height set by the
+	// application in the meantime is not overridden.
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	curRetainHeight, err := p.stateStore.GetApplicationRetainHeight()
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		p.logger.Error("Unexpected error fetching retain height", "which", "application block", "err", err)
+		return
+	}
+	// The blocks below the base are already gone, e.g. after a state sync or a
+	// migration of the block store.
+	height := max(p.initialAppRetainHeight, p.bs.Base())
+	if curRetainHeight != 0 {
+		p.initialAppRetainHeight = 0
+		return
+	}
+	if err := p.stateStore.SaveApplicationRetainHeight(height); err != nil {
+		p.logger.Error("Failed to set initial application retain height", "height", height, "err", err)
+		return
+	}
+	p.initialAppRetainHeight = 0
+	p.metrics.ApplicationBlockRetainHeight.Set(float64(height))
+	p.logger.Info("Set initial application retain height", "height", height)
 }
 
+// findMinBlockRetainHeight returns the minimum of the stored block retain
+// heights, and of the heights pinned by retain leases, clamped to the range of
+// heights held by the block store, and lowered by the target override, if any.
+// A return value of 0 means that no block retain height has been set yet.
+//
+// Stored block retain heights above the height of the block store are
+// downgraded to it, see downgradeRetainHeightAboveTip.
 func (p *Pruner) findMinBlockRetainHeight() int64 {
+	height := p.findMinStoredBlockRetainHeight()
+	if leaseHeight := p.retainLeaseHeight(); leaseHeight != 0 && leaseHeight < height {
+		height = p.clampToBlockStore(leaseHeight)
+	}
+	return p.overrideTarget(p.snapshotTarget(height))
+}
+
+// overrideTarget returns the block retain height target lowered by the
+// override set with WithPrunerTargetOverride, clamped to the range of heights
+// held by the block store, or target if there is no override. An override
+// raising the target is ignored, as it could prune blocks that must be kept.
+func (p *Pruner) overrideTarget(target int64) int64 {
+	if p.targetOverride == nil || target == 0 {
+		return target
+	}
+	height := p.targetOverride(target)
+	if height > target {
+		p.logger.Error("Ignoring the override of the block retain height, which would raise it",
+			"target", target, "override", height)
+		return target
+	}
+	if height <= 0 {
+		return 0
+	}
+	return p.clampToBlockStore(height)
+}
+
+// findMinStoredBlockRetainHeight returns the minimum of the stored block retain
+// heights, clamped to the range of heights held by the block store, or 0 if no
+// block retain height has been set yet.
+func (p *Pruner) findMinStoredBlockRetainHeight() int64 {
 	appRetainHeight, err := p.stateStore.GetApplicationRetainHeight()
 	if err != nil {
-		p.logger.Error("Unexpected error fetching application retain height", "err", err)
+		p.logStoredRetainHeightError("application block", err)
 		return 0
 	}
+	appRetainHeight = p.downgradeRetainHeightAboveTip("application block", appRetainHeight,
+		p.stateStore.GetApplicationRetainHeight, p.stateStore.SaveApplicationRetainHeight)
+	p.checkStoredRetainHeight("application block", appRetainHeight)
 	// We only care about the companion retain height if pruning is configured
 	// to respect the companion's retain height.
 	if !p.dcEnabled {
-		return appRetainHeight
+		return p.clampToBlockStore(appRetainHeight)
 	}
 	dcRetainHeight, err := p.stateStore.GetCompanionBlockRetainHeight()
 	if err != nil {
-		p.logger.Error("Unexpected error fetching data companion retain height", "err", err)
+		p.logStoredRetainHeightError("companion block", err)
+		return 0
+	}
+	dcRetainHeight = p.downgradeRetainHeightAboveTip("companion block", dcRetainHeight,
+		p.stateStore.GetCompanionBlockRetainHeight, p.stateStore.SaveCompanionBlockRetainHeight)
+	p.checkStoredRetainHeight("companion block", dcRetainHeight)
+	namedRetainHeight, err := p.minNamedCompanionRetainHeight()
+	if err != nil {
+		p.logStoredRetainHeightError("named companion block", err)
 		return 0
 	}
+	if namedRetainHeight != 0 {
+		dcRetainHeight = min(dcRetainHeight, namedRetainHeight)
+	}
 	// If we are here, both heights were set and the companion is enabled, so
 	// we pick the minimum.
-	if appRetainHeight < dcRetainHeight {
-		return appRetainHeight
+	return p.clampToBlockStore(p.minAppCompanionRetainHeight(appRetainHeight, dcRetainHeight))
+}
+
+// minAppCompanionRetainHeight returns the minimum of the application and data
+// companion block retain heights, keeping the safety margin set with
+// WithPrunerCompanionSafetyMargin below the application block retain height, or
+// 0, so that no block is pruned, if the margin is above it.
+func (p *Pruner) minAppCompanionRetainHeight(appRetainHeight, dcRetainHeight int64) int64 {
+	return max(0, min(appRetainHeight-p.companionSafetyMargin, dcRetainHeight))
+}
+
+// logStoredRetainHeightError logs an error returned when reading a block
+// retain height from the database. Blocks are not pruned in that case.
+func (p *Pruner) logStoredRetainHeightError(which string, err error) {
+	if errors.Is(err, ErrInvalidHeightValue) {
+		p.logger.Error("Stored retain height is invalid, skipping pruning", "which", which, "err", err)
+		return
+	}
+	p.logger.Error("Unexpected error fetching retain height", "which", which, "err", err)
+}
+
+// downgradeRetainHeightAboveTip lowers a retain height read from the database
+// to the height of the block store, and persists it, if it is above it. This
+// happens if the node was rolled back after the retain height was set. Without
+// the downgrade, no retain height at or below the new tip could be set until
+// the chain grows past the old retain height again, since retain heights can
+// never be lowered. It returns the downgraded retain height. Retain heights are
+// left alone while the block store is empty, e.g. before state sync.
+func (p *Pruner) downgradeRetainHeightAboveTip(
+	which string,
+	height int64,
+	get func() (int64, error),
+	save func(int64) error,
+) int64 {
+	if p.bs.Height() == 0 || height <= p.bs.Height() {
+		return height
+	}
+	// Serialize with the setters, and make sure that the retain height was not
+	// raised in the meantime.
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	height, err := get()
+	if err != nil {
+		p.logger.Error("Unexpected error fetching retain height", "which", which, "err", err)
+		return 0
+	}
+	tip := p.bs.Height()
+	if height <= tip {
+		return height
+	}
+	if err := save(tip); err != nil {
+		p.logger.Error("Failed to downgrade retain height above the block store height",
+			"which", which, "retainHeight", height, "height", tip, "err", err)
+		return height
+	}
+	p.logger.Error("Downgraded a retain height above the block store height, probably due to a rollback",
+		"which", which, "retainHeight", height, "newRetainHeight", tip)
+	return tip
+}
+
+// downgradeRetainHeightsAboveTip downgrades the stored retain heights that are
+// above the height of the block store, see downgradeRetainHeightAboveTip, as
+// soon as the pruner starts, e.g. after the node was rolled back, rather than
+// once they are used.
+func (p *Pruner) downgradeRetainHeightsAboveTip() {
+	retainHeights := []struct {
+		which string
+		get   func() (int64, error)
+		save  func(int64) error
+	}{
+		{"application block", p.stateStore.GetApplicationRetainHeight, p.stateStore.SaveApplicationRetainHeight},
+		{"companion block", p.stateStore.GetCompanionBlockRetainHeight, p.stateStore.SaveCompanionBlockRetainHeight},
+		{"ABCI results", p.stateStore.GetABCIResRetainHeight, p.stateStore.SaveABCIResRetainHeight},
+	}
+	for _, rh := range retainHeights {
+		// Errors are reported when the retain heights are used.
+		if height, err := rh.get(); err == nil {
+			p.downgradeRetainHeightAboveTip(rh.which, height, rh.get, rh.save)
+		}
+	}
+	heights, err := p.stateStore.GetNamedCompanionBlockRetainHeights()
+	if err != nil {
+		return
+	}
+	for name, height := range heights {
+		get := func() (int64, error) {
+			heights, err := p.stateStore.GetNamedCompanionBlockRetainHeights()
+			return heights[name], err
+		}
+		save := func(height int64) error {
+			return p.stateStore.SaveNamedCompanionBlockRetainHeight(name, height)
+		}
+		p.downgradeRetainHeightAboveTip("companion block "+name, height, get, save)
+	}
+}
+
+// storedRetainHeight is a retain height stored in the state store, described
+// by which, along with the gauge reporting it.
+type storedRetainHeight struct {
+	which string
+	get   func() (int64, error)
+	save  func(int64) error
+	gauge metrics.Gauge
+}
+
+// raiseRetainHeightsBelowBase raises the stored block retain heights that are
+// missing or below the base of the block store to the base, as soon as the
+// pruner starts. The blocks below the base have already been pruned, so such
+// retain heights can only be left over from a migration of the state store,
+// e.g. to another database backend, which lost or reset them. The data
+// companion retain heights are only raised if it is enabled, and the ABCI
+// results retain height only if the ABCI results are coupled to the blocks
+// (see WithPrunerCoupleABCIToBlocks), as they may otherwise be kept longer than
+// the blocks.
+func (p *Pruner) raiseRetainHeightsBelowBase() {
+	base := p.bs.Base()
+	if base <= 1 {
+		return
+	}
+	retainHeights := []storedRetainHeight{
+		{"application block", p.stateStore.GetApplicationRetainHeight, p.stateStore.SaveApplicationRetainHeight, p.metrics.ApplicationBlockRetainHeight},
+	}
+	if p.dcEnabled {
+		retainHeights = append(retainHeights, storedRetainHeight{
+			"companion block", p.stateStore.GetCompanionBlockRetainHeight, p.stateStore.SaveCompanionBlockRetainHeight, p.metrics.PruningServiceBlockRetainHeight,
+		})
+		if p.coupleABCIToBlocks {
+			retainHeights = append(retainHeights, storedRetainHeight{
+				"ABCI results", p.stateStore.GetABCIResRetainHeight, p.stateStore.SaveABCIResRetainHeight, p.metrics.PruningServiceBlockResultsRetainHeight,
+			})
+		}
+	}
+
+	// Serialize with the setters.
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for _, rh := range retainHeights {
+		height, err := rh.get()
+		if err != nil && !errors.Is(err, ErrKeyNotFound) {
+			// Reported when the retain height is used.
+			continue
+		}
+		if height >= base {
+			continue
+		}
+		if err := rh.save(base); err != nil {
+			p.logger.Error("Failed to raise retain height below the block store base",
+				"which", rh.which, "retainHeight", height, "base", base, "err", err)
+			continue
+		}
+		rh.gauge.Set(float64(base))
+		p.logger.Error("Raised a retain height below the block store base, probably due to a migration of the state store",
+			"which", rh.which, "retainHeight", height, "newRetainHeight", base)
+	}
+}
+
+// checkStoredRetainHeight logs a warning if a retain height read from the
+// database can never have been accepted by the pruner, which indicates that the
+// database was corrupted or tampered with.
+func (p *Pruner) checkStoredRetainHeight(which string, height int64) {
+	if height < 0 || height > p.bs.Height() {
+		p.logger.Error("Stored retain height is out of range, clamping it to the block store's heights",
+			"which", which, "retainHeight", height, "base", p.bs.Base(), "height", p.bs.Height())
+	}
+}
+
+// clampToBlockStore clamps height to [p.bs.Base(), p.bs.Height()]. A height
+// of 0, indicating that no retain height has been set, is returned as is.
+func (p *Pruner) clampToBlockStore(height int64) int64 {
+	if height == 0 {
+		return 0
+	}
+	if height > p.bs.Height() {
+		height = p.bs.Height()
 	}
-	return dcRetainHeight
+	if height < p.bs.Base() {
+		height = p.bs.Base()
+	}
+	return height
 }
 
-func (p *Pruner) pruneBlocksToHeight(height int64) (uint64, int64, error) {
+// pruneBlocksToHeight prunes the blocks below height, along with their states
+// unless WithAsyncStatePruning is enabled. It returns the number of blocks
+// pruned, the evidence retain height, and information about the states pruned,
+// if any.
+func (p *Pruner) pruneBlocksToHeight(height int64) (uint64, int64, *StatesPrunedInfo, error) {
 	if height <= 0 {
-		return 0, 0, ErrInvalidRetainHeight
+		return 0, 0, nil, ErrInvalidRetainHeight
 	}
 
 	base := p.bs.Base()
 
 	state, err := p.stateStore.Load()
 	if err != nil {
-		return 0, 0, ErrPrunerFailedToLoadState{Err: err}
+		return 0, 0, nil, ErrPrunerFailedToLoadState{Err: err}
+	}
+	if p.verifyBeforePrune {
+		if err := p.checkNoHole(base, height); err != nil {
+			return 0, 0, nil, err
+		}
+	}
+	if p.prefetch {
+		p.prefetchBlocks(base, height)
+	}
+	// Record the range before deleting it, so that the block store and the
+	// state store converge even if the node crashes midway, as the block store
+	// doesn't delete the blocks atomically.
+	journal := PruneJournalEntry{FromHeight: base, ToHeight: height}
+	if base < height {
+		if err := p.stateStore.SavePruneJournal(journal); err != nil {
+			err = fmt.Errorf("failed to save the prune journal: %w", err)
+			return 0, 0, nil, ErrFailedToPruneBlocks{Height: height, Err: err}
+		}
+	}
+	start := time.Now()
+	var (
+		pruned         uint64
+		evRetainHeight int64
+	)
+	err = p.retryTransient("blocks", func() error {
+		var err error
+		pruned, evRetainHeight, err = p.strategy.PruneRange(p.bs.Base(), height, p.evidenceRetentionState(state))
+		return err
+	})
+	addTimeSample(p.metrics.PruningDurationSeconds.With("phase", "blocks"), start)()
+	if err != nil {
+		return 0, 0, nil, ErrFailedToPruneBlocks{Height: height, Err: err}
+	}
+	p.logger.Debug("Deleted the blocks to prune", "from", base, "to", height-1, "duration", time.Since(start),
+		"prefetch", p.prefetch)
+	if pruned == 0 {
+		p.deletePruneJournal()
+		return 0, evRetainHeight, nil, nil
+	}
+	newBase := p.bs.Base()
+	p.reportBlocksPruned(base, newBase)
+	p.publishBaseAdvanced(base, newBase)
+	// The state pruning routine only runs while the pruner does, e.g. not when
+	// pruning with PruneOnce.
+	if p.asyncStatePruning && p.IsRunning() {
+		// The states pruned asynchronously are not journaled.
+		p.deletePruneJournal()
+		p.enqueueStatePruning(statePruneTarget{base: base, height: height, evRetainHeight: evRetainHeight})
+		return pruned, evRetainHeight, nil, nil
+	}
+	journal.EvidenceRetainHeight = evRetainHeight
+	if err := p.stateStore.SavePruneJournal(journal); err != nil {
+		// If the states are not pruned before a crash, the blocks are pruned
+		// again on restart to find the evidence retain height.
+		p.logger.Error("Failed to save the evidence retain height in the prune journal", "err", err)
+	}
+	count, err := p.pruneStates(base, height, evRetainHeight, p.Quit())
+	statesInfo := newStatesPrunedInfo(base, height, count)
+	p.observer.PrunerPrunedStates(statesInfo, err)
+	if err != nil {
+		return 0, 0, statesInfo, ErrFailedToPruneStates{Height: height, Err: err}
+	}
+	p.deletePruneJournal()
+	return pruned, evRetainHeight, statesInfo, nil
+}
+
+// deletePruneJournal deletes the range of blocks recorded in the prune
+// journal once they are pruned, with their states.
+func (p *Pruner) deletePruneJournal() {
+	if err := p.stateStore.DeletePruneJournal(); err != nil {
+		p.logger.Error("Failed to delete the prune journal", "err", err)
+	}
+}
+
+// completeInterruptedPrune completes the prune recorded in the prune journal,
+// if the node crashed before it completed, by pruning the blocks left in its
+// range, if any, and their states. If the blocks were partly pruned, their
+// evidence retain height can't be known, so the validator sets of the whole
+// range are kept. Failing to complete it is logged, and it is completed at the
+// next start.
+func (p *Pruner) completeInterruptedPrune() {
+	journal, err := p.stateStore.GetPruneJournal()
+	if errors.Is(err, ErrKeyNotFound) {
+		return
 	}
-	pruned, evRetainHeight, err := p.bs.PruneBlocks(height, state)
 	if err != nil {
-		return 0, 0, ErrFailedToPruneBlocks{Height: height, Err: err}
+		p.logger.Error("Failed to read the prune journal", "err", err)
+		return
+	}
+	p.logger.Info("Completing an interrupted prune", "from", journal.FromHeight, "to", journal.ToHeight-1)
+	evRetainHeight := journal.EvidenceRetainHeight
+	if evRetainHeight <= 0 {
+		state, err := p.stateStore.Load()
+		if err != nil {
+			p.logger.Error("Failed to complete the interrupted prune", "err", ErrPrunerFailedToLoadState{Err: err})
+			return
+		}
+		base := p.bs.Base()
+		if base < journal.ToHeight {
+			_, evRetainHeight, err = p.strategy.PruneRange(base, journal.ToHeight, p.evidenceRetentionState(state))
+			if err != nil {
+				p.logger.Error("Failed to complete the interrupted prune",
+					"err", ErrFailedToPruneBlocks{Height: journal.ToHeight, Err: err})
+				return
+			}
+			newBase := p.bs.Base()
+			p.reportBlocksPruned(base, newBase)
+			p.publishBaseAdvanced(base, newBase)
+		}
+		if base > journal.FromHeight || evRetainHeight <= 0 {
+			evRetainHeight = journal.FromHeight
+		}
 	}
-	if pruned > 0 {
-		prunedStates, err := p.stateStore.PruneStates(base, height, evRetainHeight, p.prunedStates)
-		p.prunedStates += prunedStates
+	if journal.FromHeight < journal.ToHeight {
+		count, err := p.pruneStates(journal.FromHeight, journal.ToHeight, evRetainHeight, p.Quit())
+		p.observer.PrunerPrunedStates(newStatesPrunedInfo(journal.FromHeight, journal.ToHeight, count), err)
 		if err != nil {
-			return 0, 0, ErrFailedToPruneStates{Height: height, Err: err}
+			p.logger.Error("Failed to complete the interrupted prune",
+				"err", ErrFailedToPruneStates{Height: journal.ToHeight, Err: err})
+			return
+		}
+	}
+	p.deletePruneJournal()
+}
+
+// reportBlocksPruned notifies the observer that the blocks from base to
+// newBase, excluded, were pruned, see PrunerPrunedBlockRange and
+// PrunerBlockPruned.
+func (p *Pruner) reportBlocksPruned(base, newBase int64) {
+	p.observer.PrunerPrunedBlockRange(base, newBase-1)
+	if !p.perHeightCallbacks {
+		return
+	}
+	for height := base; height < newBase; height++ {
+		p.observer.PrunerBlockPruned(height)
+	}
+}
+
+// retryTransient calls f, and calls it again as long as it fails with a
+// transient error, up to the number of retries set with
+// WithPrunerTransientErrorRetries, waiting between retries. It returns the
+// error of the last call, without waiting for the next retry if the pruner
+// stops.
+func (p *Pruner) retryTransient(what string, f func() error) error {
+	backoff := p.transientRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := checkReadOnly(f())
+		if err == nil || attempt == p.transientRetries || !p.isTransient(err) {
+			return err
+		}
+		p.logPruneError("Failed to prune with a transient error, retrying", err, "what", what, "attempt", attempt+1)
+		select {
+		case <-p.clock.After(backoff):
+		case <-p.Quit():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// transientErrors are the errors always retried by retryTransient: besides
+// ErrTransientStoreError and ErrStoreReadOnly, the system errors reported when
+// a file is temporarily locked or busy, or when a call is interrupted.
+var transientErrors = []error{
+	ErrTransientStoreError, ErrStoreReadOnly, syscall.EAGAIN, syscall.EBUSY, syscall.EINTR,
+}
+
+// logPruneError logs that pruning failed with err, as an error, unless the
+// store is temporarily read-only, which is expected during a backup or a
+// snapshot and retried later, in which case it is logged as information.
+func (p *Pruner) logPruneError(msg string, err error, keyvals ...any) {
+	keyvals = append(keyvals, "err", err)
+	if errors.Is(err, ErrStoreReadOnly) {
+		p.logger.Info(msg+", as the store is temporarily read-only", keyvals...)
+		return
+	}
+	p.logger.Error(msg, keyvals...)
+}
+
+// isTransient returns true if err is one of the transient errors retried by
+// retryTransient.
+func (p *Pruner) isTransient(err error) bool {
+	isErr := func(transient error) bool { return errors.Is(err, transient) }
+	return slices.ContainsFunc(transientErrors, isErr) || slices.ContainsFunc(p.transientErrors, isErr)
+}
+
+// prefetchBlocks reads the metas of the blocks from base up to height,
+// excluded, in a separate routine, so that they are in the caches of the
+// database when they are deleted, and waits for it to finish. It stops reading
+// early if the pruner is stopped.
+func (p *Pruner) prefetchBlocks(base, height int64) {
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for h := base; h < height; h++ {
+			select {
+			case <-p.Quit():
+				return
+			default:
+			}
+			p.bs.LoadBlockMeta(h)
+		}
+	}()
+	<-done
+	p.logger.Debug("Prefetched the blocks to prune", "from", base, "to", height-1, "duration", time.Since(start))
+}
+
+// checkNoHole returns ErrBlockStoreHole if the meta of a block from base up to
+// height, included, is missing from the block store.
+func (p *Pruner) checkNoHole(base, height int64) error {
+	for h := base; h <= height; h++ {
+		if p.bs.LoadBlockMeta(h) == nil {
+			return ErrBlockStoreHole{Height: h, Base: base, RetainHeight: height}
+		}
+	}
+	return nil
+}
+
+// evidenceRetentionState returns state with its evidence parameters extended
+// to keep the evidence data of at least the last evidenceMaxAgeBlocks blocks,
+// so that the block store, and then the state store, compute the evidence
+// retain height accordingly. Evidence data is kept until it is older than both
+// the maximum age in blocks and the maximum age duration, so extending the
+// former is enough.
+func (p *Pruner) evidenceRetentionState(state State) State {
+	if p.evidenceMaxAgeBlocks > state.ConsensusParams.Evidence.MaxAgeNumBlocks {
+		state.ConsensusParams.Evidence.MaxAgeNumBlocks = p.evidenceMaxAgeBlocks
+	}
+	return state
+}
+
+func newStatesPrunedInfo(base, height int64, count PrunedStatesCount) *StatesPrunedInfo {
+	return &StatesPrunedInfo{
+		FromHeight:      base,
+		ToHeight:        height - 1,
+		ValidatorSets:   count.ValidatorSets,
+		ConsensusParams: count.ConsensusParams,
+	}
+}
+
+// statePruneTarget is a range of states to prune, [base, height).
+type statePruneTarget struct {
+	base           int64
+	height         int64
+	evRetainHeight int64
+}
+
+// enqueueStatePruning makes the state pruning routine prune the given range of
+// states, along with the range still pending, if any. Only the latest target
+// height matters, as the ranges are contiguous.
+func (p *Pruner) enqueueStatePruning(target statePruneTarget) {
+	p.statePruneMtx.Lock()
+	if pending := p.statePruneTarget; pending != nil {
+		target.base = min(target.base, pending.base)
+	}
+	p.statePruneTarget = &target
+	p.statePruneMtx.Unlock()
+
+	select {
+	case p.statePruneSignal <- struct{}{}:
+	default:
+		// The routine is already signaled.
+	}
+}
+
+// dequeueStatePruning returns the pending range of states to prune, or nil if
+// there is none.
+func (p *Pruner) dequeueStatePruning() *statePruneTarget {
+	p.statePruneMtx.Lock()
+	defer p.statePruneMtx.Unlock()
+	target := p.statePruneTarget
+	p.statePruneTarget = nil
+	return target
+}
+
+// pruneStatesRoutine prunes the ranges of states enqueued by the routine
+// pruning the blocks, if WithAsyncStatePruning is enabled. Once stopped, it
+// prunes the pending range, if any, without retrying.
+func (p *Pruner) pruneStatesRoutine() {
+	defer close(p.statePruneDone)
+	for {
+		select {
+		case <-p.statePruneSignal:
+			p.pruneStatesTarget(p.statePruneStop)
+		case <-p.statePruneStop:
+			p.pruneStatesTarget(p.statePruneStop)
+			return
+		}
+	}
+}
+
+func (p *Pruner) pruneStatesTarget(quit <-chan struct{}) {
+	target := p.dequeueStatePruning()
+	if target == nil {
+		return
+	}
+	count, err := p.pruneStates(target.base, target.height, target.evRetainHeight, quit)
+	if err != nil {
+		p.logger.Error("Failed to prune states", "height", target.height, "err", err)
+	}
+	statesInfo := newStatesPrunedInfo(target.base, target.height, count)
+	p.observer.PrunerPrunedStates(statesInfo, err)
+	p.audit(&PrunedInfo{States: statesInfo}, err)
+}
+
+// pruneStates prunes the states in [base, height), retrying as configured by
+// WithPrunerStatePruningRetries if it fails, since the corresponding blocks
+// have already been pruned and won't be pruned again. Retrying is safe, as
+// pruning states that have already been pruned is a no-op. It stops retrying
+// once quit is closed. It returns what the last attempt pruned.
+func (p *Pruner) pruneStates(base, height, evRetainHeight int64, quit <-chan struct{}) (PrunedStatesCount, error) {
+	backoff := p.statePruningRetryBackoff
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		count, err := p.stateStore.PruneStates(base, height, evRetainHeight, p.prunedStates)
+		addTimeSample(p.metrics.PruningDurationSeconds.With("phase", "states"), start)()
+		p.prunedStates += count.Heights
+		if err == nil || attempt == p.statePruningRetries {
+			return count, err
+		}
+		p.logger.Error("Failed to prune states, retrying", "height", height, "attempt", attempt+1, "err", err)
+		select {
+		case <-p.clock.After(backoff):
+		case <-quit:
+			return count, err
 		}
+		backoff *= 2
 	}
-	return pruned, evRetainHeight, err
 }
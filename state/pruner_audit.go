@@ -0,0 +1,64 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// pruneAuditRecord is a line of the audit log set by WithPrunerAuditWriter,
+// recording a pruning action.
+type pruneAuditRecord struct {
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+	*PrunedInfo
+	Error string `json:"error,omitempty"`
+}
+
+// audit writes a record of a pruning action, described by info and err, to the
+// audit writer, if any, and flushes it. Failing to write the record is logged,
+// but doesn't fail pruning, as the data has been pruned already.
+func (p *Pruner) audit(info *PrunedInfo, err error) {
+	if p.auditWriter == nil {
+		return
+	}
+	p.auditMtx.Lock()
+	defer p.auditMtx.Unlock()
+	p.auditSeq++
+	record := pruneAuditRecord{Seq: p.auditSeq, Time: time.Now().UTC(), PrunedInfo: info}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if err := writeAuditRecord(p.auditWriter, record); err != nil {
+		p.logger.Error("Failed to write pruning audit record", "seq", record.Seq, "err", err)
+	}
+}
+
+func writeAuditRecord(w io.Writer, record pruneAuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	// Flush the record, so that it survives a crash, e.g. for a *bufio.Writer,
+	// and sync it to disk for an *os.File.
+	if f, ok := w.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			return err
+		}
+	}
+	if s, ok := w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// pruningDidFinish reports the end of a pruning action to the observer, and
+// records it in the audit log.
+func (p *Pruner) pruningDidFinish(info *PrunedInfo, err error) {
+	p.observer.PruningDidFinish(info, err)
+	p.audit(info, err)
+}
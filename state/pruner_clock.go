@@ -0,0 +1,25 @@
+package state
+
+import (
+	"time"
+)
+
+// PrunerClock abstracts the waits of the [Pruner], i.e. the intervals between
+// the cycles of its background routines and the backoff between retries of
+// pruning the state, so that tests can drive an exact number of cycles without
+// waiting in real time.
+type PrunerClock interface {
+	// After returns a channel that receives the current time once d has
+	// elapsed, like time.After.
+	After(d time.Duration) <-chan time.Time
+}
+
+// realPrunerClock is the PrunerClock used by default, which waits in real
+// time.
+type realPrunerClock struct{}
+
+var _ PrunerClock = realPrunerClock{}
+
+func (realPrunerClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
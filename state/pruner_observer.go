@@ -6,34 +6,282 @@ import (
 
 // PrunerObserver allows an external observer to be notified of certain events
 // generated by the [Pruner].
+//
+// An observer can also implement any of PrunerPassObserver,
+// PrunerHeartbeatObserver, PrunerVetoObserver, PrunerNearTipObserver,
+// PrunerStatesObserver, PrunerTargetObserver, PrunerBlockRangeObserver,
+// PrunerIndexerObserver and PrunerPauseObserver to be notified of more events.
+// They are detected when the observer is set, with WithPrunerObserver or
+// Pruner.SetObserver.
 type PrunerObserver interface {
 	// PrunerStarted is called when the pruner's background pruning routine has
 	// been started.
 	PrunerStarted(interval time.Duration)
-	// PrunerPrunedABCIRes is called after each successful pruning of ABCI results.
+	// PrunerPrunedABCIRes is called after each successful pruning of ABCI
+	// results. It is not called for runs of the pruner that prune nothing.
 	PrunerPrunedABCIRes(prunedInfo *ABCIResponsesPrunedInfo)
-	// PrunerPrunedBlocks is called after each successful pruning of blocks.
+	// PrunerPrunedBlocks is called after each successful pruning of blocks. It
+	// is not called for runs of the pruner that prune nothing.
 	PrunerPrunedBlocks(prunedInfo *BlocksPrunedInfo)
 }
 
+// PrunerPassObserver is implemented by the observers of the [Pruner] to be
+// notified before and after each pass pruning blocks or ABCI results.
+type PrunerPassObserver interface {
+	// PruningWillStart is called right before a pass of the pruner deletes
+	// blocks or ABCI results, i.e. only for passes with a new target retain
+	// height, once the target height has been determined. Pruning does not
+	// start until PruningWillStart returns.
+	//
+	// Blocks and ABCI results are pruned by separate routines, so calls for
+	// the two kinds of passes may be made concurrently. Each call is followed
+	// by exactly one call to PruningDidFinish, from the same routine.
+	//
+	// Neither PruningWillStart nor PruningDidFinish is called while the
+	// pruner's mutex is held, so they may call the [Pruner]'s methods, e.g. to
+	// set retain heights. Retain heights set from PruningWillStart are only
+	// taken into account by the next pass.
+	PruningWillStart(targetHeight int64)
+	// PruningDidFinish is called after each pass of the pruner started by
+	// PruningWillStart, whether it succeeded or not. If the pass failed, err
+	// is set, and info only describes what was pruned before the failure.
+	PruningDidFinish(info *PrunedInfo, err error)
+}
+
+// PrunerHeartbeatObserver is implemented by the observers of the [Pruner] to
+// be notified that it is alive.
+type PrunerHeartbeatObserver interface {
+	// PrunerHeartbeat is called after every run of the pruner's block pruning
+	// routine, whether or not anything was pruned, to let the observer know
+	// that the pruner is alive.
+	PrunerHeartbeat()
+}
+
+// PrunerVetoObserver is implemented by the observers of the [Pruner] that can
+// veto its pruning passes.
+type PrunerVetoObserver interface {
+	// ShouldPrune is called at the start of each cycle of the pruner's
+	// background routines. If it returns false, the cycle is skipped, and
+	// pruning is retried at the next interval. This allows an observer to
+	// temporarily pause pruning, e.g. while a snapshot or a backup is in
+	// progress, without stopping the pruner.
+	//
+	// As the routines run concurrently, ShouldPrune may be called
+	// concurrently. It is not consulted by the pruner's methods that prune
+	// synchronously, such as PruneIndexesNow.
+	ShouldPrune() bool
+}
+
+// PrunerNearTipObserver is implemented by the observers of the [Pruner] to be
+// notified of block retain heights close to the tip of the block store.
+type PrunerNearTipObserver interface {
+	// PrunerRetainHeightNearTip is called when the pruner accepts a block
+	// retain height close to the tip of the block store, as configured with
+	// WithPrunerNearTipWarnThreshold. It is called by the setter of the retain
+	// height while the pruner's mutex is held, so it must not call the
+	// [Pruner]'s setters.
+	PrunerRetainHeightNearTip(info *RetainHeightNearTipInfo)
+}
+
+// PrunerStatesObserver is implemented by the observers of the [Pruner] to be
+// notified of the pruning of the states.
+type PrunerStatesObserver interface {
+	// PrunerPrunedStates is called after the states corresponding to pruned
+	// blocks are pruned, whether it succeeded or not. If WithAsyncStatePruning
+	// is enabled, it is called by the routine pruning the states, possibly
+	// concurrently with the other methods, and once for coalesced ranges.
+	PrunerPrunedStates(info *StatesPrunedInfo, err error)
+}
+
+// PrunerTargetObserver is implemented by the observers of the [Pruner] to be
+// notified of the retain heights targeted by each cycle.
+type PrunerTargetObserver interface {
+	// TargetComputed is called once per cycle of the pruner's block pruning
+	// routine, or of the routine running the phases set by
+	// WithPrunerPhaseOrder, with the retain heights the cycle targets, before
+	// anything is pruned. It is called even if the targets didn't change, so
+	// that they can be compared with what was actually pruned. A target is 0
+	// if it has not been set, or if what it applies to is not pruned.
+	TargetComputed(blockTarget, abciTarget, indexerTarget int64)
+}
+
+// PrunerBlockRangeObserver is implemented by the observers of the [Pruner] to
+// be notified of the exact ranges of blocks deleted from the block store.
+type PrunerBlockRangeObserver interface {
+	// PrunerPrunedBlockRange is called each time the blocks from fromHeight to
+	// toHeight, inclusive, are deleted from the block store, so that data kept
+	// outside of CometBFT for these heights can be cleaned up alongside them.
+	// Unlike PrunerPrunedBlocks, it is called for every deletion, including by
+	// PruneToHeight, with the exact range of deleted heights.
+	//
+	// It is called synchronously by the routine pruning the blocks, once the
+	// deletion has been written to the database, so the blocks can no longer
+	// be loaded, and before the states of these heights are pruned. Pruning
+	// doesn't continue until it returns. Ranges are reported in increasing
+	// order of heights, and each height only once: if the node stops between
+	// the deletion and the call, the range is not reported again, so data
+	// kept outside of CometBFT below the base of the block store should be
+	// cleaned up on start.
+	PrunerPrunedBlockRange(fromHeight, toHeight int64)
+	// PrunerBlockPruned is called for each height of the ranges reported by
+	// PrunerPrunedBlockRange, in increasing order, right after it, if enabled
+	// with WithPrunerPerHeightCallbacks. The same ordering guarantees apply.
+	PrunerBlockPruned(height int64)
+}
+
+// PrunerIndexerObserver is implemented by the observers of the [Pruner] to be
+// notified of the pruning of the indexers.
+type PrunerIndexerObserver interface {
+	// IndexerPruned is called after each pruning of the tx and block
+	// indexers, including by PruneIndexesNow, that pruned anything or failed.
+	// retainHeight is the height below which both indexers are pruned, and
+	// numPruned the number of heights pruned from the tx indexer plus those
+	// pruned from the block indexer. If pruning either indexer failed, err is
+	// set, and numPruned only counts what was pruned before the failure.
+	IndexerPruned(retainHeight, numPruned int64, err error)
+}
+
+// PrunerPauseObserver is implemented by the observers of the [Pruner] to be
+// notified when it is paused and resumed.
+type PrunerPauseObserver interface {
+	// PrunerPauseChanged is called when the background routines of the pruner
+	// are paused by Pruner.Pause, with paused set, or resumed by
+	// Pruner.Resume. It is not called if they already were.
+	PrunerPauseChanged(paused bool)
+}
+
+// prunerObservers holds the observer of the pruner, along with the optional
+// interfaces it implements, or NoopPrunerObserver for those it doesn't.
+type prunerObservers struct {
+	PrunerObserver
+	PrunerPassObserver
+	PrunerHeartbeatObserver
+	PrunerVetoObserver
+	PrunerNearTipObserver
+	PrunerStatesObserver
+	PrunerTargetObserver
+	PrunerBlockRangeObserver
+	PrunerIndexerObserver
+	PrunerPauseObserver
+}
+
+func newPrunerObservers(obs PrunerObserver) prunerObservers {
+	return prunerObservers{
+		PrunerObserver:           obs,
+		PrunerPassObserver:       optionalObserver[PrunerPassObserver](obs),
+		PrunerHeartbeatObserver:  optionalObserver[PrunerHeartbeatObserver](obs),
+		PrunerVetoObserver:       optionalObserver[PrunerVetoObserver](obs),
+		PrunerNearTipObserver:    optionalObserver[PrunerNearTipObserver](obs),
+		PrunerStatesObserver:     optionalObserver[PrunerStatesObserver](obs),
+		PrunerTargetObserver:     optionalObserver[PrunerTargetObserver](obs),
+		PrunerBlockRangeObserver: optionalObserver[PrunerBlockRangeObserver](obs),
+		PrunerIndexerObserver:    optionalObserver[PrunerIndexerObserver](obs),
+		PrunerPauseObserver:      optionalObserver[PrunerPauseObserver](obs),
+	}
+}
+
+// optionalObserver returns obs if it implements T, or NoopPrunerObserver
+// otherwise.
+func optionalObserver[T any](obs PrunerObserver) T {
+	if o, ok := obs.(T); ok {
+		return o
+	}
+	var noop any = NoopPrunerObserver{}
+	return noop.(T)
+}
+
+// StatesPrunedInfo provides information about the states pruned after a run of
+// the pruner, reported by PrunerPrunedStates.
+type StatesPrunedInfo struct {
+	FromHeight int64 `json:"from_height"` // The height from which states were pruned (inclusive).
+	ToHeight   int64 `json:"to_height"`   // The height to which states were pruned (inclusive).
+	// The number of validator sets and consensus params deleted. The ones
+	// still needed to load the validator sets and consensus params of the
+	// remaining heights, or to verify evidence, are kept.
+	ValidatorSets   uint64 `json:"validator_sets"`
+	ConsensusParams uint64 `json:"consensus_params"`
+}
+
+// RetainHeightNearTipInfo describes a block retain height accepted close to
+// the tip of the block store, reported by PrunerRetainHeightNearTip.
+type RetainHeightNearTipInfo struct {
+	Which        string // The retain height that was set: "application block" or "companion block".
+	RetainHeight int64  // The retain height that was set.
+	TipHeight    int64  // The height of the block store when it was set.
+}
+
+// PrunedInfo provides information about a single pass of the pruner, reported
+// by PruningDidFinish. Exactly one of Blocks and ABCIResponses is set,
+// depending on what the pass pruned. States is set along with Blocks if the
+// pass pruned the states of the pruned blocks, i.e. if any were pruned and
+// WithAsyncStatePruning is disabled.
+//
+// PruneOnce returns a PrunedInfo for all it pruned, in which case none, either
+// or both of Blocks and ABCIResponses are set, and States is set along with
+// Blocks. The FromHeight of ABCIResponses is then 0, as the height from which
+// they are pruned is not known.
+type PrunedInfo struct {
+	Blocks        *BlocksPrunedInfo        `json:"blocks,omitempty"`
+	ABCIResponses *ABCIResponsesPrunedInfo `json:"abci_responses,omitempty"`
+	States        *StatesPrunedInfo        `json:"states,omitempty"`
+	// The number of orphaned block parts removed below the new base of the
+	// block store, i.e. parts left behind that belong to no block.
+	OrphanedBlockParts uint64 `json:"orphaned_block_parts,omitempty"`
+	// The number of heights below the new base of the block store whose
+	// header and commit were kept, as evidence of misbehavior at these heights
+	// could still be submitted.
+	EvidencePinnedHeights int64 `json:"evidence_pinned_heights,omitempty"`
+	// What was pruned from the indexers, only reported by PruneOnce.
+	Indexer *IndexerPrunedInfo `json:"indexer,omitempty"`
+}
+
 // BlocksPrunedInfo provides information about blocks pruned during a single
 // run of the pruner.
 type BlocksPrunedInfo struct {
-	FromHeight int64 // The height from which blocks were pruned (inclusive).
-	ToHeight   int64 // The height to which blocks were pruned (inclusive).
+	FromHeight int64 `json:"from_height"` // The height from which blocks were pruned (inclusive).
+	ToHeight   int64 `json:"to_height"`   // The height to which blocks were pruned (inclusive).
+	// The number of heights still to be pruned to reach the target retain
+	// height. Zero when pruning has caught up with the target.
+	RemainingHeights int64 `json:"remaining_heights"`
 }
 
 // ABCIResponsesPrunedInfo provides information about ABCI responses pruned
 // during a single run of the pruner.
 type ABCIResponsesPrunedInfo struct {
-	FromHeight int64 // The height from which ABCI responses were pruned (inclusive).
-	ToHeight   int64 // The height to which ABCI responses were pruned (inclusive).
+	FromHeight int64 `json:"from_height"` // The height from which ABCI responses were pruned (inclusive).
+	ToHeight   int64 `json:"to_height"`   // The height to which ABCI responses were pruned (inclusive).
+	// The number of heights still to be pruned to reach the target retain
+	// height. Zero when pruning has caught up with the target.
+	RemainingHeights int64 `json:"remaining_heights"`
+	// Whether the run only pruned a step catching up with the target retain
+	// height, as limited by WithPrunerABCIResCatchUp.
+	CatchingUp bool `json:"catching_up"`
+}
+
+// IndexerPrunedInfo provides information about the indexers pruned during a
+// single run of the pruner.
+type IndexerPrunedInfo struct {
+	// The height below which both the tx and the block indexers are pruned.
+	RetainHeight        int64 `json:"retain_height"`
+	TxIndexerHeights    int64 `json:"tx_indexer_heights"`    // The number of heights pruned from the tx indexer.
+	BlockIndexerHeights int64 `json:"block_indexer_heights"` // The number of heights pruned from the block indexer.
 }
 
 // NoopPrunerObserver does nothing.
 type NoopPrunerObserver struct{}
 
-var _ PrunerObserver = NoopPrunerObserver{}
+var (
+	_ PrunerObserver           = NoopPrunerObserver{}
+	_ PrunerPassObserver       = NoopPrunerObserver{}
+	_ PrunerHeartbeatObserver  = NoopPrunerObserver{}
+	_ PrunerVetoObserver       = NoopPrunerObserver{}
+	_ PrunerNearTipObserver    = NoopPrunerObserver{}
+	_ PrunerStatesObserver     = NoopPrunerObserver{}
+	_ PrunerTargetObserver     = NoopPrunerObserver{}
+	_ PrunerBlockRangeObserver = NoopPrunerObserver{}
+	_ PrunerIndexerObserver    = NoopPrunerObserver{}
+	_ PrunerPauseObserver      = NoopPrunerObserver{}
+)
 
 // PrunerPrunedABCIRes implements PrunerObserver.
 func (NoopPrunerObserver) PrunerPrunedABCIRes(*ABCIResponsesPrunedInfo) {}
@@ -41,5 +289,38 @@ func (NoopPrunerObserver) PrunerPrunedABCIRes(*ABCIResponsesPrunedInfo) {}
 // PrunerPrunedBlocks implements PrunerObserver.
 func (NoopPrunerObserver) PrunerPrunedBlocks(*BlocksPrunedInfo) {}
 
+// PrunerPrunedBlockRange implements PrunerBlockRangeObserver.
+func (NoopPrunerObserver) PrunerPrunedBlockRange(int64, int64) {}
+
+// PrunerBlockPruned implements PrunerBlockRangeObserver.
+func (NoopPrunerObserver) PrunerBlockPruned(int64) {}
+
 // PrunerStarted implements PrunerObserver.
 func (NoopPrunerObserver) PrunerStarted(time.Duration) {}
+
+// PrunerHeartbeat implements PrunerHeartbeatObserver.
+func (NoopPrunerObserver) PrunerHeartbeat() {}
+
+// ShouldPrune implements PrunerVetoObserver. It always returns true.
+func (NoopPrunerObserver) ShouldPrune() bool { return true }
+
+// PruningWillStart implements PrunerPassObserver.
+func (NoopPrunerObserver) PruningWillStart(int64) {}
+
+// PruningDidFinish implements PrunerPassObserver.
+func (NoopPrunerObserver) PruningDidFinish(*PrunedInfo, error) {}
+
+// PrunerRetainHeightNearTip implements PrunerNearTipObserver.
+func (NoopPrunerObserver) PrunerRetainHeightNearTip(*RetainHeightNearTipInfo) {}
+
+// PrunerPrunedStates implements PrunerStatesObserver.
+func (NoopPrunerObserver) PrunerPrunedStates(*StatesPrunedInfo, error) {}
+
+// TargetComputed implements PrunerTargetObserver.
+func (NoopPrunerObserver) TargetComputed(int64, int64, int64) {}
+
+// IndexerPruned implements PrunerIndexerObserver.
+func (NoopPrunerObserver) IndexerPruned(int64, int64, error) {}
+
+// PrunerPauseChanged implements PrunerPauseObserver.
+func (NoopPrunerObserver) PrunerPauseChanged(bool) {}
@@ -0,0 +1,97 @@
+package state
+
+import (
+	"time"
+)
+
+// RetainLeaseID identifies a retain lease acquired with
+// Pruner.AcquireRetainLease.
+type RetainLeaseID uint64
+
+// retainLease keeps the blocks at and above height until expiry.
+type retainLease struct {
+	height int64
+	expiry time.Time
+}
+
+// AcquireRetainLease pins the blocks at and above height, so that they are not
+// pruned, whatever the block retain heights, until ttl elapses, unless the
+// lease is renewed with RenewRetainLease or released with ReleaseRetainLease.
+// This lets transient consumers, e.g. an export job, keep the blocks they are
+// reading without setting a retain height, which could never be lowered again:
+// if the consumer dies, its lease expires, and pruning proceeds. Leases are
+// only held in memory, by this pruner. It returns ErrInvalidRetainLeaseTTL if
+// ttl is not positive, and ErrInvalidHeightValue if height is not within the
+// range of heights held by the block store.
+func (p *Pruner) AcquireRetainLease(height int64, ttl time.Duration) (RetainLeaseID, error) {
+	if ttl <= 0 {
+		return 0, ErrInvalidRetainLeaseTTL
+	}
+	if err := p.checkHeightWithinBounds(height); err != nil {
+		return 0, err
+	}
+	p.retainLeasesMtx.Lock()
+	defer p.retainLeasesMtx.Unlock()
+	if p.retainLeases == nil {
+		p.retainLeases = make(map[RetainLeaseID]retainLease)
+	}
+	p.lastRetainLeaseID++
+	id := p.lastRetainLeaseID
+	p.retainLeases[id] = retainLease{height: height, expiry: time.Now().Add(ttl)}
+	p.logger.Info("Acquired retain lease", "id", id, "height", height, "ttl", ttl)
+	return id, nil
+}
+
+// RenewRetainLease extends the retain lease id until ttl from now. It returns
+// ErrRetainLeaseNotFound if the lease has expired or was released, in which
+// case its blocks may have been pruned already.
+func (p *Pruner) RenewRetainLease(id RetainLeaseID, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidRetainLeaseTTL
+	}
+	p.retainLeasesMtx.Lock()
+	defer p.retainLeasesMtx.Unlock()
+	lease, ok := p.retainLeases[id]
+	if !ok || !lease.expiry.After(time.Now()) {
+		delete(p.retainLeases, id)
+		return ErrRetainLeaseNotFound
+	}
+	lease.expiry = time.Now().Add(ttl)
+	p.retainLeases[id] = lease
+	return nil
+}
+
+// ReleaseRetainLease releases the retain lease id, so that its blocks can be
+// pruned. It returns ErrRetainLeaseNotFound if the lease has expired or was
+// already released.
+func (p *Pruner) ReleaseRetainLease(id RetainLeaseID) error {
+	p.retainLeasesMtx.Lock()
+	defer p.retainLeasesMtx.Unlock()
+	lease, ok := p.retainLeases[id]
+	delete(p.retainLeases, id)
+	if !ok || !lease.expiry.After(time.Now()) {
+		return ErrRetainLeaseNotFound
+	}
+	p.logger.Info("Released retain lease", "id", id, "height", lease.height)
+	return nil
+}
+
+// retainLeaseHeight returns the lowest height pinned by an unexpired retain
+// lease, or 0 if there is none. Expired leases are dropped.
+func (p *Pruner) retainLeaseHeight() int64 {
+	p.retainLeasesMtx.Lock()
+	defer p.retainLeasesMtx.Unlock()
+	now := time.Now()
+	var height int64
+	for id, lease := range p.retainLeases {
+		if !lease.expiry.After(now) {
+			p.logger.Info("Retain lease expired", "id", id, "height", lease.height)
+			delete(p.retainLeases, id)
+			continue
+		}
+		if height == 0 || lease.height < height {
+			height = lease.height
+		}
+	}
+	return height
+}
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"context"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/proxy"
+)
+
+// SnapshotHeightProvider provides the height of the lowest state sync snapshot
+// served by the node, so that the [Pruner] keeps the blocks that peers
+// restoring it need. See WithPrunerSnapshotHeightProvider.
+type SnapshotHeightProvider interface {
+	// LowestServableSnapshotHeight returns the height of the lowest snapshot
+	// still served by the node, or 0 if it serves none. It is called at every
+	// pass over the blocks, so that it follows the snapshots as new ones are
+	// taken and old ones expire, and must return quickly.
+	LowestServableSnapshotHeight() (int64, error)
+}
+
+// appSnapshotHeightProvider is the SnapshotHeightProvider listing the
+// snapshots of the application.
+type appSnapshotHeightProvider struct {
+	conn proxy.AppConnSnapshot
+}
+
+var _ SnapshotHeightProvider = appSnapshotHeightProvider{}
+
+// NewAppSnapshotHeightProvider returns a SnapshotHeightProvider listing the
+// snapshots offered by the application through conn, which the node serves to
+// its peers.
+func NewAppSnapshotHeightProvider(conn proxy.AppConnSnapshot) SnapshotHeightProvider {
+	return appSnapshotHeightProvider{conn: conn}
+}
+
+func (p appSnapshotHeightProvider) LowestServableSnapshotHeight() (int64, error) {
+	res, err := p.conn.ListSnapshots(context.TODO(), &abci.ListSnapshotsRequest{})
+	if err != nil {
+		return 0, err
+	}
+	var lowest uint64
+	for _, snapshot := range res.Snapshots {
+		if snapshot != nil && (lowest == 0 || snapshot.Height < lowest) {
+			lowest = snapshot.Height
+		}
+	}
+	return int64(lowest), nil
+}
+
+// snapshotTarget returns the block retain height target lowered to the height
+// of the lowest snapshot served by the node, if it is lower, see
+// WithPrunerSnapshotHeightProvider. If the height of the snapshots can't be
+// determined, it returns 0, so that no block is pruned until it can.
+func (p *Pruner) snapshotTarget(target int64) int64 {
+	if p.snapshotHeights == nil || target == 0 {
+		return target
+	}
+	height, err := p.snapshotHeights.LowestServableSnapshotHeight()
+	if err != nil {
+		p.logger.Error("Failed to get the height of the lowest snapshot served, keeping all blocks", "err", err)
+		return 0
+	}
+	if height == 0 || height >= target {
+		return target
+	}
+	return p.clampToBlockStore(height)
+}
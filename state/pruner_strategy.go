@@ -0,0 +1,26 @@
+package state
+
+// PruneStrategy removes the blocks pruned by the [Pruner] from the block
+// store, so that specialized storage can do something else than deleting
+// them, e.g. moving them to cold storage for archival.
+type PruneStrategy interface {
+	// PruneRange removes the blocks from `from` up to `to`, excluded, keeping
+	// the data needed to verify evidence against state, and returns the number
+	// of blocks removed and the height from which the evidence data was kept,
+	// like BlockStore.PruneBlocks. `from` is the base of the block store. Once
+	// it returns, the base of the block store must be `to`, as the pruner
+	// relies on it to track its progress.
+	PruneRange(from, to int64, state State) (uint64, int64, error)
+}
+
+// blockStorePruneStrategy is the PruneStrategy used by default, which deletes
+// the blocks with PruneBlocks of the block store.
+type blockStorePruneStrategy struct {
+	bs PrunableBlockStore
+}
+
+var _ PruneStrategy = blockStorePruneStrategy{}
+
+func (s blockStorePruneStrategy) PruneRange(_, to int64, state State) (uint64, int64, error) {
+	return s.bs.PruneBlocks(to, state)
+}
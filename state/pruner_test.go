@@ -1,13 +1,24 @@
 package state_test
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
 	"golang.org/x/exp/slices"
 
 	db "github.com/cometbft/cometbft-db"
@@ -15,8 +26,11 @@ import (
 	"github.com/cometbft/cometbft/internal/test"
 	"github.com/cometbft/cometbft/libs/log"
 	"github.com/cometbft/cometbft/libs/pubsub/query"
+	pmocks "github.com/cometbft/cometbft/proxy/mocks"
 	sm "github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/state/indexer"
 	blockidxkv "github.com/cometbft/cometbft/state/indexer/block/kv"
+	"github.com/cometbft/cometbft/state/mocks"
 	"github.com/cometbft/cometbft/state/txindex"
 	"github.com/cometbft/cometbft/state/txindex/kv"
 	"github.com/cometbft/cometbft/store"
@@ -152,6 +166,53 @@ func containsAllTxs(results []*abci.TxResult, txs []string) bool {
 	return true
 }
 
+func TestPruneIndexesNow(t *testing.T) {
+	pruner, txIndexer, blockIndexer := createTestSetup(t)
+
+	// Nothing to prune until the retain heights are set.
+	require.NoError(t, pruner.PruneIndexesNow())
+
+	for height := int64(1); height <= 4; height++ {
+		events, txResult1, txResult2 := getEventsAndResults(height)
+		require.NoError(t, blockIndexer.Index(events))
+		require.NoError(t, txIndexer.Index(txResult1))
+		require.NoError(t, txIndexer.Index(txResult2))
+	}
+	require.NoError(t, pruner.SetTxIndexerRetainHeight(3))
+	require.NoError(t, pruner.SetBlockIndexerRetainHeight(3))
+
+	// The pruner doesn't need to be running.
+	require.NoError(t, pruner.PruneIndexesNow())
+
+	results, _, err := txIndexer.Search(context.Background(), query.MustCompile("tx.height < 3"), txindex.Pagination{})
+	require.NoError(t, err)
+	require.Empty(t, results)
+	heights, err := blockIndexer.Search(context.Background(), query.MustCompile("block.height <= 4"))
+	require.NoError(t, err)
+	require.Equal(t, []int64{3, 4}, heights)
+
+	// Pruning again is a no-op.
+	require.NoError(t, pruner.PruneIndexesNow())
+
+	// Errors from the indexers are returned.
+	stateStore := sm.NewStore(db.NewMemDB(), sm.StoreOptions{})
+	pruner = sm.NewPruner(stateStore, store.NewBlockStore(db.NewMemDB()), &blockIndexer,
+		failingPruneTxIndexer{txIndexer}, log.TestingLogger())
+	require.NoError(t, pruner.SetTxIndexerRetainHeight(4))
+	var pruneErr sm.ErrFailedToPruneTxIndexer
+	require.ErrorAs(t, pruner.PruneIndexesNow(), &pruneErr)
+	require.EqualValues(t, 4, pruneErr.Height)
+}
+
+// failingPruneTxIndexer is a tx indexer that always fails to prune.
+type failingPruneTxIndexer struct {
+	txindex.TxIndexer
+}
+
+func (failingPruneTxIndexer) Prune(int64) (int64, int64, error) {
+	return 0, 0, errors.New("index backend unavailable")
+}
+
 func createTestSetup(t *testing.T) (*sm.Pruner, *kv.TxIndex, blockidxkv.BlockerIndexer) {
 	t.Helper()
 	config := test.ResetTestRoot("pruner_test")
@@ -193,6 +254,45 @@ func createTestSetup(t *testing.T) (*sm.Pruner, *kv.TxIndex, blockidxkv.BlockerI
 	return pruner, txIndexer, *blockIndexer
 }
 
+// prunerFixture holds the stores pruned by the pruners of a test: a block store
+// filled with 10 blocks, and a state store holding their states and ABCI
+// results, with all the retain heights set to 0.
+type prunerFixture struct {
+	state        sm.State
+	stateStore   sm.Store
+	bs           *store.BlockStore
+	txIndexer    txindex.TxIndexer
+	blockIndexer indexer.BlockIndexer
+}
+
+func newPrunerFixture(t *testing.T) *prunerFixture {
+	t.Helper()
+	state, bs, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+	t.Cleanup(cleanup)
+	fillBlockStore(t, 10, bs, state)
+	state.LastValidators = state.Validators.Copy()
+	for h := int64(0); h < 10; h++ {
+		state.LastBlockHeight = h
+		require.NoError(t, stateStore.Save(state))
+	}
+	for h := int64(1); h <= 10; h++ {
+		require.NoError(t, stateStore.SaveFinalizeBlockResponse(h, &abci.FinalizeBlockResponse{}))
+	}
+	require.NoError(t, initStateStoreRetainHeights(stateStore))
+	return &prunerFixture{
+		state:        state,
+		stateStore:   stateStore,
+		bs:           bs,
+		txIndexer:    txIndexer,
+		blockIndexer: blockIndexer,
+	}
+}
+
+// newPruner returns a pruner of the stores of the fixture.
+func (f *prunerFixture) newPruner(options ...sm.PrunerOption) *sm.Pruner {
+	return sm.NewPruner(f.stateStore, f.bs, f.blockIndexer, f.txIndexer, log.TestingLogger(), options...)
+}
+
 func getEventsAndResults(height int64) (types.EventDataNewBlockEvents, *abci.TxResult, *abci.TxResult) {
 	events := types.EventDataNewBlockEvents{
 		Height: height,
@@ -277,3 +377,2537 @@ func TestPruningWithHeight1(t *testing.T) {
 	require.Equal(t, uint64(0), pruned)
 	require.NoError(t, err)
 }
+
+func TestRemainingHeights(t *testing.T) {
+	testCases := []struct {
+		name               string
+		targetRetainHeight int64
+		newRetainHeight    int64
+		expected           int64
+	}{
+		{"caught up", 10, 10, 0},
+		{"ahead of target", 5, 10, 0},
+		{"partially pruned", 100, 40, 60},
+		{"nothing pruned yet", 100, 0, 100},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, sm.RemainingHeights(tc.targetRetainHeight, tc.newRetainHeight))
+		})
+	}
+}
+
+func TestRetainHeightSnapshot(t *testing.T) {
+	state, bs, txIndexer, blockIndexer, callbackF, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer callbackF()
+	fillBlockStore(t, 10, bs, state)
+	pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(), sm.WithPrunerCompanionEnabled())
+
+	// Nothing has been set yet.
+	rhs, err := pruner.RetainHeightSnapshot()
+	require.NoError(t, err)
+	require.Equal(t, sm.RetainHeights{Base: 1, Height: 10}, rhs)
+
+	require.NoError(t, initStateStoreRetainHeights(stateStore))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(3))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(4))
+	require.NoError(t, pruner.SetABCIResRetainHeight(5))
+	require.NoError(t, pruner.SetTxIndexerRetainHeight(6))
+
+	rhs, err = pruner.RetainHeightSnapshot()
+	require.NoError(t, err)
+	require.Equal(t, sm.RetainHeights{
+		ApplicationBlock: sm.RetainHeight{Height: 3, Set: true},
+		CompanionBlock:   sm.RetainHeight{Height: 4, Set: true},
+		ABCIResults:      sm.RetainHeight{Height: 5, Set: true},
+		TxIndexer:        sm.RetainHeight{Height: 6, Set: true},
+		Base:             1,
+		Height:           10,
+		EffectiveBlock:   3,
+	}, rhs)
+
+	// Retain leases lower the effective block retain height.
+	_, err = pruner.AcquireRetainLease(2, time.Hour)
+	require.NoError(t, err)
+	rhs, err = pruner.RetainHeightSnapshot()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, rhs.EffectiveBlock)
+}
+
+func TestReadRetainHeights(t *testing.T) {
+	stateStore := sm.NewStore(db.NewMemDB(), sm.StoreOptions{})
+
+	// Nothing has been set yet.
+	rhs, err := sm.ReadRetainHeights(stateStore)
+	require.NoError(t, err)
+	require.Equal(t, sm.RetainHeights{}, rhs)
+
+	require.NoError(t, stateStore.SaveApplicationRetainHeight(3))
+	require.NoError(t, stateStore.SaveABCIResRetainHeight(5))
+	// Only the getters are needed, e.g. by a read-only store.
+	rhs, err = sm.ReadRetainHeights(struct{ sm.RetainHeightReader }{stateStore})
+	require.NoError(t, err)
+	require.Equal(t, sm.RetainHeights{
+		ApplicationBlock: sm.RetainHeight{Height: 3, Set: true},
+		ABCIResults:      sm.RetainHeight{Height: 5, Set: true},
+	}, rhs)
+
+	// Other errors are returned.
+	_, err = sm.ReadRetainHeights(failingRetainHeightStore{Store: stateStore})
+	require.ErrorAs(t, err, &sm.ErrPrunerFailedToGetRetainHeight{})
+}
+
+// failingRetainHeightStore is a state store that fails to get the application
+// retain height.
+type failingRetainHeightStore struct {
+	sm.Store
+}
+
+func (failingRetainHeightStore) GetApplicationRetainHeight() (int64, error) {
+	return 0, errors.New("corrupted retain height")
+}
+
+func TestPrunerHasRetainHeight(t *testing.T) {
+	state, bs, txIndexer, blockIndexer, callbackF, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer callbackF()
+	fillBlockStore(t, 10, bs, state)
+	pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(), sm.WithPrunerCompanionEnabled())
+
+	has := func() []bool {
+		t.Helper()
+		app, err := pruner.HasApplicationRetainHeight()
+		require.NoError(t, err)
+		dc, err := pruner.HasCompanionBlockRetainHeight()
+		require.NoError(t, err)
+		abciRes, err := pruner.HasABCIResRetainHeight()
+		require.NoError(t, err)
+		return []bool{app, dc, abciRes}
+	}
+
+	// Nothing has been set yet.
+	require.Equal(t, []bool{false, false, false}, has())
+
+	require.NoError(t, stateStore.SaveCompanionBlockRetainHeight(4))
+	require.Equal(t, []bool{false, true, false}, has())
+
+	// Even retain heights of 0 are set.
+	require.NoError(t, stateStore.SaveApplicationRetainHeight(0))
+	require.NoError(t, stateStore.SaveABCIResRetainHeight(5))
+	require.Equal(t, []bool{true, true, true}, has())
+
+	// Other errors are returned.
+	pruner = sm.NewPruner(failingRetainHeightStore{Store: stateStore}, bs, blockIndexer, txIndexer, log.TestingLogger())
+	_, err := pruner.HasApplicationRetainHeight()
+	require.Error(t, err)
+}
+
+func TestPrunerSetRetainHeightOnEmptyBlockStore(t *testing.T) {
+	_, bs, txIndexer, blockIndexer, callbackF, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer callbackF()
+	require.NoError(t, initStateStoreRetainHeights(stateStore))
+	pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(), sm.WithPrunerCompanionEnabled())
+
+	for _, height := range []int64{1, 10} {
+		require.ErrorIs(t, pruner.SetApplicationBlockRetainHeight(height), sm.ErrNoBlocksToPrune)
+		require.ErrorIs(t, pruner.SetCompanionBlockRetainHeight(height), sm.ErrNoBlocksToPrune)
+		require.ErrorIs(t, pruner.SetABCIResRetainHeight(height), sm.ErrNoBlocksToPrune)
+	}
+	// Retain heights of 0, which don't prune anything, are still accepted.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(0))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(0))
+	require.NoError(t, pruner.SetABCIResRetainHeight(0))
+}
+
+// failingLoadStore is a state store that always fails to load the state.
+type failingLoadStore struct {
+	sm.Store
+}
+
+func (failingLoadStore) Load() (sm.State, error) {
+	return sm.State{}, errors.New("corrupted state")
+}
+
+func TestPrunerFailFast(t *testing.T) {
+	for _, failFast := range []bool{false, true} {
+		t.Run(fmt.Sprintf("failFast=%t", failFast), func(t *testing.T) {
+			f := newPrunerFixture(t)
+
+			pruner := sm.NewPruner(
+				failingLoadStore{Store: f.stateStore},
+				f.bs,
+				f.blockIndexer,
+				f.txIndexer,
+				log.TestingLogger(),
+				sm.WithPrunerInterval(time.Millisecond),
+				sm.WithPrunerFailFast(failFast),
+				sm.WithPrunerMaxStateLoadFailures(3),
+			)
+			require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+			require.NoError(t, pruner.Start())
+			defer func() { _ = pruner.Stop() }()
+
+			if !failFast {
+				// The pruner keeps retrying.
+				time.Sleep(50 * time.Millisecond)
+				require.True(t, pruner.IsRunning())
+				require.NoError(t, pruner.Err())
+				return
+			}
+
+			require.Eventually(t, func() bool { return !pruner.IsRunning() }, time.Second, 5*time.Millisecond)
+			var loadErr sm.ErrPrunerFailedToLoadState
+			require.ErrorAs(t, pruner.Err(), &loadErr)
+			require.EqualValues(t, 1, f.bs.Base())
+		})
+	}
+}
+
+// hookObserver records the calls to the pruning hooks.
+type hookObserver struct {
+	sm.NoopPrunerObserver
+	calls []string
+	infos []*sm.PrunedInfo
+}
+
+func (o *hookObserver) PruningWillStart(targetHeight int64) {
+	o.calls = append(o.calls, fmt.Sprintf("start %d", targetHeight))
+}
+
+func (o *hookObserver) PruningDidFinish(info *sm.PrunedInfo, err error) {
+	o.calls = append(o.calls, fmt.Sprintf("finish %v", err))
+	o.infos = append(o.infos, info)
+}
+
+func TestPruningHooks(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	// The state can't be loaded, so that pruning blocks fails.
+	obs := &hookObserver{}
+	pruner := sm.NewPruner(failingLoadStore{Store: f.stateStore}, f.bs, f.blockIndexer, f.txIndexer, log.TestingLogger(),
+		sm.WithPrunerObserver(obs), sm.WithPrunerCompanionEnabled())
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(5))
+	require.NoError(t, pruner.SetABCIResRetainHeight(7))
+
+	// Each pass is bracketed by the hooks, whether it succeeds or not.
+	newRetainHeight, err := pruner.PruneBlocksToRetainHeight(0)
+	require.Error(t, err)
+	require.EqualValues(t, 1, newRetainHeight)
+	require.Equal(t, []string{"start 5", "finish " + err.Error()}, obs.calls)
+	require.Equal(t, &sm.PrunedInfo{Blocks: &sm.BlocksPrunedInfo{FromHeight: 0, ToHeight: 0, RemainingHeights: 4}}, obs.infos[0])
+
+	require.EqualValues(t, 7, pruner.PruneABCIResToRetainHeight(0))
+	require.Equal(t, []string{"start 7", "finish <nil>"}, obs.calls[2:])
+	require.Equal(t, &sm.PrunedInfo{ABCIResponses: &sm.ABCIResponsesPrunedInfo{FromHeight: 0, ToHeight: 6}}, obs.infos[1])
+
+	// Passes without a new target retain height don't call the hooks.
+	pruner.PruneABCIResToRetainHeight(7)
+	require.Len(t, obs.calls, 4)
+}
+
+// minimalObserver only implements PrunerObserver and PrunerPassObserver.
+type minimalObserver struct {
+	targets []int64
+}
+
+func (*minimalObserver) PrunerStarted(time.Duration)                     {}
+func (*minimalObserver) PrunerPrunedABCIRes(*sm.ABCIResponsesPrunedInfo) {}
+func (*minimalObserver) PrunerPrunedBlocks(*sm.BlocksPrunedInfo)         {}
+func (o *minimalObserver) PruningWillStart(targetHeight int64) {
+	o.targets = append(o.targets, targetHeight)
+}
+func (*minimalObserver) PruningDidFinish(*sm.PrunedInfo, error) {}
+
+func TestPrunerOptionalObservers(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	// The optional interfaces implemented by the observer are called, and the
+	// others are skipped.
+	obs := &minimalObserver{}
+	pruner := f.newPruner(sm.WithPrunerObserver(obs), sm.WithPrunerNearTipWarnThreshold(8))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	require.NoError(t, pruner.SetABCIResRetainHeight(7))
+	require.EqualValues(t, 7, pruner.PruneABCIResToRetainHeight(0))
+	require.Equal(t, []int64{7}, obs.targets)
+	pruner.Pause()
+	pruner.Resume()
+}
+
+// heartbeatObserver counts the calls to the pruner observer.
+type heartbeatObserver struct {
+	sm.NoopPrunerObserver
+	mtx        sync.Mutex
+	heartbeats int
+	pruned     int
+}
+
+func (o *heartbeatObserver) PrunerHeartbeat() {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.heartbeats++
+}
+
+func (o *heartbeatObserver) PrunerPrunedBlocks(*sm.BlocksPrunedInfo) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.pruned++
+}
+
+func (o *heartbeatObserver) PrunerPrunedABCIRes(*sm.ABCIResponsesPrunedInfo) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.pruned++
+}
+
+func (o *heartbeatObserver) counts() (heartbeats, pruned int) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return o.heartbeats, o.pruned
+}
+
+func TestPrunerHeartbeat(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	obs := &heartbeatObserver{}
+	pruner := f.newPruner(sm.WithPrunerInterval(time.Millisecond), sm.WithPrunerObserver(obs), sm.WithPrunerCompanionEnabled())
+	require.NoError(t, pruner.Start())
+	defer func() { _ = pruner.Stop() }()
+
+	// No retain height is set, so the pruner is alive but prunes nothing.
+	require.Eventually(t, func() bool {
+		heartbeats, _ := obs.counts()
+		return heartbeats >= 3
+	}, time.Second, 5*time.Millisecond)
+	_, pruned := obs.counts()
+	require.Zero(t, pruned)
+}
+
+func TestPrunerRestartReportsNothingPruned(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	pruner := f.newPruner(sm.WithPrunerCompanionEnabled())
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(5))
+	require.NoError(t, pruner.SetABCIResRetainHeight(5))
+	_, err := pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 5, f.bs.Base())
+
+	// After a restart, the heights pruned before it are not reported again.
+	obs := &heartbeatObserver{}
+	pruner = f.newPruner(sm.WithPrunerInterval(time.Millisecond), sm.WithPrunerObserver(obs), sm.WithPrunerCompanionEnabled())
+	require.NoError(t, pruner.Start())
+	defer func() { _ = pruner.Stop() }()
+	require.Eventually(t, func() bool {
+		heartbeats, _ := obs.counts()
+		return heartbeats >= 3
+	}, time.Second, 5*time.Millisecond)
+	_, pruned := obs.counts()
+	require.Zero(t, pruned)
+	require.EqualValues(t, 5, f.bs.Base())
+}
+
+// vetoObserver vetoes the pruning passes while veto is set.
+type vetoObserver struct {
+	sm.NoopPrunerObserver
+	veto  atomic.Bool
+	calls atomic.Int64
+}
+
+func (o *vetoObserver) ShouldPrune() bool {
+	o.calls.Add(1)
+	return !o.veto.Load()
+}
+
+func TestPrunerObserverVetoesPruning(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	obs := &vetoObserver{}
+	obs.veto.Store(true)
+	pruner := f.newPruner(sm.WithPrunerInterval(time.Millisecond), sm.WithPrunerObserver(obs))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	require.NoError(t, pruner.Start())
+	defer func() { _ = pruner.Stop() }()
+
+	// Passes are skipped while the observer vetoes them.
+	require.Eventually(t, func() bool { return obs.calls.Load() >= 10 }, time.Second, time.Millisecond)
+	require.EqualValues(t, 1, f.bs.Base())
+
+	// And pruning resumes once it doesn't.
+	obs.veto.Store(false)
+	require.Eventually(t, func() bool { return f.bs.Base() == 5 }, time.Second, time.Millisecond)
+}
+
+// flakyPruneStatesStore is a state store that fails to prune states a given
+// number of times before succeeding.
+type flakyPruneStatesStore struct {
+	sm.Store
+	failures int
+	calls    int
+}
+
+func (s *flakyPruneStatesStore) PruneStates(from, to, evidenceThresholdHeight int64, previouslyPrunedStates uint64) (sm.PrunedStatesCount, error) {
+	s.calls++
+	if s.calls <= s.failures {
+		return sm.PrunedStatesCount{}, errors.New("state store unavailable")
+	}
+	return s.Store.PruneStates(from, to, evidenceThresholdHeight, previouslyPrunedStates)
+}
+
+func TestPrunerRetriesPruneStates(t *testing.T) {
+	for _, tc := range []struct {
+		failures int
+		retries  int
+		expErr   bool
+	}{
+		{failures: 0, retries: 2, expErr: false},
+		{failures: 2, retries: 2, expErr: false},
+		{failures: 3, retries: 2, expErr: true},
+		{failures: 1, retries: 0, expErr: true},
+	} {
+		t.Run(fmt.Sprintf("failures=%d,retries=%d", tc.failures, tc.retries), func(t *testing.T) {
+			f := newPrunerFixture(t)
+
+			store := &flakyPruneStatesStore{Store: f.stateStore, failures: tc.failures}
+			pruner := sm.NewPruner(store, f.bs, f.blockIndexer, f.txIndexer, log.TestingLogger(),
+				sm.WithPrunerStatePruningRetries(tc.retries, time.Millisecond))
+			require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+
+			_, err := pruner.PruneBlocksToRetainHeight(0)
+			require.Equal(t, min(tc.failures, tc.retries)+1, store.calls)
+			// The blocks are pruned either way.
+			require.EqualValues(t, 5, f.bs.Base())
+			if tc.expErr {
+				var pruneErr sm.ErrFailedToPruneStates
+				require.ErrorAs(t, err, &pruneErr)
+				return
+			}
+			require.NoError(t, err)
+
+			// Pruning states that have already been pruned is a no-op.
+			_, err = f.stateStore.PruneStates(1, 5, 5, 0)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPrunerCoupleABCIToBlocks(t *testing.T) {
+	for _, couple := range []bool{false, true} {
+		t.Run(fmt.Sprintf("couple=%t", couple), func(t *testing.T) {
+			state, bs, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+			defer cleanup()
+			require.NoError(t, initStateStoreRetainHeights(stateStore))
+
+			// The blocks below height 5 were pruned, but not their ABCI results.
+			for h := int64(5); h <= 10; h++ {
+				block := state.MakeBlock(h, nil, new(types.Commit), nil, state.Validators.GetProposer().Address)
+				partSet, err := block.MakePartSet(types.BlockPartSizeBytes)
+				require.NoError(t, err)
+				bs.SaveBlock(block, partSet, &types.Commit{Height: h})
+			}
+			response := &abci.FinalizeBlockResponse{TxResults: []*abci.ExecTxResult{{Code: 1}}}
+			for h := int64(1); h <= 10; h++ {
+				require.NoError(t, stateStore.SaveFinalizeBlockResponse(h, response))
+			}
+
+			pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(),
+				sm.WithPrunerCompanionEnabled(), sm.WithPrunerCoupleABCIToBlocks(couple))
+			require.NoError(t, pruner.SetApplicationBlockRetainHeight(8))
+			require.NoError(t, pruner.SetCompanionBlockRetainHeight(7))
+
+			err := pruner.SetABCIResRetainHeight(6)
+			if !couple {
+				// The ABCI results retain height can be below the block retain
+				// height.
+				require.NoError(t, err)
+
+				// The ABCI results of the pruned blocks are kept if the ABCI
+				// results retain height is lower.
+				require.NoError(t, stateStore.SaveABCIResRetainHeight(3))
+				require.EqualValues(t, 3, pruner.PruneABCIResToRetainHeight(0))
+				_, err = stateStore.LoadFinalizeBlockResponse(3)
+				require.NoError(t, err)
+				return
+			}
+
+			// The ABCI results retain height is raised to the block retain
+			// height.
+			require.NoError(t, err)
+			abciResRetainHeight, err := pruner.GetABCIResRetainHeight()
+			require.NoError(t, err)
+			require.EqualValues(t, 7, abciResRetainHeight)
+			require.NoError(t, pruner.SetABCIResRetainHeight(9))
+			require.ErrorIs(t, pruner.SetABCIResRetainHeight(8), sm.ErrPrunerCannotLowerRetainHeight)
+
+			// The ABCI results of the pruned blocks are pruned, even if the
+			// ABCI results retain height is lower.
+			require.NoError(t, stateStore.SaveABCIResRetainHeight(3))
+			require.EqualValues(t, 5, pruner.PruneABCIResToRetainHeight(0))
+			for h := int64(1); h < 5; h++ {
+				_, err = stateStore.LoadFinalizeBlockResponse(h)
+				require.Error(t, err)
+			}
+			_, err = stateStore.LoadFinalizeBlockResponse(5)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPrunerDowngradesRetainHeightsAboveTipOnStart(t *testing.T) {
+	state, bs, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer cleanup()
+	fillBlockStore(t, 10, bs, state)
+
+	// The retain heights were set before the node was rolled back to height 10.
+	require.NoError(t, stateStore.SaveApplicationRetainHeight(15))
+	require.NoError(t, stateStore.SaveCompanionBlockRetainHeight(8))
+	require.NoError(t, stateStore.SaveABCIResRetainHeight(12))
+
+	// Veto pruning, which would downgrade them too.
+	obs := &vetoObserver{}
+	obs.veto.Store(true)
+	pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(),
+		sm.WithPrunerInterval(time.Hour), sm.WithPrunerObserver(obs))
+	require.NoError(t, pruner.Start())
+	defer func() { require.NoError(t, pruner.Stop()) }()
+
+	rhs, err := sm.ReadRetainHeights(stateStore)
+	require.NoError(t, err)
+	require.Equal(t, sm.RetainHeights{
+		ApplicationBlock: sm.RetainHeight{Height: 10, Set: true},
+		CompanionBlock:   sm.RetainHeight{Height: 8, Set: true},
+		ABCIResults:      sm.RetainHeight{Height: 10, Set: true},
+	}, rhs)
+	// Retain heights up to the new tip can be set again.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(10))
+	require.ErrorIs(t, pruner.SetApplicationBlockRetainHeight(11), sm.ErrInvalidHeightValue)
+}
+
+func TestPrunerRaisesRetainHeightsBelowBaseOnStart(t *testing.T) {
+	for _, couple := range []bool{false, true} {
+		t.Run(fmt.Sprintf("couple=%t", couple), func(t *testing.T) {
+			state, bs, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+			defer cleanup()
+			fillBlockStore(t, 10, bs, state)
+			_, _, err := bs.PruneBlocks(6, state)
+			require.NoError(t, err)
+
+			// The retain heights were lost or reset by a migration of the
+			// state store after the blocks below 6 were pruned.
+			require.NoError(t, stateStore.SaveApplicationRetainHeight(0))
+			require.NoError(t, stateStore.SaveABCIResRetainHeight(2))
+
+			// Veto pruning, so that only the start of the pruner is tested.
+			obs := &vetoObserver{}
+			obs.veto.Store(true)
+			pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(),
+				sm.WithPrunerInterval(time.Hour), sm.WithPrunerObserver(obs),
+				sm.WithPrunerCompanionEnabled(), sm.WithPrunerCoupleABCIToBlocks(couple))
+			require.NoError(t, pruner.Start())
+			defer func() { require.NoError(t, pruner.Stop()) }()
+
+			rhs, err := sm.ReadRetainHeights(stateStore)
+			require.NoError(t, err)
+			abciResRetainHeight := int64(2)
+			if couple {
+				abciResRetainHeight = 6
+			}
+			require.Equal(t, sm.RetainHeights{
+				ApplicationBlock: sm.RetainHeight{Height: 6, Set: true},
+				CompanionBlock:   sm.RetainHeight{Height: 6, Set: true},
+				ABCIResults:      sm.RetainHeight{Height: abciResRetainHeight, Set: true},
+			}, rhs)
+		})
+	}
+}
+
+func TestPrunerInitialAppRetainHeight(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	// The store is not seeded before the block store reaches the initial
+	// retain height.
+	pruner := f.newPruner(sm.WithPrunerInitialAppRetainHeight(20))
+	_, err := pruner.PruneBlocksToRetainHeight(0)
+	require.NoError(t, err)
+	appRetainHeight, err := f.stateStore.GetApplicationRetainHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 0, appRetainHeight)
+	require.EqualValues(t, 1, f.bs.Base())
+
+	// The store is seeded once it does, and blocks are pruned accordingly.
+	pruner = f.newPruner(sm.WithPrunerInitialAppRetainHeight(3))
+	_, err = pruner.PruneBlocksToRetainHeight(0)
+	require.NoError(t, err)
+	appRetainHeight, err = f.stateStore.GetApplicationRetainHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 3, appRetainHeight)
+	require.EqualValues(t, 3, f.bs.Base())
+
+	// A retain height already set is never overridden.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	pruner = f.newPruner(sm.WithPrunerInitialAppRetainHeight(8))
+	_, err = pruner.PruneBlocksToRetainHeight(0)
+	require.NoError(t, err)
+	appRetainHeight, err = f.stateStore.GetApplicationRetainHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 5, appRetainHeight)
+	require.EqualValues(t, 5, f.bs.Base())
+}
+
+func TestPrunerInitialAppRetainHeightBelowBase(t *testing.T) {
+	f := newPrunerFixture(t)
+	_, _, err := f.bs.PruneBlocks(6, f.state)
+	require.NoError(t, err)
+
+	pruner := f.newPruner(sm.WithPrunerInitialAppRetainHeight(3))
+	_, err = pruner.PruneBlocksToRetainHeight(0)
+	require.NoError(t, err)
+	appRetainHeight, err := f.stateStore.GetApplicationRetainHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 6, appRetainHeight)
+}
+
+func TestPrunerSubscribeBaseAdvanced(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	pruner := f.newPruner()
+	baseAdvanced := pruner.SubscribeBaseAdvanced()
+
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(3))
+	_, err := pruner.PruneBlocksToRetainHeight(0)
+	require.NoError(t, err)
+	require.Equal(t, sm.BaseAdvancedInfo{OldBase: 1, NewBase: 3}, <-baseAdvanced)
+
+	// Nothing is sent if the base doesn't advance.
+	_, err = pruner.PruneBlocksToRetainHeight(0)
+	require.NoError(t, err)
+	require.Empty(t, baseAdvanced)
+
+	// The advances that weren't received yet are merged.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	_, err = pruner.PruneBlocksToRetainHeight(3)
+	require.NoError(t, err)
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(7))
+	_, err = pruner.PruneBlocksToRetainHeight(5)
+	require.NoError(t, err)
+	require.Equal(t, sm.BaseAdvancedInfo{OldBase: 3, NewBase: 7}, <-baseAdvanced)
+	require.Empty(t, baseAdvanced)
+}
+
+func TestPrunerLease(t *testing.T) {
+	_, bs, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer cleanup()
+	require.NoError(t, initStateStoreRetainHeights(stateStore))
+
+	newPruner := func(ttl time.Duration) *sm.Pruner {
+		return sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(),
+			sm.WithPrunerInterval(time.Hour), sm.WithPrunerLease(ttl))
+	}
+
+	// Another pruner can't start while the first one holds the lease.
+	first := newPruner(time.Minute)
+	require.NoError(t, first.Start())
+	second := newPruner(time.Minute)
+	var heldErr sm.ErrPrunerLeaseHeld
+	require.ErrorAs(t, second.Start(), &heldErr)
+
+	// It can once the first one released it.
+	require.NoError(t, first.Stop())
+	_, err := stateStore.GetPrunerLease()
+	require.ErrorIs(t, err, sm.ErrKeyNotFound)
+	require.NoError(t, second.Start())
+	require.NoError(t, second.Stop())
+
+	// An expired lease is taken over.
+	require.NoError(t, stateStore.SavePrunerLease(sm.PrunerLease{
+		Owner:  "crashed",
+		Expiry: time.Now().Add(-time.Second),
+	}))
+	pruner := newPruner(300 * time.Millisecond)
+	require.NoError(t, pruner.Start())
+	lease, err := stateStore.GetPrunerLease()
+	require.NoError(t, err)
+	require.NotEqual(t, "crashed", lease.Owner)
+
+	// The lease is renewed while the pruner runs.
+	require.Eventually(t, func() bool {
+		renewed, err := stateStore.GetPrunerLease()
+		return err == nil && renewed.Expiry.After(lease.Expiry)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The pruner stops if another pruner took its lease.
+	require.NoError(t, stateStore.SavePrunerLease(sm.PrunerLease{
+		Owner:  "other",
+		Expiry: time.Now().Add(time.Hour),
+	}))
+	require.Eventually(t, func() bool { return !pruner.IsRunning() }, 5*time.Second, 10*time.Millisecond)
+	require.ErrorAs(t, pruner.Err(), &heldErr)
+	require.Equal(t, "other", heldErr.Owner)
+	lease, err = stateStore.GetPrunerLease()
+	require.NoError(t, err)
+	require.Equal(t, "other", lease.Owner, "the lease of another pruner must not be released")
+}
+
+// failingBatchDB is a DB whose batch writes fail once failAfter batches were
+// written.
+type failingBatchDB struct {
+	db.DB
+	failAfter int
+	written   int
+}
+
+func (d *failingBatchDB) NewBatch() db.Batch {
+	return &failingBatch{Batch: d.DB.NewBatch(), db: d}
+}
+
+type failingBatch struct {
+	db.Batch
+	db *failingBatchDB
+}
+
+func (b *failingBatch) Write() error {
+	if b.db.written >= b.db.failAfter {
+		return errors.New("batch write failed")
+	}
+	b.db.written++
+	return b.Batch.Write()
+}
+
+func (b *failingBatch) WriteSync() error {
+	if b.db.written >= b.db.failAfter {
+		return errors.New("batch write failed")
+	}
+	b.db.written++
+	return b.Batch.WriteSync()
+}
+
+func TestPruneABCIResponsesPartialProgress(t *testing.T) {
+	stateDB := &failingBatchDB{DB: db.NewMemDB(), failAfter: 2}
+	stateStore := sm.NewStore(stateDB, sm.StoreOptions{})
+	for h := int64(1); h < 2600; h++ {
+		require.NoError(t, stateStore.SaveFinalizeBlockResponse(h, &abci.FinalizeBlockResponse{}))
+	}
+	require.NoError(t, initStateStoreRetainHeights(stateStore))
+	obs := &hookObserver{}
+	pruner := sm.NewPruner(stateStore, store.NewBlockStore(db.NewMemDB()), nil, nil, log.TestingLogger(),
+		sm.WithPrunerObserver(obs))
+	require.NoError(t, stateStore.SaveABCIResRetainHeight(2600))
+
+	// The third batch fails, after the first two deleted heights 1 to 2000.
+	require.EqualValues(t, 2001, pruner.PruneABCIResToRetainHeight(0))
+	require.Equal(t, &sm.PrunedInfo{ABCIResponses: &sm.ABCIResponsesPrunedInfo{
+		FromHeight: 0, ToHeight: 2000, RemainingHeights: 599,
+	}}, obs.infos[0])
+	_, err := stateStore.LoadFinalizeBlockResponse(2000)
+	require.Error(t, err)
+	_, err = stateStore.LoadFinalizeBlockResponse(2001)
+	require.NoError(t, err)
+
+	// The next pass resumes from there.
+	stateDB.failAfter = math.MaxInt
+	require.EqualValues(t, 2600, pruner.PruneABCIResToRetainHeight(2001))
+	numPruned, newRetainHeight, err := stateStore.PruneABCIResponses(2600, false)
+	require.NoError(t, err)
+	require.Zero(t, numPruned)
+	require.EqualValues(t, 2600, newRetainHeight)
+	_, err = stateStore.LoadFinalizeBlockResponse(2599)
+	require.Error(t, err)
+}
+
+func TestPruneABCIResponsesCatchUp(t *testing.T) {
+	stateStore := sm.NewStore(db.NewMemDB(), sm.StoreOptions{})
+	for h := int64(1); h <= 100; h++ {
+		require.NoError(t, stateStore.SaveFinalizeBlockResponse(h, &abci.FinalizeBlockResponse{}))
+	}
+	require.NoError(t, initStateStoreRetainHeights(stateStore))
+	obs := &hookObserver{}
+	pruner := sm.NewPruner(stateStore, store.NewBlockStore(db.NewMemDB()), nil, nil, log.TestingLogger(),
+		sm.WithPrunerObserver(obs), sm.WithPrunerABCIResCatchUp(10, 40))
+	require.NoError(t, stateStore.SaveABCIResRetainHeight(100))
+
+	// The steps double up to the maximum, until the retain height is reached.
+	var (
+		step             int64
+		lastRetainHeight int64
+	)
+	for i, expected := range []struct {
+		retainHeight int64
+		catchingUp   bool
+	}{
+		{11, true},
+		{31, true},
+		{71, true},
+		{100, false},
+	} {
+		var catchingUp bool
+		lastRetainHeight, catchingUp = pruner.PruneABCIResToRetainHeightStep(lastRetainHeight, &step)
+		require.Equal(t, expected.retainHeight, lastRetainHeight, "run %d", i)
+		require.Equal(t, expected.catchingUp, catchingUp, "run %d", i)
+		info := obs.infos[i].ABCIResponses
+		require.Equal(t, expected.catchingUp, info.CatchingUp, "run %d", i)
+		require.Equal(t, 100-expected.retainHeight, info.RemainingHeights, "run %d", i)
+	}
+	require.Equal(t, []string{"start 11", "finish <nil>", "start 31", "finish <nil>",
+		"start 71", "finish <nil>", "start 100", "finish <nil>"}, obs.calls)
+	_, err := stateStore.LoadFinalizeBlockResponse(99)
+	require.Error(t, err)
+	_, err = stateStore.LoadFinalizeBlockResponse(100)
+	require.NoError(t, err)
+
+	// Once caught up, the next raise starts with the initial step again.
+	require.Zero(t, step)
+}
+
+// phaseObserver records the phases that pruned something, in order.
+type phaseObserver struct {
+	sm.NoopPrunerObserver
+	mtx        sync.Mutex
+	phases     []string
+	heartbeats int
+}
+
+func (o *phaseObserver) PrunerPrunedBlocks(*sm.BlocksPrunedInfo) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.phases = append(o.phases, sm.PrunePhaseBlocks.String())
+}
+
+func (o *phaseObserver) PrunerPrunedABCIRes(*sm.ABCIResponsesPrunedInfo) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.phases = append(o.phases, sm.PrunePhaseABCI.String())
+}
+
+func (o *phaseObserver) PrunerHeartbeat() {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.heartbeats++
+}
+
+func (o *phaseObserver) state() ([]string, int) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return slices.Clone(o.phases), o.heartbeats
+}
+
+func TestPrunerPhaseOrder(t *testing.T) {
+	testCases := []struct {
+		name   string
+		order  []sm.PrunePhase
+		phases []string
+	}{
+		{"abci first", []sm.PrunePhase{sm.PrunePhaseABCI, sm.PrunePhaseBlocks}, []string{"abci", "blocks"}},
+		{"blocks first", []sm.PrunePhase{sm.PrunePhaseBlocks, sm.PrunePhaseABCI, sm.PrunePhaseIndexer}, []string{"blocks", "abci"}},
+		{"blocks only", []sm.PrunePhase{sm.PrunePhaseBlocks}, []string{"blocks"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newPrunerFixture(t)
+
+			obs := &phaseObserver{}
+			pruner := f.newPruner(sm.WithPrunerInterval(time.Millisecond), sm.WithPrunerObserver(obs), sm.WithPrunerCompanionEnabled(),
+				sm.WithPrunerPhaseOrder(tc.order))
+			require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+			require.NoError(t, pruner.SetCompanionBlockRetainHeight(5))
+			require.NoError(t, pruner.SetABCIResRetainHeight(7))
+			require.NoError(t, pruner.Start())
+			defer func() { _ = pruner.Stop() }()
+
+			// Everything is pruned in the first run, and nothing afterwards.
+			require.Eventually(t, func() bool {
+				_, heartbeats := obs.state()
+				return heartbeats >= 3
+			}, time.Second, 5*time.Millisecond)
+			phases, _ := obs.state()
+			require.Equal(t, tc.phases, phases)
+			require.EqualValues(t, 5, f.bs.Base())
+		})
+	}
+}
+
+// nearTipObserver records the retain heights reported as close to the tip.
+type nearTipObserver struct {
+	sm.NoopPrunerObserver
+	infos []sm.RetainHeightNearTipInfo
+}
+
+func (o *nearTipObserver) PrunerRetainHeightNearTip(info *sm.RetainHeightNearTipInfo) {
+	o.infos = append(o.infos, *info)
+}
+
+func TestPrunerNearTipWarnThreshold(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	obs := &nearTipObserver{}
+	pruner := f.newPruner(sm.WithPrunerObserver(obs), sm.WithPrunerCompanionEnabled(), sm.WithPrunerNearTipWarnThreshold(3))
+	// 3 blocks are left below the tip.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(7))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(7))
+	require.False(t, pruner.IsRetainHeightNearTip(7))
+	require.Empty(t, obs.infos)
+
+	// Retain heights close to the tip are accepted, but reported.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(8))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(10))
+	require.True(t, pruner.IsRetainHeightNearTip(8))
+	require.Equal(t, []sm.RetainHeightNearTipInfo{
+		{Which: "application block", RetainHeight: 8, TipHeight: 10},
+		{Which: "companion block", RetainHeight: 10, TipHeight: 10},
+	}, obs.infos)
+	height, err := pruner.GetApplicationRetainHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 8, height)
+
+	// Nothing is reported without a threshold.
+	pruner = f.newPruner(sm.WithPrunerObserver(obs))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(10))
+	require.False(t, pruner.IsRetainHeightNearTip(10))
+	require.Len(t, obs.infos, 2)
+}
+
+func TestPrunerSkipsABCIResPhaseIfDiscarded(t *testing.T) {
+	for _, phaseOrder := range [][]sm.PrunePhase{nil, {sm.PrunePhaseABCI, sm.PrunePhaseBlocks}} {
+		t.Run(fmt.Sprintf("phase order %v", phaseOrder), func(t *testing.T) {
+			f := newPrunerFixture(t)
+
+			obs := &phaseObserver{}
+			pruner := f.newPruner(sm.WithPrunerInterval(time.Millisecond), sm.WithPrunerObserver(obs), sm.WithPrunerCompanionEnabled(),
+				sm.WithPrunerPhaseOrder(phaseOrder), sm.WithPrunerABCIResponsesDiscarded(true))
+			require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+			require.NoError(t, pruner.SetCompanionBlockRetainHeight(5))
+			require.NoError(t, pruner.SetABCIResRetainHeight(7))
+			require.NoError(t, pruner.Start())
+			defer func() { _ = pruner.Stop() }()
+
+			require.Eventually(t, func() bool {
+				_, heartbeats := obs.state()
+				return heartbeats >= 3
+			}, time.Second, 5*time.Millisecond)
+			phases, _ := obs.state()
+			require.Equal(t, []string{"blocks"}, phases)
+			_, err := f.stateStore.LoadFinalizeBlockResponse(6)
+			require.NoError(t, err)
+		})
+	}
+}
+
+// blockingPruneStatesStore is a state store that blocks pruning states until
+// released.
+type blockingPruneStatesStore struct {
+	sm.Store
+	started chan [2]int64
+	release chan struct{}
+}
+
+func (s *blockingPruneStatesStore) PruneStates(from, to, evidenceThresholdHeight int64, previouslyPrunedStates uint64) (sm.PrunedStatesCount, error) {
+	s.started <- [2]int64{from, to}
+	<-s.release
+	return s.Store.PruneStates(from, to, evidenceThresholdHeight, previouslyPrunedStates)
+}
+
+// statesObserver records the states pruned.
+type statesObserver struct {
+	sm.NoopPrunerObserver
+	mtx   sync.Mutex
+	infos []sm.StatesPrunedInfo
+}
+
+func (o *statesObserver) PrunerPrunedStates(info *sm.StatesPrunedInfo, err error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if err == nil {
+		o.infos = append(o.infos, *info)
+	}
+}
+
+func TestAsyncStatePruning(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	store := &blockingPruneStatesStore{Store: f.stateStore, started: make(chan [2]int64, 2), release: make(chan struct{})}
+	obs := &statesObserver{}
+	pruner := sm.NewPruner(store, f.bs, f.blockIndexer, f.txIndexer, log.TestingLogger(),
+		sm.WithPrunerInterval(time.Millisecond), sm.WithPrunerObserver(obs), sm.WithAsyncStatePruning(true))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(3))
+	require.NoError(t, pruner.Start())
+	require.Equal(t, [2]int64{1, 3}, <-store.started)
+
+	// Blocks keep being pruned while the states are, and the next ranges of
+	// states are coalesced.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	require.Eventually(t, func() bool { return f.bs.Base() == 5 }, time.Second, time.Millisecond)
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(7))
+	require.Eventually(t, func() bool { return f.bs.Base() == 7 }, time.Second, time.Millisecond)
+
+	// Stopping the pruner waits for the pending range to be pruned.
+	stopped := make(chan error)
+	go func() { stopped <- pruner.Stop() }()
+	close(store.release)
+	require.NoError(t, <-stopped)
+	require.Equal(t, [2]int64{3, 7}, <-store.started)
+	require.Equal(t, []sm.StatesPrunedInfo{
+		{FromHeight: 1, ToHeight: 2, ValidatorSets: 0, ConsensusParams: 1},
+		{FromHeight: 3, ToHeight: 6, ValidatorSets: 2, ConsensusParams: 4},
+	}, obs.infos)
+}
+
+func TestPrunerEvidenceMaxAgeBlocks(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		paramsMaxAgeBlocks   int64
+		evidenceMaxAgeBlocks int64
+		evRetainHeight       int64
+	}{
+		{"evidence params only", 1, 0, 8},
+		{"evidence max age above params", 1, 5, 5},
+		{"evidence params above max age", 4, 2, 6},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state, bs, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+			defer cleanup()
+			fillBlockStore(t, 10, bs, state)
+			// All the blocks are older than the maximum age duration, so that
+			// only the maximum ages in blocks matter.
+			state.LastBlockTime = time.Now().Add(time.Hour)
+			state.ConsensusParams.Evidence.MaxAgeDuration = time.Nanosecond
+			state.ConsensusParams.Evidence.MaxAgeNumBlocks = tc.paramsMaxAgeBlocks
+			state.LastValidators = state.Validators.Copy()
+			for h := int64(0); h <= 10; h++ {
+				state.LastBlockHeight = h
+				require.NoError(t, stateStore.Save(state))
+			}
+			require.NoError(t, initStateStoreRetainHeights(stateStore))
+
+			pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(),
+				sm.WithPrunerEvidenceMaxAgeBlocks(tc.evidenceMaxAgeBlocks))
+			require.NoError(t, pruner.SetApplicationBlockRetainHeight(8))
+			pruned, evRetainHeight, err := pruner.PruneBlocksToHeight(8)
+			require.NoError(t, err)
+			require.EqualValues(t, 7, pruned)
+			require.Equal(t, tc.evRetainHeight, evRetainHeight)
+
+			// The headers needed to verify evidence are kept.
+			require.EqualValues(t, 8, bs.Base())
+			require.Nil(t, bs.LoadBlockMeta(tc.evRetainHeight-1))
+			for h := tc.evRetainHeight; h < 8; h++ {
+				require.NotNil(t, bs.LoadBlockMeta(h), "height %d", h)
+			}
+		})
+	}
+}
+
+func TestPrunerReportsEvidencePinnedHeights(t *testing.T) {
+	state, bs, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer cleanup()
+	fillBlockStore(t, 10, bs, state)
+	state.LastBlockTime = time.Now().Add(time.Hour)
+	state.ConsensusParams.Evidence.MaxAgeDuration = time.Nanosecond
+	state.ConsensusParams.Evidence.MaxAgeNumBlocks = 3
+	state.LastValidators = state.Validators.Copy()
+	for h := int64(0); h <= 10; h++ {
+		state.LastBlockHeight = h
+		require.NoError(t, stateStore.Save(state))
+	}
+	require.NoError(t, initStateStoreRetainHeights(stateStore))
+
+	pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger())
+	// The evidence data of the heights from 7 is kept.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	info, err := pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, info.EvidencePinnedHeights)
+
+	info, err = pruner.PruneToHeight(9)
+	require.NoError(t, err)
+	require.EqualValues(t, 9, bs.Base())
+	require.EqualValues(t, 2, info.EvidencePinnedHeights)
+
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(10))
+	info, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 1, info.EvidencePinnedHeights)
+}
+
+func TestPruningReportsPrunedStates(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	obs := &hookObserver{}
+	pruner := f.newPruner(sm.WithPrunerObserver(obs))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	newRetainHeight, err := pruner.PruneBlocksToRetainHeight(0)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, newRetainHeight)
+	// The validator sets are all kept for evidence verification, and the
+	// consensus params at height 1, at which they were last changed.
+	require.Equal(t, &sm.StatesPrunedInfo{FromHeight: 1, ToHeight: 4, ValidatorSets: 0, ConsensusParams: 3}, obs.infos[0].States)
+
+	// The validator sets and consensus params of the remaining heights can
+	// still be loaded, as the heights they were last changed at are kept.
+	for h := int64(5); h <= 10; h++ {
+		_, err := f.stateStore.LoadValidators(h)
+		require.NoError(t, err, "validators height %v", h)
+		_, err = f.stateStore.LoadConsensusParams(h)
+		require.NoError(t, err, "params height %v", h)
+	}
+}
+
+func TestPruneOnce(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	// The states are pruned along with the blocks, as the pruner is not running.
+	pruner := f.newPruner(sm.WithAsyncStatePruning(true), sm.WithPrunerLease(time.Minute))
+	info, err := pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &sm.PrunedInfo{}, info)
+
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	// Nothing is pruned once the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	info, err = pruner.PruneOnce(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, &sm.PrunedInfo{}, info)
+	require.EqualValues(t, 1, f.bs.Base())
+
+	info, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &sm.PrunedInfo{
+		Blocks: &sm.BlocksPrunedInfo{FromHeight: 1, ToHeight: 4},
+		States: &sm.StatesPrunedInfo{FromHeight: 1, ToHeight: 4, ValidatorSets: 0, ConsensusParams: 3},
+		// The blocks are all within the evidence age window.
+		EvidencePinnedHeights: 4,
+	}, info)
+	require.EqualValues(t, 5, f.bs.Base())
+	_, err = f.stateStore.GetPrunerLease()
+	require.ErrorIs(t, err, sm.ErrKeyNotFound)
+
+	require.NoError(t, pruner.Start())
+	defer func() { require.NoError(t, pruner.Stop()) }()
+	_, err = pruner.PruneOnce(context.Background())
+	require.ErrorIs(t, err, sm.ErrPrunerRunning)
+}
+
+func TestPruneToHeight(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	pruner := f.newPruner(sm.WithPrunerCompanionEnabled())
+	_, err := pruner.PruneToHeight(0)
+	require.ErrorIs(t, err, sm.ErrInvalidRetainHeight)
+	_, err = pruner.PruneToHeight(11)
+	require.ErrorIs(t, err, sm.ErrInvalidHeightValue)
+
+	// The blocks needed by the data companion are kept.
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(3))
+	info, err := pruner.PruneToHeight(5)
+	require.NoError(t, err)
+	require.Equal(t, &sm.PrunedInfo{
+		Blocks: &sm.BlocksPrunedInfo{FromHeight: 1, ToHeight: 2},
+		States: &sm.StatesPrunedInfo{FromHeight: 1, ToHeight: 2, ValidatorSets: 0, ConsensusParams: 1},
+		// The blocks are all within the evidence age window.
+		EvidencePinnedHeights: 2,
+	}, info)
+	require.EqualValues(t, 3, f.bs.Base())
+
+	// The retain heights are left unchanged, even though the application
+	// block retain height was never set.
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(8))
+	info, err = pruner.PruneToHeight(5)
+	require.NoError(t, err)
+	require.Equal(t, &sm.PrunedInfo{
+		Blocks:                &sm.BlocksPrunedInfo{FromHeight: 3, ToHeight: 4},
+		States:                &sm.StatesPrunedInfo{FromHeight: 3, ToHeight: 4, ValidatorSets: 0, ConsensusParams: 2},
+		EvidencePinnedHeights: 2,
+	}, info)
+	require.EqualValues(t, 5, f.bs.Base())
+	appRetainHeight, err := f.stateStore.GetApplicationRetainHeight()
+	require.NoError(t, err)
+	require.Zero(t, appRetainHeight)
+	dcRetainHeight, err := f.stateStore.GetCompanionBlockRetainHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 8, dcRetainHeight)
+
+	// Nothing is left to prune below the base.
+	info, err = pruner.PruneToHeight(4)
+	require.NoError(t, err)
+	require.Equal(t, &sm.PrunedInfo{}, info)
+
+	require.NoError(t, pruner.Start())
+	defer func() { require.NoError(t, pruner.Stop()) }()
+	_, err = pruner.PruneToHeight(6)
+	require.ErrorIs(t, err, sm.ErrPrunerRunning)
+}
+
+// targetsObserver records the targets computed by the pruner.
+type targetsObserver struct {
+	sm.NoopPrunerObserver
+	mtx     sync.Mutex
+	targets [][3]int64
+}
+
+func (o *targetsObserver) TargetComputed(blockTarget, abciTarget, indexerTarget int64) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.targets = append(o.targets, [3]int64{blockTarget, abciTarget, indexerTarget})
+}
+
+func (o *targetsObserver) computed() [][3]int64 {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return append([][3]int64{}, o.targets...)
+}
+
+func TestPrunerReportsTargets(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	obs := &targetsObserver{}
+	pruner := f.newPruner(sm.WithPrunerInterval(time.Millisecond), sm.WithPrunerObserver(obs), sm.WithPrunerCompanionEnabled())
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(4))
+	require.NoError(t, pruner.SetABCIResRetainHeight(7))
+	require.NoError(t, pruner.SetTxIndexerRetainHeight(3))
+	require.NoError(t, pruner.SetBlockIndexerRetainHeight(6))
+	require.NoError(t, pruner.Start())
+	defer func() { require.NoError(t, pruner.Stop()) }()
+
+	// The targets are reported at every cycle, even once they are reached.
+	require.Eventually(t, func() bool { return len(obs.computed()) >= 3 }, time.Second, time.Millisecond)
+	require.EqualValues(t, 4, f.bs.Base())
+	for _, targets := range obs.computed() {
+		require.Equal(t, [3]int64{4, 7, 3}, targets)
+	}
+}
+
+// manualClock is a PrunerClock whose waits only elapse when the test says so.
+// Every wait is sent to waits, once the pruner starts waiting.
+type manualClock struct {
+	waits chan manualWait
+}
+
+type manualWait struct {
+	d  time.Duration
+	ch chan time.Time
+}
+
+func newManualClock() *manualClock {
+	return &manualClock{waits: make(chan manualWait, 16)}
+}
+
+func (c *manualClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.waits <- manualWait{d: d, ch: ch}
+	return ch
+}
+
+// next returns the next wait of the pruner, i.e. once it finished a cycle.
+func (c *manualClock) next(t *testing.T) manualWait {
+	t.Helper()
+	select {
+	case w := <-c.waits:
+		return w
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for the pruner to wait")
+		return manualWait{}
+	}
+}
+
+func (w manualWait) elapse() {
+	w.ch <- time.Time{}
+}
+
+func TestPrunerClock(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	clock := newManualClock()
+	obs := &phaseObserver{}
+	pruner := f.newPruner(sm.WithPrunerInterval(time.Hour), sm.WithPrunerObserver(obs), sm.WithPrunerClock(clock))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(3))
+	require.NoError(t, pruner.Start())
+	defer func() { require.NoError(t, pruner.Stop()) }()
+
+	// The first cycle runs right away, and the next one only once the
+	// interval elapsed on the clock.
+	wait := clock.next(t)
+	require.Equal(t, time.Hour, wait.d)
+	require.EqualValues(t, 3, f.bs.Base())
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(6))
+	_, heartbeats := obs.state()
+	require.Equal(t, 1, heartbeats)
+	require.EqualValues(t, 3, f.bs.Base())
+
+	wait.elapse()
+	clock.next(t)
+	_, heartbeats = obs.state()
+	require.Equal(t, 2, heartbeats)
+	require.EqualValues(t, 6, f.bs.Base())
+}
+
+func TestPrunerDiskPressure(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	var pressure atomic.Int64
+	clock := newManualClock()
+	obs := &phaseObserver{}
+	pruner := f.newPruner(sm.WithPrunerCompanionEnabled(),
+		sm.WithPrunerObserver(obs),
+		sm.WithPrunerClock(clock),
+		sm.WithPrunerPhaseOrder([]sm.PrunePhase{sm.PrunePhaseBlocks, sm.PrunePhaseABCI}),
+		sm.WithPrunerABCIResCatchUp(1, 1),
+		sm.WithPrunerDiskPressureFunc(func() sm.DiskPressure { return sm.DiskPressure(pressure.Load()) }))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(3))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(3))
+	require.NoError(t, pruner.SetABCIResRetainHeight(5))
+	require.NoError(t, pruner.Start())
+	defer func() { require.NoError(t, pruner.Stop()) }()
+
+	// Under normal pressure, the phases run in order, and the ABCI results
+	// catch up with their retain height one height at a time.
+	wait := clock.next(t)
+	phases, _ := obs.state()
+	require.Equal(t, []string{"blocks", "abci"}, phases)
+	abciRetainHeight, err := f.stateStore.GetLastABCIResponsesRetainHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, abciRetainHeight)
+
+	// Under high pressure, the ABCI results are pruned first, and up to their
+	// retain height at once.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(6))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(6))
+	pressure.Store(int64(sm.DiskPressureHigh))
+	wait.elapse()
+	clock.next(t)
+	phases, _ = obs.state()
+	require.Equal(t, []string{"blocks", "abci", "abci", "blocks"}, phases)
+	abciRetainHeight, err = f.stateStore.GetLastABCIResponsesRetainHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 5, abciRetainHeight)
+	require.EqualValues(t, 6, f.bs.Base())
+}
+
+func TestPrunerAuditWriter(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	// The records are flushed as soon as they are written.
+	var buf bytes.Buffer
+	pruner := f.newPruner(sm.WithPrunerCompanionEnabled(), sm.WithPrunerAuditWriter(bufio.NewWriter(&buf)))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(5))
+	require.NoError(t, pruner.SetABCIResRetainHeight(4))
+	_, err := pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, pruner.SetABCIResRetainHeight(7))
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+
+	type record struct {
+		Seq  uint64    `json:"seq"`
+		Time time.Time `json:"time"`
+		sm.PrunedInfo
+		Error string `json:"error"`
+	}
+	var records []record
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r record
+		require.NoError(t, dec.Decode(&r))
+		require.False(t, r.Time.IsZero())
+		r.Time = time.Time{}
+		records = append(records, r)
+	}
+	require.Equal(t, []record{
+		{Seq: 1, PrunedInfo: sm.PrunedInfo{
+			Blocks: &sm.BlocksPrunedInfo{FromHeight: 1, ToHeight: 4},
+			States: &sm.StatesPrunedInfo{FromHeight: 1, ToHeight: 4, ValidatorSets: 0, ConsensusParams: 3},
+			// The blocks are all within the evidence age window.
+			EvidencePinnedHeights: 4,
+		}},
+		{Seq: 2, PrunedInfo: sm.PrunedInfo{ABCIResponses: &sm.ABCIResponsesPrunedInfo{FromHeight: 0, ToHeight: 3}}},
+		{Seq: 3, PrunedInfo: sm.PrunedInfo{ABCIResponses: &sm.ABCIResponsesPrunedInfo{FromHeight: 0, ToHeight: 6}}},
+	}, records)
+}
+
+// sweepingBlockStore records the ranges of the orphaned block parts swept.
+type sweepingBlockStore struct {
+	*store.BlockStore
+	swept [][2]int64
+}
+
+func (bs *sweepingBlockStore) PruneOrphanedBlockParts(from, to int64) (uint64, error) {
+	bs.swept = append(bs.swept, [2]int64{from, to})
+	return uint64(to - from), nil
+}
+
+func TestPrunerSweepsOrphanedBlockParts(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	bs := &sweepingBlockStore{BlockStore: f.bs}
+	obs := &hookObserver{}
+	pruner := sm.NewPruner(f.stateStore, bs, f.blockIndexer, f.txIndexer, log.TestingLogger(), sm.WithPrunerObserver(obs))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(4))
+	info, err := pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 3, info.OrphanedBlockParts)
+
+	// Only the heights that were not swept yet are swept again.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(7))
+	info, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 3, info.OrphanedBlockParts)
+	info, err = pruner.PruneToHeight(9)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, info.OrphanedBlockParts)
+	require.Equal(t, [][2]int64{{1, 4}, {4, 7}, {7, 9}}, bs.swept)
+
+	for i, expected := range []uint64{3, 3, 2} {
+		require.Equal(t, expected, obs.infos[i].OrphanedBlockParts)
+	}
+}
+
+func TestPrunerOldestRetainedTime(t *testing.T) {
+	state, bs, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer cleanup()
+	pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger())
+	_, err := pruner.OldestRetainedTime()
+	require.ErrorIs(t, err, sm.ErrBlockStoreEmpty)
+
+	fillBlockStore(t, 10, bs, state)
+	state.LastValidators = state.Validators.Copy()
+	for h := int64(0); h < 10; h++ {
+		state.LastBlockHeight = h
+		require.NoError(t, stateStore.Save(state))
+	}
+	require.NoError(t, initStateStoreRetainHeights(stateStore))
+	oldest, err := pruner.OldestRetainedTime()
+	require.NoError(t, err)
+	require.Equal(t, bs.LoadBlockMeta(1).Header.Time, oldest)
+
+	_, err = pruner.PruneToHeight(4)
+	require.NoError(t, err)
+	oldest, err = pruner.OldestRetainedTime()
+	require.NoError(t, err)
+	require.Equal(t, bs.LoadBlockMeta(4).Header.Time, oldest)
+}
+
+// compactingStore is a state store that counts the compactions of its retain
+// height keys.
+type compactingStore struct {
+	sm.Store
+	compactions int
+}
+
+func (s *compactingStore) CompactRetainHeightKeys() error {
+	s.compactions++
+	return s.Store.CompactRetainHeightKeys()
+}
+
+func TestPrunerRetainHeightKeysCompaction(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	store := &compactingStore{Store: f.stateStore}
+	pruner := sm.NewPruner(store, f.bs, f.blockIndexer, f.txIndexer, log.TestingLogger(),
+		sm.WithPrunerCompanionEnabled(),
+		sm.WithPrunerRetainHeightKeysCompaction(3))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(2))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(2))
+	_, err := pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, store.compactions)
+
+	// The keys are compacted once they have been set 3 times.
+	require.NoError(t, pruner.SetABCIResRetainHeight(2))
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, store.compactions)
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, store.compactions)
+}
+
+func TestPrunerPauseResume(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	obs := &heartbeatObserver{}
+	pruner := f.newPruner(sm.WithPrunerInterval(time.Millisecond), sm.WithPrunerObserver(obs))
+	require.Equal(t, sm.PruningStatus{}, pruner.GetPruningStatus())
+	pruner.Pause()
+	require.NoError(t, pruner.Start())
+	defer func() { _ = pruner.Stop() }()
+	require.Equal(t, sm.PruningStatus{Running: true, Paused: true}, pruner.GetPruningStatus())
+
+	// Retain heights can be set while paused, but nothing is pruned.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	require.Eventually(t, func() bool {
+		heartbeats, _ := obs.counts()
+		return heartbeats >= 3
+	}, time.Second, time.Millisecond)
+	require.EqualValues(t, 1, f.bs.Base())
+
+	pruner.Resume()
+	require.False(t, pruner.GetPruningStatus().Paused)
+	require.Eventually(t, func() bool { return f.bs.Base() == 5 }, time.Second, time.Millisecond)
+}
+
+func TestPrunerABCIDivergenceWarn(t *testing.T) {
+	for _, tc := range []struct {
+		threshold int64
+		expWarn   bool
+	}{
+		{threshold: 0, expWarn: false},
+		{threshold: 6, expWarn: true},
+		{threshold: 7, expWarn: false},
+	} {
+		t.Run(fmt.Sprintf("threshold=%d", tc.threshold), func(t *testing.T) {
+			f := newPrunerFixture(t)
+
+			var buf bytes.Buffer
+			pruner := sm.NewPruner(f.stateStore, f.bs, f.blockIndexer, f.txIndexer, log.NewTMLogger(&buf),
+				sm.WithPrunerCompanionEnabled(), sm.WithPrunerABCIDivergenceWarn(tc.threshold))
+			// The ABCI results are pruned 7 heights ahead of the blocks.
+			require.NoError(t, pruner.SetABCIResRetainHeight(8))
+			info, err := pruner.PruneOnce(context.Background())
+			require.NoError(t, err)
+			require.NotNil(t, info.ABCIResponses)
+			require.Equal(t, tc.expWarn, strings.Contains(buf.String(), "ABCI results retain height and block store base diverge"))
+		})
+	}
+}
+
+func TestPrunerRetainLease(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	pruner := f.newPruner()
+	_, err := pruner.AcquireRetainLease(4, 0)
+	require.ErrorIs(t, err, sm.ErrInvalidRetainLeaseTTL)
+	_, err = pruner.AcquireRetainLease(11, time.Hour)
+	require.ErrorIs(t, err, sm.ErrInvalidHeightValue)
+
+	// The blocks pinned by a lease are kept, whatever the retain height.
+	id, err := pruner.AcquireRetainLease(4, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(8))
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 4, f.bs.Base())
+	_, err = pruner.PruneToHeight(6)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, f.bs.Base())
+
+	// And pruned once it is released.
+	require.NoError(t, pruner.RenewRetainLease(id, time.Hour))
+	require.NoError(t, pruner.ReleaseRetainLease(id))
+	require.ErrorIs(t, pruner.ReleaseRetainLease(id), sm.ErrRetainLeaseNotFound)
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 8, f.bs.Base())
+
+	// Or once it expires, e.g. if its consumer died.
+	id, err = pruner.AcquireRetainLease(8, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(10))
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 8, f.bs.Base())
+	time.Sleep(20 * time.Millisecond)
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 10, f.bs.Base())
+	require.ErrorIs(t, pruner.RenewRetainLease(id, time.Hour), sm.ErrRetainLeaseNotFound)
+}
+
+// holeyBlockStore is a block store missing the block at height hole.
+type holeyBlockStore struct {
+	*store.BlockStore
+	hole int64
+}
+
+func (bs *holeyBlockStore) LoadBlockMeta(height int64) *types.BlockMeta {
+	if height == bs.hole {
+		return nil
+	}
+	return bs.BlockStore.LoadBlockMeta(height)
+}
+
+func TestPrunerVerifyBeforePrune(t *testing.T) {
+	for _, verify := range []bool{false, true} {
+		t.Run(fmt.Sprintf("verify=%t", verify), func(t *testing.T) {
+			f := newPrunerFixture(t)
+
+			bs := &holeyBlockStore{BlockStore: f.bs, hole: 6}
+			pruner := sm.NewPruner(f.stateStore, bs, f.blockIndexer, f.txIndexer, log.TestingLogger(),
+				sm.WithPrunerVerifyBeforePrune(verify))
+			// The hole is above the retain height.
+			require.NoError(t, pruner.SetApplicationBlockRetainHeight(4))
+			_, err := pruner.PruneOnce(context.Background())
+			require.NoError(t, err)
+			require.EqualValues(t, 4, bs.Base())
+
+			require.NoError(t, pruner.SetApplicationBlockRetainHeight(8))
+			_, err = pruner.PruneOnce(context.Background())
+			if !verify {
+				require.NoError(t, err)
+				require.EqualValues(t, 8, bs.Base())
+				return
+			}
+			var holeErr sm.ErrBlockStoreHole
+			require.ErrorAs(t, err, &holeErr)
+			require.Equal(t, sm.ErrBlockStoreHole{Height: 6, Base: 4, RetainHeight: 8}, holeErr)
+			require.EqualValues(t, 4, bs.Base())
+		})
+	}
+}
+
+func TestPrunerNamedCompanionRetainHeights(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	pruner := f.newPruner(sm.WithPrunerCompanionEnabled())
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(9))
+	// The default data companion is the legacy one.
+	require.NoError(t, pruner.SetNamedCompanionRetainHeight(sm.DefaultCompanionName, 8))
+	height, err := pruner.GetCompanionBlockRetainHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 8, height)
+	require.NoError(t, pruner.SetNamedCompanionRetainHeight("analytics", 4))
+	require.NoError(t, pruner.SetNamedCompanionRetainHeight("archival", 6))
+	require.ErrorIs(t, pruner.SetNamedCompanionRetainHeight("archival", 5), sm.ErrPrunerCannotLowerRetainHeight)
+
+	rhs, err := pruner.RetainHeightSnapshot()
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"analytics": 4, "archival": 6}, rhs.NamedCompanionBlocks)
+	require.EqualValues(t, 4, rhs.EffectiveBlock)
+
+	// Blocks are only pruned below the lowest retain height.
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 4, f.bs.Base())
+	require.NoError(t, pruner.SetNamedCompanionRetainHeight("analytics", 7))
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 6, f.bs.Base())
+	_, err = pruner.PruneToHeight(9)
+	require.NoError(t, err)
+	require.EqualValues(t, 6, f.bs.Base())
+}
+
+func TestPrunerPrunableBlockStore(t *testing.T) {
+	state, _, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer cleanup()
+	require.NoError(t, stateStore.Save(state))
+
+	errPrune := errors.New("prune failed")
+	bs := mocks.NewPrunableBlockStore(t)
+	bs.On("Height").Return(int64(10))
+	bs.On("Base").Return(int64(1))
+	bs.On("PruneBlocks", int64(5), mock.Anything).Return(uint64(0), int64(0), errPrune)
+
+	pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger())
+	info, err := pruner.PruneToHeight(5)
+	require.ErrorIs(t, err, errPrune)
+	require.Equal(t, sm.ErrFailedToPruneBlocks{Height: 5, Err: errPrune}, err)
+	require.Nil(t, info.Blocks)
+}
+
+// prefetchRecordingBlockStore records the heights of the block metas loaded
+// through it before the blocks are pruned.
+type prefetchRecordingBlockStore struct {
+	*store.BlockStore
+	mtx        sync.Mutex
+	prefetched []int64
+	pruned     bool
+}
+
+func (bs *prefetchRecordingBlockStore) LoadBlockMeta(height int64) *types.BlockMeta {
+	bs.mtx.Lock()
+	if !bs.pruned {
+		bs.prefetched = append(bs.prefetched, height)
+	}
+	bs.mtx.Unlock()
+	return bs.BlockStore.LoadBlockMeta(height)
+}
+
+func (bs *prefetchRecordingBlockStore) PruneBlocks(height int64, state sm.State) (uint64, int64, error) {
+	bs.mtx.Lock()
+	bs.pruned = true
+	bs.mtx.Unlock()
+	return bs.BlockStore.PruneBlocks(height, state)
+}
+
+func TestPrunerPrefetch(t *testing.T) {
+	for _, prefetch := range []bool{false, true} {
+		t.Run(fmt.Sprintf("prefetch=%t", prefetch), func(t *testing.T) {
+			f := newPrunerFixture(t)
+
+			bs := &prefetchRecordingBlockStore{BlockStore: f.bs}
+			pruner := sm.NewPruner(f.stateStore, bs, f.blockIndexer, f.txIndexer, log.TestingLogger(),
+				sm.WithPrunerPrefetch(prefetch))
+			require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+			_, err := pruner.PruneOnce(context.Background())
+			require.NoError(t, err)
+			require.EqualValues(t, 5, bs.Base())
+			require.True(t, bs.pruned)
+			if !prefetch {
+				require.Empty(t, bs.prefetched)
+				return
+			}
+			require.Equal(t, []int64{1, 2, 3, 4}, bs.prefetched)
+		})
+	}
+}
+
+// archivingPruneStrategy records the blocks of the ranges it prunes before
+// deleting them from the block store.
+type archivingPruneStrategy struct {
+	bs       *store.BlockStore
+	archived []int64
+}
+
+func (s *archivingPruneStrategy) PruneRange(from, to int64, state sm.State) (uint64, int64, error) {
+	for h := from; h < to; h++ {
+		if block, _ := s.bs.LoadBlock(h); block != nil {
+			s.archived = append(s.archived, block.Height)
+		}
+	}
+	return s.bs.PruneBlocks(to, state)
+}
+
+func TestPrunerStrategy(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	strategy := &archivingPruneStrategy{bs: f.bs}
+	pruner := f.newPruner(sm.WithPrunerStrategy(strategy))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(4))
+	_, err := pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(7))
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, []int64{1, 2, 3, 4, 5, 6}, strategy.archived)
+	require.EqualValues(t, 7, f.bs.Base())
+}
+
+func TestPrunerTargetOverride(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	var hold atomic.Int64
+	hold.Store(3)
+	pruner := f.newPruner(sm.WithPrunerTargetOverride(func(target int64) int64 {
+		return min(target, hold.Load())
+	}))
+
+	// The blocks from the hold are kept.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(6))
+	_, err := pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 3, f.bs.Base())
+	rhs, err := pruner.RetainHeightSnapshot()
+	require.NoError(t, err)
+	require.EqualValues(t, 3, rhs.EffectiveBlock)
+
+	info, err := pruner.PruneToHeight(5)
+	require.NoError(t, err)
+	require.Nil(t, info.Blocks)
+	require.EqualValues(t, 3, f.bs.Base())
+
+	// An override raising the target is ignored.
+	hold.Store(9)
+	pruner = f.newPruner(sm.WithPrunerTargetOverride(func(int64) int64 { return hold.Load() }))
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 6, f.bs.Base())
+}
+
+func TestPrunerEvidenceWindowCheck(t *testing.T) {
+	for _, check := range []bool{false, true} {
+		t.Run(fmt.Sprintf("check=%t", check), func(t *testing.T) {
+			state, bs, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+			defer cleanup()
+			fillBlockStore(t, 10, bs, state)
+			state.LastValidators = state.Validators.Copy()
+			state.LastBlockHeight = 9
+			state.LastBlockTime = bs.LoadBlockMeta(9).Header.Time.Add(time.Hour)
+			state.ConsensusParams.Evidence.MaxAgeNumBlocks = 3
+			state.ConsensusParams.Evidence.MaxAgeDuration = time.Minute
+			require.NoError(t, stateStore.Save(state))
+			require.NoError(t, initStateStoreRetainHeights(stateStore))
+
+			pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(),
+				sm.WithPrunerEvidenceWindowCheck(check))
+			// Block 5 is 4 blocks old, outside of the window.
+			require.NoError(t, pruner.SetApplicationBlockRetainHeight(6))
+
+			// Block 6 is 3 blocks old, within the window.
+			err := pruner.SetApplicationBlockRetainHeight(7)
+			if !check {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, sm.ErrRetainHeightBelowEvidenceWindow{
+				RetainHeight:    7,
+				LastBlockHeight: 9,
+				MaxAgeNumBlocks: 3,
+				MaxAgeDuration:  time.Minute,
+			}, err)
+			retainHeight, err := stateStore.GetApplicationRetainHeight()
+			require.NoError(t, err)
+			require.EqualValues(t, 6, retainHeight)
+		})
+	}
+}
+
+func TestPrunerLogsNothingToPrune(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	var buf bytes.Buffer
+	pruner := sm.NewPruner(f.stateStore, f.bs, f.blockIndexer, f.txIndexer, log.NewTMLogger(&buf))
+	_, err := pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), `what=blocks reason="no retain height set"`)
+
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	buf.Reset()
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), `what=blocks reason="already at target"`)
+
+	// Starting from a height below the base, the target is below the base,
+	// so the pass doesn't start pruning.
+	buf.Reset()
+	obs := &hookObserver{}
+	pruner = sm.NewPruner(f.stateStore, f.bs, f.blockIndexer, f.txIndexer, log.NewTMLogger(&buf), sm.WithPrunerObserver(obs))
+	_, err = pruner.PruneBlocksToRetainHeight(0)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), `what=blocks reason="target below base"`)
+	require.Empty(t, obs.calls)
+}
+
+func TestPrunerABCIResTargetBelowPruned(t *testing.T) {
+	f := newPrunerFixture(t)
+	require.NoError(t, f.stateStore.SaveABCIResRetainHeight(3))
+
+	var buf, audit bytes.Buffer
+	obs := &hookObserver{}
+	pruner := sm.NewPruner(f.stateStore, f.bs, f.blockIndexer, f.txIndexer, log.NewTMLogger(&buf),
+		sm.WithPrunerObserver(obs), sm.WithPrunerAuditWriter(&audit))
+	// The ABCI results were already pruned up to height 5.
+	require.Equal(t, int64(5), pruner.PruneABCIResToRetainHeight(5))
+	require.Contains(t, buf.String(), `what="ABCI results" reason="target below base"`)
+	require.Empty(t, obs.calls)
+	require.Zero(t, audit.Len())
+}
+
+func TestPrunerEstimateBacklog(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	pruner := f.newPruner(sm.WithPrunerCompanionEnabled())
+	blocks, abciResponses, err := pruner.EstimateBacklog()
+	require.NoError(t, err)
+	require.Zero(t, blocks)
+	require.Zero(t, abciResponses)
+
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(7))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(5))
+	require.NoError(t, pruner.SetABCIResRetainHeight(4))
+	blocks, abciResponses, err = pruner.EstimateBacklog()
+	require.NoError(t, err)
+	require.EqualValues(t, 4, blocks)
+	require.EqualValues(t, 3, abciResponses)
+	// Nothing is pruned.
+	require.EqualValues(t, 1, f.bs.Base())
+
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	blocks, abciResponses, err = pruner.EstimateBacklog()
+	require.NoError(t, err)
+	require.Zero(t, blocks)
+	require.Zero(t, abciResponses)
+}
+
+func TestPrunerEstimatePruning(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	pruner := f.newPruner(sm.WithPrunerCompanionEnabled())
+	_, err := pruner.EstimatePruning(0)
+	require.ErrorIs(t, err, sm.ErrInvalidRetainHeight)
+	_, err = pruner.EstimatePruning(11)
+	require.ErrorIs(t, err, sm.ErrInvalidHeightValue)
+
+	// The blocks still needed by the data companion are kept, as with
+	// PruneToHeight.
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(5))
+	estimate, err := pruner.EstimatePruning(8)
+	require.NoError(t, err)
+	bytes, err := f.bs.EstimatePruneBytes(1, 5)
+	require.NoError(t, err)
+	require.Equal(t, sm.PruningEstimate{RetainHeight: 5, Heights: 4, Bytes: int64(bytes)}, *estimate)
+	require.Positive(t, estimate.Bytes)
+	// Nothing is pruned.
+	require.EqualValues(t, 1, f.bs.Base())
+
+	// The bytes are unknown if the block store can't estimate them.
+	pruner = sm.NewPruner(f.stateStore, struct{ sm.PrunableBlockStore }{f.bs}, f.blockIndexer, f.txIndexer,
+		log.TestingLogger())
+	estimate, err = pruner.EstimatePruning(8)
+	require.NoError(t, err)
+	require.Equal(t, sm.PruningEstimate{RetainHeight: 8, Heights: 7, Bytes: -1}, *estimate)
+
+	_, err = pruner.PruneToHeight(8)
+	require.NoError(t, err)
+	estimate, err = pruner.EstimatePruning(8)
+	require.NoError(t, err)
+	require.Equal(t, sm.PruningEstimate{RetainHeight: 8}, *estimate)
+}
+
+// flakyPruneStrategy fails to prune blocks with err the first failures times.
+type flakyPruneStrategy struct {
+	bs       *store.BlockStore
+	err      error
+	failures int
+	calls    int
+}
+
+func (s *flakyPruneStrategy) PruneRange(_, to int64, state sm.State) (uint64, int64, error) {
+	s.calls++
+	if s.calls <= s.failures {
+		return 0, -1, s.err
+	}
+	return s.bs.PruneBlocks(to, state)
+}
+
+// flakyPruneABCIResponsesStore fails to prune ABCI responses with err the
+// first failures times.
+type flakyPruneABCIResponsesStore struct {
+	sm.Store
+	err      error
+	failures int
+	calls    int
+}
+
+func (s *flakyPruneABCIResponsesStore) PruneABCIResponses(targetRetainHeight int64, forceCompact bool) (int64, int64, error) {
+	s.calls++
+	if s.calls <= s.failures {
+		return 0, 0, s.err
+	}
+	return s.Store.PruneABCIResponses(targetRetainHeight, forceCompact)
+}
+
+func TestPrunerTransientErrorRetries(t *testing.T) {
+	errBusy := errors.New("database busy")
+	for _, tc := range []struct {
+		name    string
+		err     error
+		expCall int
+		expErr  bool
+	}{
+		{"transient", fmt.Errorf("compacting: %w", sm.ErrTransientStoreError), 3, false},
+		{"retryable", errBusy, 3, false},
+		{"system", fmt.Errorf("open: %w", syscall.EAGAIN), 3, false},
+		{"read-only", fmt.Errorf("write: %w", syscall.EROFS), 3, false},
+		{"other", errors.New("corrupted"), 1, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newPrunerFixture(t)
+
+			strategy := &flakyPruneStrategy{bs: f.bs, err: tc.err, failures: 2}
+			flakyStore := &flakyPruneABCIResponsesStore{Store: f.stateStore, err: tc.err, failures: 2}
+			pruner := sm.NewPruner(flakyStore, f.bs, f.blockIndexer, f.txIndexer, log.TestingLogger(),
+				sm.WithPrunerCompanionEnabled(), sm.WithPrunerStrategy(strategy),
+				sm.WithPrunerTransientErrorRetries(2, 0, errBusy))
+			require.NoError(t, pruner.SetApplicationBlockRetainHeight(5))
+			require.NoError(t, pruner.SetCompanionBlockRetainHeight(5))
+			require.NoError(t, pruner.SetABCIResRetainHeight(5))
+			_, err := pruner.PruneOnce(context.Background())
+			require.Equal(t, tc.expCall, strategy.calls)
+			require.Equal(t, tc.expCall, flakyStore.calls)
+			if tc.expErr {
+				require.ErrorIs(t, err, strategy.err)
+				require.EqualValues(t, 1, f.bs.Base())
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, 5, f.bs.Base())
+		})
+	}
+}
+
+// readOnlyDB fails the synchronous writes with err, once it is set.
+type readOnlyDB struct {
+	db.DB
+	err error
+}
+
+func (db *readOnlyDB) SetSync(key, value []byte) error {
+	if db.err != nil {
+		return db.err
+	}
+	return db.DB.SetSync(key, value)
+}
+
+func TestPrunerStoreReadOnly(t *testing.T) {
+	state, bs, txIndexer, blockIndexer, cleanup, _ := makeStateAndBlockStoreAndIndexers()
+	defer cleanup()
+	fillBlockStore(t, 10, bs, state)
+
+	for _, tc := range []struct {
+		name        string
+		err         error
+		expReadOnly bool
+	}{
+		{"leveldb", leveldb.ErrReadOnly, true},
+		{"system", fmt.Errorf("write: %w", syscall.EROFS), true},
+		{"other", errors.New("corrupted"), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stateDB := &readOnlyDB{DB: db.NewMemDB()}
+			stateStore := sm.NewStore(stateDB, sm.StoreOptions{})
+			require.NoError(t, initStateStoreRetainHeights(stateStore))
+			stateDB.err = tc.err
+			pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger())
+			err := pruner.SetApplicationBlockRetainHeight(5)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.expReadOnly, errors.Is(err, sm.ErrStoreReadOnly))
+		})
+	}
+}
+
+func TestPrunerABCIResponseRetainDuration(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		retainHeight int64
+		duration     time.Duration
+		expTarget    int64
+	}{
+		{"height only", 4, 0, 4},
+		{"duration only", 0, 3 * time.Minute, 7},
+		{"height lower", 4, 3 * time.Minute, 4},
+		{"duration lower", 9, 3 * time.Minute, 7},
+		{"duration above history", 9, time.Hour, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			state, bs, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+			defer cleanup()
+			// One block a minute.
+			start := time.Now().Add(-time.Hour)
+			for h := int64(1); h <= 10; h++ {
+				block := state.MakeBlock(h, nil, new(types.Commit), nil, state.Validators.GetProposer().Address)
+				block.Time = start.Add(time.Duration(h) * time.Minute)
+				partSet, err := block.MakePartSet(types.BlockPartSizeBytes)
+				require.NoError(t, err)
+				bs.SaveBlock(block, partSet, &types.Commit{Height: h})
+			}
+			require.NoError(t, initStateStoreRetainHeights(stateStore))
+
+			pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(),
+				sm.WithPrunerCompanionEnabled(), sm.WithABCIResponseRetainDuration(tc.duration))
+			if tc.retainHeight > 0 {
+				require.NoError(t, pruner.SetABCIResRetainHeight(tc.retainHeight))
+			}
+			_, abciResponses, err := pruner.EstimateBacklog()
+			require.NoError(t, err)
+			require.Equal(t, tc.expTarget-1, abciResponses)
+
+			info, err := pruner.PruneOnce(context.Background())
+			require.NoError(t, err)
+			if tc.expTarget == 1 {
+				// Nothing is pruned, so nothing is reported.
+				require.Nil(t, info.ABCIResponses)
+				return
+			}
+			require.NotNil(t, info.ABCIResponses)
+			require.Equal(t, tc.expTarget-1, info.ABCIResponses.ToHeight)
+		})
+	}
+}
+
+func TestPrunerCompanionABCIResRetainHeight(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	pruner := f.newPruner(sm.WithPrunerCompanionEnabled())
+	_, err := pruner.GetCompanionABCIResRetainHeight()
+	require.ErrorIs(t, err, sm.ErrKeyNotFound)
+
+	// Until the companion ABCI results retain height is set, only the ABCI
+	// results retain height is used.
+	require.NoError(t, pruner.SetABCIResRetainHeight(6))
+	_, abciResponses, err := pruner.EstimateBacklog()
+	require.NoError(t, err)
+	require.EqualValues(t, 5, abciResponses)
+
+	// The lower of both is used.
+	require.NoError(t, pruner.SetCompanionABCIResRetainHeight(3))
+	info, err := pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 2, info.ABCIResponses.ToHeight)
+
+	require.NoError(t, pruner.SetCompanionABCIResRetainHeight(8))
+	info, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 5, info.ABCIResponses.ToHeight)
+
+	require.ErrorIs(t, pruner.SetCompanionABCIResRetainHeight(7), sm.ErrPrunerCannotLowerRetainHeight)
+	height, err := pruner.GetCompanionABCIResRetainHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 8, height)
+}
+
+func TestPrunerCompanionABCIResRetainHeightBelowPruned(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	pruner := f.newPruner(sm.WithPrunerCompanionEnabled())
+	require.NoError(t, pruner.SetABCIResRetainHeight(6))
+	_, err := pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+
+	// The ABCI results below height 6 are already pruned.
+	require.ErrorIs(t, pruner.SetCompanionABCIResRetainHeight(5), sm.ErrInvalidHeightValue)
+	require.NoError(t, pruner.SetCompanionABCIResRetainHeight(6))
+}
+
+// blockPrunedObserver records the pruned block ranges and heights.
+type blockPrunedObserver struct {
+	sm.NoopPrunerObserver
+	bs      sm.PrunableBlockStore
+	ranges  [][2]int64
+	heights []int64
+}
+
+func (o *blockPrunedObserver) PrunerPrunedBlockRange(fromHeight, toHeight int64) {
+	// The blocks are deleted before they are reported.
+	if o.bs.Base() <= toHeight {
+		panic("block reported before being deleted")
+	}
+	o.ranges = append(o.ranges, [2]int64{fromHeight, toHeight})
+}
+
+func (o *blockPrunedObserver) PrunerBlockPruned(height int64) {
+	o.heights = append(o.heights, height)
+}
+
+func TestPrunerBlockPrunedCallbacks(t *testing.T) {
+	for _, perHeight := range []bool{false, true} {
+		t.Run(fmt.Sprintf("perHeight=%t", perHeight), func(t *testing.T) {
+			f := newPrunerFixture(t)
+
+			observer := &blockPrunedObserver{bs: f.bs}
+			pruner := f.newPruner(sm.WithPrunerObserver(observer), sm.WithPrunerPerHeightCallbacks(perHeight))
+			require.NoError(t, pruner.SetApplicationBlockRetainHeight(4))
+			_, err := pruner.PruneOnce(context.Background())
+			require.NoError(t, err)
+			_, err = pruner.PruneToHeight(6)
+			require.NoError(t, err)
+
+			require.Equal(t, [][2]int64{{1, 3}, {4, 5}}, observer.ranges)
+			if !perHeight {
+				require.Empty(t, observer.heights)
+				return
+			}
+			require.Equal(t, []int64{1, 2, 3, 4, 5}, observer.heights)
+		})
+	}
+}
+
+// indexerPrunedObserver records the calls to IndexerPruned.
+type indexerPrunedObserver struct {
+	sm.NoopPrunerObserver
+	retainHeights []int64
+	numPruned     []int64
+	errs          []error
+}
+
+func (o *indexerPrunedObserver) IndexerPruned(retainHeight, numPruned int64, err error) {
+	o.retainHeights = append(o.retainHeights, retainHeight)
+	o.numPruned = append(o.numPruned, numPruned)
+	o.errs = append(o.errs, err)
+}
+
+func TestPrunerIndexerPruned(t *testing.T) {
+	_, txIndexer, blockIndexer := createTestSetup(t)
+	for height := int64(1); height <= 4; height++ {
+		events, txResult1, txResult2 := getEventsAndResults(height)
+		require.NoError(t, blockIndexer.Index(events))
+		require.NoError(t, txIndexer.Index(txResult1))
+		require.NoError(t, txIndexer.Index(txResult2))
+	}
+	observer := &indexerPrunedObserver{}
+	stateStore := sm.NewStore(db.NewMemDB(), sm.StoreOptions{})
+	pruner := sm.NewPruner(stateStore, store.NewBlockStore(db.NewMemDB()), &blockIndexer, txIndexer,
+		log.TestingLogger(), sm.WithPrunerCompanionEnabled(), sm.WithPrunerObserver(observer))
+
+	// Nothing is reported until something is pruned.
+	info, err := pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, info.Indexer)
+	require.Empty(t, observer.retainHeights)
+
+	require.NoError(t, pruner.SetTxIndexerRetainHeight(3))
+	require.NoError(t, pruner.SetBlockIndexerRetainHeight(2))
+	info, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &sm.IndexerPrunedInfo{RetainHeight: 2, TxIndexerHeights: 2, BlockIndexerHeights: 1}, info.Indexer)
+	require.Equal(t, []int64{2}, observer.retainHeights)
+	require.Equal(t, []int64{3}, observer.numPruned)
+	require.Equal(t, []error{nil}, observer.errs)
+
+	// Errors from the indexers are reported.
+	pruner = sm.NewPruner(stateStore, store.NewBlockStore(db.NewMemDB()), &blockIndexer,
+		failingPruneTxIndexer{txIndexer}, log.TestingLogger(), sm.WithPrunerObserver(observer))
+	require.NoError(t, pruner.SetTxIndexerRetainHeight(4))
+	var pruneErr sm.ErrFailedToPruneTxIndexer
+	require.ErrorAs(t, pruner.PruneIndexesNow(), &pruneErr)
+	require.Len(t, observer.errs, 2)
+	require.ErrorAs(t, observer.errs[1], &pruneErr)
+	require.Zero(t, observer.numPruned[1])
+}
+
+// blockingPruneStrategy blocks its first call to PruneRange until released.
+type blockingPruneStrategy struct {
+	bs       *store.BlockStore
+	started  chan struct{}
+	released chan struct{}
+	calls    atomic.Int32
+}
+
+func (s *blockingPruneStrategy) PruneRange(_, to int64, state sm.State) (uint64, int64, error) {
+	if s.calls.Add(1) == 1 {
+		close(s.started)
+		<-s.released
+	}
+	return s.bs.PruneBlocks(to, state)
+}
+
+// pauseObserver records the changes of the paused state of the pruner.
+type pauseObserver struct {
+	sm.NoopPrunerObserver
+	mtx    sync.Mutex
+	paused []bool
+}
+
+func (o *pauseObserver) PrunerPauseChanged(paused bool) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.paused = append(o.paused, paused)
+}
+
+func (o *pauseObserver) changes() []bool {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return slices.Clone(o.paused)
+}
+
+func TestPrunerPauseDuringPass(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	clock := newManualClock()
+	obs := &pauseObserver{}
+	strategy := &blockingPruneStrategy{bs: f.bs, started: make(chan struct{}), released: make(chan struct{})}
+	pruner := f.newPruner(sm.WithPrunerInterval(time.Hour), sm.WithPrunerObserver(obs), sm.WithPrunerClock(clock),
+		sm.WithPrunerStrategy(strategy))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(4))
+	require.NoError(t, pruner.Start())
+	defer func() { require.NoError(t, pruner.Stop()) }()
+
+	// Pausing doesn't interrupt the pass already pruning.
+	<-strategy.started
+	pruner.Pause()
+	require.Equal(t, []bool{true}, obs.changes())
+	close(strategy.released)
+	wait := clock.next(t)
+	require.EqualValues(t, 4, f.bs.Base())
+
+	// The next cycles don't prune while paused.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(7))
+	wait.elapse()
+	clock.next(t)
+	require.EqualValues(t, 4, f.bs.Base())
+	require.EqualValues(t, 1, strategy.calls.Load())
+
+	// Resuming prunes right away, without waiting for the interval.
+	pruner.Resume()
+	require.Equal(t, []bool{true, false}, obs.changes())
+	clock.next(t)
+	require.EqualValues(t, 7, f.bs.Base())
+	require.EqualValues(t, 2, strategy.calls.Load())
+}
+
+// snapshotHeights is a SnapshotHeightProvider returning height, or err.
+type snapshotHeights struct {
+	height int64
+	err    error
+}
+
+func (s *snapshotHeights) LowestServableSnapshotHeight() (int64, error) {
+	return s.height, s.err
+}
+
+func TestPrunerSnapshotHeightProvider(t *testing.T) {
+	state, bs, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer cleanup()
+	fillBlockStore(t, 20, bs, state)
+	state.LastValidators = state.Validators.Copy()
+	for h := int64(0); h < 20; h++ {
+		state.LastBlockHeight = h
+		require.NoError(t, stateStore.Save(state))
+	}
+	require.NoError(t, initStateStoreRetainHeights(stateStore))
+
+	provider := &snapshotHeights{height: 4}
+	pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(),
+		sm.WithPrunerSnapshotHeightProvider(provider))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(10))
+
+	// The blocks of the lowest snapshot served are kept.
+	_, err := pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 4, bs.Base())
+
+	// An older snapshot expired.
+	provider.height = 8
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 8, bs.Base())
+
+	// Nothing is pruned if the snapshots can't be listed.
+	provider.height, provider.err = 0, errors.New("application unavailable")
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 8, bs.Base())
+
+	// Without snapshots, the retain height applies.
+	provider.err = nil
+	_, err = pruner.PruneOnce(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 10, bs.Base())
+
+	provider.height = 12
+	_, err = pruner.PruneToHeight(15)
+	require.NoError(t, err)
+	require.EqualValues(t, 12, bs.Base())
+}
+
+func TestAppSnapshotHeightProvider(t *testing.T) {
+	conn := &pmocks.AppConnSnapshot{}
+	conn.On("ListSnapshots", mock.Anything, mock.Anything).Return(&abci.ListSnapshotsResponse{
+		Snapshots: []*abci.Snapshot{{Height: 30}, {Height: 10}, {Height: 20}},
+	}, nil).Once()
+	conn.On("ListSnapshots", mock.Anything, mock.Anything).Return(&abci.ListSnapshotsResponse{}, nil).Once()
+	provider := sm.NewAppSnapshotHeightProvider(conn)
+
+	height, err := provider.LowestServableSnapshotHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 10, height)
+	height, err = provider.LowestServableSnapshotHeight()
+	require.NoError(t, err)
+	require.Zero(t, height)
+	conn.AssertExpectations(t)
+}
+
+// compactingDatabaseStore records the compactions of its whole database.
+type compactingDatabaseStore struct {
+	sm.Store
+	compactions atomic.Int32
+}
+
+func (s *compactingDatabaseStore) CompactDatabase() (int64, int64, error) {
+	s.compactions.Add(1)
+	return 100, 40, nil
+}
+
+func TestPrunerStateCompactionInterval(t *testing.T) {
+	_, bs, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer cleanup()
+
+	clock := newManualClock()
+	compactingStore := &compactingDatabaseStore{Store: stateStore}
+	pruner := sm.NewPruner(compactingStore, bs, blockIndexer, txIndexer, log.TestingLogger(),
+		sm.WithPrunerInterval(time.Hour), sm.WithPrunerClock(clock),
+		sm.WithStateCompactionInterval(10*time.Minute))
+	require.NoError(t, pruner.Start())
+	defer func() { require.NoError(t, pruner.Stop()) }()
+
+	// The state store is compacted on its own schedule, whatever is pruned.
+	nextCompaction := func() manualWait {
+		for {
+			if w := clock.next(t); w.d == 10*time.Minute {
+				return w
+			}
+		}
+	}
+	wait := nextCompaction()
+	require.Zero(t, compactingStore.compactions.Load())
+	wait.elapse()
+	wait = nextCompaction()
+	require.EqualValues(t, 1, compactingStore.compactions.Load())
+
+	// Compactions are skipped while pruning is paused.
+	pruner.Pause()
+	wait.elapse()
+	wait = nextCompaction()
+	require.EqualValues(t, 1, compactingStore.compactions.Load())
+	pruner.Resume()
+	wait.elapse()
+	nextCompaction()
+	require.EqualValues(t, 2, compactingStore.compactions.Load())
+}
+
+func TestNewPrunerWithValidation(t *testing.T) {
+	_, bs, txIndexer, blockIndexer, cleanup, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer cleanup()
+
+	testCases := []struct {
+		name    string
+		options []sm.PrunerOption
+		valid   bool
+	}{
+		{"defaults", nil, true},
+		{
+			"ABCI results options with the data companion",
+			[]sm.PrunerOption{
+				sm.WithPrunerCompanionEnabled(),
+				sm.WithABCIResponseRetainDuration(time.Hour),
+				sm.WithPrunerCoupleABCIToBlocks(true),
+			},
+			true,
+		},
+		{
+			"all phases",
+			[]sm.PrunerOption{sm.WithPrunerPhaseOrder([]sm.PrunePhase{sm.PrunePhaseABCI, sm.PrunePhaseBlocks, sm.PrunePhaseIndexer})},
+			true,
+		},
+		{"zero interval", []sm.PrunerOption{sm.WithPrunerInterval(0)}, false},
+		{"negative ABCI interval", []sm.PrunerOption{sm.WithABCIPruningInterval(-time.Second)}, false},
+		{"negative state compaction interval", []sm.PrunerOption{sm.WithStateCompactionInterval(-time.Second)}, false},
+		{"nil observer", []sm.PrunerOption{sm.WithPrunerObserver(nil)}, false},
+		{"nil metrics", []sm.PrunerOption{sm.WithPrunerMetrics(nil)}, false},
+		{"nil clock", []sm.PrunerOption{sm.WithPrunerClock(nil)}, false},
+		{
+			"ABCI results retain duration without the data companion",
+			[]sm.PrunerOption{sm.WithABCIResponseRetainDuration(time.Hour)},
+			false,
+		},
+		{
+			"ABCI results retain duration with ABCI results discarded",
+			[]sm.PrunerOption{
+				sm.WithPrunerCompanionEnabled(),
+				sm.WithPrunerABCIResponsesDiscarded(true),
+				sm.WithABCIResponseRetainDuration(time.Hour),
+			},
+			false,
+		},
+		{
+			"negative ABCI results retain duration",
+			[]sm.PrunerOption{sm.WithPrunerCompanionEnabled(), sm.WithABCIResponseRetainDuration(-time.Hour)},
+			false,
+		},
+		{
+			"ABCI results coupled to blocks without the data companion",
+			[]sm.PrunerOption{sm.WithPrunerCoupleABCIToBlocks(true)},
+			false,
+		},
+		{
+			"duplicate phase",
+			[]sm.PrunerOption{sm.WithPrunerPhaseOrder([]sm.PrunePhase{sm.PrunePhaseBlocks, sm.PrunePhaseBlocks})},
+			false,
+		},
+		{
+			"unknown phase",
+			[]sm.PrunerOption{sm.WithPrunerPhaseOrder([]sm.PrunePhase{sm.PrunePhase(42)})},
+			false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pruner, err := sm.NewPrunerWithValidation(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(), tc.options...)
+			if tc.valid {
+				require.NoError(t, err)
+				require.NotNil(t, pruner)
+				return
+			}
+			require.ErrorIs(t, err, sm.ErrInvalidPrunerOptions)
+			require.Nil(t, pruner)
+		})
+	}
+}
+
+func TestPrunerCompletesInterruptedPrune(t *testing.T) {
+	for _, blocksPruned := range []bool{false, true} {
+		t.Run(fmt.Sprintf("blocksPruned=%t", blocksPruned), func(t *testing.T) {
+			f := newPrunerFixture(t)
+
+			// Simulate a crash after the prune was journaled, before or after
+			// the blocks were pruned, but before their states were.
+			require.NoError(t, f.stateStore.SavePruneJournal(sm.PruneJournalEntry{FromHeight: 1, ToHeight: 5}))
+			if blocksPruned {
+				_, _, err := f.bs.PruneBlocks(5, f.state)
+				require.NoError(t, err)
+			}
+
+			observer := &statesObserver{}
+			pruner := f.newPruner(sm.WithPrunerInterval(time.Hour), sm.WithPrunerObserver(observer))
+			require.NoError(t, pruner.Start())
+			defer func() { require.NoError(t, pruner.Stop()) }()
+
+			require.EqualValues(t, 5, f.bs.Base())
+			require.Len(t, observer.infos, 1)
+			require.EqualValues(t, 1, observer.infos[0].FromHeight)
+			require.EqualValues(t, 4, observer.infos[0].ToHeight)
+			_, err := f.stateStore.GetPruneJournal()
+			require.ErrorIs(t, err, sm.ErrKeyNotFound)
+		})
+	}
+}
+
+func TestPrunerPruneJournal(t *testing.T) {
+	f := newPrunerFixture(t)
+
+	// The journal is deleted once the blocks and their states are pruned.
+	strategy := &flakyPruneStrategy{bs: f.bs, err: errors.New("crashed"), failures: 1}
+	pruner := f.newPruner(sm.WithPrunerStrategy(strategy))
+	_, err := pruner.PruneToHeight(4)
+	require.Error(t, err)
+	journal, err := f.stateStore.GetPruneJournal()
+	require.NoError(t, err)
+	require.Equal(t, sm.PruneJournalEntry{FromHeight: 1, ToHeight: 4}, journal)
+
+	_, err = pruner.PruneToHeight(4)
+	require.NoError(t, err)
+	_, err = f.stateStore.GetPruneJournal()
+	require.ErrorIs(t, err, sm.ErrKeyNotFound)
+}
+
+func TestPrunerCompanionSafetyMargin(t *testing.T) {
+	testCases := []struct {
+		name         string
+		dcEnabled    bool
+		margin       int64
+		appHeight    int64
+		dcHeight     int64
+		retainHeight int64
+	}{
+		{"no margin", true, 0, 8, 6, 6},
+		{"margin below the application", true, 3, 8, 6, 5},
+		{"companion below the margin", true, 3, 8, 4, 4},
+		{"margin above the application", true, 10, 8, 6, 0},
+		{"companion disabled", false, 3, 8, 6, 8},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newPrunerFixture(t)
+
+			opts := []sm.PrunerOption{sm.WithPrunerCompanionSafetyMargin(tc.margin)}
+			if tc.dcEnabled {
+				opts = append(opts, sm.WithPrunerCompanionEnabled())
+			}
+			pruner := f.newPruner(opts...)
+			require.NoError(t, pruner.SetApplicationBlockRetainHeight(tc.appHeight))
+			require.NoError(t, pruner.SetCompanionBlockRetainHeight(tc.dcHeight))
+
+			rhs, err := pruner.RetainHeightSnapshot()
+			require.NoError(t, err)
+			require.Equal(t, tc.retainHeight, rhs.EffectiveBlock)
+
+			_, err = pruner.PruneOnce(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, max(tc.retainHeight, 1), f.bs.Base())
+		})
+	}
+}
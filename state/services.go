@@ -28,6 +28,7 @@ type BlockStore interface {
 	SaveBlockWithExtendedCommit(block *types.Block, blockParts *types.PartSet, seenCommit *types.ExtendedCommit)
 
 	PruneBlocks(height int64, state State) (uint64, int64, error)
+	PruneOrphanedBlockParts(from, to int64) (uint64, error)
 
 	LoadBlockByHash(hash []byte) (*types.Block, *types.BlockMeta)
 	LoadBlockMetaByHash(hash []byte) *types.BlockMeta
@@ -42,6 +43,23 @@ type BlockStore interface {
 	Close() error
 }
 
+//go:generate ../scripts/mockery_generate.sh PrunableBlockStore
+
+// PrunableBlockStore defines the subset of the BlockStore interface used by
+// the Pruner, so that tests and tools can give it a block store of their own
+// without implementing the whole BlockStore.
+type PrunableBlockStore interface {
+	Base() int64
+	Height() int64
+
+	LoadBlockMeta(height int64) *types.BlockMeta
+
+	PruneBlocks(height int64, state State) (uint64, int64, error)
+	PruneOrphanedBlockParts(from, to int64) (uint64, error)
+}
+
+var _ PrunableBlockStore = BlockStore(nil)
+
 // -----------------------------------------------------------------------------
 // evidence pool
 
@@ -1,14 +1,18 @@
 package state
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"slices"
+	"syscall"
 	"time"
 
 	"github.com/cosmos/gogoproto/proto"
 	"github.com/go-kit/kit/metrics"
 	"github.com/google/orderedcode"
+	"github.com/syndtr/goleveldb/leveldb"
 
 	dbm "github.com/cometbft/cometbft-db"
 	abci "github.com/cometbft/cometbft/abci/types"
@@ -67,6 +71,9 @@ var (
 	lastABCIResponseKey              = []byte("lastABCIResponseKey") // DEPRECATED
 	lastABCIResponsesRetainHeightKey = []byte("lastABCIResponsesRetainHeight")
 	offlineStateSyncHeight           = []byte("offlineStateSyncHeightKey")
+	prunerLeaseKey                   = []byte("prunerLeaseKey")
+	pruneJournalKey                  = []byte("pruneJournalKey")
+	retainHeightJournalPrefix        = []byte("retainHeightJournal:")
 )
 
 var (
@@ -132,22 +139,74 @@ type Store interface {
 	SaveFinalizeBlockResponse(height int64, res *abci.FinalizeBlockResponse) error
 	// Bootstrap is used for bootstrapping state when not starting from a initial height.
 	Bootstrap(state State) error
+	// SaveValidatorSets saves the validator set at all heights from lowerHeight
+	// to upperHeight, inclusive, e.g. when backfilling heights after state sync
+	SaveValidatorSets(lowerHeight, upperHeight int64, vals *types.ValidatorSet) error
 	// PruneStates takes the height from which to start pruning and which height stop at
-	PruneStates(fromHeight, toHeight, evidenceThresholdHeight int64, previouslyPrunedStates uint64) (uint64, error)
+	PruneStates(fromHeight, toHeight, evidenceThresholdHeight int64, previouslyPrunedStates uint64) (PrunedStatesCount, error)
 	// PruneABCIResponses will prune all ABCI responses below the given height.
+	// It returns the number of heights pruned and the new retain height, also
+	// on error, as some heights may have been pruned before it happened.
 	PruneABCIResponses(targetRetainHeight int64, forceCompact bool) (int64, int64, error)
-	// SaveApplicationRetainHeight persists the application retain height from the application
+	// GetLastABCIResponsesRetainHeight returns the height up to which
+	// PruneABCIResponses last pruned the ABCI responses, or 0 if it never
+	// pruned any.
+	GetLastABCIResponsesRetainHeight() (int64, error)
+	// SaveApplicationRetainHeight persists the application retain height from the application.
+	// Like the other retain heights, it is durable once it returns.
 	SaveApplicationRetainHeight(height int64) error
 	// GetApplicationRetainHeight returns the retain height set by the application
 	GetApplicationRetainHeight() (int64, error)
-	// SaveCompanionBlockRetainHeight saves the retain height set by the data companion
+	// SaveCompanionBlockRetainHeight saves the retain height set by the data companion.
+	// It is durable once it returns, so that a crash can't make the node prune
+	// blocks again that the data companion has deleted its own copies of.
 	SaveCompanionBlockRetainHeight(height int64) error
 	// GetCompanionBlockRetainHeight returns the retain height set by the data companion
 	GetCompanionBlockRetainHeight() (int64, error)
-	// SaveABCIResRetainHeight persists the retain height for ABCI results set by the data companion
+	// SaveNamedCompanionBlockRetainHeight saves the block retain height set by
+	// the data companion name, when several of them are run. It is durable
+	// once it returns.
+	SaveNamedCompanionBlockRetainHeight(name string, height int64) error
+	// GetNamedCompanionBlockRetainHeights returns the block retain heights set
+	// by the named data companions, by name. The one of the default data
+	// companion, returned by GetCompanionBlockRetainHeight, is not included.
+	GetNamedCompanionBlockRetainHeights() (map[string]int64, error)
+	// SaveABCIResRetainHeight persists the retain height for ABCI results set by the data companion.
+	// It is durable once it returns.
 	SaveABCIResRetainHeight(height int64) error
 	// GetABCIResRetainHeight returns the last saved retain height for ABCI results set by the data companion
 	GetABCIResRetainHeight() (int64, error)
+	// SaveCompanionABCIResRetainHeight persists the retain height for ABCI
+	// results set by the data companion archiving them. It is durable once it
+	// returns.
+	SaveCompanionABCIResRetainHeight(height int64) error
+	// GetCompanionABCIResRetainHeight returns the last saved retain height for
+	// ABCI results set by the data companion archiving them
+	GetCompanionABCIResRetainHeight() (int64, error)
+	// LoadRetainHeightAt returns the application, data companion and ABCI
+	// results retain heights as they were set at time t, rebuilt from the
+	// journal of the retain heights, or 0 for those not set yet. It returns
+	// ErrNotSupported if the store keeps no such journal, see
+	// StoreOptions.RetainHeightJournal.
+	LoadRetainHeightAt(t time.Time) (app, companion, abci int64, err error)
+	// CompactRetainHeightKeys compacts the range of keys holding the retain
+	// heights, which are rewritten every time a retain height is set.
+	CompactRetainHeightKeys() error
+	// SavePrunerLease persists the advisory lock of the pruner of the store
+	SavePrunerLease(lease PrunerLease) error
+	// GetPrunerLease returns the advisory lock of the pruner of the store
+	GetPrunerLease() (PrunerLease, error)
+	// DeletePrunerLease deletes the advisory lock of the pruner of the store
+	DeletePrunerLease() error
+	// SavePruneJournal persists the range of blocks the pruner of the store is
+	// about to prune. It is durable once it returns.
+	SavePruneJournal(entry PruneJournalEntry) error
+	// GetPruneJournal returns the range of blocks the pruner of the store was
+	// pruning, if it was interrupted
+	GetPruneJournal() (PruneJournalEntry, error)
+	// DeletePruneJournal deletes the range of blocks the pruner of the store
+	// was pruning, once it is pruned
+	DeletePruneJournal() error
 	// Saves the height at which the store is bootstrapped after out of band statesync
 	SetOfflineStateSyncHeight(height int64) error
 	// Gets the height at which the store is bootstrapped after out of band statesync
@@ -183,6 +242,12 @@ type StoreOptions struct {
 	Logger log.Logger
 
 	DBKeyLayout string
+
+	// RetainHeightJournal determines whether the store journals every update
+	// of the application, data companion and ABCI results retain heights with
+	// its time, so that Store.LoadRetainHeightAt can tell what they were at a
+	// past time. The journal grows with every update and is never pruned.
+	RetainHeightJournal bool
 }
 
 var _ Store = (*dbStore)(nil)
@@ -416,31 +481,39 @@ func (store dbStore) Bootstrap(state State) error {
 	return batch.Close()
 }
 
+// PrunedStatesCount is the number of entries deleted by PruneStates.
+type PrunedStatesCount struct {
+	Heights         uint64 // The number of heights pruned.
+	ValidatorSets   uint64 // The number of validator sets deleted.
+	ConsensusParams uint64 // The number of consensus params deleted.
+}
+
 // PruneStates deletes states between the given heights (including from, excluding to). It is not
 // guaranteed to delete all states, since the last checkpointed state and states being pointed to by
-// e.g. `LastHeightChanged` must remain. The state at to must also exist.
+// e.g. `LastHeightChanged` must remain. The state at to must also exist. The validator sets at and
+// above evidenceThresholdHeight are kept for evidence verification.
 //
 // The from parameter is necessary since we can't do a key scan in a performant way due to the key
 // encoding not preserving ordering: https://github.com/tendermint/tendermint/issues/4567
 // This will cause some old states to be left behind when doing incremental partial prunes,
 // specifically older checkpoints and LastHeightChanged targets.
-func (store dbStore) PruneStates(from int64, to int64, evidenceThresholdHeight int64, previosulyPrunedStates uint64) (uint64, error) {
+func (store dbStore) PruneStates(from int64, to int64, evidenceThresholdHeight int64, previosulyPrunedStates uint64) (PrunedStatesCount, error) {
 	defer addTimeSample(store.StoreOptions.Metrics.StoreAccessDurationSeconds.With("method", "prune_states"), time.Now())()
 	if from <= 0 || to <= 0 {
-		return 0, fmt.Errorf("from height %v and to height %v must be greater than 0", from, to)
+		return PrunedStatesCount{}, fmt.Errorf("from height %v and to height %v must be greater than 0", from, to)
 	}
 	if from >= to {
-		return 0, fmt.Errorf("from height %v must be lower than to height %v", from, to)
+		return PrunedStatesCount{}, fmt.Errorf("from height %v must be lower than to height %v", from, to)
 	}
 
 	valInfo, elapsedTime, err := loadValidatorsInfo(store.db, store.DBKeyLayout.CalcValidatorsKey(min(to, evidenceThresholdHeight)))
 	if err != nil {
-		return 0, fmt.Errorf("validators at height %v not found: %w", to, err)
+		return PrunedStatesCount{}, fmt.Errorf("validators at height %v not found: %w", to, err)
 	}
 
 	paramsInfo, err := store.loadConsensusParamsInfo(to)
 	if err != nil {
-		return 0, fmt.Errorf("consensus params at height %v not found: %w", to, err)
+		return PrunedStatesCount{}, fmt.Errorf("consensus params at height %v not found: %w", to, err)
 	}
 
 	keepVals := make(map[int64]bool)
@@ -455,7 +528,7 @@ func (store dbStore) PruneStates(from int64, to int64, evidenceThresholdHeight i
 
 	batch := store.db.NewBatch()
 	defer batch.Close()
-	pruned := uint64(0)
+	var pruned PrunedStatesCount
 
 	// We have to delete in reverse order, to avoid deleting previous heights that have validator
 	// sets and consensus params that we may need to retrieve.
@@ -494,6 +567,7 @@ func (store dbStore) PruneStates(from int64, to int64, evidenceThresholdHeight i
 			if err != nil {
 				return pruned, err
 			}
+			pruned.ValidatorSets++
 		}
 		// else we keep the validator set because we might need
 		// it later on for evidence verification
@@ -527,16 +601,17 @@ func (store dbStore) PruneStates(from int64, to int64, evidenceThresholdHeight i
 			if err != nil {
 				return pruned, err
 			}
+			pruned.ConsensusParams++
 		}
 
 		err = batch.Delete(store.DBKeyLayout.CalcABCIResponsesKey(h))
 		if err != nil {
 			return pruned, err
 		}
-		pruned++
+		pruned.Heights++
 
 		// avoid batches growing too large by flushing to database regularly
-		if pruned%1000 == 0 && pruned > 0 {
+		if pruned.Heights%1000 == 0 && pruned.Heights > 0 {
 			err := batch.Write()
 			if err != nil {
 				return pruned, err
@@ -553,7 +628,7 @@ func (store dbStore) PruneStates(from int64, to int64, evidenceThresholdHeight i
 	}
 
 	// We do not want to panic or interrupt consensus on compaction failure
-	if store.StoreOptions.Compact && previosulyPrunedStates+pruned >= uint64(store.StoreOptions.CompactionInterval) {
+	if store.StoreOptions.Compact && previosulyPrunedStates+pruned.Heights >= uint64(store.StoreOptions.CompactionInterval) {
 		// When the range is nil,nil, the database will try to compact
 		// ALL levels. Another option is to set a predefined range of
 		// specific keys.
@@ -565,14 +640,16 @@ func (store dbStore) PruneStates(from int64, to int64, evidenceThresholdHeight i
 }
 
 // PruneABCIResponses attempts to prune all ABCI responses up to, but not
-// including, the given height. On success, returns the number of heights
-// pruned and the new retain height.
+// including, the given height. Returns the number of heights pruned and the
+// new retain height, i.e. the height just after the last one pruned. On error,
+// these reflect the heights pruned before the error, which are persisted so
+// that the next call resumes from there.
 func (store dbStore) PruneABCIResponses(targetRetainHeight int64, forceCompact bool) (pruned int64, newRetainHeight int64, err error) {
 	if store.DiscardABCIResponses {
 		return 0, 0, nil
 	}
 	defer addTimeSample(store.StoreOptions.Metrics.StoreAccessDurationSeconds.With("method", "prune_abci_responses"), time.Now())()
-	lastRetainHeight, err := store.getLastABCIResponsesRetainHeight()
+	lastRetainHeight, err := store.GetLastABCIResponsesRetainHeight()
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to look up last ABCI responses retain height: %w", err)
 	}
@@ -598,7 +675,7 @@ func (store dbStore) PruneABCIResponses(targetRetainHeight int64, forceCompact b
 
 			pruned += batchPruned
 			batchPruned = 0
-			if err := store.setLastABCIResponsesRetainHeight(h); err != nil {
+			if err := store.setLastABCIResponsesRetainHeight(lastRetainHeight + pruned); err != nil {
 				return pruned, lastRetainHeight + pruned, fmt.Errorf("failed to set last ABCI responses retain height: %w", err)
 			}
 
@@ -608,15 +685,21 @@ func (store dbStore) PruneABCIResponses(targetRetainHeight int64, forceCompact b
 	}
 
 	if err = batch.WriteSync(); err != nil {
-		return pruned + batchPruned, targetRetainHeight, err
+		return pruned, lastRetainHeight + pruned, err
+	}
+	pruned += batchPruned
+	if pruned > 0 {
+		if err := store.setLastABCIResponsesRetainHeight(targetRetainHeight); err != nil {
+			return pruned, targetRetainHeight, fmt.Errorf("failed to set last ABCI responses retain height: %w", err)
+		}
 	}
 
 	if forceCompact && store.Compact {
-		if pruned+batchPruned >= store.CompactionInterval || targetRetainHeight-lastRetainHeight >= store.CompactionInterval {
+		if pruned >= store.CompactionInterval || targetRetainHeight-lastRetainHeight >= store.CompactionInterval {
 			err = store.db.Compact(nil, nil)
 		}
 	}
-	return pruned + batchPruned, targetRetainHeight, err
+	return pruned, targetRetainHeight, err
 }
 
 // ------------------------------------------------------------------------
@@ -771,7 +854,7 @@ func (store dbStore) getValue(key []byte) ([]byte, error) {
 
 // ApplicationRetainHeight.
 func (store dbStore) SaveApplicationRetainHeight(height int64) error {
-	return store.db.SetSync(AppRetainHeightKey, int64ToBytes(height))
+	return store.saveRetainHeight(AppRetainHeightKey, height)
 }
 
 func (store dbStore) GetApplicationRetainHeight() (int64, error) {
@@ -790,7 +873,7 @@ func (store dbStore) GetApplicationRetainHeight() (int64, error) {
 
 // DataCompanionRetainHeight.
 func (store dbStore) SaveCompanionBlockRetainHeight(height int64) error {
-	return store.db.SetSync(CompanionBlockRetainHeightKey, int64ToBytes(height))
+	return store.saveRetainHeight(CompanionBlockRetainHeightKey, height)
 }
 
 func (store dbStore) GetCompanionBlockRetainHeight() (int64, error) {
@@ -807,9 +890,34 @@ func (store dbStore) GetCompanionBlockRetainHeight() (int64, error) {
 	return height, nil
 }
 
+func (store dbStore) SaveNamedCompanionBlockRetainHeight(name string, height int64) error {
+	return checkReadOnly(store.db.SetSync(namedCompanionBlockRetainHeightKey(name), int64ToBytes(height)))
+}
+
+func (store dbStore) GetNamedCompanionBlockRetainHeights() (map[string]int64, error) {
+	it, err := dbm.IteratePrefix(store.db, NamedCompanionBlockRetainHeightPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	heights := make(map[string]int64)
+	for ; it.Valid(); it.Next() {
+		height := int64FromBytes(it.Value())
+		if height < 0 {
+			return nil, ErrInvalidHeightValue
+		}
+		heights[string(it.Key()[len(NamedCompanionBlockRetainHeightPrefix):])] = height
+	}
+	return heights, it.Error()
+}
+
+func namedCompanionBlockRetainHeightKey(name string) []byte {
+	return append(append([]byte{}, NamedCompanionBlockRetainHeightPrefix...), name...)
+}
+
 // DataCompanionRetainHeight.
 func (store dbStore) SaveABCIResRetainHeight(height int64) error {
-	return store.db.SetSync(ABCIResultsRetainHeightKey, int64ToBytes(height))
+	return store.saveRetainHeight(ABCIResultsRetainHeightKey, height)
 }
 
 func (store dbStore) GetABCIResRetainHeight() (int64, error) {
@@ -826,7 +934,91 @@ func (store dbStore) GetABCIResRetainHeight() (int64, error) {
 	return height, nil
 }
 
-func (store dbStore) getLastABCIResponsesRetainHeight() (int64, error) {
+func (store dbStore) SaveCompanionABCIResRetainHeight(height int64) error {
+	return checkReadOnly(store.db.SetSync(CompanionABCIResultsRetainHeightKey, int64ToBytes(height)))
+}
+
+func (store dbStore) GetCompanionABCIResRetainHeight() (int64, error) {
+	buf, err := store.getValue(CompanionABCIResultsRetainHeightKey)
+	if err != nil {
+		return 0, err
+	}
+	height := int64FromBytes(buf)
+
+	if height < 0 {
+		return 0, ErrInvalidHeightValue
+	}
+
+	return height, nil
+}
+
+// saveRetainHeight saves height at key, along with an entry of the retain
+// height journal if it is enabled, and syncs the write.
+func (store dbStore) saveRetainHeight(key []byte, height int64) error {
+	if !store.RetainHeightJournal {
+		return checkReadOnly(store.db.SetSync(key, int64ToBytes(height)))
+	}
+	batch := store.db.NewBatch()
+	defer batch.Close()
+	if err := batch.Set(key, int64ToBytes(height)); err != nil {
+		return err
+	}
+	if err := batch.Set(retainHeightJournalKey(key, time.Now()), int64ToBytes(height)); err != nil {
+		return err
+	}
+	return checkReadOnly(batch.WriteSync())
+}
+
+func (store dbStore) LoadRetainHeightAt(t time.Time) (app, companion, abci int64, err error) {
+	if !store.RetainHeightJournal {
+		return 0, 0, 0, ErrNotSupported
+	}
+	if app, err = store.journaledRetainHeightAt(AppRetainHeightKey, t); err != nil {
+		return 0, 0, 0, err
+	}
+	if companion, err = store.journaledRetainHeightAt(CompanionBlockRetainHeightKey, t); err != nil {
+		return 0, 0, 0, err
+	}
+	if abci, err = store.journaledRetainHeightAt(ABCIResultsRetainHeightKey, t); err != nil {
+		return 0, 0, 0, err
+	}
+	return app, companion, abci, nil
+}
+
+// journaledRetainHeightAt returns the retain height saved at key as it was at
+// time t, i.e. the one of the last journal entry up to t, or 0 if there is
+// none.
+func (store dbStore) journaledRetainHeightAt(key []byte, t time.Time) (int64, error) {
+	it, err := store.db.ReverseIterator(retainHeightJournalKeyPrefix(key), retainHeightJournalKey(key, t.Add(time.Nanosecond)))
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+	if !it.Valid() {
+		return 0, it.Error()
+	}
+	height := int64FromBytes(it.Value())
+	if height < 0 {
+		return 0, ErrInvalidHeightValue
+	}
+	return height, nil
+}
+
+// retainHeightJournalKeyPrefix returns the prefix of the keys of the journal
+// entries of the retain height saved at key.
+func retainHeightJournalKeyPrefix(key []byte) []byte {
+	prefix := append(append([]byte{}, retainHeightJournalPrefix...), key...)
+	return append(prefix, ':')
+}
+
+// retainHeightJournalKey returns the key of the journal entry of the retain
+// height saved at key at time t. The keys of the entries of a retain height
+// are ordered by time.
+func retainHeightJournalKey(key []byte, t time.Time) []byte {
+	return binary.BigEndian.AppendUint64(retainHeightJournalKeyPrefix(key), uint64(t.UnixNano()))
+}
+
+func (store dbStore) GetLastABCIResponsesRetainHeight() (int64, error) {
 	bz, err := store.getValue(lastABCIResponsesRetainHeightKey)
 	if errors.Is(err, ErrKeyNotFound) {
 		return 0, nil
@@ -839,7 +1031,82 @@ func (store dbStore) getLastABCIResponsesRetainHeight() (int64, error) {
 }
 
 func (store dbStore) setLastABCIResponsesRetainHeight(height int64) error {
-	return store.db.SetSync(lastABCIResponsesRetainHeightKey, int64ToBytes(height))
+	return checkReadOnly(store.db.SetSync(lastABCIResponsesRetainHeightKey, int64ToBytes(height)))
+}
+
+// readOnlyErrors are the errors returned by the databases while they are
+// read-only, e.g. during a backup or a snapshot.
+var readOnlyErrors = []error{leveldb.ErrReadOnly, syscall.EROFS}
+
+// checkReadOnly returns err wrapped in ErrStoreReadOnly if it was returned
+// because the database is read-only, or err as is. The store wraps the errors
+// of its writes with it, so that callers only need to check ErrStoreReadOnly.
+func checkReadOnly(err error) error {
+	if err == nil || errors.Is(err, ErrStoreReadOnly) {
+		return err
+	}
+	if slices.ContainsFunc(readOnlyErrors, func(readOnly error) bool { return errors.Is(err, readOnly) }) {
+		return fmt.Errorf("%w: %w", ErrStoreReadOnly, err)
+	}
+	return err
+}
+
+// CompactDatabase compacts the whole database of the store, and returns the
+// size of its tables before and after, in bytes, or -1 if the database doesn't
+// report it. Backends that don't support compaction ignore it.
+func (store dbStore) CompactDatabase() (sizeBefore, sizeAfter int64, err error) {
+	sizeBefore = dbTablesSize(store.db)
+	if err := store.db.Compact(nil, nil); err != nil {
+		return sizeBefore, -1, err
+	}
+	return sizeBefore, dbTablesSize(store.db), nil
+}
+
+// dbTablesSize returns the size of the tables of db, in bytes, or -1 if its
+// backend doesn't report it.
+func dbTablesSize(db dbm.DB) int64 {
+	goLevelDB, ok := db.(*dbm.GoLevelDB)
+	if !ok {
+		return -1
+	}
+	var stats leveldb.DBStats
+	if err := goLevelDB.DB().Stats(&stats); err != nil {
+		return -1
+	}
+	return stats.LevelSizes.Sum()
+}
+
+// CompactRetainHeightKeys compacts only the range of keys holding the
+// application, data companions and ABCI results retain heights, which is much
+// cheaper than compacting the whole database. Backends that don't support
+// compacting a range of keys ignore it.
+func (store dbStore) CompactRetainHeightKeys() error {
+	start, end := retainHeightKeysRange()
+	return store.db.Compact(start, end)
+}
+
+// retainHeightKeysRange returns the smallest range of keys, end excluded,
+// holding all the retain height keys, including those of the named data
+// companions.
+func retainHeightKeysRange() (start, end []byte) {
+	// The smallest key after all the keys starting with the prefix.
+	namedEnd := append([]byte{}, NamedCompanionBlockRetainHeightPrefix...)
+	namedEnd[len(namedEnd)-1]++
+	for _, key := range [][]byte{
+		AppRetainHeightKey, CompanionBlockRetainHeightKey, ABCIResultsRetainHeightKey, CompanionABCIResultsRetainHeightKey,
+	} {
+		if start == nil || bytes.Compare(key, start) < 0 {
+			start = key
+		}
+		if end == nil || bytes.Compare(key, end) >= 0 {
+			// The smallest key after key.
+			end = append(append([]byte{}, key...), 0)
+		}
+	}
+	if bytes.Compare(namedEnd, end) > 0 {
+		end = namedEnd
+	}
+	return start, end
 }
 
 // -----------------------------------------------------------------------------
@@ -919,6 +1186,24 @@ func loadValidatorsInfo(db dbm.DB, valInfoKey []byte) (*cmtstate.ValidatorsInfo,
 	return v, elapsedTime, nil
 }
 
+// SaveValidatorSets is used to backfill the validator set at the heights from
+// lowerHeight to upperHeight, inclusive, which is assumed not to have changed
+// in between.
+func (store dbStore) SaveValidatorSets(lowerHeight, upperHeight int64, vals *types.ValidatorSet) error {
+	if lowerHeight <= 0 || lowerHeight > upperHeight {
+		return fmt.Errorf("invalid height range [%d, %d]", lowerHeight, upperHeight)
+	}
+	batch := store.db.NewBatch()
+	defer batch.Close()
+
+	for height := lowerHeight; height <= upperHeight; height++ {
+		if err := store.saveValidatorsInfo(height, lowerHeight, vals, batch); err != nil {
+			return err
+		}
+	}
+	return batch.WriteSync()
+}
+
 // saveValidatorsInfo persists the validator set.
 //
 // `height` is the effective height for which the validator is responsible for
@@ -1036,6 +1321,76 @@ func (store dbStore) saveConsensusParamsInfo(nextHeight, changeHeight int64, par
 	return nil
 }
 
+// PrunerLease is an advisory lock held by the pruner of a store, so that two
+// pruners never prune the same store at the same time (see WithPrunerLease).
+type PrunerLease struct {
+	Owner  string
+	Expiry time.Time
+}
+
+func (store dbStore) SavePrunerLease(lease PrunerLease) error {
+	bz := make([]byte, 8, 8+len(lease.Owner))
+	binary.BigEndian.PutUint64(bz, uint64(lease.Expiry.UnixNano()))
+	return store.db.SetSync(prunerLeaseKey, append(bz, lease.Owner...))
+}
+
+func (store dbStore) GetPrunerLease() (PrunerLease, error) {
+	bz, err := store.getValue(prunerLeaseKey)
+	if err != nil {
+		return PrunerLease{}, err
+	}
+	if len(bz) < 8 {
+		return PrunerLease{}, errors.New("invalid pruner lease")
+	}
+	return PrunerLease{
+		Owner:  string(bz[8:]),
+		Expiry: time.Unix(0, int64(binary.BigEndian.Uint64(bz[:8]))).UTC(),
+	}, nil
+}
+
+func (store dbStore) DeletePrunerLease() error {
+	return store.db.DeleteSync(prunerLeaseKey)
+}
+
+// PruneJournalEntry is the range of blocks, [FromHeight, ToHeight), that the
+// pruner of a store is pruning, with their states, recorded before deleting
+// them, so that a prune interrupted by a crash can be completed on restart.
+// EvidenceRetainHeight is the height from which the validator sets are kept
+// to verify evidence, as returned by the block store once the blocks are
+// pruned, or 0 until then.
+type PruneJournalEntry struct {
+	FromHeight           int64
+	ToHeight             int64
+	EvidenceRetainHeight int64
+}
+
+func (store dbStore) SavePruneJournal(entry PruneJournalEntry) error {
+	bz := make([]byte, 24)
+	binary.BigEndian.PutUint64(bz, uint64(entry.FromHeight))
+	binary.BigEndian.PutUint64(bz[8:], uint64(entry.ToHeight))
+	binary.BigEndian.PutUint64(bz[16:], uint64(entry.EvidenceRetainHeight))
+	return store.db.SetSync(pruneJournalKey, bz)
+}
+
+func (store dbStore) GetPruneJournal() (PruneJournalEntry, error) {
+	bz, err := store.getValue(pruneJournalKey)
+	if err != nil {
+		return PruneJournalEntry{}, err
+	}
+	if len(bz) != 24 {
+		return PruneJournalEntry{}, errors.New("invalid prune journal entry")
+	}
+	return PruneJournalEntry{
+		FromHeight:           int64(binary.BigEndian.Uint64(bz)),
+		ToHeight:             int64(binary.BigEndian.Uint64(bz[8:])),
+		EvidenceRetainHeight: int64(binary.BigEndian.Uint64(bz[16:])),
+	}, nil
+}
+
+func (store dbStore) DeletePruneJournal() error {
+	return store.db.DeleteSync(pruneJournalKey)
+}
+
 func (store dbStore) SetOfflineStateSyncHeight(height int64) error {
 	err := store.db.SetSync(offlineStateSyncHeight, int64ToBytes(height))
 	if err != nil {
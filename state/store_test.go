@@ -1,7 +1,9 @@
 package state_test
 
 import (
+	"bytes"
 	"fmt"
+	"math"
 	"os"
 	"testing"
 	"time"
@@ -185,7 +187,7 @@ func TestPruneStates(t *testing.T) {
 			}
 
 			// Test assertions
-			_, err := stateStore.PruneStates(tc.pruneFrom, tc.pruneTo, tc.evidenceThresholdHeight, 0)
+			count, err := stateStore.PruneStates(tc.pruneFrom, tc.pruneTo, tc.evidenceThresholdHeight, 0)
 			if tc.expectErr {
 				require.Error(t, err)
 				return
@@ -196,6 +198,20 @@ func TestPruneStates(t *testing.T) {
 			expectParams := sliceToMap(tc.expectParams)
 			expectABCI := sliceToMap(tc.expectABCI)
 
+			// Every pruned height whose validator set or consensus params
+			// are not expected to be kept must be counted.
+			var expectCount sm.PrunedStatesCount
+			for h := tc.pruneFrom; h < tc.pruneTo; h++ {
+				expectCount.Heights++
+				if !expectVals[h] {
+					expectCount.ValidatorSets++
+				}
+				if !expectParams[h] {
+					expectCount.ConsensusParams++
+				}
+			}
+			require.Equal(t, expectCount, count)
+
 			for h := int64(1); h <= tc.makeHeights; h++ {
 				vals, err := stateStore.LoadValidators(h)
 				if expectVals[h] {
@@ -341,9 +357,154 @@ func TestSaveRetainHeight(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// syncRecordingDB records the keys written to a DB without syncing.
+type syncRecordingDB struct {
+	dbm.DB
+	unsynced [][]byte
+}
+
+func (db *syncRecordingDB) Set(key, value []byte) error {
+	db.unsynced = append(db.unsynced, key)
+	return db.DB.Set(key, value)
+}
+
+// Retain heights must be durable once saved, as data companions may delete
+// their own copies of the data below them.
+func TestRetainHeightsAreSynced(t *testing.T) {
+	db := &syncRecordingDB{DB: dbm.NewMemDB()}
+	stateStore := sm.NewStore(db, sm.StoreOptions{})
+
+	require.NoError(t, stateStore.SaveApplicationRetainHeight(1))
+	require.NoError(t, stateStore.SaveCompanionBlockRetainHeight(2))
+	require.NoError(t, stateStore.SaveABCIResRetainHeight(3))
+	require.Empty(t, db.unsynced)
+}
+
+// unsyncedDB writes to a DB without syncing, even when asked to.
+type unsyncedDB struct {
+	dbm.DB
+}
+
+func (db unsyncedDB) SetSync(key, value []byte) error {
+	return db.DB.Set(key, value)
+}
+
+// BenchmarkSaveRetainHeight measures the latency of writing a retain height
+// with SetSync, as the store does, against writing it with Set.
+func BenchmarkSaveRetainHeight(b *testing.B) {
+	for _, tc := range []struct {
+		name string
+		wrap func(dbm.DB) dbm.DB
+	}{
+		{"Set", func(db dbm.DB) dbm.DB { return unsyncedDB{DB: db} }},
+		{"SetSync", func(db dbm.DB) dbm.DB { return db }},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			db, err := dbm.NewGoLevelDB("state", b.TempDir())
+			require.NoError(b, err)
+			defer db.Close()
+			stateStore := sm.NewStore(tc.wrap(db), sm.StoreOptions{})
+
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				if err := stateStore.SaveApplicationRetainHeight(int64(n + 1)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// compactRecordingDB records the ranges of keys compacted in a DB.
+type compactRecordingDB struct {
+	dbm.DB
+	compacted [][2][]byte
+}
+
+func (db *compactRecordingDB) Compact(start, end []byte) error {
+	db.compacted = append(db.compacted, [2][]byte{start, end})
+	return db.DB.Compact(start, end)
+}
+
+func TestCompactRetainHeightKeys(t *testing.T) {
+	db := &compactRecordingDB{DB: dbm.NewMemDB()}
+	stateStore := sm.NewStore(db, sm.StoreOptions{})
+
+	require.NoError(t, stateStore.CompactRetainHeightKeys())
+	require.Len(t, db.compacted, 1)
+	start, end := db.compacted[0][0], db.compacted[0][1]
+	// Only the range holding the retain height keys is compacted.
+	require.NotNil(t, start)
+	require.NotNil(t, end)
+	namedKey := append(append([]byte{}, sm.NamedCompanionBlockRetainHeightPrefix...), "archival"...)
+	for _, key := range [][]byte{
+		sm.AppRetainHeightKey, sm.CompanionBlockRetainHeightKey, sm.ABCIResultsRetainHeightKey,
+		sm.CompanionABCIResultsRetainHeightKey, namedKey,
+	} {
+		require.GreaterOrEqual(t, bytes.Compare(key, start), 0, string(key))
+		require.Negative(t, bytes.Compare(key, end), string(key))
+	}
+}
+
+func TestLoadRetainHeightAt(t *testing.T) {
+	stateStore := sm.NewStore(dbm.NewMemDB(), sm.StoreOptions{RetainHeightJournal: true})
+	before := time.Now()
+	time.Sleep(time.Millisecond)
+	require.NoError(t, stateStore.SaveApplicationRetainHeight(2))
+	require.NoError(t, stateStore.SaveABCIResRetainHeight(1))
+	time.Sleep(time.Millisecond)
+	middle := time.Now()
+	time.Sleep(time.Millisecond)
+	require.NoError(t, stateStore.SaveApplicationRetainHeight(5))
+	require.NoError(t, stateStore.SaveCompanionBlockRetainHeight(4))
+
+	for _, tc := range []struct {
+		name                     string
+		time                     time.Time
+		expApp, expComp, expABCI int64
+	}{
+		{"before", before, 0, 0, 0},
+		{"middle", middle, 2, 0, 1},
+		{"now", time.Now(), 5, 4, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			app, companion, abci, err := stateStore.LoadRetainHeightAt(tc.time)
+			require.NoError(t, err)
+			require.Equal(t, tc.expApp, app)
+			require.Equal(t, tc.expComp, companion)
+			require.Equal(t, tc.expABCI, abci)
+		})
+	}
+
+	// The retain heights themselves are saved as usual.
+	app, err := stateStore.GetApplicationRetainHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 5, app)
+}
+
+func TestLoadRetainHeightAtNotSupported(t *testing.T) {
+	stateStore := sm.NewStore(dbm.NewMemDB(), sm.StoreOptions{})
+	require.NoError(t, stateStore.SaveApplicationRetainHeight(1))
+
+	_, _, _, err := stateStore.LoadRetainHeightAt(time.Now())
+	require.ErrorIs(t, err, sm.ErrNotSupported)
+}
+
+// fillBlockStore saves empty blocks at heights 1 to height in bs.
+func fillBlockStore(t *testing.T, height int64, bs *store.BlockStore, state sm.State) {
+	t.Helper()
+	for h := int64(1); h <= height; h++ {
+		block := state.MakeBlock(h, nil, new(types.Commit), nil, state.Validators.GetProposer().Address)
+		partSet, err := block.MakePartSet(types.BlockPartSizeBytes)
+		require.NoError(t, err)
+		bs.SaveBlock(block, partSet, &types.Commit{Height: h})
+	}
+}
+
 func TestMinRetainHeight(t *testing.T) {
-	_, bs, txIndexer, blockIndexer, callbackF, stateStore := makeStateAndBlockStoreAndIndexers()
+	state, bs, txIndexer, blockIndexer, callbackF, stateStore := makeStateAndBlockStoreAndIndexers()
 	defer callbackF()
+	fillBlockStore(t, 12, bs, state)
 	pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(), sm.WithPrunerCompanionEnabled())
 
 	require.NoError(t, initStateStoreRetainHeights(stateStore))
@@ -361,6 +522,82 @@ func TestMinRetainHeight(t *testing.T) {
 	require.Equal(t, int64(10), minHeight)
 }
 
+func TestMinRetainHeightOutOfRange(t *testing.T) {
+	state, bs, txIndexer, blockIndexer, callbackF, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer callbackF()
+	fillBlockStore(t, 10, bs, state)
+	pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(), sm.WithPrunerCompanionEnabled())
+	require.NoError(t, initStateStoreRetainHeights(stateStore))
+
+	testCases := []struct {
+		name              string
+		appRetainHeight   int64
+		dcRetainHeight    int64
+		expectedMinHeight int64
+	}{
+		{"both in range", 5, 7, 5},
+		{"both above the tip", math.MaxInt64, math.MaxInt64, 10},
+		{"one above the tip", 7, math.MaxInt64, 7},
+		// Negative values are rejected by the store, so no pruning happens.
+		{"negative", -5, 8, 0},
+		{"minimum negative value", math.MinInt64, 8, 0},
+		{"not set", 0, 8, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, stateStore.SaveApplicationRetainHeight(tc.appRetainHeight))
+			require.NoError(t, stateStore.SaveCompanionBlockRetainHeight(tc.dcRetainHeight))
+			require.Equal(t, tc.expectedMinHeight, pruner.FindMinRetainHeight())
+		})
+	}
+
+	// Clamping to the base of the block store only applies once it has been
+	// pruned.
+	_, _, err := bs.PruneBlocks(4, state)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), bs.Base())
+	require.NoError(t, stateStore.SaveApplicationRetainHeight(2))
+	require.NoError(t, stateStore.SaveCompanionBlockRetainHeight(3))
+	require.Equal(t, int64(4), pruner.FindMinRetainHeight())
+}
+
+func TestRetainHeightsAboveTipAfterRollback(t *testing.T) {
+	state, bs, txIndexer, blockIndexer, callbackF, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer callbackF()
+	fillBlockStore(t, 10, bs, state)
+	pruner := sm.NewPruner(stateStore, bs, blockIndexer, txIndexer, log.TestingLogger(), sm.WithPrunerCompanionEnabled())
+	require.NoError(t, initStateStoreRetainHeights(stateStore))
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(9))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(10))
+	require.NoError(t, pruner.SetABCIResRetainHeight(10))
+
+	// Simulate a rollback to height 7.
+	for i := 0; i < 3; i++ {
+		require.NoError(t, bs.DeleteLatestBlock())
+	}
+	require.Equal(t, int64(7), bs.Height())
+
+	// The block retain heights are clamped to the tip, and downgraded in the
+	// database.
+	require.Equal(t, int64(7), pruner.FindMinRetainHeight())
+	appRetainHeight, err := stateStore.GetApplicationRetainHeight()
+	require.NoError(t, err)
+	require.Equal(t, int64(7), appRetainHeight)
+	dcRetainHeight, err := stateStore.GetCompanionBlockRetainHeight()
+	require.NoError(t, err)
+	require.Equal(t, int64(7), dcRetainHeight)
+
+	// So is the ABCI results retain height.
+	require.Equal(t, int64(7), pruner.PruneABCIResToRetainHeight(0))
+	abciResRetainHeight, err := stateStore.GetABCIResRetainHeight()
+	require.NoError(t, err)
+	require.Equal(t, int64(7), abciResRetainHeight)
+
+	// Retain heights can be set again from the new tip.
+	require.NoError(t, pruner.SetApplicationBlockRetainHeight(7))
+	require.NoError(t, pruner.SetCompanionBlockRetainHeight(7))
+}
+
 func TestABCIResPruningStandalone(t *testing.T) {
 	stateDB := dbm.NewMemDB()
 	stateStore := sm.NewStore(stateDB, sm.StoreOptions{
@@ -404,7 +641,7 @@ func TestABCIResPruningStandalone(t *testing.T) {
 
 	// This should not have any impact because the retain height is still 2 and we will not prune blocks to 3
 	newRetainHeight = pruner.PruneABCIResToRetainHeight(3)
-	require.Equal(t, retainHeight, newRetainHeight)
+	require.Equal(t, int64(3), newRetainHeight)
 
 	retainHeight = 3
 	err = stateStore.SaveABCIResRetainHeight(retainHeight)
@@ -498,6 +735,7 @@ func TestFinalizeBlockResponsePruning(t *testing.T) {
 		select {
 		case info := <-obs.prunedABCIResInfoCh:
 			require.Equal(t, height-1, info.ToHeight)
+			require.Zero(t, info.RemainingHeights)
 			t.Log("Done pruning ABCI results ")
 		case <-time.After(5 * time.Second):
 			require.Fail(t, "timed out waiting for pruning run to complete")
@@ -511,6 +749,47 @@ func TestFinalizeBlockResponsePruning(t *testing.T) {
 	})
 }
 
+func TestABCIResPruningInterval(t *testing.T) {
+	state, bs, txIndexer, blockIndexer, callbackF, stateStore := makeStateAndBlockStoreAndIndexers()
+	defer callbackF()
+	height := int64(10)
+	fillBlockStore(t, height, bs, state)
+	for h := int64(1); h <= height; h++ {
+		require.NoError(t, stateStore.SaveFinalizeBlockResponse(h, &abci.FinalizeBlockResponse{}))
+	}
+	require.NoError(t, initStateStoreRetainHeights(stateStore))
+
+	obs := newPrunerObserver(1)
+	pruner := sm.NewPruner(
+		stateStore,
+		bs,
+		blockIndexer,
+		txIndexer,
+		log.TestingLogger(),
+		// Blocks are only pruned once, when the pruner starts.
+		sm.WithPrunerInterval(time.Hour),
+		sm.WithABCIPruningInterval(10*time.Millisecond),
+		sm.WithPrunerObserver(obs),
+		sm.WithPrunerCompanionEnabled(),
+	)
+	require.NoError(t, pruner.Start())
+	defer func() { require.NoError(t, pruner.Stop()) }()
+
+	// Every increase of the retain height is picked up by a later run of the
+	// ABCI results pruner, long before the block pruner runs again.
+	for _, retainHeight := range []int64{height / 2, height} {
+		require.NoError(t, pruner.SetABCIResRetainHeight(retainHeight))
+		select {
+		case info := <-obs.prunedABCIResInfoCh:
+			require.Equal(t, retainHeight-1, info.ToHeight)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timed out waiting for ABCI results pruning run to complete")
+		}
+		_, err := stateStore.LoadFinalizeBlockResponse(retainHeight - 1)
+		require.Error(t, err)
+	}
+}
+
 func TestLastFinalizeBlockResponses(t *testing.T) {
 	t.Run("persisting responses", func(t *testing.T) {
 		stateDB := dbm.NewMemDB()
@@ -631,3 +910,46 @@ func TestIntConversion(t *testing.T) {
 	b := sm.Int64ToBytes(x)
 	require.Equal(t, x, sm.Int64FromBytes(b))
 }
+
+func TestNamedCompanionBlockRetainHeights(t *testing.T) {
+	stateStore := sm.NewStore(dbm.NewMemDB(), sm.StoreOptions{})
+
+	heights, err := stateStore.GetNamedCompanionBlockRetainHeights()
+	require.NoError(t, err)
+	require.Empty(t, heights)
+
+	require.NoError(t, stateStore.SaveCompanionBlockRetainHeight(2))
+	require.NoError(t, stateStore.SaveNamedCompanionBlockRetainHeight("analytics", 3))
+	require.NoError(t, stateStore.SaveNamedCompanionBlockRetainHeight("archival", 4))
+	require.NoError(t, stateStore.SaveNamedCompanionBlockRetainHeight("analytics", 5))
+
+	// The default data companion is not included.
+	heights, err = stateStore.GetNamedCompanionBlockRetainHeights()
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"analytics": 5, "archival": 4}, heights)
+}
+
+func TestStoreCompactDatabase(t *testing.T) {
+	type databaseCompactor interface {
+		CompactDatabase() (int64, int64, error)
+	}
+
+	// The size of the database is only reported by some backends.
+	stateStore := sm.NewStore(dbm.NewMemDB(), sm.StoreOptions{})
+	sizeBefore, sizeAfter, err := stateStore.(databaseCompactor).CompactDatabase()
+	require.NoError(t, err)
+	require.EqualValues(t, -1, sizeBefore)
+	require.EqualValues(t, -1, sizeAfter)
+
+	db, err := dbm.NewGoLevelDB("state", t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+	stateStore = sm.NewStore(db, sm.StoreOptions{})
+	for h := int64(1); h <= 100; h++ {
+		require.NoError(t, stateStore.SaveApplicationRetainHeight(h))
+	}
+	sizeBefore, sizeAfter, err = stateStore.(databaseCompactor).CompactDatabase()
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, sizeBefore, int64(0))
+	require.Positive(t, sizeAfter)
+}
@@ -31,10 +31,15 @@ type TxIndexer interface {
 	// Set Logger
 	SetLogger(l log.Logger)
 
+	// Prune removes the transactions indexed below retainHeight, i.e. their
+	// results, stored by hash, and their events, and returns the number of
+	// heights pruned and the new retain height of the indexer.
 	Prune(retainHeight int64) (int64, int64, error)
 
 	GetRetainHeight() (int64, error)
 
+	// SetRetainHeight persists the retain height of the indexer. It is durable
+	// once it returns.
 	SetRetainHeight(retainHeight int64) error
 }
 
@@ -52,6 +57,17 @@ type Pagination struct {
 	IsPaginated bool
 	Page        int
 	PerPage     int
+	// If set, the page starts right after the transaction at After, following
+	// the order of the results, and Page is ignored. Unlike pages, this keeps
+	// the next page stable as new transactions get indexed.
+	After *Cursor
+}
+
+// Cursor is the position of a transaction in the results of a search. The
+// results are sorted by height, then by hash.
+type Cursor struct {
+	Height int64
+	Hash   []byte
 }
 
 // NewBatch creates a new Batch.
@@ -538,7 +538,15 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query, pagSettings txin
 	})
 
 	// If paginated, determine which hash keys to return
-	if pagSettings.IsPaginated {
+	if pagSettings.IsPaginated && pagSettings.After != nil {
+		// Skip the results up to the cursor, which were already returned.
+		startIndex := sort.Search(len(hashKeys), func(i int) bool {
+			info := filteredHashes[hashKeys[i]]
+			return isAfterCursor(info.Height, info.TxBytes, pagSettings.After, pagSettings.OrderDesc)
+		})
+		endIndex := min(startIndex+pagSettings.PerPage, len(hashKeys))
+		hashKeys = hashKeys[startIndex:endIndex]
+	} else if pagSettings.IsPaginated {
 		// Now that we know the total number of results, validate that the page
 		// requested is within bounds
 		pagSettings.Page, err = validatePage(&pagSettings.Page, pagSettings.PerPage, numResults)
@@ -586,6 +594,21 @@ RESULTS_LOOP:
 	return results, numResults, nil
 }
 
+// isAfterCursor returns true if the transaction of the given height and hash
+// comes after the cursor in the results sorted in the given order.
+func isAfterCursor(height int64, hash []byte, cursor *txindex.Cursor, orderDesc bool) bool {
+	if height != cursor.Height {
+		if orderDesc {
+			return height < cursor.Height
+		}
+		return height > cursor.Height
+	}
+	if orderDesc {
+		return bytes.Compare(hash, cursor.Hash) < 0
+	}
+	return bytes.Compare(hash, cursor.Hash) > 0
+}
+
 func lookForHash(conditions []syntax.Condition) (hash []byte, ok bool, err error) {
 	for _, c := range conditions {
 		if c.Tag == types.TxHashKey {
@@ -739,6 +739,69 @@ func TestTxSearchMultipleTxs(t *testing.T) {
 	require.Len(t, results, 3)
 }
 
+func TestTxSearchCursor(t *testing.T) {
+	indexer := NewTxIndex(db.NewMemDB())
+
+	index := func(height int64, txIndex uint32) {
+		txResult := txResultWithEvents([]abci.Event{
+			{Type: "account", Attributes: []abci.EventAttribute{{Key: "owner", Value: "Ivan", Index: true}}},
+		})
+		txResult.Tx = types.Tx(fmt.Sprintf("tx %d/%d", height, txIndex))
+		txResult.Height = height
+		txResult.Index = txIndex
+		require.NoError(t, indexer.Index(txResult))
+	}
+	for h := int64(1); h <= 3; h++ {
+		for i := uint32(0); i < 3; i++ {
+			index(h, i)
+		}
+	}
+
+	ctx := context.Background()
+	q := query.MustCompile(`account.owner = 'Ivan'`)
+
+	for _, orderDesc := range []bool{false, true} {
+		all, _, err := indexer.Search(ctx, q, txindex.Pagination{OrderDesc: orderDesc, IsPaginated: true, Page: 1, PerPage: 100})
+		require.NoError(t, err)
+		require.Len(t, all, 9)
+
+		var (
+			got    []*abci.TxResult
+			cursor *txindex.Cursor
+		)
+		for {
+			page, _, err := indexer.Search(ctx, q, txindex.Pagination{OrderDesc: orderDesc, IsPaginated: true, Page: 1, PerPage: 2, After: cursor})
+			require.NoError(t, err)
+			if len(page) == 0 {
+				break
+			}
+			got = append(got, page...)
+			last := page[len(page)-1]
+			cursor = &txindex.Cursor{Height: last.Height, Hash: types.Tx(last.Tx).Hash()}
+		}
+		assert.Equal(t, all, got)
+	}
+
+	// Iterate over the first results, then index new ones at a height smaller
+	// than the cursor: they must not shift the next page.
+	page, _, err := indexer.Search(ctx, q, txindex.Pagination{OrderDesc: true, IsPaginated: true, Page: 1, PerPage: 4})
+	require.NoError(t, err)
+	require.Len(t, page, 4)
+	last := page[len(page)-1]
+	cursor := &txindex.Cursor{Height: last.Height, Hash: types.Tx(last.Tx).Hash()}
+
+	index(4, 0)
+	index(4, 1)
+
+	page, _, err = indexer.Search(ctx, q, txindex.Pagination{OrderDesc: true, IsPaginated: true, PerPage: 100, After: cursor})
+	require.NoError(t, err)
+	require.Len(t, page, 5)
+	for _, txr := range page {
+		assert.NotEqual(t, int64(4), txr.Height)
+		assert.LessOrEqual(t, txr.Height, last.Height)
+	}
+}
+
 func txResultWithEvents(events []abci.Event) *abci.TxResult {
 	tx := types.Tx("HELLO WORLD")
 	return &abci.TxResult{
@@ -0,0 +1,109 @@
+package statesync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cometbft/cometbft/internal/evidence"
+	sm "github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/store"
+	"github.com/cometbft/cometbft/types"
+)
+
+// LightBlockProvider is an optional interface of a StateProvider, needed to
+// backfill the heights below the snapshot height after a state sync (see
+// Reactor.Backfill). The light blocks it returns don't need to be verified, as
+// Backfill verifies them against the state the node was bootstrapped with.
+type LightBlockProvider interface {
+	// LightBlock returns the light block at the given height.
+	LightBlock(ctx context.Context, height uint64) (*types.LightBlock, error)
+}
+
+// Backfill fetches the light blocks below the height of a state sync, starting
+// from state.LastBlockHeight, and saves their header and commit to blockStore
+// and their validator set to stateStore. It stops at the first height whose
+// evidence would be expired according to the evidence parameters of state, or
+// at the initial height, so that the node can verify any evidence that is
+// still valid, although it has no historical blocks.
+//
+// Each light block is verified by checking that its hash is the one of the
+// last block ID of the light block above, starting from state.LastBlockID, and
+// that its commit is signed by its validator set. stateProvider must implement
+// LightBlockProvider.
+func (r *Reactor) Backfill(
+	stateProvider StateProvider,
+	state sm.State,
+	stateStore sm.Store,
+	blockStore *store.BlockStore,
+) error {
+	provider, ok := stateProvider.(LightBlockProvider)
+	if !ok {
+		return errors.New("state provider can't provide light blocks to backfill")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.Quit():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	r.metrics.Syncing.Set(1)
+	defer r.metrics.Syncing.Set(0)
+
+	r.Logger.Info("Starting backfill", "height", state.LastBlockHeight)
+	trustedBlockID := state.LastBlockID
+	height := state.LastBlockHeight
+	for ; height >= state.InitialHeight; height-- {
+		lb, err := provider.LightBlock(ctx, uint64(height))
+		if err != nil {
+			return fmt.Errorf("failed to fetch light block at height %d: %w", height, err)
+		}
+		if err := verifyBackfilledLightBlock(lb, state.ChainID, height, trustedBlockID); err != nil {
+			return fmt.Errorf("invalid light block at height %d: %w", height, err)
+		}
+		if evidence.IsEvidenceExpired(state.LastBlockHeight, state.LastBlockTime, height, lb.Time,
+			state.ConsensusParams.Evidence) {
+			break
+		}
+
+		if err := blockStore.SaveSignedHeader(lb.SignedHeader, trustedBlockID); err != nil {
+			return fmt.Errorf("failed to save signed header at height %d: %w", height, err)
+		}
+		if err := stateStore.SaveValidatorSets(height, height, lb.ValidatorSet); err != nil {
+			return fmt.Errorf("failed to save validator set at height %d: %w", height, err)
+		}
+		r.metrics.BackfilledBlocks.Add(1)
+		r.metrics.BackfillHeight.Set(float64(height))
+		trustedBlockID = lb.LastBlockID
+	}
+
+	r.Logger.Info("Backfill complete", "from", height+1, "to", state.LastBlockHeight)
+	return nil
+}
+
+// verifyBackfilledLightBlock checks that lb is the light block at height with
+// the given block ID, and that its commit is signed by its validator set.
+func verifyBackfilledLightBlock(lb *types.LightBlock, chainID string, height int64, blockID types.BlockID) error {
+	if lb == nil {
+		return errors.New("no light block")
+	}
+	if err := lb.ValidateBasic(chainID); err != nil {
+		return err
+	}
+	if lb.Height != height {
+		return fmt.Errorf("light block is for height %d", lb.Height)
+	}
+	if hash := lb.Hash(); !bytes.Equal(hash, blockID.Hash) {
+		return fmt.Errorf("light block hash is %X, expected %X", hash, blockID.Hash)
+	}
+	if !lb.Commit.BlockID.Equals(blockID) {
+		return fmt.Errorf("commit is for block %v, expected %v", lb.Commit.BlockID, blockID)
+	}
+	return lb.ValidatorSet.VerifyCommitLight(chainID, blockID, height, lb.Commit)
+}
@@ -0,0 +1,120 @@
+package statesync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	dbm "github.com/cometbft/cometbft-db"
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/internal/test"
+	sm "github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/statesync/mocks"
+	"github.com/cometbft/cometbft/store"
+	"github.com/cometbft/cometbft/types"
+	cmttime "github.com/cometbft/cometbft/types/time"
+)
+
+// lightBlockStateProvider is a state provider that provides light blocks.
+type lightBlockStateProvider struct {
+	*mocks.StateProvider
+	lightBlocks map[int64]*types.LightBlock
+}
+
+func (p *lightBlockStateProvider) LightBlock(_ context.Context, height uint64) (*types.LightBlock, error) {
+	lb, ok := p.lightBlocks[int64(height)]
+	if !ok {
+		return nil, errors.New("light block not found")
+	}
+	return lb, nil
+}
+
+// makeLightBlockChain makes a chain of signed light blocks from height 1 to
+// height, one minute apart.
+func makeLightBlockChain(t *testing.T, height int64) map[int64]*types.LightBlock {
+	t.Helper()
+	vals, privVals := test.ValidatorSet(context.Background(), t, 4, 10)
+	lightBlocks := make(map[int64]*types.LightBlock, height)
+	start := cmttime.Now().Add(-time.Duration(height) * time.Minute)
+	lastBlockID := types.BlockID{}
+	for h := int64(1); h <= height; h++ {
+		header := test.MakeHeader(t, &types.Header{
+			ChainID:            test.DefaultTestChainID,
+			Height:             h,
+			Time:               start.Add(time.Duration(h) * time.Minute),
+			LastBlockID:        lastBlockID,
+			ValidatorsHash:     vals.Hash(),
+			NextValidatorsHash: vals.Hash(),
+			ProposerAddress:    vals.Proposer.Address,
+		})
+		blockID := test.MakeBlockIDWithHash(header.Hash())
+		commit, err := test.MakeCommit(blockID, h, 0, vals, privVals, test.DefaultTestChainID, header.Time)
+		require.NoError(t, err)
+		lightBlocks[h] = &types.LightBlock{
+			SignedHeader: &types.SignedHeader{Header: header, Commit: commit},
+			ValidatorSet: vals,
+		}
+		lastBlockID = blockID
+	}
+	return lightBlocks
+}
+
+func TestReactor_Backfill(t *testing.T) {
+	lightBlocks := makeLightBlockChain(t, 10)
+	state := sm.State{
+		ChainID:         test.DefaultTestChainID,
+		InitialHeight:   1,
+		LastBlockHeight: 10,
+		LastBlockTime:   lightBlocks[10].Time,
+		LastBlockID:     lightBlocks[10].Commit.BlockID,
+		ConsensusParams: *types.DefaultConsensusParams(),
+	}
+	state.ConsensusParams.Evidence.MaxAgeNumBlocks = 3
+	state.ConsensusParams.Evidence.MaxAgeDuration = time.Second
+
+	r := NewReactor(*config.DefaultStateSyncConfig(), nil, nil, NopMetrics())
+
+	t.Run("backfills the heights of valid evidence", func(t *testing.T) {
+		stateStore := sm.NewStore(dbm.NewMemDB(), sm.StoreOptions{})
+		blockStore := store.NewBlockStore(dbm.NewMemDB())
+		provider := &lightBlockStateProvider{lightBlocks: lightBlocks}
+		require.NoError(t, r.Backfill(provider, state, stateStore, blockStore))
+
+		for h := int64(7); h <= 10; h++ {
+			meta := blockStore.LoadBlockMeta(h)
+			require.NotNil(t, meta, "height %d", h)
+			require.Equal(t, lightBlocks[h].Hash(), meta.BlockID.Hash)
+			require.Equal(t, lightBlocks[h].Commit, blockStore.LoadBlockCommit(h))
+			vals, err := stateStore.LoadValidators(h)
+			require.NoError(t, err)
+			require.Equal(t, lightBlocks[h].ValidatorSet.Hash(), vals.Hash())
+		}
+		// Evidence at height 6 is expired.
+		require.Nil(t, blockStore.LoadBlockMeta(6))
+		require.True(t, blockStore.IsEmpty())
+	})
+
+	t.Run("fails on a light block that doesn't match the chain", func(t *testing.T) {
+		tampered := make(map[int64]*types.LightBlock, len(lightBlocks))
+		for h, lb := range lightBlocks {
+			tampered[h] = lb
+		}
+		tampered[9] = makeLightBlockChain(t, 10)[9]
+
+		stateStore := sm.NewStore(dbm.NewMemDB(), sm.StoreOptions{})
+		blockStore := store.NewBlockStore(dbm.NewMemDB())
+		provider := &lightBlockStateProvider{lightBlocks: tampered}
+		require.Error(t, r.Backfill(provider, state, stateStore, blockStore))
+		require.NotNil(t, blockStore.LoadBlockMeta(10))
+		require.Nil(t, blockStore.LoadBlockMeta(9))
+	})
+
+	t.Run("requires a light block provider", func(t *testing.T) {
+		stateStore := sm.NewStore(dbm.NewMemDB(), sm.StoreOptions{})
+		blockStore := store.NewBlockStore(dbm.NewMemDB())
+		require.Error(t, r.Backfill(&mocks.StateProvider{}, state, stateStore, blockStore))
+	})
+}
@@ -1,6 +1,7 @@
 package statesync
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -15,6 +16,9 @@ import (
 // errDone is returned by chunkQueue.Next() when all chunks have been returned.
 var errDone = errors.New("chunk queue has completed")
 
+// snapshotFile is the name of the file holding the snapshot of a resumable chunk queue.
+const snapshotFile = "snapshot.json"
+
 // chunk contains data for a chunk.
 type chunk struct {
 	Height uint64
@@ -59,6 +63,100 @@ func newChunkQueue(snapshot *snapshot, tempDir string) (*chunkQueue, error) {
 	}, nil
 }
 
+// newResumableChunkQueue creates a new chunk queue for a snapshot, storing the chunks in dir so
+// that they survive restarts. If dir holds chunks of the same snapshot, received before the node
+// was restarted in the middle of a state sync, they are loaded into the queue and are not fetched
+// again. Otherwise, the contents of dir are discarded. It returns the number of chunks loaded.
+// Callers must call Close() when done, which removes dir.
+func newResumableChunkQueue(snapshot *snapshot, dir string) (*chunkQueue, uint32, error) {
+	if snapshot.Chunks == 0 {
+		return nil, 0, errors.New("snapshot has no chunks")
+	}
+	q := &chunkQueue{
+		snapshot:       snapshot,
+		dir:            dir,
+		chunkFiles:     make(map[uint32]string, snapshot.Chunks),
+		chunkSenders:   make(map[uint32]p2p.ID, snapshot.Chunks),
+		chunkAllocated: make(map[uint32]bool, snapshot.Chunks),
+		chunkReturned:  make(map[uint32]bool, snapshot.Chunks),
+		waiters:        make(map[uint32][]chan<- uint32),
+	}
+
+	// A snapshot that can't be loaded is discarded, just like a different snapshot.
+	persisted, err := loadPersistedSnapshot(dir)
+	if err == nil && persisted != nil && persisted.Key() == snapshot.Key() {
+		loaded, err := q.loadPersistedChunks()
+		if err != nil {
+			return nil, 0, err
+		}
+		return q, loaded, nil
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, 0, fmt.Errorf("failed to discard state sync chunks in %v: %w", dir, err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, 0, fmt.Errorf("unable to create dir for state sync chunks: %w", err)
+	}
+	bz, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, snapshotFile), bz); err != nil {
+		return nil, 0, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return q, 0, nil
+}
+
+// loadPersistedSnapshot loads the snapshot of a resumable chunk queue stored in dir. It returns
+// nil if there is none.
+func loadPersistedSnapshot(dir string) (*snapshot, error) {
+	bz, err := os.ReadFile(filepath.Join(dir, snapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &snapshot{}
+	if err := json.Unmarshal(bz, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// loadPersistedChunks adds the chunks stored in the queue's dir to the queue, returning the number
+// of chunks added. The chunks are not fetched again, and since their senders are unknown, they
+// are only discarded if the app asks for them to be refetched.
+func (q *chunkQueue) loadPersistedChunks() (uint32, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read state sync chunks from %v: %w", q.dir, err)
+	}
+	loaded := uint32(0)
+	for _, entry := range entries {
+		// Skip the snapshot, as well as chunks that were being written when the node stopped.
+		index, err := strconv.ParseUint(entry.Name(), 10, 32)
+		if err != nil || entry.IsDir() || uint32(index) >= q.snapshot.Chunks {
+			continue
+		}
+		q.chunkFiles[uint32(index)] = filepath.Join(q.dir, entry.Name())
+		q.chunkAllocated[uint32(index)] = true
+		loaded++
+	}
+	return loaded, nil
+}
+
+// writeFileAtomic writes data to the file at path, such that the file either holds all of data or
+// is not created at all, even if the node crashes while writing it.
+func writeFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
 // Add adds a chunk to the queue. It ignores chunks that already exist, returning false.
 func (q *chunkQueue) Add(chunk *chunk) (bool, error) {
 	if chunk == nil || chunk.Chunk == nil {
@@ -83,7 +181,7 @@ func (q *chunkQueue) Add(chunk *chunk) (bool, error) {
 	}
 
 	path := filepath.Join(q.dir, strconv.FormatUint(uint64(chunk.Index), 10))
-	err := os.WriteFile(path, chunk.Chunk, 0o600)
+	err := writeFileAtomic(path, chunk.Chunk)
 	if err != nil {
 		return false, fmt.Errorf("failed to save chunk %v to file %v: %w", chunk.Index, path, err)
 	}
@@ -2,6 +2,7 @@ package statesync
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -54,6 +55,58 @@ func TestNewChunkQueue_TempDir(t *testing.T) {
 	assert.Empty(t, files)
 }
 
+func TestNewResumableChunkQueue(t *testing.T) {
+	snapshot := &snapshot{
+		Height:   3,
+		Format:   1,
+		Chunks:   5,
+		Hash:     []byte{7},
+		Metadata: nil,
+	}
+	dir := filepath.Join(t.TempDir(), "statesync")
+
+	queue, resumed, err := newResumableChunkQueue(snapshot, dir)
+	require.NoError(t, err)
+	assert.Zero(t, resumed)
+	for _, index := range []uint32{0, 2} {
+		added, err := queue.Add(&chunk{Height: 3, Format: 1, Index: index, Chunk: []byte{3, 1, byte(index)}})
+		require.NoError(t, err)
+		assert.True(t, added)
+	}
+
+	// Reopening the queue for the same snapshot, as after a restart, loads the received chunks
+	// and only allocates the missing ones.
+	queue, resumed, err = newResumableChunkQueue(snapshot, dir)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, resumed)
+	assert.True(t, queue.Has(0))
+	assert.True(t, queue.Has(2))
+	for _, expected := range []uint32{1, 3, 4} {
+		index, err := queue.Allocate()
+		require.NoError(t, err)
+		assert.Equal(t, expected, index)
+	}
+	c, err := queue.Next()
+	require.NoError(t, err)
+	assert.Equal(t, &chunk{Height: 3, Format: 1, Index: 0, Chunk: []byte{3, 1, 0}}, c)
+
+	// Opening the queue for a different snapshot discards the persisted chunks.
+	other := *snapshot
+	other.Hash = []byte{8}
+	queue, resumed, err = newResumableChunkQueue(&other, dir)
+	require.NoError(t, err)
+	assert.Zero(t, resumed)
+	assert.False(t, queue.Has(0))
+	persisted, err := loadPersistedSnapshot(dir)
+	require.NoError(t, err)
+	assert.Equal(t, other.Key(), persisted.Key())
+
+	// Closing the queue removes the dir.
+	require.NoError(t, queue.Close())
+	_, err = os.Stat(dir)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
 func TestChunkQueue(t *testing.T) {
 	queue, teardown := setupChunkQueue(t)
 	defer teardown()
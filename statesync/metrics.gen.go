@@ -20,11 +20,39 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "syncing",
 			Help:      "Whether or not a node is state syncing. 1 if yes, 0 if no.",
 		}, labels).With(labelsAndValues...),
+		ChunkRequestsInFlight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "chunk_requests_in_flight",
+			Help:      "The number of snapshot chunk requests in flight.",
+		}, labels).With(labelsAndValues...),
+		PeerChunkRequestsInFlight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_chunk_requests_in_flight",
+			Help:      "The number of snapshot chunk requests in flight per peer.",
+		}, append(labels, "peer_id")).With(labelsAndValues...),
+		BackfilledBlocks: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "backfilled_blocks",
+			Help:      "The number of blocks backfilled after a state sync.",
+		}, labels).With(labelsAndValues...),
+		BackfillHeight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "backfill_height",
+			Help:      "The lowest height backfilled after a state sync.",
+		}, labels).With(labelsAndValues...),
 	}
 }
 
 func NopMetrics() *Metrics {
 	return &Metrics{
-		Syncing: discard.NewGauge(),
+		Syncing:                   discard.NewGauge(),
+		ChunkRequestsInFlight:     discard.NewGauge(),
+		PeerChunkRequestsInFlight: discard.NewGauge(),
+		BackfilledBlocks:          discard.NewCounter(),
+		BackfillHeight:            discard.NewGauge(),
 	}
 }
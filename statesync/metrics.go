@@ -16,4 +16,12 @@ const (
 type Metrics struct {
 	// Whether or not a node is state syncing. 1 if yes, 0 if no.
 	Syncing metrics.Gauge
+	// The number of snapshot chunk requests in flight.
+	ChunkRequestsInFlight metrics.Gauge
+	// The number of snapshot chunk requests in flight per peer.
+	PeerChunkRequestsInFlight metrics.Gauge `metrics_labels:"peer_id"`
+	// The number of blocks backfilled after a state sync.
+	BackfilledBlocks metrics.Counter
+	// The lowest height backfilled after a state sync.
+	BackfillHeight metrics.Gauge
 }
@@ -36,6 +36,7 @@ type Reactor struct {
 	conn      proxy.AppConnSnapshot
 	connQuery proxy.AppConnQuery
 	tempDir   string
+	resumeDir string
 	metrics   *Metrics
 
 	// This will only be set when a state sync is in progress. It is used to feed received
@@ -44,12 +45,23 @@ type Reactor struct {
 	syncer *syncer
 }
 
+// ReactorOption sets an optional parameter on the Reactor.
+type ReactorOption func(*Reactor)
+
+// ResumeDir sets the dir in which received chunks are persisted, so that a state sync interrupted
+// by a restart resumes where it left off instead of fetching all chunks again. By default, chunks
+// are only stored in a temp dir and a state sync always starts from scratch.
+func ResumeDir(dir string) ReactorOption {
+	return func(r *Reactor) { r.resumeDir = dir }
+}
+
 // NewReactor creates a new state sync reactor.
 func NewReactor(
 	cfg config.StateSyncConfig,
 	conn proxy.AppConnSnapshot,
 	connQuery proxy.AppConnQuery,
 	metrics *Metrics,
+	options ...ReactorOption,
 ) *Reactor {
 	r := &Reactor{
 		cfg:       cfg,
@@ -59,6 +71,10 @@ func NewReactor(
 	}
 	r.BaseReactor = *p2p.NewBaseReactor("StateSync", r)
 
+	for _, option := range options {
+		option(r)
+	}
+
 	return r
 }
 
@@ -114,6 +130,7 @@ func (r *Reactor) Receive(e p2p.Envelope) {
 	err := validateMsg(e.Message)
 	if err != nil {
 		r.Logger.Error("Invalid message", "peer", e.Src, "msg", e.Message, "err", err)
+		r.Switch.ReportPeerBehavior(e.Src, p2p.PeerBehaviorInvalidMessage)
 		r.Switch.StopPeerForError(e.Src, err)
 		return
 	}
@@ -122,7 +139,9 @@ func (r *Reactor) Receive(e p2p.Envelope) {
 	case SnapshotChannel:
 		switch msg := e.Message.(type) {
 		case *ssproto.SnapshotsRequest:
-			snapshots, err := r.recentSnapshots(recentSnapshots)
+			// Peers running older versions do not advertise the formats they
+			// support, in which case all snapshots are offered.
+			snapshots, err := r.recentSnapshots(recentSnapshots, msg.Formats)
 			if err != nil {
 				r.Logger.Error("Failed to fetch snapshots", "err", err)
 				return
@@ -157,6 +176,13 @@ func (r *Reactor) Receive(e p2p.Envelope) {
 				Hash:     msg.Hash,
 				Metadata: msg.Metadata,
 			})
+			if errors.Is(err, errUnsupportedFormat) {
+				// The peer may be running an older version, which offers
+				// snapshots in all formats.
+				r.Logger.Info("Rejected snapshot in unsupported format", "height", msg.Height,
+					"format", msg.Format, "supportedFormats", r.cfg.SnapshotFormats, "peer", e.Src.ID())
+				return
+			}
 			// TODO: We may want to consider punishing the peer for certain errors
 			if err != nil {
 				r.Logger.Error("Failed to add snapshot", "height", msg.Height, "format", msg.Format,
@@ -227,8 +253,9 @@ func (r *Reactor) Receive(e p2p.Envelope) {
 	}
 }
 
-// recentSnapshots fetches the n most recent snapshots from the app.
-func (r *Reactor) recentSnapshots(n uint32) ([]*snapshot, error) {
+// recentSnapshots fetches the n most recent snapshots in one of the given formats from the app.
+// If formats is empty, snapshots in all formats are fetched.
+func (r *Reactor) recentSnapshots(n uint32, formats []uint32) ([]*snapshot, error) {
 	resp, err := r.conn.ListSnapshots(context.TODO(), &abci.ListSnapshotsRequest{})
 	if err != nil {
 		return nil, err
@@ -246,10 +273,13 @@ func (r *Reactor) recentSnapshots(n uint32) ([]*snapshot, error) {
 		}
 	})
 	snapshots := make([]*snapshot, 0, n)
-	for i, s := range resp.Snapshots {
-		if i >= recentSnapshots {
+	for _, s := range resp.Snapshots {
+		if len(snapshots) >= int(n) {
 			break
 		}
+		if !supportsFormat(formats, s.Format) {
+			continue
+		}
 		snapshots = append(snapshots, &snapshot{
 			Height:   s.Height,
 			Format:   s.Format,
@@ -270,7 +300,7 @@ func (r *Reactor) Sync(stateProvider StateProvider, discoveryTime time.Duration)
 		return sm.State{}, nil, errors.New("a state sync is already in progress")
 	}
 	r.metrics.Syncing.Set(1)
-	r.syncer = newSyncer(r.cfg, r.Logger, r.conn, r.connQuery, stateProvider, r.tempDir)
+	r.syncer = newSyncer(r.cfg, r.Logger, r.conn, r.connQuery, stateProvider, r.tempDir, r.resumeDir, r.metrics)
 	r.mtx.Unlock()
 
 	hook := func() {
@@ -279,7 +309,7 @@ func (r *Reactor) Sync(stateProvider StateProvider, discoveryTime time.Duration)
 
 		r.Switch.Broadcast(p2p.Envelope{
 			ChannelID: SnapshotChannel,
-			Message:   &ssproto.SnapshotsRequest{},
+			Message:   &ssproto.SnapshotsRequest{Formats: r.cfg.SnapshotFormats},
 		})
 	}
 
@@ -98,9 +98,10 @@ func TestReactor_Receive_ChunkRequest(t *testing.T) {
 func TestReactor_Receive_SnapshotsRequest(t *testing.T) {
 	testcases := map[string]struct {
 		snapshots       []*abci.Snapshot
+		formats         []uint32
 		expectResponses []*ssproto.SnapshotsResponse
 	}{
-		"no snapshots": {nil, []*ssproto.SnapshotsResponse{}},
+		"no snapshots": {nil, nil, []*ssproto.SnapshotsResponse{}},
 		">10 unordered snapshots": {
 			[]*abci.Snapshot{
 				{Height: 1, Format: 2, Chunks: 7, Hash: []byte{1, 2}, Metadata: []byte{1}},
@@ -116,6 +117,7 @@ func TestReactor_Receive_SnapshotsRequest(t *testing.T) {
 				{Height: 2, Format: 3, Chunks: 7, Hash: []byte{2, 3}, Metadata: []byte{11}},
 				{Height: 3, Format: 3, Chunks: 7, Hash: []byte{3, 3}, Metadata: []byte{12}},
 			},
+			nil,
 			[]*ssproto.SnapshotsResponse{
 				{Height: 3, Format: 4, Chunks: 7, Hash: []byte{3, 4}, Metadata: []byte{9}},
 				{Height: 3, Format: 3, Chunks: 7, Hash: []byte{3, 3}, Metadata: []byte{12}},
@@ -129,6 +131,28 @@ func TestReactor_Receive_SnapshotsRequest(t *testing.T) {
 				{Height: 1, Format: 3, Chunks: 7, Hash: []byte{1, 3}, Metadata: []byte{10}},
 			},
 		},
+		"supported formats only": {
+			[]*abci.Snapshot{
+				{Height: 1, Format: 1, Chunks: 7, Hash: []byte{1, 1}, Metadata: []byte{1}},
+				{Height: 1, Format: 2, Chunks: 7, Hash: []byte{1, 2}, Metadata: []byte{2}},
+				{Height: 2, Format: 1, Chunks: 7, Hash: []byte{2, 1}, Metadata: []byte{3}},
+				{Height: 2, Format: 2, Chunks: 7, Hash: []byte{2, 2}, Metadata: []byte{4}},
+				{Height: 2, Format: 3, Chunks: 7, Hash: []byte{2, 3}, Metadata: []byte{5}},
+			},
+			[]uint32{1, 3},
+			[]*ssproto.SnapshotsResponse{
+				{Height: 2, Format: 3, Chunks: 7, Hash: []byte{2, 3}, Metadata: []byte{5}},
+				{Height: 2, Format: 1, Chunks: 7, Hash: []byte{2, 1}, Metadata: []byte{3}},
+				{Height: 1, Format: 1, Chunks: 7, Hash: []byte{1, 1}, Metadata: []byte{1}},
+			},
+		},
+		"no snapshots in supported formats": {
+			[]*abci.Snapshot{
+				{Height: 1, Format: 1, Chunks: 7, Hash: []byte{1, 1}, Metadata: []byte{1}},
+			},
+			[]uint32{2},
+			[]*ssproto.SnapshotsResponse{},
+		},
 	}
 
 	for name, tc := range testcases {
@@ -173,7 +197,7 @@ func TestReactor_Receive_SnapshotsRequest(t *testing.T) {
 			r.Receive(p2p.Envelope{
 				ChannelID: SnapshotChannel,
 				Src:       peer,
-				Message:   &ssproto.SnapshotsRequest{},
+				Message:   &ssproto.SnapshotsRequest{Formats: tc.formats},
 			})
 			time.Sleep(100 * time.Millisecond)
 			assert.Equal(t, tc.expectResponses, responses)
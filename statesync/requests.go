@@ -0,0 +1,113 @@
+package statesync
+
+import (
+	"context"
+	"math/rand"
+
+	cmtsync "github.com/cometbft/cometbft/libs/sync"
+	"github.com/cometbft/cometbft/p2p"
+)
+
+// chunkRequests tracks the chunk requests in flight to each peer. It spreads requests evenly
+// across the peers offering a snapshot, while never sending more than maxPerPeer concurrent
+// requests to a single peer, so that slow peers aren't overwhelmed.
+type chunkRequests struct {
+	cmtsync.Mutex
+	maxPerPeer int
+	inflight   map[p2p.ID]int
+	total      int
+	released   chan struct{} // closed and replaced whenever a request is released
+	metrics    *Metrics
+}
+
+// newChunkRequests creates a new chunk request tracker.
+func newChunkRequests(maxPerPeer int, metrics *Metrics) *chunkRequests {
+	return &chunkRequests{
+		maxPerPeer: maxPerPeer,
+		inflight:   make(map[p2p.ID]int),
+		released:   make(chan struct{}),
+		metrics:    metrics,
+	}
+}
+
+// Acquire picks the peer with the fewest requests in flight among the given peers, and records a
+// new request to it. If all peers already have the maximum number of requests in flight, it blocks
+// until a request is released, picking among the peers returned by getPeers at that time. It
+// returns nil if there are no peers or ctx is canceled. Callers must call Release() once the
+// request has completed.
+func (r *chunkRequests) Acquire(ctx context.Context, getPeers func() []p2p.Peer) p2p.Peer {
+	for {
+		peers := getPeers()
+		if len(peers) == 0 {
+			return nil
+		}
+
+		r.Lock()
+		if peer := r.leastLoaded(peers); peer != nil {
+			r.inflight[peer.ID()]++
+			r.total++
+			r.updateMetrics(peer.ID())
+			r.Unlock()
+			return peer
+		}
+		released := r.released
+		r.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// leastLoaded returns a random peer among the ones with the fewest requests in flight, or nil if
+// all peers are at capacity. The caller must hold the mutex.
+func (r *chunkRequests) leastLoaded(peers []p2p.Peer) p2p.Peer {
+	var candidates []p2p.Peer
+	least := r.maxPerPeer
+	for _, peer := range peers {
+		switch n := r.inflight[peer.ID()]; {
+		case n < least:
+			least = n
+			candidates = append(candidates[:0], peer)
+		case n == least && n < r.maxPerPeer:
+			candidates = append(candidates, peer)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[rand.Intn(len(candidates))] //nolint:gosec // G404: Use of weak random number generator
+}
+
+// Release records that a request to a peer, previously returned by Acquire(), has completed,
+// either because the chunk was received or the request timed out.
+func (r *chunkRequests) Release(peerID p2p.ID) {
+	r.Lock()
+	defer r.Unlock()
+	if r.inflight[peerID] == 0 {
+		return
+	}
+	r.inflight[peerID]--
+	r.total--
+	r.updateMetrics(peerID)
+	if r.inflight[peerID] == 0 {
+		delete(r.inflight, peerID)
+	}
+	close(r.released)
+	r.released = make(chan struct{})
+}
+
+// InFlight returns the number of requests in flight to a peer.
+func (r *chunkRequests) InFlight(peerID p2p.ID) int {
+	r.Lock()
+	defer r.Unlock()
+	return r.inflight[peerID]
+}
+
+// updateMetrics updates the in-flight request metrics. The caller must hold the mutex.
+func (r *chunkRequests) updateMetrics(peerID p2p.ID) {
+	r.metrics.ChunkRequestsInFlight.Set(float64(r.total))
+	r.metrics.PeerChunkRequestsInFlight.With("peer_id", string(peerID)).Set(float64(r.inflight[peerID]))
+}
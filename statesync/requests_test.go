@@ -0,0 +1,59 @@
+package statesync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/p2p"
+)
+
+func TestChunkRequests(t *testing.T) {
+	requests := newChunkRequests(2, NopMetrics())
+	peers := []p2p.Peer{simplePeer("a"), simplePeer("b")}
+	getPeers := func() []p2p.Peer { return peers }
+
+	// Requests are spread evenly across peers.
+	counts := make(map[p2p.ID]int)
+	for i := 0; i < 4; i++ {
+		peer := requests.Acquire(context.Background(), getPeers)
+		require.NotNil(t, peer)
+		counts[peer.ID()]++
+	}
+	assert.Equal(t, map[p2p.ID]int{"a": 2, "b": 2}, counts)
+	assert.Equal(t, 2, requests.InFlight("a"))
+	assert.Equal(t, 2, requests.InFlight("b"))
+
+	// Once all peers are at capacity, Acquire blocks until a request is released.
+	acquired := make(chan p2p.Peer)
+	go func() {
+		acquired <- requests.Acquire(context.Background(), getPeers)
+	}()
+	select {
+	case peer := <-acquired:
+		t.Fatalf("acquired peer %v while all peers were at capacity", peer.ID())
+	case <-time.After(50 * time.Millisecond):
+	}
+	requests.Release("b")
+	select {
+	case peer := <-acquired:
+		assert.Equal(t, p2p.ID("b"), peer.ID())
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for peer to be acquired")
+	}
+
+	// Acquire gives up when the context is canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Nil(t, requests.Acquire(ctx, getPeers))
+
+	// Acquire returns nil without peers.
+	assert.Nil(t, requests.Acquire(context.Background(), func() []p2p.Peer { return nil }))
+
+	// Releasing a peer without requests in flight is a no-op.
+	requests.Release("c")
+	assert.Zero(t, requests.InFlight("c"))
+}
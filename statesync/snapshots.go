@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"math/rand"
+	"slices"
 	"sort"
 
 	cmtsync "github.com/cometbft/cometbft/libs/sync"
@@ -38,6 +39,12 @@ func (s *snapshot) Key() snapshotKey {
 	return key
 }
 
+// supportsFormat returns true if format is one of the given supported
+// formats. An empty list of supported formats supports all formats.
+func supportsFormat(formats []uint32, format uint32) bool {
+	return len(formats) == 0 || slices.Contains(formats, format)
+}
+
 // snapshotPool discovers and aggregates snapshots across peers.
 type snapshotPool struct {
 	cmtsync.Mutex
@@ -126,6 +133,13 @@ func (p *snapshotPool) Best() *snapshot {
 	return ranked[0]
 }
 
+// Get returns the snapshot with the given key, if any.
+func (p *snapshotPool) Get(key snapshotKey) *snapshot {
+	p.Lock()
+	defer p.Unlock()
+	return p.snapshots[key]
+}
+
 // GetPeer returns a random peer for a snapshot, if any.
 func (p *snapshotPool) GetPeer(snapshot *snapshot) p2p.Peer {
 	peers := p.GetPeers(snapshot)
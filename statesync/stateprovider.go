@@ -135,6 +135,14 @@ func (s *lightClientStateProvider) Commit(ctx context.Context, height uint64) (*
 	return header.Commit, nil
 }
 
+// LightBlock implements LightBlockProvider. The light block is fetched from the
+// primary provider of the light client, without verifying it.
+func (s *lightClientStateProvider) LightBlock(ctx context.Context, height uint64) (*types.LightBlock, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.lc.Primary().LightBlock(ctx, int64(height))
+}
+
 // State implements StateProvider.
 func (s *lightClientStateProvider) State(ctx context.Context, height uint64) (sm.State, error) {
 	s.Lock()
@@ -37,6 +37,8 @@ var (
 	errRejectSnapshot = errors.New("snapshot was rejected")
 	// errRejectFormat is returned by Sync() when the snapshot format is rejected.
 	errRejectFormat = errors.New("snapshot format was rejected")
+	// errUnsupportedFormat is returned by AddSnapshot() when the snapshot format is not supported.
+	errUnsupportedFormat = errors.New("unsupported snapshot format")
 	// errRejectSender is returned by Sync() when the snapshot sender is rejected.
 	errRejectSender = errors.New("snapshot sender was rejected")
 	// errVerifyFailed is returned by Sync() when app hash or last height verification fails.
@@ -57,8 +59,11 @@ type syncer struct {
 	connQuery     proxy.AppConnQuery
 	snapshots     *snapshotPool
 	tempDir       string
+	resumeDir     string
 	chunkFetchers int32
+	chunkRequests *chunkRequests
 	retryTimeout  time.Duration
+	formats       []uint32
 
 	mtx    cmtsync.RWMutex
 	chunks *chunkQueue
@@ -72,6 +77,8 @@ func newSyncer(
 	connQuery proxy.AppConnQuery,
 	stateProvider StateProvider,
 	tempDir string,
+	resumeDir string,
+	metrics *Metrics,
 ) *syncer {
 	return &syncer{
 		logger:        logger,
@@ -80,8 +87,11 @@ func newSyncer(
 		connQuery:     connQuery,
 		snapshots:     newSnapshotPool(),
 		tempDir:       tempDir,
+		resumeDir:     resumeDir,
 		chunkFetchers: cfg.ChunkFetchers,
+		chunkRequests: newChunkRequests(int(cfg.MaxChunkRequestsPerPeer), metrics),
 		retryTimeout:  cfg.ChunkRequestTimeout,
+		formats:       cfg.SnapshotFormats,
 	}
 }
 
@@ -108,8 +118,12 @@ func (s *syncer) AddChunk(chunk *chunk) (bool, error) {
 }
 
 // AddSnapshot adds a snapshot to the snapshot pool. It returns true if a new, previously unseen
-// snapshot was accepted and added.
+// snapshot was accepted and added. Snapshots in a format that is not supported are rejected with
+// errUnsupportedFormat.
 func (s *syncer) AddSnapshot(peer p2p.Peer, snapshot *snapshot) (bool, error) {
+	if !supportsFormat(s.formats, snapshot.Format) {
+		return false, fmt.Errorf("%w %v (supported formats: %v)", errUnsupportedFormat, snapshot.Format, s.formats)
+	}
 	added, err := s.snapshots.Add(peer, snapshot)
 	if err != nil {
 		return false, err
@@ -127,7 +141,7 @@ func (s *syncer) AddPeer(peer p2p.Peer) {
 	s.logger.Debug("Requesting snapshots from peer", "peer", peer.ID())
 	e := p2p.Envelope{
 		ChannelID: SnapshotChannel,
-		Message:   &ssproto.SnapshotsRequest{},
+		Message:   &ssproto.SnapshotsRequest{Formats: s.formats},
 	}
 	peer.Send(e)
 }
@@ -158,10 +172,19 @@ func (s *syncer) SyncAny(discoveryTime time.Duration, retryHook func()) (sm.Stat
 		chunks   *chunkQueue
 		err      error
 	)
+	resumeChecked := s.resumeDir == ""
 	for {
 		// If not nil, we're going to retry restoration of the same snapshot.
 		if snapshot == nil {
-			snapshot = s.snapshots.Best()
+			// Before picking the best snapshot, try to resume the sync that was in progress
+			// when the node was stopped, if it is still being offered by peers.
+			if !resumeChecked && s.snapshots.Best() != nil {
+				resumeChecked = true
+				snapshot = s.resumableSnapshot()
+			}
+			if snapshot == nil {
+				snapshot = s.snapshots.Best()
+			}
 			chunks = nil
 		}
 		if snapshot == nil {
@@ -174,7 +197,16 @@ func (s *syncer) SyncAny(discoveryTime time.Duration, retryHook func()) (sm.Stat
 			continue
 		}
 		if chunks == nil {
-			chunks, err = newChunkQueue(snapshot, s.tempDir)
+			if s.resumeDir != "" {
+				var resumed uint32
+				chunks, resumed, err = newResumableChunkQueue(snapshot, s.resumeDir)
+				if err == nil && resumed > 0 {
+					s.logger.Info("Resuming state sync", "height", snapshot.Height, "format", snapshot.Format,
+						"hash", log.NewLazySprintf("%X", snapshot.Hash), "chunks", resumed, "total", snapshot.Chunks)
+				}
+			} else {
+				chunks, err = newChunkQueue(snapshot, s.tempDir)
+			}
 			if err != nil {
 				return sm.State{}, nil, fmt.Errorf("failed to create chunk queue: %w", err)
 			}
@@ -235,6 +267,26 @@ func (s *syncer) SyncAny(discoveryTime time.Duration, retryHook func()) (sm.Stat
 	}
 }
 
+// resumableSnapshot returns the snapshot that was being restored from the resume dir when the
+// node was stopped, if it is still offered by peers. Otherwise, it returns nil, and the chunks
+// persisted for it will be discarded once a chunk queue is created for another snapshot.
+func (s *syncer) resumableSnapshot() *snapshot {
+	persisted, err := loadPersistedSnapshot(s.resumeDir)
+	if err != nil {
+		s.logger.Error("Failed to load persisted snapshot, discarding it", "err", err)
+		return nil
+	}
+	if persisted == nil {
+		return nil
+	}
+	snapshot := s.snapshots.Get(persisted.Key())
+	if snapshot == nil {
+		s.logger.Info("Persisted snapshot is no longer offered by peers, discarding it",
+			"height", persisted.Height, "format", persisted.Format, "hash", log.NewLazySprintf("%X", persisted.Hash))
+	}
+	return snapshot
+}
+
 // Sync executes a sync for a specific snapshot, returning the latest state and block commit which
 // the caller must use to bootstrap the node.
 func (s *syncer) Sync(snapshot *snapshot, chunks *chunkQueue) (sm.State, *types.Commit, error) {
@@ -440,7 +492,7 @@ func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *ch
 		s.logger.Info("Fetching snapshot chunk", "height", snapshot.Height,
 			"format", snapshot.Format, "chunk", index, "total", chunks.Size())
 
-		s.requestChunk(snapshot, index)
+		peer := s.requestChunk(ctx, snapshot, index)
 
 		select {
 		case <-chunks.WaitFor(index):
@@ -450,18 +502,27 @@ func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *ch
 			next = false
 
 		case <-ctx.Done():
+		}
+		if peer != nil {
+			s.chunkRequests.Release(peer.ID())
+		}
+		if ctx.Err() != nil {
 			return
 		}
 	}
 }
 
-// requestChunk requests a chunk from a peer.
-func (s *syncer) requestChunk(snapshot *snapshot, chunk uint32) {
-	peer := s.snapshots.GetPeer(snapshot)
+// requestChunk requests a chunk from the least loaded peer offering the snapshot, waiting for one
+// to have capacity if needed. It returns the peer, if any, which the caller must release once the
+// request has completed.
+func (s *syncer) requestChunk(ctx context.Context, snapshot *snapshot, chunk uint32) p2p.Peer {
+	peer := s.chunkRequests.Acquire(ctx, func() []p2p.Peer { return s.snapshots.GetPeers(snapshot) })
 	if peer == nil {
-		s.logger.Error("No valid peers found for snapshot", "height", snapshot.Height,
-			"format", snapshot.Format, "hash", log.NewLazySprintf("%X", snapshot.Hash))
-		return
+		if ctx.Err() == nil {
+			s.logger.Error("No valid peers found for snapshot", "height", snapshot.Height,
+				"format", snapshot.Format, "hash", log.NewLazySprintf("%X", snapshot.Hash))
+		}
+		return nil
 	}
 	s.logger.Debug("Requesting snapshot chunk", "height", snapshot.Height,
 		"format", snapshot.Format, "chunk", chunk, "peer", peer.ID())
@@ -473,6 +534,7 @@ func (s *syncer) requestChunk(snapshot *snapshot, chunk uint32) {
 			Index:  chunk,
 		},
 	})
+	return peer
 }
 
 // verifyApp verifies the sync, checking the app hash, last block height and app version.
@@ -2,6 +2,7 @@ package statesync
 
 import (
 	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -36,7 +37,7 @@ func setupOfferSyncer() (*syncer, *proxymocks.AppConnSnapshot) {
 	stateProvider := &mocks.StateProvider{}
 	stateProvider.On("AppHash", mock.Anything, mock.Anything).Return([]byte("app_hash"), nil)
 	cfg := config.DefaultStateSyncConfig()
-	syncer := newSyncer(*cfg, log.NewNopLogger(), connSnapshot, connQuery, stateProvider, "")
+	syncer := newSyncer(*cfg, log.NewNopLogger(), connSnapshot, connQuery, stateProvider, "", "", NopMetrics())
 
 	return syncer, connSnapshot
 }
@@ -90,7 +91,7 @@ func TestSyncer_SyncAny(t *testing.T) {
 	connQuery := &proxymocks.AppConnQuery{}
 
 	cfg := config.DefaultStateSyncConfig()
-	syncer := newSyncer(*cfg, log.NewNopLogger(), connSnapshot, connQuery, stateProvider, "")
+	syncer := newSyncer(*cfg, log.NewNopLogger(), connSnapshot, connQuery, stateProvider, "", "", NopMetrics())
 
 	// Adding a chunk should error when no sync is in progress
 	_, err := syncer.AddChunk(&chunk{Height: 1, Format: 1, Index: 0, Chunk: []byte{1}})
@@ -365,6 +366,64 @@ func TestSyncer_SyncAny_abciError(t *testing.T) {
 	connSnapshot.AssertExpectations(t)
 }
 
+func TestSyncer_AddSnapshot_unsupportedFormat(t *testing.T) {
+	cfg := config.DefaultStateSyncConfig()
+	cfg.SnapshotFormats = []uint32{1, 3}
+	syncer := newSyncer(*cfg, log.NewNopLogger(), &proxymocks.AppConnSnapshot{}, &proxymocks.AppConnQuery{},
+		&mocks.StateProvider{}, "", "", NopMetrics())
+
+	// The supported formats are advertised when requesting snapshots.
+	peer := simplePeer("id")
+	peer.On("Send", p2p.Envelope{
+		ChannelID: SnapshotChannel,
+		Message:   &ssproto.SnapshotsRequest{Formats: []uint32{1, 3}},
+	}).Return(true)
+	syncer.AddPeer(peer)
+	peer.AssertExpectations(t)
+
+	added, err := syncer.AddSnapshot(peer, &snapshot{Height: 1, Format: 2, Chunks: 1, Hash: []byte{1}})
+	require.ErrorIs(t, err, errUnsupportedFormat)
+	assert.False(t, added)
+
+	added, err = syncer.AddSnapshot(peer, &snapshot{Height: 1, Format: 3, Chunks: 1, Hash: []byte{1}})
+	require.NoError(t, err)
+	assert.True(t, added)
+}
+
+func TestSyncer_resumableSnapshot(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "statesync")
+	cfg := config.DefaultStateSyncConfig()
+	syncer := newSyncer(*cfg, log.NewNopLogger(), &proxymocks.AppConnSnapshot{}, &proxymocks.AppConnQuery{},
+		&mocks.StateProvider{}, "", dir, NopMetrics())
+
+	// Nothing to resume without a persisted snapshot.
+	assert.Nil(t, syncer.resumableSnapshot())
+
+	persisted := &snapshot{Height: 2, Format: 1, Chunks: 3, Hash: []byte{2}}
+	queue, _, err := newResumableChunkQueue(persisted, dir)
+	require.NoError(t, err)
+	defer queue.Close()
+
+	// The persisted snapshot is not resumed until a peer offers it.
+	peer := simplePeer("id")
+	_, err = syncer.AddSnapshot(peer, &snapshot{Height: 1, Format: 1, Chunks: 3, Hash: []byte{1}})
+	require.NoError(t, err)
+	assert.Nil(t, syncer.resumableSnapshot())
+
+	// The persisted snapshot is resumed even if it is not the best one.
+	_, err = syncer.AddSnapshot(peer, &snapshot{Height: 2, Format: 1, Chunks: 3, Hash: []byte{2}})
+	require.NoError(t, err)
+	_, err = syncer.AddSnapshot(peer, &snapshot{Height: 3, Format: 1, Chunks: 3, Hash: []byte{3}})
+	require.NoError(t, err)
+	resumable := syncer.resumableSnapshot()
+	require.NotNil(t, resumable)
+	assert.Equal(t, persisted.Key(), resumable.Key())
+
+	// The persisted snapshot is not resumed if it was rejected.
+	syncer.snapshots.Reject(resumable)
+	assert.Nil(t, syncer.resumableSnapshot())
+}
+
 func TestSyncer_offerSnapshot(t *testing.T) {
 	unknownErr := errors.New("unknown error")
 	boom := errors.New("boom")
@@ -431,7 +490,7 @@ func TestSyncer_applyChunks_Results(t *testing.T) {
 			stateProvider.On("AppHash", mock.Anything, mock.Anything).Return([]byte("app_hash"), nil)
 
 			cfg := config.DefaultStateSyncConfig()
-			syncer := newSyncer(*cfg, log.NewNopLogger(), connSnapshot, connQuery, stateProvider, "")
+			syncer := newSyncer(*cfg, log.NewNopLogger(), connSnapshot, connQuery, stateProvider, "", "", NopMetrics())
 
 			body := []byte{1, 2, 3}
 			chunks, err := newChunkQueue(&snapshot{Height: 1, Format: 1, Chunks: 1}, "")
@@ -484,7 +543,7 @@ func TestSyncer_applyChunks_RefetchChunks(t *testing.T) {
 			stateProvider.On("AppHash", mock.Anything, mock.Anything).Return([]byte("app_hash"), nil)
 
 			cfg := config.DefaultStateSyncConfig()
-			syncer := newSyncer(*cfg, log.NewNopLogger(), connSnapshot, connQuery, stateProvider, "")
+			syncer := newSyncer(*cfg, log.NewNopLogger(), connSnapshot, connQuery, stateProvider, "", "", NopMetrics())
 
 			chunks, err := newChunkQueue(&snapshot{Height: 1, Format: 1, Chunks: 3}, "")
 			require.NoError(t, err)
@@ -548,7 +607,7 @@ func TestSyncer_applyChunks_RejectSenders(t *testing.T) {
 			stateProvider.On("AppHash", mock.Anything, mock.Anything).Return([]byte("app_hash"), nil)
 
 			cfg := config.DefaultStateSyncConfig()
-			syncer := newSyncer(*cfg, log.NewNopLogger(), connSnapshot, connQuery, stateProvider, "")
+			syncer := newSyncer(*cfg, log.NewNopLogger(), connSnapshot, connQuery, stateProvider, "", "", NopMetrics())
 
 			// Set up three peers across two snapshots, and ask for one of them to be banned.
 			// It should be banned from all snapshots.
@@ -669,7 +728,7 @@ func TestSyncer_verifyApp(t *testing.T) {
 			stateProvider := &mocks.StateProvider{}
 
 			cfg := config.DefaultStateSyncConfig()
-			syncer := newSyncer(*cfg, log.NewNopLogger(), connSnapshot, connQuery, stateProvider, "")
+			syncer := newSyncer(*cfg, log.NewNopLogger(), connSnapshot, connQuery, stateProvider, "", "", NopMetrics())
 
 			connQuery.On("Info", mock.Anything, proxy.InfoRequest).Return(tc.response, tc.err)
 			err := syncer.verifyApp(s, appVersion)
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	"github.com/cometbft/cometbft/libs/protoio"
+	"github.com/cometbft/cometbft/types"
+)
+
+// maxExportMsgSize is the maximum size of a message read by Import. Blocks are
+// the largest messages, and can't be larger than types.MaxBlockSizeBytes.
+const maxExportMsgSize = types.MaxBlockSizeBytes + 1024*1024
+
+// Export writes the blocks in [from, to], along with their commits, to w. Each
+// height is written as a length-prefixed (uvarint) protobuf-encoded block,
+// followed by its length-prefixed protobuf-encoded commit. The commit of a
+// height is the one included in the next block if it is stored, and the seen
+// commit otherwise. Extended commits are not exported.
+//
+// As heights are written in order and independently of each other, an export
+// that was interrupted can be resumed by exporting from the height after the
+// last one written and appending to the same file.
+//
+// It returns an error if the range is not within [Base(), Height()], data is
+// missing from the store, or writing to w fails.
+func (bs *BlockStore) Export(w io.Writer, from, to int64) error {
+	base, height := bs.Base(), bs.Height()
+	if base == 0 {
+		return errors.New("block store is empty")
+	}
+	if from < base || to > height || from > to {
+		return fmt.Errorf("invalid height range [%d, %d], the block store has heights [%d, %d]",
+			from, to, base, height)
+	}
+
+	writer := protoio.NewDelimitedWriter(w)
+	for h := from; h <= to; h++ {
+		block, _ := bs.LoadBlock(h)
+		if block == nil {
+			return fmt.Errorf("block at height %d not found", h)
+		}
+		commit := bs.LoadBlockCommit(h)
+		if commit == nil {
+			commit = bs.LoadSeenCommit(h)
+		}
+		if commit == nil {
+			return fmt.Errorf("commit at height %d not found", h)
+		}
+
+		pbb, err := block.ToProto()
+		if err != nil {
+			return fmt.Errorf("converting block at height %d to proto: %w", h, err)
+		}
+		if _, err := writer.WriteMsg(pbb); err != nil {
+			return fmt.Errorf("writing block at height %d: %w", h, err)
+		}
+		if _, err := writer.WriteMsg(commit.ToProto()); err != nil {
+			return fmt.Errorf("writing commit at height %d: %w", h, err)
+		}
+	}
+	return nil
+}
+
+// Import reads blocks and their commits written by Export from r and saves
+// them to the store, until r is exhausted. The first block imported into an
+// empty store becomes its base, and the following blocks must be contiguous.
+//
+// Each block is validated before being saved: it must be valid on its own,
+// link to the previous block, and its commit must be for the block. Commit
+// signatures are not verified, as doing so requires the validator sets, so the
+// file must come from a trusted source.
+//
+// Blocks that are already in the store are checked against the stored ones and
+// skipped, so that an import that was interrupted can be resumed by importing
+// the same file again.
+func (bs *BlockStore) Import(r io.Reader) error {
+	reader := protoio.NewDelimitedReader(r, maxExportMsgSize)
+	for {
+		pbb := new(cmtproto.Block)
+		if _, err := reader.ReadMsg(pbb); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("reading block: %w", err)
+		}
+		block, err := types.BlockFromProto(pbb)
+		if err != nil {
+			return fmt.Errorf("reading block: %w", err)
+		}
+
+		pbc := new(cmtproto.Commit)
+		if _, err := reader.ReadMsg(pbc); err != nil {
+			return fmt.Errorf("reading commit at height %d: %w", block.Height, err)
+		}
+		commit, err := types.CommitFromProto(pbc)
+		if err != nil {
+			return fmt.Errorf("reading commit at height %d: %w", block.Height, err)
+		}
+
+		if err := bs.importBlock(block, commit); err != nil {
+			return fmt.Errorf("importing block at height %d: %w", block.Height, err)
+		}
+	}
+}
+
+// importBlock validates a block and its commit read by Import and saves them,
+// unless the block is already in the store.
+func (bs *BlockStore) importBlock(block *types.Block, commit *types.Commit) error {
+	if err := block.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid block: %w", err)
+	}
+	if err := commit.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid commit: %w", err)
+	}
+	partSet, err := block.MakePartSet(types.BlockPartSizeBytes)
+	if err != nil {
+		return err
+	}
+	blockID := types.BlockID{Hash: block.Hash(), PartSetHeader: partSet.Header()}
+	if commit.Height != block.Height {
+		return fmt.Errorf("commit is for height %d", commit.Height)
+	}
+	if !commit.BlockID.Equals(blockID) {
+		return fmt.Errorf("commit is for block %v, expected %v", commit.BlockID, blockID)
+	}
+
+	base, height := bs.Base(), bs.Height()
+	if base > 0 && block.Height <= height {
+		if block.Height < base {
+			return fmt.Errorf("block is below the base %d of the store", base)
+		}
+		meta := bs.LoadBlockMeta(block.Height)
+		if meta == nil || !meta.BlockID.Equals(blockID) {
+			return errors.New("block differs from the one in the store")
+		}
+		return nil
+	}
+	if base > 0 {
+		if block.Height != height+1 {
+			return fmt.Errorf("blocks must be contiguous, expected height %d", height+1)
+		}
+		if meta := bs.LoadBlockMeta(height); !block.LastBlockID.Equals(meta.BlockID) {
+			return fmt.Errorf("block doesn't link to the block at height %d: last block ID is %v, expected %v",
+				height, block.LastBlockID, meta.BlockID)
+		}
+	}
+
+	batch := bs.db.NewBatch()
+	defer batch.Close()
+	if err := bs.saveBlockToBatch(block, partSet, commit, batch); err != nil {
+		return err
+	}
+
+	bs.mtx.Lock()
+	defer bs.mtx.Unlock()
+	bs.height = block.Height
+	if bs.base == 0 {
+		bs.base = block.Height
+	}
+	return bs.saveStateAndWriteDB(batch, "failed to import block")
+}
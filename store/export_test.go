@@ -0,0 +1,86 @@
+package store
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/types"
+	cmttime "github.com/cometbft/cometbft/types/time"
+)
+
+func TestExportImport(t *testing.T) {
+	state, _, _, _, cleanup, _ := makeStateAndBlockStoreAndIndexers()
+	defer cleanup()
+	bs, _ := newInMemoryBlockStore()
+
+	var buf bytes.Buffer
+	require.Error(t, bs.Export(&buf, 1, 1), "an empty block store can't be exported")
+
+	// Save a chain of blocks with two parts each.
+	txs := []types.Tx{make([]byte, types.BlockPartSizeBytes)}
+	lastCommit := new(types.Commit)
+	for h := int64(1); h <= 5; h++ {
+		block := state.MakeBlock(h, txs, lastCommit, nil, state.Validators.GetProposer().Address)
+		partSet, err := block.MakePartSet(types.BlockPartSizeBytes)
+		require.NoError(t, err)
+		commit := makeTestExtCommit(h, cmttime.Now()).ToCommit()
+		commit.BlockID = types.BlockID{Hash: block.Hash(), PartSetHeader: partSet.Header()}
+		bs.SaveBlock(block, partSet, commit)
+		state.LastBlockID = commit.BlockID
+		lastCommit = commit
+	}
+
+	require.Error(t, bs.Export(&buf, 0, 5))
+	require.Error(t, bs.Export(&buf, 1, 6))
+	require.Error(t, bs.Export(&buf, 3, 2))
+
+	// Export in two steps, as if the export was resumed.
+	require.NoError(t, bs.Export(&buf, 1, 3))
+	require.NoError(t, bs.Export(&buf, 4, 5))
+	exported := buf.Bytes()
+
+	imported, _ := newInMemoryBlockStore()
+	require.NoError(t, imported.Import(bytes.NewReader(exported)))
+	require.EqualValues(t, 1, imported.Base())
+	require.EqualValues(t, 5, imported.Height())
+	for h := int64(1); h <= 5; h++ {
+		require.Equal(t, bs.LoadBlockMeta(h), imported.LoadBlockMeta(h))
+		require.Equal(t, bs.LoadSeenCommit(h).BlockID, imported.LoadSeenCommit(h).BlockID)
+	}
+	problems, err := imported.VerifyIntegrity(1, 5)
+	require.NoError(t, err)
+	require.Empty(t, problems)
+
+	// Importing again skips the blocks that are already in the store.
+	require.NoError(t, imported.Import(bytes.NewReader(exported)))
+	require.EqualValues(t, 5, imported.Height())
+
+	// A truncated file is an error, but the blocks before it are imported,
+	// and the import can be resumed.
+	partial, _ := newInMemoryBlockStore()
+	require.Error(t, partial.Import(bytes.NewReader(exported[:len(exported)/2])))
+	require.Positive(t, partial.Height())
+	require.NoError(t, partial.Import(bytes.NewReader(exported)))
+	require.EqualValues(t, 5, partial.Height())
+
+	// Blocks that don't match their commit are rejected.
+	buf.Reset()
+	require.NoError(t, bs.Export(&buf, 1, 1))
+	tampered := buf.Bytes()
+	i := bytes.Index(tampered, txs[0][:32])
+	require.Positive(t, i)
+	tampered[i] ^= 0xff
+	fresh, _ := newInMemoryBlockStore()
+	require.Error(t, fresh.Import(bytes.NewReader(tampered)))
+	require.True(t, fresh.IsEmpty())
+
+	// Blocks must be contiguous.
+	buf.Reset()
+	require.NoError(t, bs.Export(&buf, 1, 1))
+	require.NoError(t, bs.Export(&buf, 3, 3))
+	fresh, _ = newInMemoryBlockStore()
+	require.Error(t, fresh.Import(&buf))
+	require.EqualValues(t, 1, fresh.Height())
+}
@@ -0,0 +1,202 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/cosmos/gogoproto/proto"
+
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	"github.com/cometbft/cometbft/types"
+)
+
+// ProblemKind is the kind of a problem found by VerifyIntegrity.
+type ProblemKind string
+
+const (
+	// ProblemMissingBlockMeta means that there is no block meta at the height.
+	ProblemMissingBlockMeta ProblemKind = "missing block meta"
+	// ProblemMissingBlockPart means that a part of the block is missing.
+	ProblemMissingBlockPart ProblemKind = "missing block part"
+	// ProblemMissingCommit means that there is no commit for the block.
+	ProblemMissingCommit ProblemKind = "missing commit"
+	// ProblemHashMismatch means that some data doesn't match the hash it is
+	// expected to have.
+	ProblemHashMismatch ProblemKind = "hash mismatch"
+	// ProblemCorrupted means that some data can't be decoded.
+	ProblemCorrupted ProblemKind = "corrupted data"
+)
+
+// HeightProblem is a problem found by VerifyIntegrity at a given height.
+type HeightProblem struct {
+	Height  int64
+	Kind    ProblemKind
+	Details string
+}
+
+func (p HeightProblem) String() string {
+	return fmt.Sprintf("height %d: %s: %s", p.Height, p.Kind, p.Details)
+}
+
+// VerifyIntegrity checks that every height in [from, to] has a block meta, all
+// the parts of the block, and a commit for the block, and that they are
+// consistent with each other. It returns the problems found, if any, or an
+// error if the range is not within [Base(), Height()] or the database can't be
+// read.
+//
+// The commit of the latest height is the seen commit, while the commits of
+// previous heights are the ones included in the next block.
+//
+// Unlike the Load* methods, it doesn't panic when the data it reads is
+// corrupted, but reports it as a problem. It should be run while the node is
+// stopped, otherwise pruning may be reported as missing data.
+func (bs *BlockStore) VerifyIntegrity(from, to int64) ([]HeightProblem, error) {
+	base, height := bs.Base(), bs.Height()
+	if base == 0 {
+		return nil, errors.New("block store is empty")
+	}
+	if from < base || to > height || from > to {
+		return nil, fmt.Errorf("invalid height range [%d, %d], the block store has heights [%d, %d]",
+			from, to, base, height)
+	}
+
+	var problems []HeightProblem
+	for h := from; h <= to; h++ {
+		heightProblems, err := bs.verifyHeight(h, h == height)
+		if err != nil {
+			return problems, err
+		}
+		problems = append(problems, heightProblems...)
+	}
+	return problems, nil
+}
+
+// verifyHeight verifies the data stored for a single height. isLatest must be
+// true for the latest height of the store, whose commit is the seen commit.
+func (bs *BlockStore) verifyHeight(height int64, isLatest bool) ([]HeightProblem, error) {
+	var problems []HeightProblem
+	report := func(kind ProblemKind, format string, args ...any) {
+		problems = append(problems, HeightProblem{Height: height, Kind: kind, Details: fmt.Sprintf(format, args...)})
+	}
+
+	var blockID *types.BlockID
+	bz, err := bs.db.Get(bs.dbKeyLayout.CalcBlockMetaKey(height))
+	if err != nil {
+		return nil, err
+	}
+	if len(bz) == 0 {
+		report(ProblemMissingBlockMeta, "no block meta")
+	} else if meta, err := decodeBlockMeta(bz); err != nil {
+		report(ProblemCorrupted, "block meta: %v", err)
+	} else {
+		if meta.Header.Height != height {
+			report(ProblemHashMismatch, "block meta is for height %d", meta.Header.Height)
+		}
+		blockID = &meta.BlockID
+		if err := bs.verifyBlockParts(height, meta, report); err != nil {
+			return nil, err
+		}
+	}
+
+	commitKey, commitName := bs.dbKeyLayout.CalcBlockCommitKey(height), "commit"
+	if isLatest {
+		commitKey, commitName = bs.dbKeyLayout.CalcSeenCommitKey(height), "seen commit"
+	}
+	bz, err = bs.db.Get(commitKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(bz) == 0 {
+		report(ProblemMissingCommit, "no %s", commitName)
+		return problems, nil
+	}
+	switch commit, err := decodeCommit(bz); {
+	case err != nil:
+		report(ProblemCorrupted, "%s: %v", commitName, err)
+	case commit.Height != height:
+		report(ProblemHashMismatch, "%s is for height %d", commitName, commit.Height)
+	case blockID != nil && !commit.BlockID.Equals(*blockID):
+		report(ProblemHashMismatch, "%s is for block %v, expected %v", commitName, commit.BlockID, *blockID)
+	}
+	return problems, nil
+}
+
+// verifyBlockParts checks that all the parts of a block are stored and match
+// its part set header, and that the block they make up has the hash in meta.
+// Problems are reported with report, while the returned error is only set if
+// the database can't be read.
+func (bs *BlockStore) verifyBlockParts(
+	height int64,
+	meta *types.BlockMeta,
+	report func(kind ProblemKind, format string, args ...any),
+) error {
+	header := meta.BlockID.PartSetHeader
+	complete := true
+	buf := []byte{}
+	for i := 0; i < int(header.Total); i++ {
+		bz, err := bs.db.Get(bs.dbKeyLayout.CalcBlockPartKey(height, i))
+		if err != nil {
+			return err
+		}
+		if len(bz) == 0 {
+			report(ProblemMissingBlockPart, "no part %d of %d", i, header.Total)
+			complete = false
+			continue
+		}
+		part, err := decodePart(bz)
+		if err != nil {
+			report(ProblemCorrupted, "part %d: %v", i, err)
+			complete = false
+			continue
+		}
+		if err := part.Proof.Verify(header.Hash, part.Bytes); err != nil {
+			report(ProblemHashMismatch, "part %d doesn't match the part set header: %v", i, err)
+			complete = false
+			continue
+		}
+		buf = append(buf, part.Bytes...)
+	}
+	if !complete {
+		return nil
+	}
+
+	pbb := new(cmtproto.Block)
+	if err := proto.Unmarshal(buf, pbb); err != nil {
+		report(ProblemCorrupted, "block: %v", err)
+		return nil
+	}
+	block, err := types.BlockFromProto(pbb)
+	if err != nil {
+		report(ProblemCorrupted, "block: %v", err)
+		return nil
+	}
+	if hash := block.Hash(); !bytes.Equal(hash, meta.BlockID.Hash) {
+		report(ProblemHashMismatch, "block hash is %X, expected %X", hash, meta.BlockID.Hash)
+	}
+	return nil
+}
+
+func decodeBlockMeta(bz []byte) (*types.BlockMeta, error) {
+	pbbm := new(cmtproto.BlockMeta)
+	if err := proto.Unmarshal(bz, pbbm); err != nil {
+		return nil, err
+	}
+	return types.BlockMetaFromTrustedProto(pbbm)
+}
+
+func decodePart(bz []byte) (*types.Part, error) {
+	pbpart := new(cmtproto.Part)
+	if err := proto.Unmarshal(bz, pbpart); err != nil {
+		return nil, err
+	}
+	return types.PartFromProto(pbpart)
+}
+
+func decodeCommit(bz []byte) (*types.Commit, error) {
+	pbc := new(cmtproto.Commit)
+	if err := proto.Unmarshal(bz, pbc); err != nil {
+		return nil, err
+	}
+	return types.CommitFromProto(pbc)
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/types"
+	cmttime "github.com/cometbft/cometbft/types/time"
+)
+
+func TestVerifyIntegrity(t *testing.T) {
+	state, _, _, _, cleanup, _ := makeStateAndBlockStoreAndIndexers()
+	defer cleanup()
+	bs, db := newInMemoryBlockStore()
+
+	_, err := bs.VerifyIntegrity(1, 1)
+	require.Error(t, err, "an empty block store can't be verified")
+
+	// Save a chain of blocks with two parts each, whose commits are
+	// consistent with the blocks.
+	txs := []types.Tx{make([]byte, types.BlockPartSizeBytes)}
+	lastCommit := new(types.Commit)
+	for h := int64(1); h <= 5; h++ {
+		block := state.MakeBlock(h, txs, lastCommit, nil, state.Validators.GetProposer().Address)
+		partSet, err := block.MakePartSet(types.BlockPartSizeBytes)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, partSet.Total())
+		commit := makeTestExtCommit(h, cmttime.Now()).ToCommit()
+		commit.BlockID = types.BlockID{Hash: block.Hash(), PartSetHeader: partSet.Header()}
+		bs.SaveBlock(block, partSet, commit)
+		lastCommit = commit
+	}
+
+	problems, err := bs.VerifyIntegrity(1, 5)
+	require.NoError(t, err)
+	require.Empty(t, problems)
+
+	_, err = bs.VerifyIntegrity(0, 5)
+	require.Error(t, err)
+	_, err = bs.VerifyIntegrity(1, 6)
+	require.Error(t, err)
+	_, err = bs.VerifyIntegrity(3, 2)
+	require.Error(t, err)
+
+	// Delete a part, a commit and the seen commit of the latest height.
+	require.NoError(t, db.Delete(bs.dbKeyLayout.CalcBlockPartKey(2, 1)))
+	require.NoError(t, db.Delete(bs.dbKeyLayout.CalcBlockCommitKey(3)))
+	require.NoError(t, db.Delete(bs.dbKeyLayout.CalcSeenCommitKey(5)))
+
+	// Tamper with a part.
+	part := bs.LoadBlockPart(4, 0)
+	part.Bytes[0] ^= 0xff
+	pbpart, err := part.ToProto()
+	require.NoError(t, err)
+	require.NoError(t, db.Set(bs.dbKeyLayout.CalcBlockPartKey(4, 0), mustEncode(pbpart)))
+
+	// Corrupt a block meta.
+	require.NoError(t, db.Set(bs.dbKeyLayout.CalcBlockMetaKey(1), []byte("garbage")))
+
+	problems, err = bs.VerifyIntegrity(1, 5)
+	require.NoError(t, err)
+	kinds := make(map[int64][]ProblemKind)
+	for _, problem := range problems {
+		kinds[problem.Height] = append(kinds[problem.Height], problem.Kind)
+	}
+	require.Equal(t, map[int64][]ProblemKind{
+		1: {ProblemCorrupted},
+		2: {ProblemMissingBlockPart},
+		3: {ProblemMissingCommit},
+		4: {ProblemHashMismatch},
+		5: {ProblemMissingCommit},
+	}, kinds)
+
+	// A commit for another block is a mismatch.
+	bz, err := db.Get(bs.dbKeyLayout.CalcBlockCommitKey(1))
+	require.NoError(t, err)
+	require.NoError(t, db.Set(bs.dbKeyLayout.CalcBlockCommitKey(2), bz))
+	problems, err = bs.VerifyIntegrity(2, 2)
+	require.NoError(t, err)
+	require.Len(t, problems, 2)
+	require.Equal(t, ProblemHashMismatch, problems[1].Kind)
+}
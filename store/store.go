@@ -1,6 +1,7 @@
 package store
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/cosmos/gogoproto/proto"
 	"github.com/go-kit/kit/metrics"
+	"github.com/google/orderedcode"
 	lru "github.com/hashicorp/golang-lru/v2"
 
 	dbm "github.com/cometbft/cometbft-db"
@@ -555,6 +557,156 @@ func (bs *BlockStore) PruneBlocks(height int64, state sm.State) (uint64, int64,
 	return pruned, evidencePoint, err
 }
 
+// PruneOrphanedBlockParts removes the block parts left at heights from from
+// (inclusive) to to (exclusive), which must be below the base of the store.
+// Such parts don't belong to any block of the store, e.g. parts of a block
+// whose meta was deleted before its parts, or parts beyond the number of parts
+// of the block, so PruneBlocks doesn't remove them. It returns the number of
+// parts removed.
+func (bs *BlockStore) PruneOrphanedBlockParts(from, to int64) (uint64, error) {
+	if from <= 0 || from > to {
+		return 0, fmt.Errorf("invalid height range [%d, %d)", from, to)
+	}
+	if base := bs.Base(); to > base {
+		return 0, fmt.Errorf("cannot prune block parts up to height %v, it is above base height %v", to, base)
+	}
+
+	defer addTimeSample(bs.metrics.BlockStoreAccessDurationSeconds.With("method", "prune_orphaned_block_parts"), time.Now())()
+
+	var keys [][]byte
+	for _, r := range bs.blockPartsRanges(from, to) {
+		rangeKeys, err := bs.collectKeys(r[0], r[1])
+		if err != nil {
+			return 0, err
+		}
+		keys = append(keys, rangeKeys...)
+	}
+
+	pruned := uint64(0)
+	batch := bs.db.NewBatch()
+	defer func() { batch.Close() }()
+	for i, key := range keys {
+		if err := batch.Delete(key); err != nil {
+			return pruned, err
+		}
+		// flush every 1000 parts to avoid batches becoming too large
+		if (i+1)%1000 == 0 {
+			if err := batch.WriteSync(); err != nil {
+				return pruned, err
+			}
+			pruned = uint64(i + 1)
+			batch.Close()
+			batch = bs.db.NewBatch()
+		}
+	}
+	if err := batch.WriteSync(); err != nil {
+		return pruned, err
+	}
+	return uint64(len(keys)), nil
+}
+
+// estimatePruneBytesSamples is the maximum number of heights whose sizes
+// EstimatePruneBytes reads, beyond which it extrapolates from a sample.
+const estimatePruneBytesSamples = 1000
+
+// EstimatePruneBytes returns an estimate of the disk space, in bytes, freed by
+// pruning the blocks from height from to height to, excluded, i.e. the sizes of
+// their metas, commits, seen commits, and parts, the latter being taken from
+// the block size recorded in the metas. Heights whose block is already pruned
+// count for nothing. Above estimatePruneBytesSamples heights, only evenly
+// spaced heights are read, and the total is extrapolated from them. It is an
+// upper bound, as PruneBlocks keeps the metas and commits needed to verify
+// evidence, and the size of the database on disk depends on its compression
+// and compactions.
+func (bs *BlockStore) EstimatePruneBytes(from, to int64) (uint64, error) {
+	if from <= 0 || from > to {
+		return 0, fmt.Errorf("invalid height range [%d, %d)", from, to)
+	}
+	from, to = max(from, bs.Base()), min(to, bs.Height()+1)
+	if from >= to {
+		return 0, nil
+	}
+
+	defer addTimeSample(bs.metrics.BlockStoreAccessDurationSeconds.With("method", "estimate_prune_bytes"), time.Now())()
+
+	heights := to - from
+	step := max(1, heights/estimatePruneBytesSamples)
+	var total, samples uint64
+	for h := from; h < to; h += step {
+		size, err := bs.storedBlockSize(h)
+		if err != nil {
+			return 0, err
+		}
+		total += size
+		samples++
+	}
+	if step == 1 {
+		return total, nil
+	}
+	return total * uint64(heights) / samples, nil
+}
+
+// storedBlockSize returns the number of bytes stored for the block at height,
+// or 0 if there is none.
+func (bs *BlockStore) storedBlockSize(height int64) (uint64, error) {
+	bz, err := bs.db.Get(bs.dbKeyLayout.CalcBlockMetaKey(height))
+	if err != nil || len(bz) == 0 {
+		return 0, err
+	}
+	pbbm := new(cmtproto.BlockMeta)
+	if err := proto.Unmarshal(bz, pbbm); err != nil {
+		return 0, fmt.Errorf("unmarshal to cmtproto.BlockMeta: %w", err)
+	}
+	size := uint64(len(bz)) + uint64(max(0, pbbm.BlockSize))
+	for _, key := range [][]byte{bs.dbKeyLayout.CalcBlockCommitKey(height), bs.dbKeyLayout.CalcSeenCommitKey(height)} {
+		bz, err := bs.db.Get(key)
+		if err != nil {
+			return 0, err
+		}
+		size += uint64(len(bz))
+	}
+	return size, nil
+}
+
+// blockPartsRanges returns the ranges of keys holding the block parts of the
+// heights in [from, to). The keys of the v1 layout aren't ordered by height, so
+// there is then a range per height.
+func (bs *BlockStore) blockPartsRanges(from, to int64) [][2][]byte {
+	if _, ok := bs.dbKeyLayout.(*v2Layout); ok {
+		start, err := orderedcode.Append(nil, prefixBlockPart, from)
+		if err != nil {
+			panic(err)
+		}
+		end, err := orderedcode.Append(nil, prefixBlockPart, to)
+		if err != nil {
+			panic(err)
+		}
+		return [][2][]byte{{start, end}}
+	}
+	ranges := make([][2][]byte, 0, to-from)
+	for h := from; h < to; h++ {
+		// The keys of the parts of height h are "P:h:index", so they sort
+		// before "P:h;".
+		prefix := fmt.Sprintf("P:%v:", h)
+		ranges = append(ranges, [2][]byte{[]byte(prefix), []byte(prefix[:len(prefix)-1] + ";")})
+	}
+	return ranges
+}
+
+// collectKeys returns a copy of the keys in [start, end).
+func (bs *BlockStore) collectKeys(start, end []byte) ([][]byte, error) {
+	it, err := bs.db.Iterator(start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var keys [][]byte
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, bytes.Clone(it.Key()))
+	}
+	return keys, it.Error()
+}
+
 // SaveBlock persists the given block, blockParts, and seenCommit to the underlying db.
 // blockParts: Must be parts of the block
 // seenCommit: The +2/3 precommits that were seen which committed at height.
@@ -775,6 +927,51 @@ func (bs *BlockStore) SaveSeenCommit(height int64, seenCommit *types.Commit) err
 	return bs.db.Set(bs.dbKeyLayout.CalcSeenCommitKey(height), seenCommitBytes)
 }
 
+// SaveSignedHeader saves the header and commit of a block below the base of the
+// store, without the block itself, so that they can be loaded with
+// LoadBlockMeta, LoadBlockMetaByHash and LoadBlockCommit. It is used by the
+// state sync reactor to backfill the heights needed to verify evidence after
+// bootstrapping the node. As the block parts are missing, the size and number
+// of transactions of the block meta are set to -1. The base and height of the
+// store are not changed. Saving a header that is already stored is a no-op.
+func (bs *BlockStore) SaveSignedHeader(sh *types.SignedHeader, blockID types.BlockID) error {
+	height := sh.Height
+	if base := bs.Base(); base > 0 && height >= base {
+		return fmt.Errorf("BlockStore can only save signed headers below its base %d, got %d", base, height)
+	}
+	if !bytes.Equal(sh.Hash(), blockID.Hash) {
+		return fmt.Errorf("signed header hash %X doesn't match block ID %v", sh.Hash(), blockID)
+	}
+	bz, err := bs.db.Get(bs.dbKeyLayout.CalcBlockMetaKey(height))
+	if err != nil {
+		return err
+	}
+	if len(bz) > 0 {
+		return nil
+	}
+
+	defer addTimeSample(bs.metrics.BlockStoreAccessDurationSeconds.With("method", "save_signed_header"), time.Now())()
+
+	blockMeta := &types.BlockMeta{
+		BlockID:   blockID,
+		BlockSize: -1,
+		Header:    *sh.Header,
+		NumTxs:    -1,
+	}
+	batch := bs.db.NewBatch()
+	defer batch.Close()
+	if err := batch.Set(bs.dbKeyLayout.CalcBlockMetaKey(height), mustEncode(blockMeta.ToProto())); err != nil {
+		return err
+	}
+	if err := batch.Set(bs.dbKeyLayout.CalcBlockHashKey(blockID.Hash), []byte(strconv.FormatInt(height, 10))); err != nil {
+		return err
+	}
+	if err := batch.Set(bs.dbKeyLayout.CalcBlockCommitKey(height), mustEncode(sh.Commit.ToProto())); err != nil {
+		return err
+	}
+	return batch.WriteSync()
+}
+
 func (bs *BlockStore) Close() error {
 	return bs.db.Close()
 }
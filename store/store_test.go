@@ -856,6 +856,116 @@ func TestPruneBlocks(t *testing.T) {
 	assert.Nil(t, meta)
 }
 
+func TestPruneOrphanedBlockParts(t *testing.T) {
+	for _, layout := range []string{"v1", "v2"} {
+		t.Run(layout, func(t *testing.T) {
+			config := test.ResetTestRoot("blockchain_reactor_test")
+			defer os.RemoveAll(config.RootDir)
+			stateStore := sm.NewStore(dbm.NewMemDB(), sm.StoreOptions{})
+			state, err := stateStore.LoadFromDBOrGenesisFile(config.GenesisFile())
+			require.NoError(t, err)
+			db := dbm.NewMemDB()
+			bs := NewBlockStore(db, WithDBKeyLayout(layout))
+			for h := int64(1); h <= 12; h++ {
+				block := state.MakeBlock(h, nil, new(types.Commit), nil, state.Validators.GetProposer().Address)
+				partSet, err := block.MakePartSet(types.BlockPartSizeBytes)
+				require.NoError(t, err)
+				bs.SaveBlockWithExtendedCommit(block, partSet, makeTestExtCommit(h, cmttime.Now()))
+			}
+			state.LastBlockHeight = 12
+			_, _, err = bs.PruneBlocks(6, state)
+			require.NoError(t, err)
+
+			// Parts left below the base, including at height 1, whose keys
+			// share a prefix with the ones of height 10 in the v1 layout, and
+			// an extra part of a block of the store at height 10.
+			orphans := [][2]int64{{2, 0}, {2, 1}, {5, 7}, {1, 10}}
+			for _, o := range orphans {
+				require.NoError(t, db.Set(bs.dbKeyLayout.CalcBlockPartKey(o[0], int(o[1])), []byte("part")))
+			}
+			extraKey := bs.dbKeyLayout.CalcBlockPartKey(10, 3)
+			require.NoError(t, db.Set(extraKey, []byte("part")))
+
+			_, err = bs.PruneOrphanedBlockParts(1, 7)
+			require.Error(t, err)
+			_, err = bs.PruneOrphanedBlockParts(0, 6)
+			require.Error(t, err)
+
+			pruned, err := bs.PruneOrphanedBlockParts(2, 6)
+			require.NoError(t, err)
+			require.EqualValues(t, 3, pruned)
+			pruned, err = bs.PruneOrphanedBlockParts(1, 6)
+			require.NoError(t, err)
+			require.EqualValues(t, 1, pruned)
+			for _, o := range orphans {
+				bz, err := db.Get(bs.dbKeyLayout.CalcBlockPartKey(o[0], int(o[1])))
+				require.NoError(t, err)
+				require.Nil(t, bz)
+			}
+
+			// The blocks of the store are left untouched.
+			bz, err := db.Get(extraKey)
+			require.NoError(t, err)
+			require.NotNil(t, bz)
+			for h := int64(6); h <= 12; h++ {
+				block, _ := bs.LoadBlock(h)
+				require.NotNil(t, block, "height %d", h)
+			}
+		})
+	}
+}
+
+func TestEstimatePruneBytes(t *testing.T) {
+	config := test.ResetTestRoot("blockchain_reactor_test")
+	defer os.RemoveAll(config.RootDir)
+	stateStore := sm.NewStore(dbm.NewMemDB(), sm.StoreOptions{})
+	state, err := stateStore.LoadFromDBOrGenesisFile(config.GenesisFile())
+	require.NoError(t, err)
+	bs := NewBlockStore(dbm.NewMemDB())
+	for h := int64(1); h <= 12; h++ {
+		block := state.MakeBlock(h, nil, new(types.Commit), nil, state.Validators.GetProposer().Address)
+		partSet, err := block.MakePartSet(types.BlockPartSizeBytes)
+		require.NoError(t, err)
+		bs.SaveBlockWithExtendedCommit(block, partSet, makeTestExtCommit(h, cmttime.Now()))
+	}
+
+	_, err = bs.EstimatePruneBytes(0, 6)
+	require.Error(t, err)
+	_, err = bs.EstimatePruneBytes(6, 5)
+	require.Error(t, err)
+
+	// The estimate of a range is the sum of the ones of its heights, each at
+	// least as large as the block.
+	var sum uint64
+	for h := int64(1); h < 6; h++ {
+		bytes, err := bs.EstimatePruneBytes(h, h+1)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, bytes, uint64(bs.LoadBlockMeta(h).BlockSize))
+		sum += bytes
+	}
+	bytes, err := bs.EstimatePruneBytes(1, 6)
+	require.NoError(t, err)
+	require.Equal(t, sum, bytes)
+
+	// Heights above the store count for nothing.
+	all, err := bs.EstimatePruneBytes(1, 13)
+	require.NoError(t, err)
+	above, err := bs.EstimatePruneBytes(1, 100)
+	require.NoError(t, err)
+	require.Equal(t, all, above)
+
+	// Pruned blocks count for nothing.
+	state.LastBlockHeight = 12
+	_, _, err = bs.PruneBlocks(6, state)
+	require.NoError(t, err)
+	bytes, err = bs.EstimatePruneBytes(1, 6)
+	require.NoError(t, err)
+	require.Zero(t, bytes)
+	bytes, err = bs.EstimatePruneBytes(1, 7)
+	require.NoError(t, err)
+	require.Positive(t, bytes)
+}
+
 func TestLoadBlockMeta(t *testing.T) {
 	bs, db := newInMemoryBlockStore()
 	height := int64(10)
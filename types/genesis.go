@@ -1,10 +1,12 @@
 package types
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
@@ -43,6 +45,10 @@ type GenesisDoc struct {
 	Validators      []GenesisValidator `json:"validators,omitempty"`
 	AppHash         cmtbytes.HexBytes  `json:"app_hash"`
 	AppState        json.RawMessage    `json:"app_state,omitempty"`
+
+	// appStateSection is the section of the genesis file holding the app
+	// state, if GenesisDocFromFileStreaming deferred reading it.
+	appStateSection *genesisFileSection
 }
 
 // SaveAs is a utility method for saving GenensisDoc as a JSON file.
@@ -135,3 +141,182 @@ func GenesisDocFromFile(genDocFile string) (*GenesisDoc, error) {
 	}
 	return genDoc, nil
 }
+
+// ------------------------------------------------------------
+// Stream genesis state from file
+
+// genesisFileSection is a section of a genesis file, along with the size and
+// modification time of the file when it was read, used to detect if it was
+// modified since.
+type genesisFileSection struct {
+	file    string
+	offset  int64
+	length  int64
+	size    int64
+	modTime time.Time
+}
+
+// GenesisDocFromFileStreaming is like GenesisDocFromFile, but it decodes the
+// genesis file incrementally and defers reading its app_state, so that
+// neither the file nor the app state are held in memory at once, which helps
+// with the very large genesis files of chains launched from a state export.
+// All the fields but app_state are decoded and validated eagerly. The app
+// state is only checked to be valid JSON, and is read from the genesis file
+// on demand with AppStateReader or LoadAppState, so the file must not be
+// modified or removed meanwhile. The AppState field of the returned genesis
+// doc is nil.
+func GenesisDocFromFileStreaming(genDocFile string) (*GenesisDoc, error) {
+	f, err := os.Open(genDocFile)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read GenesisDoc file: %w", err)
+	}
+	defer f.Close()
+	genDoc, err := genesisDocFromFileStreaming(f)
+	if err != nil {
+		return nil, fmt.Errorf("error reading GenesisDoc at %s: %w", genDocFile, err)
+	}
+	if genDoc.appStateSection != nil {
+		genDoc.appStateSection.file = genDocFile
+	}
+	return genDoc, nil
+}
+
+func genesisDocFromFileStreaming(f *os.File) (*GenesisDoc, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(f)
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object, got %v", tok)
+	}
+	fields := make(map[string]json.RawMessage)
+	var section *genesisFileSection
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := tok.(string) // keys of objects are always strings
+		if key != "app_state" {
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			fields[key] = value
+			continue
+		}
+		keyEnd := dec.InputOffset()
+		if err := skipJSONValue(dec); err != nil {
+			return nil, fmt.Errorf("invalid app_state: %w", err)
+		}
+		section = &genesisFileSection{length: dec.InputOffset(), size: info.Size(), modTime: info.ModTime()}
+		// The value starts after the whitespace and the colon following the key.
+		section.offset, err = skipJSONSeparator(io.NewSectionReader(f, keyEnd, section.length-keyEnd), keyEnd)
+		if err != nil {
+			return nil, err
+		}
+		section.length -= section.offset
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after the genesis doc")
+	}
+
+	// The fields other than app_state are small, so they are decoded at once.
+	jsonBlob, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	genDoc, err := GenesisDocFromJSON(jsonBlob)
+	if err != nil {
+		return nil, err
+	}
+	genDoc.appStateSection = section
+	return genDoc, nil
+}
+
+// skipJSONValue reads the next value from dec without decoding it.
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// skipJSONSeparator returns the offset of the first byte read from r that is
+// neither whitespace nor a colon, given that r starts at offset.
+func skipJSONSeparator(r io.Reader, offset int64) (int64, error) {
+	br := bufio.NewReader(r)
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\n', '\r', ':':
+			offset++
+		default:
+			return offset, nil
+		}
+	}
+}
+
+// AppStateReader returns a reader of the app_state of the genesis doc, which
+// must be closed. If GenesisDocFromFileStreaming deferred reading the app
+// state, it is read from the genesis file, and an error is returned if the
+// file was modified since.
+func (genDoc *GenesisDoc) AppStateReader() (io.ReadCloser, error) {
+	section := genDoc.appStateSection
+	if section == nil {
+		return io.NopCloser(bytes.NewReader(genDoc.AppState)), nil
+	}
+	f, err := os.Open(section.file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read GenesisDoc file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() != section.size || !info.ModTime().Equal(section.modTime) {
+		f.Close()
+		return nil, fmt.Errorf("genesis file %s was modified since it was loaded", section.file)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.NewSectionReader(f, section.offset, section.length), f}, nil
+}
+
+// LoadAppState returns the app_state of the genesis doc, reading it from the
+// genesis file if GenesisDocFromFileStreaming deferred reading it. Its result
+// is not kept, so that the app state is only held in memory while needed.
+func (genDoc *GenesisDoc) LoadAppState() (json.RawMessage, error) {
+	if genDoc.appStateSection == nil {
+		return genDoc.AppState, nil
+	}
+	r, err := genDoc.AppStateReader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
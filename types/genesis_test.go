@@ -1,7 +1,9 @@
 package types
 
 import (
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -153,6 +155,73 @@ func TestGenesisSaveAs(t *testing.T) {
 	assert.Equal(t, genDoc2.Validators, genDoc.Validators)
 }
 
+func TestGenesisDocFromFileStreaming(t *testing.T) {
+	appState := `{"accounts": [{"owner": "Bob", "coins": [1, 2]}], "wasm": "AAAA\"}"}`
+	genDocBytes := []byte(`{
+		"genesis_time": "2024-01-01T00:00:00Z",
+		"chain_id": "test-chain-QDKdJr",
+		"initial_height": "1000",
+		"validators": [{
+			"pub_key":{"type":"tendermint/PubKeyEd25519","value":"AT/+aaL1eB0477Mud9JMm8Sh8BIvOYlPGC9KkIUmFaE="},
+			"power":"10",
+			"name":""
+		}],
+		"app_state" :
+			` + appState + `,
+		"app_hash":""
+	}`)
+	genDocFile := filepath.Join(t.TempDir(), "genesis.json")
+	require.NoError(t, os.WriteFile(genDocFile, genDocBytes, 0o600))
+
+	// All the fields but the app state are loaded and validated.
+	genDoc, err := GenesisDocFromFileStreaming(genDocFile)
+	require.NoError(t, err)
+	expected, err := GenesisDocFromFile(genDocFile)
+	require.NoError(t, err)
+	require.Nil(t, genDoc.AppState)
+	genDoc.appStateSection = nil
+	expected.AppState = nil
+	require.Equal(t, expected, genDoc)
+
+	// The app state is read from the file on demand.
+	genDoc, err = GenesisDocFromFileStreaming(genDocFile)
+	require.NoError(t, err)
+	loaded, err := genDoc.LoadAppState()
+	require.NoError(t, err)
+	require.Equal(t, appState, string(loaded))
+	r, err := genDoc.AppStateReader()
+	require.NoError(t, err)
+	read, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, appState, string(read))
+
+	// The app state can't be read once the file was modified.
+	require.NoError(t, os.WriteFile(genDocFile, append(genDocBytes, ' '), 0o600))
+	_, err = genDoc.LoadAppState()
+	require.Error(t, err)
+
+	// A genesis doc without app state has an empty one.
+	require.NoError(t, os.WriteFile(genDocFile, []byte(`{"chain_id":"mychain"}`), 0o600))
+	genDoc, err = GenesisDocFromFileStreaming(genDocFile)
+	require.NoError(t, err)
+	loaded, err = genDoc.LoadAppState()
+	require.NoError(t, err)
+	require.Empty(t, loaded)
+
+	for _, bad := range []string{
+		`{"chain_id":"mychain","app_state":{"a":}}`, // invalid app state
+		`{"chain_id":"mychain","app_state":{}`,      // truncated
+		`{"chain_id":"mychain"} {}`,                 // trailing data
+		`{"app_state":{}}`,                          // missing chain_id
+		`[]`,                                        // not an object
+	} {
+		require.NoError(t, os.WriteFile(genDocFile, []byte(bad), 0o600))
+		_, err = GenesisDocFromFileStreaming(genDocFile)
+		require.Error(t, err, bad)
+	}
+}
+
 func TestGenesisValidatorHash(t *testing.T) {
 	genDoc := randomGenesisDoc()
 	assert.NotEmpty(t, genDoc.ValidatorHash())
@@ -12,8 +12,23 @@ import (
 
 const batchVerifyThreshold = 2
 
+// shouldBatchVerify returns true if the signatures of the commit can be batch
+// verified, i.e. if there are enough of them and all the validators have keys
+// of the same type, which supports batch verification.
 func shouldBatchVerify(vals *ValidatorSet, commit *Commit) bool {
-	return len(commit.Signatures) >= batchVerifyThreshold && batch.SupportsBatchVerifier(vals.GetProposer().PubKey)
+	if len(commit.Signatures) < batchVerifyThreshold {
+		return false
+	}
+	pubKey := vals.GetProposer().PubKey
+	if !batch.SupportsBatchVerifier(pubKey) {
+		return false
+	}
+	for _, val := range vals.Validators {
+		if val.PubKey.Type() != pubKey.Type() {
+			return false
+		}
+	}
+	return true
 }
 
 // VerifyCommit verifies +2/3 of the set had signed the given commit.
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/cometbft/cometbft/crypto/secp256k1"
 	cmtmath "github.com/cometbft/cometbft/libs/math"
 	cmttime "github.com/cometbft/cometbft/types/time"
 )
@@ -180,6 +181,43 @@ func TestValidatorSet_VerifyCommit_CheckAllSignatures(t *testing.T) {
 	}
 }
 
+// Commits of validator sets with keys of different types are verified one
+// signature at a time, as they can't be batch verified.
+func TestValidatorSet_VerifyCommit_MixedKeyTypes(t *testing.T) {
+	var (
+		chainID = "test_chain_id"
+		h       = int64(3)
+		blockID = makeBlockIDRandom()
+	)
+
+	privVals := []PrivValidator{NewMockPV(), NewMockPV(), NewMockPV(), NewMockPVWithParams(secp256k1.GenPrivKey(), false, false)}
+	validators := make([]*Validator, len(privVals))
+	byAddress := make(map[string]PrivValidator, len(privVals))
+	for i, val := range privVals {
+		pubKey, err := val.GetPubKey()
+		require.NoError(t, err)
+		// Make sure that the proposer has a key that supports batch
+		// verification.
+		validators[i] = NewValidator(pubKey, 20-int64(i/3)*10)
+		byAddress[string(pubKey.Address())] = val
+	}
+	valSet := NewValidatorSet(validators)
+	// Sign in the order of the validator set, which is sorted by voting power
+	// first.
+	vals := make([]PrivValidator, len(privVals))
+	for i, val := range valSet.Validators {
+		vals[i] = byAddress[string(val.Address)]
+	}
+	voteSet := NewVoteSet(chainID, h, 0, PrecommitType, valSet)
+
+	extCommit, err := MakeExtCommit(blockID, h, 0, voteSet, vals, cmttime.Now(), false)
+	require.NoError(t, err)
+	commit := extCommit.ToCommit()
+	require.NoError(t, valSet.VerifyCommit(chainID, blockID, h, commit))
+	require.NoError(t, valSet.VerifyCommitLight(chainID, blockID, h, commit))
+	require.NoError(t, valSet.VerifyCommitLightTrusting(chainID, commit, cmtmath.Fraction{Numerator: 1, Denominator: 3}))
+}
+
 func TestValidatorSet_VerifyCommitLight_ReturnsAsSoonAsMajOfVotingPowerSignedIffNotAllSigs(t *testing.T) {
 	var (
 		chainID = "test_chain_id"
@@ -306,3 +344,33 @@ func TestValidatorSet_VerifyCommitLightTrustingErrorsOnOverflow(t *testing.T) {
 		assert.Contains(t, err.Error(), "int64 overflow")
 	}
 }
+
+func BenchmarkValidatorSet_VerifyCommit(b *testing.B) {
+	var (
+		chainID = "test_chain_id"
+		h       = int64(3)
+		blockID = makeBlockIDRandom()
+	)
+	for _, n := range []int{10, 100, 1000} {
+		voteSet, valSet, vals := randVoteSet(h, 0, PrecommitType, n, 10, false)
+		extCommit, err := MakeExtCommit(blockID, h, 0, voteSet, vals, cmttime.Now(), false)
+		require.NoError(b, err)
+		commit := extCommit.ToCommit()
+		votingPowerNeeded := valSet.TotalVotingPower() * 2 / 3
+		ignore := func(c CommitSig) bool { return c.BlockIDFlag != BlockIDFlagCommit }
+		count := func(c CommitSig) bool { return c.BlockIDFlag == BlockIDFlagCommit }
+
+		b.Run(strconv.Itoa(n)+"/batch", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				err := verifyCommitBatch(chainID, valSet, commit, votingPowerNeeded, ignore, count, true, true)
+				require.NoError(b, err)
+			}
+		})
+		b.Run(strconv.Itoa(n)+"/single", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				err := verifyCommitSingle(chainID, valSet, commit, votingPowerNeeded, ignore, count, true, true)
+				require.NoError(b, err)
+			}
+		})
+	}
+}